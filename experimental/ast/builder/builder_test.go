@@ -0,0 +1,184 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/ast/builder"
+	"github.com/bufbuild/protocompile/experimental/ast/printer"
+	"github.com/bufbuild/protocompile/experimental/incremental"
+	"github.com/bufbuild/protocompile/experimental/incremental/queries"
+	"github.com/bufbuild/protocompile/experimental/ir"
+	"github.com/bufbuild/protocompile/experimental/parser"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/source"
+)
+
+const want = `syntax = "proto3";
+package foo.v1;
+
+import "google/protobuf/descriptor.proto";
+import "google/protobuf/timestamp.proto";
+
+option java_package = "com.foo.v1";
+option optimize_for = SPEED;
+
+message Foo {
+  option deprecated = true;
+  string name = 1;
+  repeated int32 ids = 2 [packed = true, deprecated = false];
+  map<string, google.protobuf.Timestamp> times = 3;
+  double ratio = 4 [(note) = {text: "ratio" tags: ["a", "b"]}];
+  oneof kind {
+    string s = 5;
+    int64 i = 6;
+  }
+  message Inner {
+    bool ok = 1;
+  }
+  reserved 10 to 12;
+  reserved 15;
+  reserved 100 to max;
+  reserved "old", "older";
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+  STATUS_NEG = -1 [deprecated = true];
+}
+
+service FooService {
+  rpc GetFoo(Foo) returns (Foo);
+  rpc Watch(Foo) returns (stream Foo) {
+    option idempotency_level = NO_SIDE_EFFECTS;
+  }
+}
+
+message Note {
+  string text = 1;
+  repeated string tags = 2;
+}
+
+extend google.protobuf.FieldOptions {
+  Note note = 50000;
+}
+`
+
+func build() *builder.File {
+	f := builder.NewFile("foo/v1/foo.proto").
+		Syntax("proto3").
+		Package("foo.v1").
+		Import("google/protobuf/descriptor.proto").
+		Import("google/protobuf/timestamp.proto").
+		Option("java_package", builder.String("com.foo.v1")).
+		Option("optimize_for", builder.Ident("SPEED"))
+
+	m := f.Message("Foo").Option("deprecated", builder.Bool(true))
+	m.Field("string", "name", 1)
+	m.Field("repeated int32", "ids", 2).
+		Option("packed", builder.Bool(true)).
+		Option("deprecated", builder.Bool(false))
+	m.Field("map<string, google.protobuf.Timestamp>", "times", 3)
+	m.Field("double", "ratio", 4).Option("(note)", builder.Dict(
+		builder.Entry{Name: "text", Value: builder.String("ratio")},
+		builder.Entry{Name: "tags", Value: builder.List(builder.String("a"), builder.String("b"))},
+	))
+	o := m.Oneof("kind")
+	o.Field("string", "s", 5)
+	o.Field("int64", "i", 6)
+	m.Message("Inner").Field("bool", "ok", 1)
+	m.Reserved(10, 12).
+		Reserved(15, 15).
+		Reserved(100, builder.Max).
+		ReservedNames("old", "older")
+
+	e := f.Enum("Status")
+	e.Value("STATUS_UNSPECIFIED", 0)
+	e.Value("STATUS_NEG", -1).Option("deprecated", builder.Bool(true))
+
+	s := f.Service("FooService")
+	s.Method("GetFoo", "Foo", "Foo")
+	s.Method("Watch", "Foo", "stream Foo").
+		Option("idempotency_level", builder.Ident("NO_SIDE_EFFECTS"))
+
+	n := f.Message("Note")
+	n.Field("string", "text", 1)
+	n.Field("repeated string", "tags", 2)
+	f.Extend("google.protobuf.FieldOptions").Field("Note", "note", 50000)
+	return f
+}
+
+func TestBuild(t *testing.T) {
+	t.Parallel()
+
+	file := build().AST()
+	got, err := printer.PrintFile(printer.Options{Format: true, Formatting: printer.Default()}, file)
+	require.NoError(t, err)
+	assert.Equal(t, want, got)
+
+	// The printed file must parse cleanly.
+	r := new(report.Report)
+	parser.Parse(file.Path(), source.NewFile(file.Path(), got), r)
+	assertNoErrors(t, r)
+
+	// The AST itself must lower cleanly, resolving the imports from the
+	// well-known types.
+	session := new(ir.Session)
+	exec := incremental.New()
+	importer := func(_ int, path string, _ ast.DeclImport) (*ir.File, error) {
+		results, _, err := incremental.Run(context.Background(), exec, queries.IR{
+			Opener:  source.WKTs(),
+			Session: session,
+			Path:    path,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return results[0].Value, results[0].Fatal
+	}
+
+	r = new(report.Report)
+	session.Lower(file, r, func(n int, path string, decl ast.DeclImport) (*ir.File, error) {
+		if n == -1 {
+			path = "google/protobuf/descriptor.proto"
+		}
+		return importer(n, path, decl)
+	})
+	assertNoErrors(t, r)
+}
+
+func TestInvalidNames(t *testing.T) {
+	t.Parallel()
+
+	f := builder.NewFile("test.proto")
+	assert.Panics(t, func() { f.Message("") })
+	assert.Panics(t, func() { f.Message("foo.Bar") })
+	assert.Panics(t, func() { f.Message("1Foo") })
+	assert.Panics(t, func() { f.Message("Foo").Field("map<string", "x", 1) })
+	assert.Panics(t, func() { f.Option("(foo", builder.Bool(true)) })
+}
+
+func assertNoErrors(t *testing.T, r *report.Report) {
+	t.Helper()
+	for _, d := range r.Diagnostics {
+		assert.Greater(t, d.Level(), report.Error, "%v", d)
+	}
+}
@@ -0,0 +1,40 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package builder provides a fluent API for synthesizing Protobuf ASTs from
+// scratch, such as when generating .proto files from another IDL.
+//
+// A [File] is the entry point. Its methods add file-level declarations and
+// return builders for the definitions they create, which in turn add nested
+// declarations:
+//
+//	f := builder.NewFile("foo/v1/foo.proto").
+//		Syntax("proto3").
+//		Package("foo.v1")
+//	m := f.Message("Foo")
+//	m.Field("string", "name", 1)
+//	m.Field("repeated int32", "ids", 2).Option("packed", builder.Bool(true))
+//	f.Service("FooService").Method("GetFoo", "Foo", "Foo")
+//
+// The result, returned by [File.AST], consists entirely of synthetic tokens.
+// It can be rendered with the
+// [github.com/bufbuild/protocompile/experimental/ast/printer] package in
+// format mode, or lowered with
+// [github.com/bufbuild/protocompile/experimental/ir.Session.Lower] like a
+// parsed file.
+//
+// Names, types, and option names are given as strings in Protobuf syntax, such
+// as "map<string, foo.Bar>" or "(buf.validate.field).string.min_len". Invalid
+// names are programming errors, and cause a panic.
+package builder
@@ -0,0 +1,90 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/token/keyword"
+)
+
+// Enum builds an enum definition.
+type Enum struct {
+	file *ast.File
+	decl ast.DeclDef
+}
+
+func newEnum(file *ast.File, name string) *Enum {
+	return &Enum{file: file, decl: newDef(file, keyword.Enum, name)}
+}
+
+// Decl returns the enum's definition.
+func (e *Enum) Decl() ast.DeclDef {
+	return e.decl
+}
+
+// Option adds an option to the enum, such as `option allow_alias = true;`.
+func (e *Enum) Option(name string, value Value) *Enum {
+	e.add(newOption(e.file, name, value).AsAny())
+	return e
+}
+
+// Value adds an enum value and returns a builder for it.
+func (e *Enum) Value(name string, number int32) *EnumValue {
+	stream := e.file.Stream()
+	decl := e.file.Nodes().NewDeclDef(ast.DeclDefArgs{
+		Name:      newName(e.file, name),
+		Equals:    stream.NewPunct(keyword.Assign.String()),
+		Value:     Int(int64(number)).expr(e.file),
+		Semicolon: stream.NewPunct(keyword.Semi.String()),
+	})
+	e.add(decl.AsAny())
+	return &EnumValue{file: e.file, decl: decl}
+}
+
+// Reserved adds a reserved range of enum numbers. If start == end, the range
+// contains a single number. Pass [Max] as end for an open range.
+func (e *Enum) Reserved(start, end int32) *Enum {
+	e.add(newRange(e.file, keyword.Reserved, start, end).AsAny())
+	return e
+}
+
+// ReservedNames adds a reserved declaration for the given value names.
+func (e *Enum) ReservedNames(names ...string) *Enum {
+	e.add(newReservedNames(e.file, names).AsAny())
+	return e
+}
+
+func (e *Enum) add(decl ast.DeclAny) {
+	seq.Append(e.decl.Body().Decls(), decl)
+}
+
+// EnumValue builds an enum value definition.
+type EnumValue struct {
+	file *ast.File
+	decl ast.DeclDef
+}
+
+// Decl returns the enum value's definition.
+func (v *EnumValue) Decl() ast.DeclDef {
+	return v.decl
+}
+
+// Option adds a compact option to the enum value, such as
+// `[deprecated = true]`.
+func (v *EnumValue) Option(name string, value Value) *EnumValue {
+	addCompactOption(v.file, v.decl, name, value)
+	return v
+}
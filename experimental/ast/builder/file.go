@@ -0,0 +1,182 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/source"
+	"github.com/bufbuild/protocompile/experimental/token"
+	"github.com/bufbuild/protocompile/experimental/token/keyword"
+)
+
+// File builds an [ast.File].
+type File struct {
+	file *ast.File
+}
+
+// NewFile returns a builder for an empty file with the given path.
+func NewFile(path string) *File {
+	stream := &token.Stream{File: source.NewFile(path, "")}
+	return &File{file: ast.New(path, stream)}
+}
+
+// AST returns the file built so far.
+func (f *File) AST() *ast.File {
+	return f.file
+}
+
+// Syntax adds a syntax declaration, such as `syntax = "proto3";`.
+func (f *File) Syntax(syntax string) *File {
+	f.syntax(keyword.Syntax, syntax)
+	return f
+}
+
+// Edition adds an edition declaration, such as `edition = "2023";`.
+func (f *File) Edition(edition string) *File {
+	f.syntax(keyword.Edition, edition)
+	return f
+}
+
+func (f *File) syntax(kw keyword.Keyword, value string) {
+	stream := f.file.Stream()
+	decl := f.file.Nodes().NewDeclSyntax(ast.DeclSyntaxArgs{
+		Keyword:   stream.NewIdent(kw.String()),
+		Equals:    stream.NewPunct(keyword.Assign.String()),
+		Value:     String(value).expr(f.file),
+		Semicolon: stream.NewPunct(keyword.Semi.String()),
+	})
+	f.add(decl.AsAny())
+}
+
+// Package adds a package declaration.
+func (f *File) Package(name string) *File {
+	stream := f.file.Stream()
+	decl := f.file.Nodes().NewDeclPackage(ast.DeclPackageArgs{
+		Keyword:   stream.NewIdent(keyword.Package.String()),
+		Path:      newPath(f.file, name),
+		Semicolon: stream.NewPunct(keyword.Semi.String()),
+	})
+	f.add(decl.AsAny())
+	return f
+}
+
+// Import adds an import declaration.
+func (f *File) Import(path string) *File {
+	f.imports(path)
+	return f
+}
+
+// ImportPublic adds a public import declaration.
+func (f *File) ImportPublic(path string) *File {
+	f.imports(path, keyword.Public)
+	return f
+}
+
+// ImportWeak adds a weak import declaration.
+func (f *File) ImportWeak(path string) *File {
+	f.imports(path, keyword.Weak)
+	return f
+}
+
+func (f *File) imports(path string, modifiers ...keyword.Keyword) {
+	stream := f.file.Stream()
+	args := ast.DeclImportArgs{
+		Keyword:    stream.NewIdent(keyword.Import.String()),
+		ImportPath: String(path).expr(f.file),
+		Semicolon:  stream.NewPunct(keyword.Semi.String()),
+	}
+	for _, kw := range modifiers {
+		args.Modifiers = append(args.Modifiers, stream.NewIdent(kw.String()))
+	}
+	f.add(f.file.Nodes().NewDeclImport(args).AsAny())
+}
+
+// Option adds a file option, such as `option java_package = "foo";`.
+func (f *File) Option(name string, value Value) *File {
+	f.add(newOption(f.file, name, value).AsAny())
+	return f
+}
+
+// Message adds a message and returns a builder for it.
+func (f *File) Message(name string) *Message {
+	m := newMessage(f.file, name)
+	f.add(m.decl.AsAny())
+	return m
+}
+
+// Enum adds an enum and returns a builder for it.
+func (f *File) Enum(name string) *Enum {
+	e := newEnum(f.file, name)
+	f.add(e.decl.AsAny())
+	return e
+}
+
+// Service adds a service and returns a builder for it.
+func (f *File) Service(name string) *Service {
+	s := &Service{file: f.file, decl: newDef(f.file, keyword.Service, name)}
+	f.add(s.decl.AsAny())
+	return s
+}
+
+// Extend adds an extend block for the given message and returns a builder
+// for it.
+func (f *File) Extend(extendee string) *Extend {
+	x := newExtend(f.file, extendee)
+	f.add(x.decl.AsAny())
+	return x
+}
+
+func (f *File) add(decl ast.DeclAny) {
+	seq.Append(f.file.Decls(), decl)
+}
+
+// newDef creates a definition introduced by kw, with an empty body.
+func newDef(file *ast.File, kw keyword.Keyword, name string) ast.DeclDef {
+	return newDefPath(file, kw, newName(file, name))
+}
+
+func newDefPath(file *ast.File, kw keyword.Keyword, name ast.Path) ast.DeclDef {
+	return file.Nodes().NewDeclDef(ast.DeclDefArgs{
+		Keyword: file.Stream().NewIdent(kw.String()),
+		Name:    name,
+		Body:    newBody(file),
+	})
+}
+
+// newBody creates an empty {} body.
+func newBody(file *ast.File) ast.DeclBody {
+	return file.Nodes().NewDeclBody(newFused(file, keyword.LBrace, keyword.RBrace))
+}
+
+// newOption creates an option declaration.
+func newOption(file *ast.File, name string, value Value) ast.DeclDef {
+	stream := file.Stream()
+	return file.Nodes().NewDeclDef(ast.DeclDefArgs{
+		Keyword:   stream.NewIdent(keyword.Option.String()),
+		Name:      newPath(file, name),
+		Equals:    stream.NewPunct(keyword.Assign.String()),
+		Value:     value.expr(file),
+		Semicolon: stream.NewPunct(keyword.Semi.String()),
+	})
+}
+
+// newFused creates a synthetic pair of brackets, returning the opening one.
+func newFused(file *ast.File, open, close keyword.Keyword) token.Token { //nolint:predeclared,revive // For close.
+	stream := file.Stream()
+	openTok := stream.NewPunct(open.String())
+	stream.NewFused(openTok, stream.NewPunct(close.String()))
+	return openTok
+}
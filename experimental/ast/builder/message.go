@@ -0,0 +1,262 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/token/keyword"
+)
+
+// Max may be passed as the end of a range to [Message.Reserved],
+// [Message.Extensions], or [Enum.Reserved] to produce the `max` keyword.
+const Max = math.MaxInt32
+
+// Message builds a message definition.
+type Message struct {
+	file *ast.File
+	decl ast.DeclDef
+}
+
+func newMessage(file *ast.File, name string) *Message {
+	return &Message{file: file, decl: newDef(file, keyword.Message, name)}
+}
+
+// Decl returns the message's definition.
+func (m *Message) Decl() ast.DeclDef {
+	return m.decl
+}
+
+// Option adds an option to the message, such as `option deprecated = true;`.
+func (m *Message) Option(name string, value Value) *Message {
+	m.add(newOption(m.file, name, value).AsAny())
+	return m
+}
+
+// Field adds a field with the given type, name, and number, and returns a
+// builder for it.
+//
+// The type may include a label or be a map, such as "repeated string" or
+// "map<string, int32>".
+func (m *Message) Field(ty, name string, number int32) *Field {
+	f := newField(m.file, ty, name, number)
+	m.add(f.decl.AsAny())
+	return f
+}
+
+// Message adds a nested message and returns a builder for it.
+func (m *Message) Message(name string) *Message {
+	n := newMessage(m.file, name)
+	m.add(n.decl.AsAny())
+	return n
+}
+
+// Enum adds a nested enum and returns a builder for it.
+func (m *Message) Enum(name string) *Enum {
+	e := newEnum(m.file, name)
+	m.add(e.decl.AsAny())
+	return e
+}
+
+// Oneof adds a oneof and returns a builder for it.
+func (m *Message) Oneof(name string) *Oneof {
+	o := &Oneof{file: m.file, decl: newDef(m.file, keyword.Oneof, name)}
+	m.add(o.decl.AsAny())
+	return o
+}
+
+// Extend adds a nested extend block for the given message and returns a
+// builder for it.
+func (m *Message) Extend(extendee string) *Extend {
+	x := newExtend(m.file, extendee)
+	m.add(x.decl.AsAny())
+	return x
+}
+
+// Reserved adds a reserved range of field numbers. If start == end, the range
+// contains a single number. Pass [Max] as end for an open range.
+func (m *Message) Reserved(start, end int32) *Message {
+	m.add(newRange(m.file, keyword.Reserved, start, end).AsAny())
+	return m
+}
+
+// ReservedNames adds a reserved declaration for the given field names.
+func (m *Message) ReservedNames(names ...string) *Message {
+	m.add(newReservedNames(m.file, names).AsAny())
+	return m
+}
+
+// Extensions adds an extension range. If start == end, the range contains a
+// single number. Pass [Max] as end for an open range.
+func (m *Message) Extensions(start, end int32) *Message {
+	m.add(newRange(m.file, keyword.Extensions, start, end).AsAny())
+	return m
+}
+
+func (m *Message) add(decl ast.DeclAny) {
+	seq.Append(m.decl.Body().Decls(), decl)
+}
+
+// Field builds a field definition.
+type Field struct {
+	file *ast.File
+	decl ast.DeclDef
+}
+
+func newField(file *ast.File, ty, name string, number int32) *Field {
+	stream := file.Stream()
+	decl := file.Nodes().NewDeclDef(ast.DeclDefArgs{
+		Type:      newType(file, ty),
+		Name:      newName(file, name),
+		Equals:    stream.NewPunct(keyword.Assign.String()),
+		Value:     Int(int64(number)).expr(file),
+		Semicolon: stream.NewPunct(keyword.Semi.String()),
+	})
+	return &Field{file: file, decl: decl}
+}
+
+// Decl returns the field's definition.
+func (f *Field) Decl() ast.DeclDef {
+	return f.decl
+}
+
+// Option adds a compact option to the field, such as `[deprecated = true]`.
+func (f *Field) Option(name string, value Value) *Field {
+	addCompactOption(f.file, f.decl, name, value)
+	return f
+}
+
+// Oneof builds a oneof definition.
+type Oneof struct {
+	file *ast.File
+	decl ast.DeclDef
+}
+
+// Decl returns the oneof's definition.
+func (o *Oneof) Decl() ast.DeclDef {
+	return o.decl
+}
+
+// Option adds an option to the oneof.
+func (o *Oneof) Option(name string, value Value) *Oneof {
+	seq.Append(o.decl.Body().Decls(), newOption(o.file, name, value).AsAny())
+	return o
+}
+
+// Field adds a field to the oneof and returns a builder for it.
+func (o *Oneof) Field(ty, name string, number int32) *Field {
+	f := newField(o.file, ty, name, number)
+	seq.Append(o.decl.Body().Decls(), f.decl.AsAny())
+	return f
+}
+
+// Extend builds an extend block.
+type Extend struct {
+	file *ast.File
+	decl ast.DeclDef
+}
+
+func newExtend(file *ast.File, extendee string) *Extend {
+	return &Extend{file: file, decl: newDefPath(file, keyword.Extend, newPath(file, extendee))}
+}
+
+// Decl returns the extend block's definition.
+func (x *Extend) Decl() ast.DeclDef {
+	return x.decl
+}
+
+// Field adds an extension field and returns a builder for it.
+func (x *Extend) Field(ty, name string, number int32) *Field {
+	f := newField(x.file, ty, name, number)
+	seq.Append(x.decl.Body().Decls(), f.decl.AsAny())
+	return f
+}
+
+// addCompactOption appends an option to def's compact options, creating
+// them if necessary.
+func addCompactOption(file *ast.File, def ast.DeclDef, name string, value Value) {
+	stream := file.Stream()
+	opts := def.Options()
+	if opts.IsZero() {
+		opts = file.Nodes().NewCompactOptions(newFused(file, keyword.LBracket, keyword.RBracket))
+		def.SetOptions(opts)
+	}
+
+	entries := opts.Entries()
+	if n := entries.Len(); n > 0 {
+		entries.SetComma(n-1, stream.NewPunct(keyword.Comma.String()))
+	}
+	seq.Append(entries, ast.Option{
+		Path:   newPath(file, name),
+		Equals: stream.NewPunct(keyword.Assign.String()),
+		Value:  value.expr(file),
+	})
+}
+
+// newRange creates a reserved or extensions declaration for a single range.
+func newRange(file *ast.File, kw keyword.Keyword, start, end int32) ast.DeclRange {
+	if start > end {
+		panic(fmt.Sprintf("protocompile/ast/builder: invalid range %d to %d", start, end))
+	}
+
+	stream := file.Stream()
+	decl := file.Nodes().NewDeclRange(ast.DeclRangeArgs{
+		Keyword:   stream.NewIdent(kw.String()),
+		Semicolon: stream.NewPunct(keyword.Semi.String()),
+	})
+
+	expr := Int(int64(start)).expr(file)
+	if start != end {
+		hi := Int(int64(end))
+		if end == Max {
+			hi = Ident(keyword.Max.String())
+		}
+		expr = file.Nodes().NewExprRange(ast.ExprRangeArgs{
+			Start: expr,
+			To:    stream.NewIdent(keyword.To.String()),
+			End:   hi.expr(file),
+		}).AsAny()
+	}
+	seq.Append(decl.Ranges(), expr)
+	return decl
+}
+
+// newReservedNames creates a reserved declaration for a list of names, which
+// are spelled as strings or identifiers depending on the file's syntax.
+func newReservedNames(file *ast.File, names []string) ast.DeclRange {
+	stream := file.Stream()
+	decl := file.Nodes().NewDeclRange(ast.DeclRangeArgs{
+		Keyword:   stream.NewIdent(keyword.Reserved.String()),
+		Semicolon: stream.NewPunct(keyword.Semi.String()),
+	})
+
+	ranges := decl.Ranges()
+	for i, name := range names {
+		mustIdent(name)
+		if i > 0 {
+			ranges.SetComma(i-1, stream.NewPunct(keyword.Comma.String()))
+		}
+		value := String(name)
+		if file.Syntax().IsEdition() {
+			// Editions spell reserved names as identifiers.
+			value = Ident(name)
+		}
+		seq.Append(ranges, value.expr(file))
+	}
+	return decl
+}
@@ -0,0 +1,144 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/token"
+	"github.com/bufbuild/protocompile/experimental/token/keyword"
+)
+
+// newName creates the path for a declared name, which must be a single
+// identifier.
+func newName(file *ast.File, name string) ast.Path {
+	ident := file.Stream().NewIdent(mustIdent(name))
+	return file.Nodes().NewPath(file.Nodes().NewPathComponent(token.Zero, ident))
+}
+
+// newPath creates a path such as "foo.Bar", ".foo.Bar", or
+// "(foo.bar).baz".
+func newPath(file *ast.File, text string) ast.Path {
+	stream, nodes := file.Stream(), file.Nodes()
+
+	var components []ast.PathComponent
+	rest := text
+	for first := true; first || rest != ""; first = false {
+		sep := token.Zero
+		if after, ok := strings.CutPrefix(rest, "."); ok {
+			sep = stream.NewPunct(keyword.Dot.String())
+			rest = after
+		} else if !first {
+			panic(fmt.Sprintf("protocompile/ast/builder: invalid path %q", text))
+		}
+
+		if after, ok := strings.CutPrefix(rest, "("); ok {
+			inner, after, ok := strings.Cut(after, ")")
+			if !ok {
+				panic(fmt.Sprintf("protocompile/ast/builder: invalid path %q", text))
+			}
+			components = append(components, nodes.NewExtensionComponent(sep, newPath(file, inner)))
+			rest = after
+			continue
+		}
+
+		name := rest
+		if i := strings.IndexAny(rest, ".("); i >= 0 {
+			name, rest = rest[:i], rest[i:]
+		} else {
+			rest = ""
+		}
+		components = append(components, nodes.NewPathComponent(sep, stream.NewIdent(mustIdent(name))))
+	}
+	return nodes.NewPath(components...)
+}
+
+// newType creates a type such as "foo.Bar", "repeated int32", or
+// "map<string, foo.Bar>".
+func newType(file *ast.File, text string) ast.TypeAny {
+	text = strings.TrimSpace(text)
+	if prefix, rest, ok := strings.Cut(text, " "); ok {
+		switch kw := keyword.Lookup(prefix); kw {
+		case keyword.Optional, keyword.Required, keyword.Repeated, keyword.Stream:
+			return file.Nodes().NewTypePrefixed(ast.TypePrefixedArgs{
+				Prefix: file.Stream().NewIdent(kw.String()),
+				Type:   newType(file, rest),
+			}).AsAny()
+		}
+	}
+
+	name, args, ok := strings.Cut(text, "<")
+	if !ok {
+		return ast.TypePath{Path: newPath(file, text)}.AsAny()
+	}
+	args, ok = strings.CutSuffix(args, ">")
+	if !ok {
+		panic(fmt.Sprintf("protocompile/ast/builder: invalid type %q", text))
+	}
+
+	generic := file.Nodes().NewTypeGeneric(ast.TypeGenericArgs{
+		Path:          newPath(file, strings.TrimSpace(name)),
+		AngleBrackets: newFused(file, keyword.Lt, keyword.Gt),
+	})
+	list := generic.Args()
+	for i, arg := range splitArgs(args) {
+		if i > 0 {
+			list.SetComma(i-1, file.Stream().NewPunct(keyword.Comma.String()))
+		}
+		seq.Append(list, newType(file, arg))
+	}
+	return generic.AsAny()
+}
+
+// splitArgs splits a comma-separated list of type arguments, ignoring commas
+// nested inside of angle brackets.
+func splitArgs(text string) []string {
+	var args []string
+	depth, start := 0, 0
+	for i, r := range text {
+		switch r {
+		case '<':
+			depth++
+		case '>':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, text[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(args, text[start:])
+}
+
+// mustIdent returns name if it is a valid identifier, and panics otherwise.
+func mustIdent(name string) string {
+	valid := name != ""
+	for i, r := range name {
+		switch {
+		case r == '_', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case '0' <= r && r <= '9' && i > 0:
+		default:
+			valid = false
+		}
+	}
+	if !valid {
+		panic(fmt.Sprintf("protocompile/ast/builder: invalid identifier %q", name))
+	}
+	return name
+}
@@ -0,0 +1,84 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/token"
+	"github.com/bufbuild/protocompile/experimental/token/keyword"
+)
+
+// Service builds a service definition.
+type Service struct {
+	file *ast.File
+	decl ast.DeclDef
+}
+
+// Decl returns the service's definition.
+func (s *Service) Decl() ast.DeclDef {
+	return s.decl
+}
+
+// Option adds an option to the service.
+func (s *Service) Option(name string, value Value) *Service {
+	seq.Append(s.decl.Body().Decls(), newOption(s.file, name, value).AsAny())
+	return s
+}
+
+// Method adds a method with the given input and output types, and returns a
+// builder for it.
+//
+// Either type may be prefixed with "stream " to make it a streaming type.
+func (s *Service) Method(name, input, output string) *Method {
+	stream := s.file.Stream()
+	decl := s.file.Nodes().NewDeclDef(ast.DeclDefArgs{
+		Keyword:   stream.NewIdent(keyword.RPC.String()),
+		Name:      newName(s.file, name),
+		Returns:   stream.NewIdent(keyword.Returns.String()),
+		Semicolon: stream.NewPunct(keyword.Semi.String()),
+	})
+
+	sig := decl.Signature()
+	sig.Inputs().SetBrackets(newFused(s.file, keyword.LParen, keyword.RParen))
+	seq.Append(sig.Inputs(), newType(s.file, input))
+	sig.Outputs().SetBrackets(newFused(s.file, keyword.LParen, keyword.RParen))
+	seq.Append(sig.Outputs(), newType(s.file, output))
+
+	seq.Append(s.decl.Body().Decls(), decl.AsAny())
+	return &Method{file: s.file, decl: decl}
+}
+
+// Method builds a method definition.
+type Method struct {
+	file *ast.File
+	decl ast.DeclDef
+}
+
+// Decl returns the method's definition.
+func (m *Method) Decl() ast.DeclDef {
+	return m.decl
+}
+
+// Option adds an option to the method, giving it a body if it does not have
+// one yet.
+func (m *Method) Option(name string, value Value) *Method {
+	if m.decl.Body().IsZero() {
+		m.decl.SetBody(newBody(m.file))
+		m.decl.SetSemicolon(token.Zero)
+	}
+	seq.Append(m.decl.Body().Decls(), newOption(m.file, name, value).AsAny())
+	return m
+}
@@ -0,0 +1,138 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package builder
+
+import (
+	"math"
+
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/token"
+	"github.com/bufbuild/protocompile/experimental/token/keyword"
+)
+
+// Value is an option value.
+type Value struct {
+	expr func(*ast.File) ast.ExprAny
+}
+
+// Entry is a field of a message literal; see [Dict].
+type Entry struct {
+	Name  string
+	Value Value
+}
+
+// String returns a string value.
+func String(v string) Value {
+	return Value{func(file *ast.File) ast.ExprAny {
+		return ast.ExprLiteral{File: file, Token: file.Stream().NewString(v)}.AsAny()
+	}}
+}
+
+// Int returns a signed integer value.
+func Int(v int64) Value {
+	if v < 0 {
+		return negate(Uint(uint64(-v)))
+	}
+	return Uint(uint64(v))
+}
+
+// Uint returns an unsigned integer value.
+func Uint(v uint64) Value {
+	return Value{func(file *ast.File) ast.ExprAny {
+		return ast.ExprLiteral{File: file, Token: file.Stream().NewInt(v)}.AsAny()
+	}}
+}
+
+// Float returns a floating-point value. Infinities and NaN are spelled with
+// the inf and nan identifiers.
+func Float(v float64) Value {
+	var abs Value
+	switch {
+	case math.IsNaN(v):
+		return Ident(keyword.NaN.String())
+	case math.IsInf(v, 0):
+		abs = Ident(keyword.Inf.String())
+	default:
+		abs = Value{func(file *ast.File) ast.ExprAny {
+			return ast.ExprLiteral{File: file, Token: file.Stream().NewFloat(math.Abs(v))}.AsAny()
+		}}
+	}
+	if math.Signbit(v) {
+		return negate(abs)
+	}
+	return abs
+}
+
+// Bool returns a boolean value.
+func Bool(v bool) Value {
+	if v {
+		return Ident(keyword.True.String())
+	}
+	return Ident(keyword.False.String())
+}
+
+// Ident returns an identifier value, such as an enum value name.
+func Ident(name string) Value {
+	return Value{func(file *ast.File) ast.ExprAny {
+		return ast.ExprPath{Path: newPath(file, name)}.AsAny()
+	}}
+}
+
+// List returns a list value, such as `[1, 2, 3]`.
+func List(values ...Value) Value {
+	return Value{func(file *ast.File) ast.ExprAny {
+		array := file.Nodes().NewExprArray(newFused(file, keyword.LBracket, keyword.RBracket))
+		elems := array.Elements()
+		for i, v := range values {
+			comma := token.Zero
+			if i < len(values)-1 {
+				comma = file.Stream().NewPunct(keyword.Comma.String())
+			}
+			elems.AppendComma(v.expr(file), comma)
+		}
+		return array.AsAny()
+	}}
+}
+
+// Dict returns a message value, such as `{foo: 1, bar: "x"}`.
+func Dict(entries ...Entry) Value {
+	return Value{func(file *ast.File) ast.ExprAny {
+		stream := file.Stream()
+		dict := file.Nodes().NewExprDict(newFused(file, keyword.LBrace, keyword.RBrace))
+		elems := dict.Elements()
+		for i, e := range entries {
+			field := file.Nodes().NewExprField(ast.ExprFieldArgs{
+				Key:   Ident(mustIdent(e.Name)).expr(file),
+				Colon: stream.NewPunct(keyword.Colon.String()),
+				Value: e.Value.expr(file),
+			})
+			comma := token.Zero
+			if i < len(entries)-1 {
+				comma = stream.NewPunct(keyword.Comma.String())
+			}
+			elems.AppendComma(field, comma)
+		}
+		return dict.AsAny()
+	}}
+}
+
+func negate(v Value) Value {
+	return Value{func(file *ast.File) ast.ExprAny {
+		return file.Nodes().NewExprPrefixed(ast.ExprPrefixedArgs{
+			Prefix: file.Stream().NewPunct(keyword.Sub.String()),
+			Expr:   v.expr(file),
+		}).AsAny()
+	}}
+}
@@ -61,6 +61,14 @@ func (c commas[T, _]) Comma(n int) token.Token {
 	return id.Wrap(c.file.Stream(), (*c.SliceInserter.Slice)[n].Comma)
 }
 
+// SetAt implements [seq.Setter]. Unlike [seq.SliceInserter.SetAt], it keeps
+// the comma that follows the nth element, as [TypeList.SetAt] does.
+func (c commas[T, _]) SetAt(n int, value T) {
+	comma := (*c.SliceInserter.Slice)[n].Comma
+	c.SliceInserter.SetAt(n, value)
+	(*c.SliceInserter.Slice)[n].Comma = comma
+}
+
 func (c commas[T, _]) AppendComma(value T, comma token.Token) {
 	c.InsertComma(c.Len(), value, comma)
 }
@@ -188,6 +188,13 @@ func (d DeclDef) Name() Path {
 	return d.Raw().name.In(d.Context())
 }
 
+// SetName sets the declared name of this definition.
+//
+// See [DeclDef.Name].
+func (d DeclDef) SetName(path Path) {
+	d.Raw().name = path.raw
+}
+
 // Stem returns a span that contains both this definition's type and name.
 //
 // For e.g. a message, this is the "message Foo" part.
@@ -291,6 +298,14 @@ func (d DeclDef) Semicolon() token.Token {
 	return id.Wrap(d.Context().Stream(), d.Raw().semi)
 }
 
+// SetSemicolon sets the ending semicolon token for this definition.
+//
+// See [DeclDef.Semicolon].
+func (d DeclDef) SetSemicolon(semi token.Token) {
+	d.Context().Nodes().panicIfNotOurs(semi.Context())
+	d.Raw().semi = semi.ID()
+}
+
 // IsCorrupt reports whether or not some part of the parser decided that this
 // definition is not interpretable as any specific kind of definition.
 func (d DeclDef) IsCorrupt() bool {
@@ -86,6 +86,13 @@
 // In some places, the zero value of a pointer-like type is (incorrectly)
 // referred to as nil. This is a documentation bug; instead, it should say zero.
 //
+// # Walking
+//
+// Rather than recursing through typed accessors by hand, use [Walk], which
+// visits every declaration, expression, type, path, and compact option in a
+// file. Its [Cursor] tracks each node's ancestors, and can replace nodes in
+// place, for tools that rewrite the AST.
+//
 // # Coming Soon
 //
 // This library will replace the existing [github.com/bufbuild/protocompile/ast]
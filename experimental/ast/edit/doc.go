@@ -17,9 +17,13 @@
 //
 // The package exposes a single entry point, [ApplyEdits], which
 // applies a list of [Edit] values to an [ast.File] in order. Edits
-// cover adding, deleting, and moving declarations within decl-
-// bearing bodies (file, message, enum, service, oneof, extend, and
-// RPC method bodies).
+// cover adding, deleting, replacing, and moving declarations within
+// and between decl-bearing bodies (file, message, enum, service,
+// oneof, extend, and RPC method bodies), as well as changing the
+// name, type, number, or value of a single definition in place.
+// Comments attached to edited declarations are preserved.
+// [OrganizeImports] builds on it to add, remove, deduplicate, and
+// sort a file's imports.
 //
 // After applying edits, the file is typically rendered with the
 // [github.com/bufbuild/protocompile/experimental/ast/printer]
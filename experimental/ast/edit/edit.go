@@ -33,9 +33,27 @@ const (
 	// KindDelete removes [Edit.Target] from its parent's decl list.
 	KindDelete
 	// KindMove moves [Edit.Target] so that it appears immediately
-	// before [Edit.Before] in their shared parent's decl list.
-	// Currently both must be top-level decls.
+	// before [Edit.Before], which may be in a different body (e.g.
+	// moving a field into a oneof). If Before is zero, Target is
+	// appended to the body of [Edit.Into] instead. Target must be
+	// allowed in its new container, and may not be moved into itself.
 	KindMove
+	// KindReplace replaces [Edit.Target] with [Edit.Insertions], in
+	// place. The insertions must be allowed in Target's container.
+	KindReplace
+	// KindRename renames the definition [Edit.Target] to [Edit.Name].
+	// Target must have a single-identifier name, so options and extend
+	// blocks cannot be renamed.
+	KindRename
+	// KindSetType changes the type of the field [Edit.Target] to
+	// [Edit.Type].
+	KindSetType
+	// KindSetNumber changes the number of the field, group, or enum
+	// value [Edit.Target] to [Edit.Number].
+	KindSetNumber
+	// KindSetValue changes the value of the option [Edit.Target] to
+	// [Edit.Value].
+	KindSetValue
 )
 
 // String returns a human-readable name for the kind, used in
@@ -48,6 +66,16 @@ func (k Kind) String() string {
 		return "delete"
 	case KindMove:
 		return "move"
+	case KindReplace:
+		return "replace"
+	case KindRename:
+		return "rename"
+	case KindSetType:
+		return "set type"
+	case KindSetNumber:
+		return "set number"
+	case KindSetValue:
+		return "set value"
 	default:
 		return fmt.Sprintf("Kind(%d)", int(k))
 	}
@@ -63,9 +91,15 @@ func (k Kind) String() string {
 // any edits already applied remain in place.
 //
 // Edits currently operate on decl-bearing bodies (file, message,
-// enum, service, oneof, extend, and method bodies). Modifying the
+// enum, service, oneof, extend, and method bodies), and on the name,
+// type, number, and value of definitions. Modifying the
 // compact-options bracket on a field or enum value (e.g. adding
 // `[deprecated = true]`) is not yet supported.
+//
+// Edits preserve comments attached to the decls they touch: moved
+// decls keep their own tokens, and the tokens an edit replaces hand
+// their comments to the tokens that replace them (see
+// [token.Stream.Supersede]).
 type Edit struct {
 	// Kind selects the operation.
 	Kind Kind
@@ -75,12 +109,17 @@ type Edit struct {
 	//                 the file's top-level decl list.
 	//   - KindDelete: the decl to remove.
 	//   - KindMove:   the decl to relocate.
+	//   - KindReplace: the decl to replace.
+	//   - KindRename, KindSetType, KindSetNumber, KindSetValue:
+	//                 the definition to modify.
 	Target ast.DeclAny
 
-	// Insertions are the decls to append to Target's body, in order.
-	// Honored only by [KindAdd].
+	// Insertions are the decls to append to Target's body, in order,
+	// for [KindAdd], or the decls to replace Target with for
+	// [KindReplace].
 	//
 	// Allowed insertion-vs-container pairings:
+	//   - import:               file
 	//   - option:               file or any decl-bearing body
 	//   - message, enum:        file or message body
 	//   - field:                message body, oneof body
@@ -88,6 +127,8 @@ type Edit struct {
 	//   - service:              file
 	//   - method (RPC):         service body
 	//   - oneof, extend, group: message body
+	//   - reserved:             message or enum body
+	//   - extensions:           message body
 	Insertions []ast.DeclAny
 
 	// Before is the destination anchor for positional inserts:
@@ -95,9 +136,27 @@ type Edit struct {
 	//               before Before in Target's decl list (in the order
 	//               given). Before must be a current member of Target.
 	//               When zero, Insertions are appended (default).
-	//   - KindMove: the moved decl is reinserted immediately before
-	//               Before. Required.
+	//   - KindMove: when non-zero, the moved decl is reinserted
+	//               immediately before Before, which may be anywhere
+	//               in the file.
 	Before ast.DeclAny
+
+	// Into is the container a [KindMove] appends Target to when Before
+	// is zero. Zero means the file's top-level decl list.
+	Into ast.DeclAny
+
+	// Name is the new name for [KindRename]. It must be a single
+	// identifier.
+	Name string
+
+	// Type is the new type for [KindSetType].
+	Type ast.TypeAny
+
+	// Number is the new number for [KindSetNumber].
+	Number int64
+
+	// Value is the new value for [KindSetValue].
+	Value ast.ExprAny
 }
 
 // ApplyEdits applies edits to file in order, stopping at the first
@@ -127,6 +186,16 @@ func applyEdit(file *ast.File, edit Edit) error {
 		return applyDelete(file, edit)
 	case KindMove:
 		return applyMove(file, edit)
+	case KindReplace:
+		return applyReplace(file, edit)
+	case KindRename:
+		return applyRename(file, edit)
+	case KindSetType:
+		return applySetType(file, edit)
+	case KindSetNumber:
+		return applySetNumber(file, edit)
+	case KindSetValue:
+		return applySetValue(file, edit)
 	default:
 		return fmt.Errorf("unknown kind %d", edit.Kind)
 	}
@@ -169,37 +238,81 @@ func applyDelete(file *ast.File, edit Edit) error {
 	if edit.Target.IsZero() {
 		return errors.New("target is zero")
 	}
-	parent, idx, ok := findInFile(file, edit.Target)
-	if !ok {
-		return errors.New("target not found in file")
+	decls, _, idx, err := locate(file, edit.Target)
+	if err != nil {
+		return fmt.Errorf("target %w", err)
 	}
-	parent.Delete(idx)
+	decls.Delete(idx)
 	return nil
 }
 
-// applyMove relocates target to immediately before Before. Both must
-// be top-level decls.
+// applyMove relocates target to immediately before Before, or to the
+// end of Into's body when Before is zero.
 func applyMove(file *ast.File, edit Edit) error {
 	if edit.Target.IsZero() {
 		return errors.New("target is zero")
 	}
+	from, _, fromIdx, err := locate(file, edit.Target)
+	if err != nil {
+		return fmt.Errorf("target %w", err)
+	}
+
+	dest := edit.Into
+	if !edit.Before.IsZero() {
+		if edit.Before == edit.Target {
+			return errors.New("cannot move a decl before itself")
+		}
+		var ok bool
+		if dest, ok = findParent(file, edit.Before); !ok {
+			return errors.New("before not found in file")
+		}
+	}
+	if !dest.IsZero() && (dest == edit.Target || contains(edit.Target, dest)) {
+		return errors.New("cannot move a decl into itself")
+	}
+	to, container, err := targetDecls(file, dest)
+	if err != nil {
+		return err
+	}
+	if err := validateInsertion(container, edit.Target); err != nil {
+		return err
+	}
+
+	from.Delete(fromIdx)
 	if edit.Before.IsZero() {
-		return errors.New("before is zero")
-	}
-	decls := file.Decls()
-	targetIdx := indexOf(decls, edit.Target)
-	if targetIdx < 0 {
-		return errors.New("target not found at file level")
-	}
-	saved := decls.At(targetIdx)
-	decls.Delete(targetIdx)
-	beforeIdx := indexOf(decls, edit.Before)
-	if beforeIdx < 0 {
-		// Restore on failure so the file is left unchanged.
-		decls.Insert(targetIdx, saved)
-		return errors.New("before not found at file level")
-	}
-	decls.Insert(beforeIdx, saved)
+		seq.Append(to, edit.Target)
+	} else {
+		to.Insert(indexOf(to, edit.Before), edit.Target)
+	}
+	return nil
+}
+
+// applyReplace replaces target with the insertions, in place.
+func applyReplace(file *ast.File, edit Edit) error {
+	if edit.Target.IsZero() {
+		return errors.New("target is zero")
+	}
+	decls, container, idx, err := locate(file, edit.Target)
+	if err != nil {
+		return fmt.Errorf("target %w", err)
+	}
+	for j, ins := range edit.Insertions {
+		if ins.IsZero() {
+			return fmt.Errorf("insertion[%d] is zero", j)
+		}
+		if err := validateInsertion(container, ins); err != nil {
+			return fmt.Errorf("insertion[%d]: %w", j, err)
+		}
+	}
+
+	if n := len(edit.Insertions); n > 0 {
+		supersede(declFirstToken(edit.Insertions[0]), declFirstToken(edit.Target))
+		supersede(declLastToken(edit.Insertions[n-1]), declLastToken(edit.Target))
+	}
+	decls.Delete(idx)
+	for j, ins := range edit.Insertions {
+		decls.Insert(idx+j, ins)
+	}
 	return nil
 }
 
@@ -256,7 +369,7 @@ func targetDecls(file *ast.File, target ast.DeclAny) (seq.Inserter[ast.DeclAny],
 		}
 		var ck containerKind
 		switch def.Classify() {
-		case ast.DefKindMessage:
+		case ast.DefKindMessage, ast.DefKindGroup:
 			ck = containerMessage
 		case ast.DefKindEnum:
 			ck = containerEnum
@@ -280,11 +393,24 @@ func targetDecls(file *ast.File, target ast.DeclAny) (seq.Inserter[ast.DeclAny],
 // validateInsertion checks that an insertion is allowed in the given
 // container per the rules documented on [Edit.Insertions].
 func validateInsertion(container containerKind, ins ast.DeclAny) error {
+	if ins.Kind() == ast.DeclKindImport {
+		if container == containerFile {
+			return nil
+		}
+		return fmt.Errorf("cannot insert import into %s", container)
+	}
+	if rng := ins.AsRange(); !rng.IsZero() {
+		if container == containerMessage ||
+			(container == containerEnum && rng.IsReserved()) {
+			return nil
+		}
+		return fmt.Errorf("cannot insert %s range into %s", rng.Keyword(), container)
+	}
 	def := ins.AsDef()
 	if def.IsZero() {
-		// Non-definition decls (syntax, package, import, range, body,
-		// empty) are not valid Edit insertions.
-		return fmt.Errorf("only definition decls may be inserted (got %s)", ins.Kind())
+		// Other non-definition decls (syntax, package, body, empty) are
+		// not valid Edit insertions.
+		return fmt.Errorf("only definition, range, and import decls may be inserted (got %s)", ins.Kind())
 	}
 	kind := def.Classify()
 	switch kind {
@@ -321,23 +447,38 @@ func validateInsertion(container containerKind, ins ast.DeclAny) error {
 	return fmt.Errorf("cannot insert %s into %s", kind, container)
 }
 
-// findInFile recursively searches the file for target, returning the
-// containing decl-list inserter and the index, or false if not found.
-func findInFile(file *ast.File, target ast.DeclAny) (seq.Inserter[ast.DeclAny], int, bool) {
-	if idx := indexOf(file.Decls(), target); idx >= 0 {
-		return file.Decls(), idx, true
+// locate finds target in the file, returning the decl list that
+// contains it, the classification of that list, and its index in it.
+func locate(file *ast.File, target ast.DeclAny) (seq.Inserter[ast.DeclAny], containerKind, int, error) {
+	parent, ok := findParent(file, target)
+	if !ok {
+		return nil, containerInvalid, 0, errors.New("not found in file")
+	}
+	decls, container, err := targetDecls(file, parent)
+	if err != nil {
+		return nil, containerInvalid, 0, err
+	}
+	return decls, container, indexOf(decls, target), nil
+}
+
+// findParent recursively searches the file for target, returning the
+// decl whose body contains it (zero for the file itself), or false if
+// not found.
+func findParent(file *ast.File, target ast.DeclAny) (ast.DeclAny, bool) {
+	if indexOf(file.Decls(), target) >= 0 {
+		return ast.DeclAny{}, true
 	}
 	for d := range seq.Values(file.Decls()) {
-		if found, idx, ok := findInDecl(d, target); ok {
-			return found, idx, true
+		if parent, ok := findParentIn(d, target); ok {
+			return parent, true
 		}
 	}
-	return nil, 0, false
+	return ast.DeclAny{}, false
 }
 
-// findInDecl is the recursive worker for [findInFile]: searches decl's
-// own body and any nested body decls for target.
-func findInDecl(decl, target ast.DeclAny) (seq.Inserter[ast.DeclAny], int, bool) {
+// findParentIn is the recursive worker for [findParent]: searches
+// decl's own body and any nested body decls for target.
+func findParentIn(decl, target ast.DeclAny) (ast.DeclAny, bool) {
 	var body ast.DeclBody
 	if b := decl.AsBody(); !b.IsZero() {
 		body = b
@@ -345,17 +486,24 @@ func findInDecl(decl, target ast.DeclAny) (seq.Inserter[ast.DeclAny], int, bool)
 		body = def.Body()
 	}
 	if body.IsZero() {
-		return nil, 0, false
+		return ast.DeclAny{}, false
 	}
-	if idx := indexOf(body.Decls(), target); idx >= 0 {
-		return body.Decls(), idx, true
+	if indexOf(body.Decls(), target) >= 0 {
+		return decl, true
 	}
 	for d := range seq.Values(body.Decls()) {
-		if found, idx, ok := findInDecl(d, target); ok {
-			return found, idx, true
+		if parent, ok := findParentIn(d, target); ok {
+			return parent, true
 		}
 	}
-	return nil, 0, false
+	return ast.DeclAny{}, false
+}
+
+// contains reports whether target is nested anywhere inside decl's
+// body.
+func contains(decl, target ast.DeclAny) bool {
+	_, ok := findParentIn(decl, target)
+	return ok
 }
 
 // indexOf returns the index of target in decls, or -1.
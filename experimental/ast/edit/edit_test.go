@@ -17,6 +17,8 @@ package edit_test
 import (
 	"errors"
 	"fmt"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -129,6 +131,119 @@ func TestApplyEdits(t *testing.T) {
 	})
 }
 
+// TestApplyEditsInvalid checks that [edit.ApplyEdits] rejects edits
+// that would produce a malformed AST.
+func TestApplyEditsInvalid(t *testing.T) {
+	t.Parallel()
+
+	const src = `syntax = "proto3";
+package test;
+message M {
+  int32 a = 1;
+  oneof o {
+    string b = 2;
+  }
+  message N {}
+}
+enum E {
+  E_ZERO = 0;
+}
+option java_package = "x";
+`
+
+	tests := []struct {
+		name string
+		spec editSpec
+	}{
+		{"move into itself", editSpec{Kind: "move_decl", Target: "M", Into: "M.N"}},
+		{"move before itself", editSpec{Kind: "move_decl", Target: "M", Before: "M"}},
+		{"move field to file", editSpec{Kind: "move_decl", Target: "M.a", Before: "E"}},
+		{"move message into oneof", editSpec{Kind: "move_decl", Target: "M.N", Into: "M.o"}},
+		{"replace enum value with field", editSpec{Kind: "replace_field", Target: "E.E_ZERO", Type: "int32", Name: "x", Tag: "1"}},
+		{"rename option", editSpec{Kind: "rename", Target: "java_package", Name: "x"}},
+		{"rename to qualified name", editSpec{Kind: "rename", Target: "M", Name: "a.b"}},
+		{"set type of message", editSpec{Kind: "set_type", Target: "M", Type: "string"}},
+		{"set field number to zero", editSpec{Kind: "set_number", Target: "M.a", Number: 0}},
+		{"set value of field", editSpec{Kind: "set_value", Target: "M.a", Value: "1"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			file, _ := parser.Parse("test.proto", source.NewFile("test.proto", src), &report.Report{})
+			e, err := buildEdit(file, pendingDecls{}, tt.spec)
+			if err != nil {
+				t.Fatalf("building edit: %v", err)
+			}
+			if err := edit.ApplyEdits(file, []edit.Edit{e}); err == nil {
+				t.Errorf("ApplyEdits succeeded, want error")
+			}
+		})
+	}
+}
+
+// TestOrganizeImports exercises [edit.OrganizeImports] against
+// testdata/imports.
+//
+// Each <name>.yaml fixture defines a `source` proto and the `add` and
+// `remove` lists of an [edit.ImportChanges]. The result is rendered and
+// re-parsed the same way as in [TestApplyEdits].
+//
+// To regenerate goldens:
+//
+//	PROTOCOMPILE_REFRESH=** go test ./experimental/ast/edit/... -run TestOrganizeImports
+func TestOrganizeImports(t *testing.T) {
+	t.Parallel()
+
+	corpus := golden.Corpus{
+		Root:       "testdata/imports",
+		Extensions: []string{"yaml"},
+		Refresh:    "PROTOCOMPILE_REFRESH",
+		Outputs: []golden.Output{
+			{Extension: "txt"},
+		},
+	}
+
+	opts := printer.Options{
+		Format:     true,
+		Formatting: printer.Default(),
+	}
+
+	corpus.Run(t, func(t *testing.T, path, text string, outputs []string) {
+		var spec struct {
+			Source string   `yaml:"source"`
+			Add    []string `yaml:"add"`
+			Remove []string `yaml:"remove"`
+		}
+		if err := yaml.Unmarshal([]byte(text), &spec); err != nil {
+			t.Fatalf("parsing yaml spec: %v", err)
+		}
+
+		file, _ := parser.Parse(path, source.NewFile(path, spec.Source), &report.Report{})
+		err := edit.OrganizeImports(file, edit.ImportChanges{
+			Add:    spec.Add,
+			Remove: spec.Remove,
+		})
+		if err != nil {
+			t.Fatalf("OrganizeImports: %v", err)
+		}
+
+		got, err := printer.PrintFile(opts, file)
+		if err != nil {
+			t.Fatalf("PrintFile: %v", err)
+		}
+		outputs[0] = got
+
+		errs := &report.Report{}
+		_, _ = parser.Parse(path, source.NewFile(path, got), errs)
+		for _, d := range errs.Diagnostics {
+			if d.Level() <= report.Error {
+				t.Errorf("formatted output does not re-parse: %v", d)
+			}
+		}
+	})
+}
+
 // editSpec is the YAML shape used by testdata/edits/*.yaml. It is
 // converted to [edit.Edit] by [buildEdit] using the file's stream
 // and AST helpers.
@@ -146,6 +261,10 @@ type editSpec struct {
 	// dotted path for `move_decl`). When empty, the insertion
 	// appends.
 	Before string `yaml:"before"`
+	// Into is the dotted path of the container `move_decl` appends
+	// to when neither Name nor Before is set.
+	Into   string `yaml:"into"`
+	Number int64  `yaml:"number"`
 }
 
 // pendingDecls maps a dotted path to a [ast.DeclAny] that has been
@@ -237,18 +356,71 @@ func buildEdit(file *ast.File, pending pendingDecls, spec editSpec) (edit.Edit,
 		}, nil
 
 	case "move_decl":
-		target, ok := findTopLevelDeclByName(file, spec.Target)
+		target, ok := pending.resolve(file, spec.Target)
+		if !ok {
+			return edit.Edit{}, fmt.Errorf("decl %q not found", spec.Target)
+		}
+		move := edit.Edit{Kind: edit.KindMove, Target: target}
+		if spec.Name != "" {
+			spec.Before = spec.Name
+		}
+		if spec.Before != "" {
+			if move.Before, ok = pending.resolve(file, spec.Before); !ok {
+				return edit.Edit{}, fmt.Errorf("decl %q not found", spec.Before)
+			}
+		} else if spec.Into != "" {
+			if move.Into, ok = pending.resolve(file, spec.Into); !ok {
+				return edit.Edit{}, fmt.Errorf("decl %q not found", spec.Into)
+			}
+		}
+		return move, nil
+
+	case "replace_field":
+		target, ok := pending.resolve(file, spec.Target)
 		if !ok {
 			return edit.Edit{}, fmt.Errorf("decl %q not found", spec.Target)
 		}
-		before, ok := findTopLevelDeclByName(file, spec.Name)
+		field := createFieldDecl(stream, nodes, spec.Type, spec.Name, spec.Tag)
+		return edit.Edit{
+			Kind:       edit.KindReplace,
+			Target:     target,
+			Insertions: []ast.DeclAny{field.AsAny()},
+		}, nil
+
+	case "rename":
+		target, ok := pending.resolve(file, spec.Target)
 		if !ok {
-			return edit.Edit{}, fmt.Errorf("decl %q not found", spec.Name)
+			return edit.Edit{}, fmt.Errorf("decl %q not found", spec.Target)
+		}
+		return edit.Edit{Kind: edit.KindRename, Target: target, Name: spec.Name}, nil
+
+	case "set_type":
+		target, ok := pending.resolve(file, spec.Target)
+		if !ok {
+			return edit.Edit{}, fmt.Errorf("decl %q not found", spec.Target)
 		}
 		return edit.Edit{
-			Kind:   edit.KindMove,
+			Kind:   edit.KindSetType,
 			Target: target,
-			Before: before,
+			Type:   createType(stream, nodes, spec.Type),
+		}, nil
+
+	case "set_number":
+		target, ok := pending.resolve(file, spec.Target)
+		if !ok {
+			return edit.Edit{}, fmt.Errorf("decl %q not found", spec.Target)
+		}
+		return edit.Edit{Kind: edit.KindSetNumber, Target: target, Number: spec.Number}, nil
+
+	case "set_value":
+		target, ok := pending.resolve(file, spec.Target)
+		if !ok {
+			return edit.Edit{}, fmt.Errorf("decl %q not found", spec.Target)
+		}
+		return edit.Edit{
+			Kind:   edit.KindSetValue,
+			Target: target,
+			Value:  createValue(file, spec.Value),
 		}, nil
 
 	default:
@@ -292,90 +464,45 @@ func ensureOptionTarget(file *ast.File, pending pendingDecls, targetPath string)
 		// file's top-level decl list.
 		return ast.DeclAny{}, nil
 	}
-	if d, ok := pending.resolve(file, targetPath); ok {
-		return d, nil
+	d, ok := pending.resolve(file, targetPath)
+	if !ok {
+		return ast.DeclAny{}, fmt.Errorf("option target %q not found", targetPath)
 	}
-	// Service.Method: pending.resolve descends only into
-	// messages/enums via findDeclByPath. Locate the method
-	// directly, ensuring it has a body for the option to land in.
-	parts := strings.Split(targetPath, ".")
-	if len(parts) == 2 {
-		for d := range seq.Values(file.Decls()) {
-			def := d.AsDef()
-			if def.IsZero() || def.Classify() != ast.DefKindService {
-				continue
-			}
-			if defName(def) != parts[0] {
-				continue
-			}
-			for md := range seq.Values(def.Body().Decls()) {
-				mdef := md.AsDef()
-				if mdef.IsZero() || mdef.Classify() != ast.DefKindMethod {
-					continue
-				}
-				if defName(mdef) != parts[1] {
-					continue
-				}
-				if mdef.Body().IsZero() {
-					stream := file.Stream()
-					nodes := file.Nodes()
-					openBrace := stream.NewPunct(keyword.LBrace.String())
-					closeBrace := stream.NewPunct(keyword.RBrace.String())
-					stream.NewFused(openBrace, closeBrace)
-					body := nodes.NewDeclBody(openBrace)
-					mdef.SetBody(body)
-				}
-				return md, nil
-			}
-		}
+	if def := d.AsDef(); def.Classify() == ast.DefKindMethod && def.Body().IsZero() {
+		stream := file.Stream()
+		openBrace := stream.NewPunct(keyword.LBrace.String())
+		closeBrace := stream.NewPunct(keyword.RBrace.String())
+		stream.NewFused(openBrace, closeBrace)
+		def.SetBody(file.Nodes().NewDeclBody(openBrace))
 	}
-	return ast.DeclAny{}, fmt.Errorf("option target %q not found", targetPath)
+	return d, nil
 }
 
 // findDeclByPath returns the [ast.DeclAny] for a decl at the given
-// dotted path, searching messages and enums recursively.
+// dotted path, descending through the bodies of any definitions
+// (messages, enums, oneofs, services, ...).
 func findDeclByPath(file *ast.File, targetPath string) (ast.DeclAny, bool) {
-	parts := strings.Split(targetPath, ".")
-
-	if len(parts) == 1 {
-		for d := range seq.Values(file.Decls()) {
-			def := d.AsDef()
-			if def.IsZero() {
-				continue
-			}
-			if defName(def) == parts[0] {
-				return d, true
-			}
-		}
-		return ast.DeclAny{}, false
-	}
-
-	// Nested path: find the parent body, then the named decl in it.
-	parentPath := strings.Join(parts[:len(parts)-1], ".")
-	name := parts[len(parts)-1]
-	if body := findMessageBody(file, parentPath); !body.IsZero() {
-		for d := range seq.Values(body.Decls()) {
-			def := d.AsDef()
-			if def.IsZero() {
-				continue
-			}
-			if defName(def) == name {
-				return d, true
-			}
+	decls := seq.Indexer[ast.DeclAny](file.Decls())
+	var found ast.DeclAny
+outer:
+	for part := range strings.SplitSeq(targetPath, ".") {
+		if decls == nil {
+			return ast.DeclAny{}, false
 		}
-	}
-	if body := findEnumBody(file, parentPath); !body.IsZero() {
-		for d := range seq.Values(body.Decls()) {
+		for d := range seq.Values(decls) {
 			def := d.AsDef()
-			if def.IsZero() {
+			if def.IsZero() || defName(def) != part {
 				continue
 			}
-			if defName(def) == name {
-				return d, true
+			found, decls = d, nil
+			if !def.Body().IsZero() {
+				decls = def.Body().Decls()
 			}
+			continue outer
 		}
+		return ast.DeclAny{}, false
 	}
-	return ast.DeclAny{}, false
+	return found, true
 }
 
 // resolveAnchor returns the decl named `name` in the target container.
@@ -405,21 +532,6 @@ func resolveAnchor(file *ast.File, target ast.DeclAny, name string) (ast.DeclAny
 	return ast.DeclAny{}, fmt.Errorf("decl %q not found in target", name)
 }
 
-// findTopLevelDeclByName returns the file-level decl with the given
-// name.
-func findTopLevelDeclByName(file *ast.File, name string) (ast.DeclAny, bool) {
-	for d := range seq.Values(file.Decls()) {
-		def := d.AsDef()
-		if def.IsZero() {
-			continue
-		}
-		if defName(def) == name {
-			return d, true
-		}
-	}
-	return ast.DeclAny{}, false
-}
-
 // defName returns the simple name of a definition, handling both natural
 // paths (where AsIdent works) and synthetic paths (where we must iterate
 // Components). Returns "" if the name cannot be determined.
@@ -439,86 +551,6 @@ func defName(def ast.DeclDef) string {
 	return ""
 }
 
-// findMessageBody finds a message body by path (e.g., "M" or "M.Inner").
-func findMessageBody(file *ast.File, targetPath string) ast.DeclBody {
-	parts := strings.Split(targetPath, ".")
-
-	var searchDecls func(decls seq.Indexer[ast.DeclAny], depth int) ast.DeclBody
-	searchDecls = func(decls seq.Indexer[ast.DeclAny], depth int) ast.DeclBody {
-		if depth >= len(parts) {
-			return ast.DeclBody{}
-		}
-
-		for decl := range seq.Values(decls) {
-			def := decl.AsDef()
-			if def.IsZero() {
-				continue
-			}
-			if def.Classify() != ast.DefKindMessage {
-				continue
-			}
-
-			if defName(def) != parts[depth] {
-				continue
-			}
-
-			// Found matching message at this level
-			msg := def.AsMessage()
-			if depth == len(parts)-1 {
-				return msg.Body
-			}
-
-			// Need to go deeper
-			if !msg.Body.IsZero() {
-				if result := searchDecls(msg.Body.Decls(), depth+1); !result.IsZero() {
-					return result
-				}
-			}
-		}
-		return ast.DeclBody{}
-	}
-
-	return searchDecls(file.Decls(), 0)
-}
-
-// findEnumBody finds an enum body by path (e.g., "Status" or "M.Status").
-func findEnumBody(file *ast.File, targetPath string) ast.DeclBody {
-	parts := strings.Split(targetPath, ".")
-
-	var searchDecls func(decls seq.Indexer[ast.DeclAny], depth int) ast.DeclBody
-	searchDecls = func(decls seq.Indexer[ast.DeclAny], depth int) ast.DeclBody {
-		if depth >= len(parts) {
-			return ast.DeclBody{}
-		}
-
-		for decl := range seq.Values(decls) {
-			def := decl.AsDef()
-			if def.IsZero() {
-				continue
-			}
-
-			// Check for enum at final level
-			if depth == len(parts)-1 && def.Classify() == ast.DefKindEnum {
-				if defName(def) == parts[depth] {
-					return def.AsEnum().Body
-				}
-			}
-
-			// Check for message to recurse into
-			if def.Classify() == ast.DefKindMessage {
-				if defName(def) == parts[depth] && !def.Body().IsZero() {
-					if result := searchDecls(def.Body().Decls(), depth+1); !result.IsZero() {
-						return result
-					}
-				}
-			}
-		}
-		return ast.DeclBody{}
-	}
-
-	return searchDecls(file.Decls(), 0)
-}
-
 // createOptionDecl creates an option declaration.
 func createOptionDecl(stream *token.Stream, nodes *ast.Nodes, optionName, optionValue string) ast.DeclDef {
 	optionKw := stream.NewIdent(keyword.Option.String())
@@ -657,3 +689,43 @@ func createServiceDecl(stream *token.Stream, nodes *ast.Nodes, name string) ast.
 		Body:    body,
 	})
 }
+
+// createType creates a type from a string such as "repeated foo.Bar":
+// space-separated prefixes followed by a dotted path.
+func createType(stream *token.Stream, nodes *ast.Nodes, text string) ast.TypeAny {
+	words := strings.Fields(text)
+	ty := ast.TypePath{Path: createPath(stream, nodes, words[len(words)-1])}.AsAny()
+	for _, prefix := range slices.Backward(words[:len(words)-1]) {
+		ty = nodes.NewTypePrefixed(ast.TypePrefixedArgs{
+			Prefix: stream.NewIdent(prefix),
+			Type:   ty,
+		}).AsAny()
+	}
+	return ty
+}
+
+// createValue creates an option value: an integer, a quoted string, or
+// an identifier path.
+func createValue(file *ast.File, text string) ast.ExprAny {
+	stream := file.Stream()
+	if n, err := strconv.ParseUint(text, 10, 64); err == nil {
+		return ast.ExprLiteral{File: file, Token: stream.NewInt(n)}.AsAny()
+	}
+	if s, err := strconv.Unquote(text); err == nil {
+		return ast.ExprLiteral{File: file, Token: stream.NewString(s)}.AsAny()
+	}
+	return ast.ExprPath{Path: createPath(stream, file.Nodes(), text)}.AsAny()
+}
+
+// createPath creates a relative dotted path.
+func createPath(stream *token.Stream, nodes *ast.Nodes, text string) ast.Path {
+	var components []ast.PathComponent
+	for i, name := range strings.Split(text, ".") {
+		sep := token.Zero
+		if i > 0 {
+			sep = stream.NewPunct(keyword.Dot.String())
+		}
+		components = append(components, nodes.NewPathComponent(sep, stream.NewIdent(name)))
+	}
+	return nodes.NewPath(components...)
+}
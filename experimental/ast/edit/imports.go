@@ -0,0 +1,158 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edit
+
+import (
+	"slices"
+
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/token/keyword"
+	"github.com/bufbuild/protocompile/internal/ext/cmpx"
+)
+
+// ImportChanges describes the changes [OrganizeImports] makes to a file's
+// imports, beyond deduplicating and sorting them.
+type ImportChanges struct {
+	// Paths to add plain imports for. Paths that the file already imports are
+	// ignored.
+	Add []string
+
+	// Paths to stop importing, regardless of the import's modifiers. Takes
+	// precedence over Add.
+	Remove []string
+}
+
+// OrganizeImports rewrites the top-level imports of file in place.
+//
+// Imports listed in changes.Remove are deleted, and plain imports are added
+// for each path in changes.Add. If a path is imported more than once, only the
+// strongest import is kept: `import public` is stronger than a plain import,
+// which is stronger than `import weak`, which is stronger than
+// `import option`.
+//
+// The resulting imports are sorted by path, with all `import option`s after
+// the other imports, and placed together where the first import was, or after
+// the syntax and package declarations if the file had no imports.
+func OrganizeImports(file *ast.File, changes ImportChanges) error {
+	decls := file.Decls()
+
+	var (
+		edits       []Edit
+		firstImport = -1
+		firstOther  = -1
+		byPath      = make(map[string]ast.DeclImport)
+	)
+	for i, decl := range seq.All(decls) {
+		imp := decl.AsImport()
+		if imp.IsZero() {
+			if firstOther == -1 && decl.Kind() != ast.DeclKindSyntax &&
+				decl.Kind() != ast.DeclKindPackage {
+				firstOther = i
+			}
+			continue
+		}
+
+		if firstImport == -1 {
+			firstImport = i
+		}
+		edits = append(edits, Edit{Kind: KindDelete, Target: decl})
+
+		path := importPath(imp)
+		if slices.Contains(changes.Remove, path) {
+			continue
+		}
+		if prev, ok := byPath[path]; !ok || importRank(imp) > importRank(prev) {
+			byPath[path] = imp
+		}
+	}
+
+	for _, path := range changes.Add {
+		if _, ok := byPath[path]; ok || slices.Contains(changes.Remove, path) {
+			continue
+		}
+		byPath[path] = newImport(file, path)
+	}
+
+	imports := slices.Collect(func(yield func(ast.DeclImport) bool) {
+		for _, imp := range byPath {
+			if !yield(imp) {
+				return
+			}
+		}
+	})
+	slices.SortFunc(imports, cmpx.Join(
+		cmpx.Map(ast.DeclImport.IsOption, cmpx.Bool),
+		cmpx.Key(importPath),
+	))
+
+	// The new imports are inserted before the first decl following the first
+	// import that is not itself an import; because the old imports are deleted
+	// first, this puts them where the first import was.
+	anchor := firstImport
+	if anchor == -1 {
+		anchor = firstOther
+	}
+	var before ast.DeclAny
+	for i := anchor; i >= 0 && i < decls.Len(); i++ {
+		if decl := decls.At(i); decl.AsImport().IsZero() {
+			before = decl
+			break
+		}
+	}
+
+	edits = append(edits, Edit{
+		Kind:   KindAdd,
+		Before: before,
+		Insertions: slices.Collect(func(yield func(ast.DeclAny) bool) {
+			for _, imp := range imports {
+				if !yield(imp.AsAny()) {
+					return
+				}
+			}
+		}),
+	})
+	return ApplyEdits(file, edits)
+}
+
+// importPath returns the path an import refers to.
+func importPath(imp ast.DeclImport) string {
+	return imp.ImportPath().AsLiteral().AsString().Text()
+}
+
+// importRank ranks imports of the same path by how strong they are, for
+// deduplicating them in [OrganizeImports].
+func importRank(imp ast.DeclImport) int {
+	switch {
+	case imp.IsPublic():
+		return 3
+	case imp.IsWeak():
+		return 1
+	case imp.IsOption():
+		return 0
+	default:
+		return 2
+	}
+}
+
+// newImport creates a plain import of path.
+func newImport(file *ast.File, path string) ast.DeclImport {
+	stream := file.Stream()
+	return file.Nodes().NewDeclImport(ast.DeclImportArgs{
+		Keyword:    stream.NewIdent(keyword.Import.String()),
+		ImportPath: ast.ExprLiteral{File: file, Token: stream.NewString(path)}.AsAny(),
+		Semicolon:  stream.NewPunct(keyword.Semi.String()),
+	})
+}
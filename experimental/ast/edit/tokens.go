@@ -0,0 +1,289 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package edit
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/token"
+	"github.com/bufbuild/protocompile/experimental/token/keyword"
+	"github.com/bufbuild/protocompile/internal/ext/iterx"
+)
+
+// maxFieldNumber is the largest valid field number.
+const maxFieldNumber = 1<<29 - 1
+
+// applyRename replaces the name of the target definition.
+func applyRename(file *ast.File, edit Edit) error {
+	def, err := targetDef(file, edit)
+	if err != nil {
+		return err
+	}
+	switch kind := def.Classify(); kind {
+	case ast.DefKindOption, ast.DefKindExtend, ast.DefKindInvalid:
+		return fmt.Errorf("cannot rename %s", kind)
+	}
+	if !isIdent(edit.Name) {
+		return fmt.Errorf("invalid name %q", edit.Name)
+	}
+	old := def.Name()
+	if iterx.Count(old.Components()) != 1 || old.Absolute() {
+		return errors.New("target does not have a simple name")
+	}
+
+	name := file.Stream().NewIdent(edit.Name)
+	supersede(name, pathFirstToken(old))
+	def.SetName(file.Nodes().NewPath(file.Nodes().NewPathComponent(token.Zero, name)))
+	return nil
+}
+
+// applySetType replaces the type of the target field.
+func applySetType(file *ast.File, edit Edit) error {
+	def, err := targetDef(file, edit)
+	if err != nil {
+		return err
+	}
+	if kind := def.Classify(); kind != ast.DefKindField {
+		return fmt.Errorf("cannot set the type of %s", kind)
+	}
+	if edit.Type.IsZero() {
+		return errors.New("type is zero")
+	}
+
+	// The new type may wrap the old one, as in adding a repeated label,
+	// in which case the old tokens keep their own comments.
+	if old := typeFirstToken(def.Type()); old != typeFirstToken(edit.Type.RemovePrefixes()) {
+		supersede(typeFirstToken(edit.Type), old)
+	}
+	def.SetType(edit.Type)
+	return nil
+}
+
+// applySetNumber replaces the number of the target field, group, or
+// enum value.
+func applySetNumber(file *ast.File, edit Edit) error {
+	def, err := targetDef(file, edit)
+	if err != nil {
+		return err
+	}
+	switch kind := def.Classify(); kind {
+	case ast.DefKindField, ast.DefKindGroup:
+		if edit.Number < 1 || edit.Number > maxFieldNumber {
+			return fmt.Errorf("field number %d out of range", edit.Number)
+		}
+	case ast.DefKindEnumValue:
+		if edit.Number < math.MinInt32 || edit.Number > math.MaxInt32 {
+			return fmt.Errorf("enum value number %d out of range", edit.Number)
+		}
+	default:
+		return fmt.Errorf("cannot set the number of %s", kind)
+	}
+	if def.Value().IsZero() {
+		return errors.New("target has no number")
+	}
+
+	stream := file.Stream()
+	abs := uint64(edit.Number)
+	if edit.Number < 0 {
+		abs = -abs
+	}
+	value := ast.ExprLiteral{File: file, Token: stream.NewInt(abs)}.AsAny()
+	if edit.Number < 0 {
+		value = file.Nodes().NewExprPrefixed(ast.ExprPrefixedArgs{
+			Prefix: stream.NewPunct(keyword.Sub.String()),
+			Expr:   value,
+		}).AsAny()
+	}
+
+	supersede(exprFirstToken(value), exprFirstToken(def.Value()))
+	def.SetValue(value)
+	return nil
+}
+
+// applySetValue replaces the value of the target option.
+func applySetValue(file *ast.File, edit Edit) error {
+	def, err := targetDef(file, edit)
+	if err != nil {
+		return err
+	}
+	if kind := def.Classify(); kind != ast.DefKindOption {
+		return fmt.Errorf("cannot set the value of %s", kind)
+	}
+	if edit.Value.IsZero() {
+		return errors.New("value is zero")
+	}
+	if def.Equals().IsZero() {
+		return errors.New("target has no value")
+	}
+
+	// As with types, the new value may wrap the old one, e.g. by negating
+	// it.
+	inner := edit.Value
+	for inner.Kind() == ast.ExprKindPrefixed {
+		inner = inner.AsPrefixed().Expr()
+	}
+	if old := exprFirstToken(def.Value()); old != exprFirstToken(inner) {
+		supersede(exprFirstToken(edit.Value), old)
+	}
+	def.SetValue(edit.Value)
+	return nil
+}
+
+// targetDef returns the definition an edit modifies, checking that it
+// is part of file.
+func targetDef(file *ast.File, edit Edit) (ast.DeclDef, error) {
+	def := edit.Target.AsDef()
+	if def.IsZero() {
+		return ast.DeclDef{}, errors.New("target is not a definition")
+	}
+	if _, ok := findParent(file, edit.Target); !ok {
+		return ast.DeclDef{}, errors.New("target not found in file")
+	}
+	return def, nil
+}
+
+// isIdent reports whether name is a valid identifier.
+func isIdent(name string) bool {
+	for i, r := range name {
+		switch {
+		case r == '_', 'a' <= r && r <= 'z', 'A' <= r && r <= 'Z':
+		case '0' <= r && r <= '9' && i > 0:
+		default:
+			return false
+		}
+	}
+	return name != ""
+}
+
+// supersede hands the comments of orig, the first or last token of
+// some node being replaced, to tok, the corresponding token of its
+// replacement. Does nothing unless tok is a fresh synthetic token; if
+// orig is itself a replacement, tok takes over whatever orig replaced.
+func supersede(tok, orig token.Token) {
+	if !tok.IsSynthetic() || !tok.IsLeaf() || !tok.Supersedes().IsZero() {
+		return
+	}
+	if orig.IsSynthetic() {
+		orig = orig.Supersedes()
+	}
+	if orig.IsZero() || !orig.IsLeaf() {
+		return
+	}
+	tok.Context().Supersede(tok, orig)
+}
+
+// declFirstToken returns the first token of decl, or zero if it cannot
+// be determined.
+func declFirstToken(decl ast.DeclAny) token.Token {
+	switch decl.Kind() {
+	case ast.DeclKindEmpty:
+		return decl.AsEmpty().Semicolon()
+	case ast.DeclKindSyntax:
+		return decl.AsSyntax().KeywordToken()
+	case ast.DeclKindPackage:
+		return decl.AsPackage().KeywordToken()
+	case ast.DeclKindImport:
+		return decl.AsImport().KeywordToken()
+	case ast.DeclKindRange:
+		return decl.AsRange().KeywordToken()
+	case ast.DeclKindDef:
+		def := decl.AsDef()
+		if tok := typeFirstToken(def.Type()); !tok.IsZero() {
+			return tok
+		}
+		return pathFirstToken(def.Name())
+	default:
+		return token.Zero
+	}
+}
+
+// declLastToken returns the semicolon that ends decl, or zero if it
+// does not end in one.
+func declLastToken(decl ast.DeclAny) token.Token {
+	switch decl.Kind() {
+	case ast.DeclKindEmpty:
+		return decl.AsEmpty().Semicolon()
+	case ast.DeclKindSyntax:
+		return decl.AsSyntax().Semicolon()
+	case ast.DeclKindPackage:
+		return decl.AsPackage().Semicolon()
+	case ast.DeclKindImport:
+		return decl.AsImport().Semicolon()
+	case ast.DeclKindRange:
+		return decl.AsRange().Semicolon()
+	case ast.DeclKindDef:
+		return decl.AsDef().Semicolon()
+	default:
+		return token.Zero
+	}
+}
+
+// typeFirstToken returns the first token of ty.
+func typeFirstToken(ty ast.TypeAny) token.Token {
+	switch ty.Kind() {
+	case ast.TypeKindPath:
+		return pathFirstToken(ty.AsPath().Path)
+	case ast.TypeKindPrefixed:
+		return ty.AsPrefixed().PrefixToken()
+	case ast.TypeKindGeneric:
+		return pathFirstToken(ty.AsGeneric().Path())
+	default:
+		return token.Zero
+	}
+}
+
+// exprFirstToken returns the first token of expr.
+func exprFirstToken(expr ast.ExprAny) token.Token {
+	switch expr.Kind() {
+	case ast.ExprKindLiteral:
+		return expr.AsLiteral().Token
+	case ast.ExprKindPath:
+		return pathFirstToken(expr.AsPath().Path)
+	case ast.ExprKindPrefixed:
+		return expr.AsPrefixed().PrefixToken()
+	case ast.ExprKindRange:
+		start, _ := expr.AsRange().Bounds()
+		return exprFirstToken(start)
+	case ast.ExprKindArray:
+		return expr.AsArray().Brackets()
+	case ast.ExprKindDict:
+		return expr.AsDict().Braces()
+	case ast.ExprKindField:
+		return exprFirstToken(expr.AsField().Key())
+	default:
+		return token.Zero
+	}
+}
+
+// pathFirstToken returns the first token of path: the separator of
+// its first component if set, otherwise its name.
+func pathFirstToken(path ast.Path) token.Token {
+	if ident := path.AsIdent(); !ident.IsZero() {
+		// This also covers the keyword paths of synthetic definitions,
+		// which have no components.
+		return ident
+	}
+	pc, ok := iterx.First(path.Components())
+	if !ok {
+		return token.Zero
+	}
+	if !pc.Separator().IsZero() {
+		return pc.Separator()
+	}
+	return pc.Name()
+}
@@ -69,7 +69,7 @@ func (e ExprAny) AsLiteral() ExprLiteral {
 	}
 	return ExprLiteral{
 		File:  e.Context(),
-		Token: id.Wrap(e.Context().Stream(), id.ID[token.Token](e.ID().Value())),
+		Token: id.Wrap(e.Context().Stream(), literalToken(id.ID[ExprAny](e.ID().Value()))),
 	}
 }
 
@@ -15,6 +15,8 @@
 package ast
 
 import (
+	"math"
+
 	"github.com/bufbuild/protocompile/experimental/id"
 	"github.com/bufbuild/protocompile/experimental/token"
 )
@@ -48,6 +50,27 @@ func (e ExprLiteral) AsAny() ExprAny {
 
 	return id.WrapDyn(
 		e.File,
-		id.NewDyn(ExprKindLiteral, id.ID[ExprAny](e.ID())),
+		id.NewDyn(ExprKindLiteral, literalID(e.ID())),
 	)
 }
+
+// literalID returns the ID under which an [ExprAny] stores a literal whose
+// token is tok.
+//
+// [ExprKind.DecodeDynID] tells a path, which is stored as a pair of token IDs,
+// apart from other expressions by the sign of the second half of the ID. That
+// is where the token ID of a literal goes, but synthetic token IDs are
+// negative. Synthetic token IDs have every high bit set, whereas natural token
+// IDs never get anywhere near 2^30, so the sign bit is cleared here, and bit
+// 30 tells [literalToken] whether to set it again.
+func literalID(tok token.ID) id.ID[ExprAny] {
+	return id.ID[ExprAny](int32(tok) &^ math.MinInt32)
+}
+
+// literalToken is the inverse of [literalID].
+func literalToken(e id.ID[ExprAny]) token.ID {
+	if e&(1<<30) != 0 {
+		return token.ID(int32(e) | math.MinInt32)
+	}
+	return token.ID(e)
+}
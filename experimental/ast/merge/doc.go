@@ -0,0 +1,40 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package merge merges an overlay Protobuf AST into a base AST, such as
+// when maintaining local additions to upstream definitions.
+//
+// [Merge] matches messages, enums, services, and oneofs by name, merging
+// their bodies recursively, and appends the members of the overlay that the
+// base does not have. Imports and options are added unless the base already
+// has them. Conflicts, such as a field whose number is already in use, are
+// reported to a [report.Report] with the [rtags.MergeConflict] tag, and the
+// conflicting parts of the overlay are dropped.
+//
+// Comments on both sides are preserved, so the result is suitable for
+// rendering with the
+// [github.com/bufbuild/protocompile/experimental/ast/printer] package:
+//
+//	merged, err := merge.Merge(base, overlay, r)
+//	if err != nil {
+//	    return err
+//	}
+//	out, err := printer.PrintFile(printer.Options{
+//	    Format:     true,
+//	    Formatting: printer.Default(),
+//	}, merged)
+//
+// The merge itself is expressed as a list of
+// [github.com/bufbuild/protocompile/experimental/ast/edit] edits.
+package merge
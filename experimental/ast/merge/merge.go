@@ -0,0 +1,320 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/ast/edit"
+	"github.com/bufbuild/protocompile/experimental/ast/printer"
+	"github.com/bufbuild/protocompile/experimental/parser"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/source"
+)
+
+// Merge merges overlay into base, returning the merged file. Neither input
+// is modified.
+//
+// Definitions in overlay that match a definition in base by name and kind
+// have their members merged into it; everything else in overlay is added to
+// base. Where overlay conflicts with base, base wins: a diagnostic is
+// reported to r and the conflicting part of overlay is dropped. Members that
+// overlay redeclares exactly as they appear in base are dropped silently.
+//
+// The merged file has base's path. Comments are carried along with the
+// declarations they are attached to. Diagnostics point into base and
+// overlay, unless an input was modified after it was parsed, in which case
+// they point into the merged file.
+//
+// Returns an error only if the inputs cannot be combined at all, which
+// happens if an input is so malformed that printing it does not round-trip.
+func Merge(base, overlay *ast.File, r *report.Report) (*ast.File, error) {
+	m, err := combine(base, overlay, r)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mergeFile()
+	if err := edit.ApplyEdits(m.file, m.edits); err != nil {
+		return nil, fmt.Errorf("merging %q into %q: %w", overlay.Path(), base.Path(), err)
+	}
+	return m.file, nil
+}
+
+// merger holds the state for a single call to [Merge].
+//
+// Declarations cannot be shared between files, and tokens carry their
+// comments only within the file that lexed them. So rather than copying
+// overlay's declarations into base, the merger parses the text of both
+// inputs as a single file, and then moves overlay's declarations from the
+// end of that file into base's definitions.
+type merger struct {
+	file  *ast.File
+	r     *report.Report
+	edits []edit.Edit
+
+	// The top-level declarations of file that came from each input.
+	base, overlay []ast.DeclAny
+
+	// The offset in file at which overlay's text starts, and the inputs'
+	// own source files, if file's text is a copy of them; see [merger.span].
+	split                 int
+	baseFile, overlayFile *source.File
+
+	// Extension numbers in use in base, by extendee.
+	extensions map[string]map[int64]ast.DeclDef
+}
+
+// combine prints both inputs and parses the result as a single file.
+func combine(base, overlay *ast.File, r *report.Report) (*merger, error) {
+	baseText, err := printer.PrintFile(printer.Options{}, base)
+	if err != nil {
+		return nil, fmt.Errorf("printing %q: %w", base.Path(), err)
+	}
+	overlayText, err := printer.PrintFile(printer.Options{}, overlay)
+	if err != nil {
+		return nil, fmt.Errorf("printing %q: %w", overlay.Path(), err)
+	}
+
+	m := &merger{r: r}
+	if base.Stream().Text() == baseText {
+		m.baseFile = base.Stream().File
+	}
+	if overlay.Stream().Text() == overlayText {
+		m.overlayFile = overlay.Stream().File
+	}
+
+	text := baseText
+	if text != "" && !strings.HasSuffix(text, "\n") {
+		text += "\n"
+	}
+	m.split = len(text)
+	text += overlayText
+
+	// Parsing reports a second syntax and package declaration, among other
+	// things; those are the merger's to diagnose, not the parser's.
+	m.file, _ = parser.Parse(base.Path(), source.NewFile(base.Path(), text), new(report.Report))
+
+	decls := slices.Collect(seq.Values(m.file.Decls()))
+	n := base.Decls().Len()
+	if len(decls) != n+overlay.Decls().Len() ||
+		!sameShape(base.Decls(), decls[:n]) ||
+		!sameShape(overlay.Decls(), decls[n:]) {
+		return nil, fmt.Errorf("merging %q into %q: inputs do not round-trip through the printer", overlay.Path(), base.Path())
+	}
+	m.base, m.overlay = decls[:n], decls[n:]
+	return m, nil
+}
+
+// sameShape reports whether got has the same kinds of declarations as want,
+// recursively.
+func sameShape(want seq.Indexer[ast.DeclAny], got []ast.DeclAny) bool {
+	if want.Len() != len(got) {
+		return false
+	}
+	for i, decl := range got {
+		if want.At(i).Kind() != decl.Kind() {
+			return false
+		}
+		a, b := bodyOf(want.At(i)), bodyOf(decl)
+		if a.IsZero() != b.IsZero() {
+			return false
+		}
+		if !a.IsZero() && !sameShape(a.Decls(), slices.Collect(seq.Values(b.Decls()))) {
+			return false
+		}
+	}
+	return true
+}
+
+// mergeFile merges the top-level declarations of overlay into base.
+func (m *merger) mergeFile() {
+	var syntax ast.DeclSyntax
+	var pkg ast.DeclPackage
+	imports := make(map[string]bool)
+	file := newScope(ast.DeclAny{}, m.base)
+	for _, decl := range m.base {
+		switch decl.Kind() {
+		case ast.DeclKindSyntax:
+			syntax = decl.AsSyntax()
+		case ast.DeclKindPackage:
+			pkg = decl.AsPackage()
+		case ast.DeclKindImport:
+			imports[importPath(decl.AsImport())] = true
+		}
+	}
+
+	m.extensions = make(map[string]map[int64]ast.DeclDef)
+	for _, decl := range m.base {
+		m.indexExtensions(decl)
+	}
+
+	for _, decl := range m.overlay {
+		switch decl.Kind() {
+		case ast.DeclKindSyntax:
+			switch over := decl.AsSyntax(); {
+			case syntax.IsZero():
+				m.place(decl)
+				continue
+			case !sameText(syntax.Value(), over.Value()):
+				m.r.Errorf("overlay has a different %s than base", over.Keyword()).Apply(
+					report.Tag(rtags.MergeConflict),
+					report.Snippetf(m.span(over.Value()), "declared here"),
+					report.Snippetf(m.span(syntax.Value()), "base declares this"),
+				)
+			}
+			m.delete(decl)
+
+		case ast.DeclKindPackage:
+			switch over := decl.AsPackage(); {
+			case pkg.IsZero():
+				m.place(decl)
+				continue
+			case over.Path().Canonicalized() != pkg.Path().Canonicalized():
+				m.r.Errorf("overlay has a different package than base").Apply(
+					report.Tag(rtags.MergeConflict),
+					report.Snippetf(m.span(over.Path()), "declared here"),
+					report.Snippetf(m.span(pkg.Path()), "base declares this"),
+				)
+			}
+			m.delete(decl)
+
+		case ast.DeclKindImport:
+			path := importPath(decl.AsImport())
+			if imports[path] {
+				m.delete(decl)
+				continue
+			}
+			imports[path] = true
+			m.place(decl)
+
+		case ast.DeclKindEmpty:
+			m.delete(decl)
+
+		default:
+			// New definitions are already where they belong: at the end of
+			// the file, after base's.
+			m.mergeDecl(file, decl)
+		}
+	}
+}
+
+// place moves a new top-level header declaration from overlay to just after
+// base's declarations of the same kind.
+func (m *merger) place(decl ast.DeclAny) {
+	rank := headerRank(decl)
+	for _, before := range m.base {
+		if headerRank(before) > rank {
+			m.edits = append(m.edits, edit.Edit{Kind: edit.KindMove, Target: decl, Before: before})
+			return
+		}
+	}
+}
+
+// headerRank orders the kinds of declarations that appear at the top of a
+// file.
+func headerRank(decl ast.DeclAny) int {
+	switch decl.Kind() {
+	case ast.DeclKindSyntax:
+		return 0
+	case ast.DeclKindPackage:
+		return 1
+	case ast.DeclKindImport:
+		return 2
+	case ast.DeclKindDef:
+		if decl.AsDef().Classify() == ast.DefKindOption {
+			return 3
+		}
+	}
+	return 4
+}
+
+// add adds a new declaration from overlay to the container of s.
+func (m *merger) add(s *scope, decl ast.DeclAny) {
+	switch {
+	case s.inPlace:
+		// Already where it belongs.
+	case s.container.IsZero():
+		if decl.AsDef().Classify() == ast.DefKindOption {
+			m.place(decl)
+		}
+	default:
+		m.edits = append(m.edits, edit.Edit{Kind: edit.KindMove, Target: decl, Into: s.container})
+	}
+}
+
+// delete removes a declaration from overlay that is not merged.
+func (m *merger) delete(decl ast.DeclAny) {
+	m.edits = append(m.edits, edit.Edit{Kind: edit.KindDelete, Target: decl})
+}
+
+// span maps a span of the merged file back into the input it came from, so
+// that diagnostics point at the inputs rather than at a file that the user
+// has not seen.
+func (m *merger) span(s source.Spanner) source.Span {
+	span := s.Span()
+	switch {
+	case span.IsZero():
+	case span.Start < m.split:
+		if m.baseFile != nil {
+			span.File = m.baseFile
+		}
+	case m.overlayFile != nil:
+		span.File = m.overlayFile
+		span.Start -= m.split
+		span.End -= m.split
+	}
+	return span
+}
+
+// bodyOf returns the body of decl, if it has one.
+func bodyOf(decl ast.DeclAny) ast.DeclBody {
+	if body := decl.AsBody(); !body.IsZero() {
+		return body
+	}
+	return decl.AsDef().Body()
+}
+
+// importPath returns the path an import declaration imports.
+func importPath(imp ast.DeclImport) string {
+	if lit := imp.ImportPath().AsLiteral().AsString(); !lit.IsZero() {
+		return lit.Text()
+	}
+	return imp.ImportPath().Span().Text()
+}
+
+// sameText reports whether two nodes have the same text, disregarding
+// whitespace.
+func sameText(a, b source.Spanner) bool {
+	text := func(s source.Spanner) string {
+		span := s.Span()
+		if span.IsZero() {
+			return ""
+		}
+		return strings.Map(func(r rune) rune {
+			if unicode.IsSpace(r) {
+				return -1
+			}
+			return r
+		}, span.Text())
+	}
+	return text(a) == text(b)
+}
@@ -0,0 +1,103 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge_test
+
+import (
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/ast/merge"
+	"github.com/bufbuild/protocompile/experimental/ast/printer"
+	"github.com/bufbuild/protocompile/experimental/parser"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/source"
+	"github.com/bufbuild/protocompile/internal/golden"
+)
+
+// TestMerge exercises [merge.Merge] against testdata.
+//
+// Each <name>.yaml fixture defines a `base` and an `overlay` proto. The test
+// merges them and renders the result with [printer.Default] to compare
+// against the <name>.yaml.txt golden, and the diagnostics to compare against
+// <name>.yaml.stderr.txt. The rendered output must re-parse cleanly.
+//
+// To regenerate goldens:
+//
+//	PROTOCOMPILE_REFRESH=** go test ./experimental/ast/merge/...
+func TestMerge(t *testing.T) {
+	t.Parallel()
+
+	corpus := golden.Corpus{
+		Root:       "testdata",
+		Extensions: []string{"yaml"},
+		Refresh:    "PROTOCOMPILE_REFRESH",
+		Outputs: []golden.Output{
+			{Extension: "txt"},
+			{Extension: "stderr.txt"},
+		},
+	}
+
+	opts := printer.Options{
+		Format:     true,
+		Formatting: printer.Default(),
+	}
+
+	corpus.Run(t, func(t *testing.T, path, text string, outputs []string) {
+		var spec struct {
+			Base    string `yaml:"base"`
+			Overlay string `yaml:"overlay"`
+		}
+		if err := yaml.Unmarshal([]byte(text), &spec); err != nil {
+			t.Fatalf("parsing yaml spec: %v", err)
+		}
+
+		base := parse(t, "base.proto", spec.Base)
+		overlay := parse(t, "overlay.proto", spec.Overlay)
+
+		r := &report.Report{}
+		merged, err := merge.Merge(base, overlay, r)
+		if err != nil {
+			t.Fatalf("Merge: %v", err)
+		}
+
+		outputs[0], err = printer.PrintFile(opts, merged)
+		if err != nil {
+			t.Fatalf("PrintFile: %v", err)
+		}
+		for _, d := range r.Diagnostics {
+			if d.Tag() == "" {
+				t.Errorf("untagged diagnostic: %q", d.Message())
+			}
+		}
+		outputs[1], _, _ = report.Renderer{}.RenderString(r)
+
+		// Merging should never produce a file that does not parse.
+		parse(t, path, outputs[0])
+	})
+}
+
+func parse(t *testing.T, path, text string) *ast.File {
+	t.Helper()
+	r := &report.Report{}
+	file, _ := parser.Parse(path, source.NewFile(path, text), r)
+	for _, d := range r.Diagnostics {
+		if d.Level() <= report.Error {
+			t.Fatalf("%s does not parse: %q", path, d.Message())
+		}
+	}
+	return file
+}
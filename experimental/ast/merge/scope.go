@@ -0,0 +1,532 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package merge
+
+import (
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/internal/taxa"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/token"
+	"github.com/bufbuild/protocompile/experimental/token/keyword"
+)
+
+// maxFieldNumber is the largest valid field number.
+const maxFieldNumber = 1<<29 - 1
+
+// scope indexes the members of a body in base, against which the members of
+// the matching body in overlay are merged.
+type scope struct {
+	// The definition whose body this is, or zero for the file.
+	container ast.DeclAny
+	// Set if container is itself new, so that new members need not be
+	// moved into it.
+	inPlace bool
+
+	// Named members, and options by name. The members of a oneof share
+	// their names and numbers with the enclosing message.
+	names   map[string]ast.DeclDef
+	options map[string]ast.DeclDef
+
+	// Set for messages and enums.
+	numbers *numbers
+}
+
+// numbers tracks the numbers and reserved names of a message or enum.
+type numbers struct {
+	max    int64
+	alias  bool // Set for enums that allow aliases.
+	used   map[int64]ast.DeclDef
+	ranges []numberRange
+	names  map[string]ast.DeclRange
+}
+
+// numberRange is a reserved or extension range.
+type numberRange struct {
+	start, end int64
+	decl       ast.DeclRange
+}
+
+// newScope indexes the members of container, which has the given body
+// declarations.
+func newScope(container ast.DeclAny, decls []ast.DeclAny) *scope {
+	s := &scope{
+		container: container,
+		names:     make(map[string]ast.DeclDef),
+		options:   make(map[string]ast.DeclDef),
+	}
+	switch container.AsDef().Classify() {
+	case ast.DefKindMessage, ast.DefKindGroup:
+		s.numbers = newNumbers(maxFieldNumber)
+	case ast.DefKindEnum:
+		s.numbers = newNumbers(math.MaxInt32)
+	}
+
+	for _, decl := range decls {
+		s.index(decl)
+	}
+	return s
+}
+
+// oneof returns a scope for a oneof in s's message.
+func (s *scope) oneof(container ast.DeclAny, inPlace bool) *scope {
+	sub := &scope{
+		container: container,
+		inPlace:   inPlace,
+		names:     s.names,
+		options:   make(map[string]ast.DeclDef),
+		numbers:   s.numbers,
+	}
+	if !inPlace {
+		for _, decl := range members(container.AsDef()) {
+			if def := decl.AsDef(); def.Classify() == ast.DefKindOption {
+				sub.index(decl)
+			}
+		}
+	}
+	return sub
+}
+
+// index adds a declaration from base to s.
+func (s *scope) index(decl ast.DeclAny) {
+	if rng := decl.AsRange(); !rng.IsZero() {
+		if s.numbers != nil {
+			s.numbers.reserve(rng)
+		}
+		return
+	}
+
+	def := decl.AsDef()
+	switch kind := def.Classify(); kind {
+	case ast.DefKindInvalid, ast.DefKindExtend:
+		return
+
+	case ast.DefKindOption:
+		s.setOption(def)
+		return
+
+	case ast.DefKindOneof:
+		for _, member := range members(def) {
+			if member.AsDef().Classify() != ast.DefKindOption {
+				s.index(member)
+			}
+		}
+
+	case ast.DefKindField, ast.DefKindGroup, ast.DefKindEnumValue:
+		if s.numbers == nil {
+			break
+		}
+		if n, ok := evalNumber(def.Value(), s.numbers.max); ok {
+			if _, ok := s.numbers.used[n]; !ok {
+				s.numbers.used[n] = def
+			}
+		}
+	}
+
+	if name := def.Name().AsIdent(); !name.IsZero() {
+		if _, ok := s.names[name.Name()]; !ok {
+			s.names[name.Name()] = def
+		}
+	}
+}
+
+// setOption records an option setting.
+func (s *scope) setOption(def ast.DeclDef) {
+	name := def.Name().Canonicalized()
+	s.options[name] = def
+	if s.numbers != nil && name == "allow_alias" {
+		s.numbers.alias = def.Value().AsPath().AsKeyword() == keyword.True
+	}
+}
+
+func newNumbers(limit int64) *numbers {
+	return &numbers{
+		max:   limit,
+		used:  make(map[int64]ast.DeclDef),
+		names: make(map[string]ast.DeclRange),
+	}
+}
+
+// reserve records a reserved or extension range.
+func (n *numbers) reserve(rng ast.DeclRange) {
+	ranges, names := n.items(rng)
+	n.ranges = append(n.ranges, ranges...)
+	for _, name := range names {
+		n.names[name] = rng
+	}
+}
+
+// items evaluates the numbers and names that a range declaration covers.
+func (n *numbers) items(rng ast.DeclRange) (ranges []numberRange, names []string) {
+	for expr := range seq.Values(rng.Ranges()) {
+		switch expr.Kind() {
+		case ast.ExprKindLiteral:
+			if str := expr.AsLiteral().AsString(); !str.IsZero() {
+				names = append(names, str.Text())
+				continue
+			}
+		case ast.ExprKindPath:
+			if ident := expr.AsPath().AsIdent(); !ident.IsZero() && ident.Keyword() != keyword.Max {
+				names = append(names, ident.Name())
+				continue
+			}
+		case ast.ExprKindRange:
+			lo, hi := expr.AsRange().Bounds()
+			start, ok1 := evalNumber(lo, n.max)
+			end, ok2 := evalNumber(hi, n.max)
+			if ok1 && ok2 {
+				ranges = append(ranges, numberRange{start, end, rng})
+			}
+			continue
+		}
+		if v, ok := evalNumber(expr, n.max); ok {
+			ranges = append(ranges, numberRange{v, v, rng})
+		}
+	}
+	return ranges, names
+}
+
+// rangeOf returns the range that contains v, if any.
+func (n *numbers) rangeOf(v int64) (numberRange, bool) {
+	for _, rng := range n.ranges {
+		if rng.start <= v && v <= rng.end {
+			return rng, true
+		}
+	}
+	return numberRange{}, false
+}
+
+// usedIn returns the smallest number in use within rng, if any.
+func (n *numbers) usedIn(rng numberRange) (int64, ast.DeclDef, bool) {
+	var found []int64
+	for v := range n.used {
+		if rng.start <= v && v <= rng.end {
+			found = append(found, v)
+		}
+	}
+	if len(found) == 0 {
+		return 0, ast.DeclDef{}, false
+	}
+	v := slices.Min(found)
+	return v, n.used[v], true
+}
+
+// mergeDecl merges a declaration from overlay into s.
+func (m *merger) mergeDecl(s *scope, decl ast.DeclAny) {
+	switch decl.Kind() {
+	case ast.DeclKindEmpty:
+		m.delete(decl)
+	case ast.DeclKindRange:
+		m.mergeRange(s, decl.AsRange())
+	case ast.DeclKindDef:
+		m.mergeDef(s, decl.AsDef())
+	}
+}
+
+// mergeBody merges the members of a definition from overlay into s.
+func (m *merger) mergeBody(s *scope, def ast.DeclDef) {
+	for _, decl := range members(def) {
+		m.mergeDecl(s, decl)
+	}
+}
+
+// mergeDef merges a definition from overlay into s.
+func (m *merger) mergeDef(s *scope, def ast.DeclDef) {
+	decl := def.AsAny()
+	switch kind := def.Classify(); kind {
+	case ast.DefKindOption:
+		name := def.Name().Canonicalized()
+		prev, ok := s.options[name]
+		switch {
+		case !ok:
+			s.setOption(def)
+			m.add(s, decl)
+		case sameText(prev.Value(), def.Value()):
+			m.delete(decl)
+		default:
+			m.r.Errorf("option `%s` is already set to a different value", name).Apply(
+				report.Tag(rtags.MergeConflict),
+				report.Snippetf(m.span(def.Value()), "set here"),
+				report.Snippetf(m.span(prev.Value()), "base sets this"),
+			)
+			m.delete(decl)
+		}
+
+	case ast.DefKindExtend:
+		m.mergeExtend(def)
+		m.add(s, decl)
+
+	case ast.DefKindMessage, ast.DefKindEnum, ast.DefKindService, ast.DefKindOneof:
+		if m.redeclared(s, def) {
+			return
+		}
+		prev, ok := s.names[def.Name().AsIdent().Name()]
+		if !ok {
+			s.names[def.Name().AsIdent().Name()] = def
+			if kind == ast.DefKindOneof {
+				// A new oneof's fields still need to fit into the message.
+				m.mergeBody(s.oneof(decl, true), def)
+			}
+			m.add(s, decl)
+			return
+		}
+
+		sub := s.oneof(prev.AsAny(), false)
+		if kind != ast.DefKindOneof {
+			sub = newScope(prev.AsAny(), members(prev))
+		}
+		m.mergeBody(sub, def)
+		m.delete(decl)
+
+	case ast.DefKindField, ast.DefKindGroup, ast.DefKindEnumValue:
+		if m.redeclared(s, def) || !m.checkNumber(s, def) {
+			return
+		}
+		name := def.Name().AsIdent().Name()
+		s.names[name] = def
+		if n, ok := evalNumber(def.Value(), s.numbers.max); ok {
+			if _, ok := s.numbers.used[n]; !ok {
+				s.numbers.used[n] = def
+			}
+		}
+		m.add(s, decl)
+
+	case ast.DefKindMethod:
+		if m.redeclared(s, def) {
+			return
+		}
+		s.names[def.Name().AsIdent().Name()] = def
+		m.add(s, decl)
+	}
+}
+
+// redeclared checks whether def from overlay has the name of a member of s,
+// and if so, diagnoses any conflict and drops it. Definitions with bodies of
+// the same kind are not redeclarations, since their bodies get merged.
+func (m *merger) redeclared(s *scope, def ast.DeclDef) bool {
+	name := def.Name().AsIdent()
+	if name.IsZero() {
+		return false
+	}
+	prev, ok := s.names[name.Name()]
+	if !ok {
+		return false
+	}
+
+	kind := def.Classify()
+	switch {
+	case prev.Classify() != kind:
+		m.r.Errorf("`%s` is already declared as a different kind of definition", name.Name()).Apply(
+			report.Tag(rtags.MergeConflict),
+			report.Snippetf(m.span(def.Name()), "%v declared here", taxa.Classify(def)),
+			report.Snippetf(m.span(prev.Name()), "%v in base", taxa.Classify(prev)),
+		)
+
+	case kind == ast.DefKindMessage, kind == ast.DefKindEnum,
+		kind == ast.DefKindService, kind == ast.DefKindOneof:
+		return false
+
+	case !sameText(prev, def):
+		m.r.Errorf("%v `%s` differs from its declaration in base", taxa.Classify(def), name.Name()).Apply(
+			report.Tag(rtags.MergeConflict),
+			report.Snippetf(m.span(def), "declared here"),
+			report.Snippetf(m.span(prev), "base declares it like this"),
+		)
+	}
+
+	m.delete(def.AsAny())
+	return true
+}
+
+// checkNumber checks that the number and name of a new field or enum value
+// from overlay are not in use or reserved in s, diagnosing and dropping it
+// if they are.
+func (m *merger) checkNumber(s *scope, def ast.DeclDef) bool {
+	if s.numbers == nil {
+		return true
+	}
+
+	what := taxa.FieldNumber
+	if def.Classify() == ast.DefKindEnumValue {
+		what = taxa.EnumValue
+	}
+
+	name := def.Name().AsIdent().Name()
+	n, ok := evalNumber(def.Value(), s.numbers.max)
+	var d *report.Diagnostic
+	if ok {
+		if prev, used := s.numbers.used[n]; used && !(what == taxa.EnumValue && s.numbers.alias) {
+			d = m.r.Errorf("%v `%d` is already used by `%s`", what, n, prev.Name().AsIdent().Name()).Apply(
+				report.Snippetf(m.span(def.Value()), "used here"),
+				report.Snippetf(m.span(prev.Value()), "base uses it here"),
+			)
+		} else if rng, ok := s.numbers.rangeOf(n); ok {
+			format, note := "use of reserved %v `%d`", "base reserves it here"
+			if rng.decl.IsExtensions() {
+				format, note = "%v `%d` is in an extension range", "base declares the range here"
+			}
+			d = m.r.Errorf(format, what, n).Apply(
+				report.Snippetf(m.span(def.Value()), "used here"),
+				report.Snippetf(m.span(rng.decl), "%s", note),
+			)
+		}
+	}
+	if rng, ok := s.numbers.names[name]; ok && d == nil {
+		d = m.r.Errorf("use of reserved name `%s`", name).Apply(
+			report.Snippetf(m.span(def.Name()), "used here"),
+			report.Snippetf(m.span(rng), "base reserves it here"),
+		)
+	}
+
+	if d == nil {
+		return true
+	}
+	d.Apply(report.Tag(rtags.MergeConflict))
+	m.delete(def.AsAny())
+	return false
+}
+
+// mergeRange merges a reserved or extension range from overlay into s.
+func (m *merger) mergeRange(s *scope, rng ast.DeclRange) {
+	if s.numbers == nil {
+		return
+	}
+
+	ranges, names := s.numbers.items(rng)
+	var d *report.Diagnostic
+	for _, r := range ranges {
+		if n, prev, ok := s.numbers.usedIn(r); ok {
+			d = m.r.Errorf("cannot reserve number `%d`, which is used by `%s`", n, prev.Name().AsIdent().Name()).Apply(
+				report.Snippetf(m.span(rng), "reserved here"),
+				report.Snippetf(m.span(prev.Value()), "base uses it here"),
+			)
+			break
+		}
+	}
+	for _, name := range names {
+		if prev, ok := s.names[name]; ok && d == nil {
+			d = m.r.Errorf("cannot reserve name `%s`, which is in use", name).Apply(
+				report.Snippetf(m.span(rng), "reserved here"),
+				report.Snippetf(m.span(prev.Name()), "base uses it here"),
+			)
+		}
+	}
+
+	if d != nil {
+		d.Apply(report.Tag(rtags.MergeConflict))
+		m.delete(rng.AsAny())
+		return
+	}
+	s.numbers.reserve(rng)
+	m.add(s, rng.AsAny())
+}
+
+// indexExtensions records the extension numbers declared anywhere within a
+// declaration from base.
+func (m *merger) indexExtensions(decl ast.DeclAny) {
+	def := decl.AsDef()
+	if def.Classify() != ast.DefKindExtend {
+		for _, member := range members(def) {
+			m.indexExtensions(member)
+		}
+		return
+	}
+
+	used := m.extendee(def)
+	for _, member := range members(def) {
+		if n, ok := evalNumber(member.AsDef().Value(), maxFieldNumber); ok {
+			if _, ok := used[n]; !ok {
+				used[n] = member.AsDef()
+			}
+		}
+	}
+}
+
+// mergeExtend checks the extensions declared by an extend block from overlay
+// against those in base, dropping any whose number is already in use.
+func (m *merger) mergeExtend(def ast.DeclDef) {
+	used := m.extendee(def)
+	for _, member := range members(def) {
+		field := member.AsDef()
+		n, ok := evalNumber(field.Value(), maxFieldNumber)
+		if !ok {
+			continue
+		}
+		if prev, ok := used[n]; ok {
+			m.r.Errorf("extension number `%d` of `%s` is already used by `%s`",
+				n, strings.TrimPrefix(def.Name().Canonicalized(), "."), prev.Name().AsIdent().Name()).Apply(
+				report.Tag(rtags.MergeConflict),
+				report.Snippetf(m.span(field.Value()), "used here"),
+				report.Snippetf(m.span(prev.Value()), "base uses it here"),
+			)
+			m.delete(member)
+			continue
+		}
+		used[n] = field
+	}
+}
+
+// extendee returns the extension numbers in use for an extend block's
+// extendee.
+func (m *merger) extendee(def ast.DeclDef) map[int64]ast.DeclDef {
+	name := strings.TrimPrefix(def.Name().Canonicalized(), ".")
+	used := m.extensions[name]
+	if used == nil {
+		used = make(map[int64]ast.DeclDef)
+		m.extensions[name] = used
+	}
+	return used
+}
+
+// members returns the body declarations of def, if it has a body.
+func members(def ast.DeclDef) []ast.DeclAny {
+	body := def.Body()
+	if body.IsZero() {
+		return nil
+	}
+	return slices.Collect(seq.Values(body.Decls()))
+}
+
+// evalNumber evaluates a field number, enum value, or range bound, where limit
+// is the value of the `max` keyword.
+func evalNumber(expr ast.ExprAny, limit int64) (int64, bool) {
+	switch expr.Kind() {
+	case ast.ExprKindLiteral:
+		tok := expr.AsLiteral().Token
+		if tok.Kind() != token.Number {
+			break
+		}
+		v, exact := tok.AsNumber().Int()
+		if exact && v <= math.MaxInt64 {
+			return int64(v), true
+		}
+	case ast.ExprKindPrefixed:
+		prefixed := expr.AsPrefixed()
+		if prefixed.Prefix() == keyword.Sub {
+			v, ok := evalNumber(prefixed.Expr(), limit)
+			return -v, ok
+		}
+	case ast.ExprKindPath:
+		if expr.AsPath().AsKeyword() == keyword.Max {
+			return limit, true
+		}
+	}
+	return 0, false
+}
@@ -112,7 +112,17 @@ func (p Path) ToRelative() Path {
 // the zero token.
 func (p Path) AsIdent() token.Token {
 	if p.raw.start != p.raw.end {
-		return token.Zero
+		if !p.IsSynthetic() {
+			return token.Zero
+		}
+
+		// Synthetic paths built by [Nodes.NewPath] always have a range, even
+		// when they contain a single identifier.
+		pc, ok := iterx.OnlyOne(p.Components())
+		if !ok || !pc.Separator().IsZero() {
+			return token.Zero
+		}
+		return pc.AsIdent()
 	}
 
 	tok := id.Wrap(p.Context().Stream(), p.raw.start)
@@ -152,6 +162,16 @@ func (p Path) IsIdents(idents ...string) bool {
 
 // source.Span implements [source.Spanner].
 func (p Path) Span() source.Span {
+	if p.IsSynthetic() && p.raw.start != p.raw.end {
+		// Synthetic paths have no span of their own, but their components may
+		// supersede natural tokens; see [token.Stream.Supersede].
+		var span source.Span
+		for pc := range p.Components() {
+			span = source.JoinSpans(span, pc.Span())
+		}
+		return span
+	}
+
 	// No need to check for zero here, if p is zero both start and end will be
 	// zero tokens.
 	return source.JoinSpans(
@@ -290,7 +310,7 @@ func (p Path) Canonicalized() string {
 	// a fresh string.
 	if id := p.AsIdent(); !id.IsZero() {
 		return id.Name()
-	} else if p.isCanonical() {
+	} else if !p.IsSynthetic() && p.isCanonical() {
 		return p.Span().Text()
 	}
 
@@ -308,7 +328,7 @@ func (p Path) canonicalized(out *strings.Builder) {
 		if i > 0 || !pc.Separator().IsZero() {
 			out.WriteString(pc.Separator().Text())
 		}
-		if id := pc.Name(); !id.IsZero() {
+		if id := pc.AsIdent(); !id.IsZero() {
 			out.WriteString(id.Name())
 		} else {
 			out.WriteByte('(')
@@ -438,8 +458,9 @@ func (p PathComponent) IsFirst() bool {
 // IsLast returns whether this is the last component of its path.
 func (p PathComponent) IsLast() bool {
 	if p.Path().IsSynthetic() {
-		i, j := p.path.synthRange()
-		return int(p.idx) == j-i
+		_, end := p.synthBounds()
+		_, j := p.path.synthRange()
+		return end == j
 	}
 	return p.separator == p.path.end || p.name == p.path.end
 }
@@ -448,15 +469,15 @@ func (p PathComponent) IsLast() bool {
 // component boundary before this component.
 //
 // after's first component will be this component.
-//
-// Not currently implemented for synthetic paths.
 func (p PathComponent) SplitBefore() (before, after Path) {
 	if p.IsFirst() {
 		return Path{}, p.Path()
 	}
 
 	if p.Path().IsSynthetic() {
-		panic("protocompile/ast: called PathComponent.SplitBefore with synthetic path")
+		i, j := p.path.synthRange()
+		start, _ := p.synthBounds()
+		return p.synthSlice(i, start), p.synthSlice(start, j)
 	}
 
 	prefix, suffix := p.Path(), p.Path()
@@ -481,7 +502,9 @@ func (p PathComponent) SplitAfter() (before, after Path) {
 	}
 
 	if p.Path().IsSynthetic() {
-		panic("protocompile/ast: called PathComponent.SplitAfter with synthetic path")
+		i, j := p.path.synthRange()
+		_, end := p.synthBounds()
+		return p.synthSlice(i, end), p.synthSlice(end, j)
 	}
 
 	prefix, suffix := p.Path(), p.Path()
@@ -496,6 +519,35 @@ func (p PathComponent) SplitAfter() (before, after Path) {
 	return prefix.trim(), suffix.trim()
 }
 
+// synthBounds returns the range of children of this component's synthetic
+// path that make up this component.
+func (p PathComponent) synthBounds() (start, end int) {
+	start, _ = p.path.synthRange()
+	for pc := range p.Path().Components() {
+		end = start
+		if !pc.Separator().IsZero() {
+			end++
+		}
+		if !pc.Name().IsZero() {
+			end++
+		}
+		if pc.idx == p.idx {
+			break
+		}
+		start = end
+	}
+	return start, end
+}
+
+// synthSlice returns the path consisting of children i through j of this
+// component's synthetic path.
+func (p PathComponent) synthSlice(i, j int) Path {
+	if i >= j {
+		return Path{}
+	}
+	return Path{p.withContext, PathID{start: p.path.start}.withSynthRange(i, j)}
+}
+
 // Separator is the token that separates this component from the previous one, if
 // any. This may be a dot or a slash.
 func (p PathComponent) Separator() token.Token {
@@ -535,7 +587,8 @@ func (p PathComponent) AsExtension() Path {
 	// If this is a synthetic token, its children are already precisely a path,
 	// so we can use the "synthetic with children" form of Path.
 	if p.Name().IsSynthetic() {
-		return Path{p.withContext, PathID{p.Name().ID(), 0}}
+		n := iterx.Count(p.Name().Children().Rest())
+		return Path{p.withContext, PathID{start: p.Name().ID()}.withSynthRange(0, n)}
 	}
 
 	// Find the first and last non-skippable tokens to be the bounds.
@@ -148,6 +148,32 @@ func TestSyntheticSplit(t *testing.T) {
 	start, end = path.Split(2)
 	pathEq(t, start, components[:2])
 	pathEq(t, end, components[2:])
+
+	start, end = nth(path.Components(), 0).SplitBefore()
+	pathEq(t, start, [][2]token.Token{})
+	pathEq(t, end, components)
+	start, end = nth(path.Components(), 0).SplitAfter()
+	pathEq(t, start, components[:1])
+	pathEq(t, end, components[1:])
+
+	start, end = nth(path.Components(), 2).SplitBefore()
+	pathEq(t, start, components[:2])
+	pathEq(t, end, components[2:])
+	start, end = nth(path.Components(), 2).SplitAfter()
+	pathEq(t, start, components[:3])
+	pathEq(t, end, components[3:])
+
+	start, end = nth(path.Components(), 3).SplitAfter()
+	pathEq(t, start, components)
+	pathEq(t, end, [][2]token.Token{})
+
+	assert.True(t, nth(path.Components(), 3).IsLast())
+	assert.False(t, nth(path.Components(), 2).IsLast())
+	assert.Equal(t, "a.b.(a.b.c).d", path.Canonicalized())
+
+	single := ctx.Nodes().NewPath(ctx.Nodes().NewPathComponent(token.Zero, a))
+	assert.Equal(t, a, single.AsIdent())
+	assert.Equal(t, token.Zero, inner.AsIdent())
 }
 
 func pathEq(t *testing.T, path ast.Path, want [][2]token.Token) {
@@ -16,6 +16,8 @@ package printer
 
 import (
 	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/dom"
+	"github.com/bufbuild/protocompile/experimental/seq"
 	"github.com/bufbuild/protocompile/experimental/token"
 )
 
@@ -23,7 +25,7 @@ import (
 // path begins with a leading comment. Such comments cannot render
 // cleanly inline since `[/* comment */ key = value]` would force a
 // softline break after the comment in the broken (file-level) context.
-func (p *printer) firstOptionKeyHasLeadingComment(entries ast.Commas[ast.Option]) bool {
+func (p *printer) firstOptionKeyHasLeadingComment(entries seq.Indexer[ast.Option]) bool {
 	if entries.Len() == 0 {
 		return false
 	}
@@ -221,6 +223,7 @@ func (p *printer) printField(f ast.DefField, gap gapStyle) {
 	p.printType(f.Type, gap)
 	p.printPath(f.Decl.Name(), gapSpace)
 	if !f.Equals.IsZero() {
+		p.pushPadding(p.align.equals)
 		p.printToken(f.Equals, gapSpace)
 		p.printExpr(f.Tag, gapSpace)
 	}
@@ -231,6 +234,7 @@ func (p *printer) printField(f ast.DefField, gap gapStyle) {
 func (p *printer) printEnumValue(ev ast.DefEnumValue, gap gapStyle) {
 	p.printPath(ev.Decl.Name(), gap)
 	if !ev.Equals.IsZero() {
+		p.pushPadding(p.align.equals)
 		p.printToken(ev.Equals, gapSpace)
 		p.printExpr(ev.Tag, gapSpace)
 	}
@@ -323,7 +327,8 @@ func (p *printer) printBody(body ast.DeclBody) {
 	p.printToken(openTok, gapSpace)
 
 	closeComments, closeAtt := p.extractCloseComments(closeTok)
-	hasContent := body.Decls().Len() > 0 || !trivia.isEmpty() || len(closeComments) > 0
+	decls, sourceIdx := p.bodyDecls(body)
+	hasContent := decls.Len() > 0 || !trivia.isEmpty() || len(closeComments) > 0
 	if !hasContent {
 		p.printToken(closeTok, gapNone)
 		return
@@ -339,7 +344,6 @@ func (p *printer) printBody(body ast.DeclBody) {
 			len(closeComments) > 0 ||
 			p.scopeHasAttachedComments(body.Braces())
 		if !forceBroken && !p.bodyShouldBreak(openTok, closeTok) {
-			decls := body.Decls()
 			p.withGroup(func(p *printer) {
 				p.withIndent(func(indented *printer) {
 					for i := range decls.Len() {
@@ -354,7 +358,7 @@ func (p *printer) printBody(body ast.DeclBody) {
 	}
 
 	p.withIndent(func(indented *printer) {
-		indented.printScopeDecls(trivia, body.Decls(), scopeBody)
+		indented.printScopeDecls(trivia, decls, sourceIdx, scopeBody)
 		// Emit close comments inside the indent block. Also flush
 		// any pending slot comments that would otherwise be emitted
 		// outside the indent block with wrong indentation.
@@ -416,6 +420,14 @@ func (p *printer) printRange(r ast.DeclRange, gap gapStyle) {
 		p.printToken(r.KeywordToken(), gap)
 	}
 
+	if collapsed, ok := p.collapseRanges(r); ok {
+		p.emitGap(gapSpace)
+		p.push(dom.Text(collapsed))
+		p.printCompactOptions(r.Options())
+		p.printToken(r.Semicolon(), p.semiGap())
+		return
+	}
+
 	ranges := r.Ranges()
 	for i := range ranges.Len() {
 		if i > 0 {
@@ -460,6 +472,9 @@ func (p *printer) printCompactOptions(co ast.CompactOptions) {
 	entries := co.Entries()
 
 	if p.options.Format {
+		// Entries may be reordered, but the commas and detached trivia
+		// slots between them stay in place.
+		opts := p.compactOptionEntries(entries)
 		openTrailing := p.extractOpenTrailing(openTok)
 
 		// For formatted compact option elements, force multi-line expansion if the
@@ -472,7 +487,7 @@ func (p *printer) printCompactOptions(co ast.CompactOptions) {
 		forceExpand := len(openTrailing) > 0 ||
 			triviaHasComments(slots) ||
 			p.scopeHasUninlineableLeadingComments(brackets) ||
-			p.firstOptionKeyHasLeadingComment(entries)
+			p.firstOptionKeyHasLeadingComment(opts)
 		// Layout fallback: when trailing `//` rewrite is disabled, a `//`
 		// inside an inline `[...]` would consume the closing bracket.
 		// Force broken so the comment terminates safely on its own line.
@@ -499,7 +514,7 @@ func (p *printer) printCompactOptions(co ast.CompactOptions) {
 			// field line and the value expands naturally inside,
 			// matching the legacy formatter byte-for-byte.
 			singleRestore := p.ctx.with(lineToBlock(true))
-			opt := entries.At(0)
+			opt := opts.At(0)
 			emitEntry := func(p *printer) {
 				p.emitTriviaSlot(slots, 0)
 				p.printPath(opt.Path, gapNone)
@@ -539,7 +554,7 @@ func (p *printer) printCompactOptions(co ast.CompactOptions) {
 					indented.push(tagSoftbreak)
 					for i := range entries.Len() {
 						indented.emitTriviaSlot(slots, i)
-						opt := entries.At(i)
+						opt := opts.At(i)
 						if i > 0 {
 							indented.printToken(entries.Comma(i-1), gapNone)
 							indented.printPath(opt.Path, gapSoftline)
@@ -587,7 +602,7 @@ func (p *printer) printCompactOptions(co ast.CompactOptions) {
 						restore()
 					}
 					indented.emitTriviaSlot(slots, i)
-					opt := entries.At(i)
+					opt := opts.At(i)
 					optGap := gapNewline
 					if i > 0 && slots.hasBlankBefore(i) {
 						optGap = gapBlankline
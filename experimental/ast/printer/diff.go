@@ -0,0 +1,224 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/bufbuild/protocompile/experimental/report"
+)
+
+// diff returns a list of edits that turn from into to, sorted by offset.
+//
+// The edits are computed by aligning the words of both strings (runs of
+// identifier characters and individual punctuation characters, ignoring
+// whitespace) with Myers' algorithm, and then replacing the text between
+// each pair of aligned words that differs. Because formatting rarely
+// changes anything other than whitespace, this produces edits that touch
+// only the whitespace that actually changed.
+//
+// No two edits returned by diff are adjacent: there is always at least one
+// aligned word between them.
+func diff(from, to string) []report.Edit {
+	d := &differ{a: words(from), b: words(to)}
+	d.changedA = make([]bool, len(d.a))
+	d.changedB = make([]bool, len(d.b))
+	size := 2*(len(d.a)+len(d.b)) + 3
+	d.fwd = make([]int, size)
+	d.bwd = make([]int, size)
+	d.compare(0, len(d.a), 0, len(d.b))
+
+	var edits []report.Edit
+	var prevA, prevB, i, j int
+	for {
+		for i < len(d.a) && d.changedA[i] {
+			i++
+		}
+		for j < len(d.b) && d.changedB[j] {
+			j++
+		}
+
+		// Either (i, j) is a pair of aligned words, or we have reached the
+		// end of both strings.
+		startA, startB := len(from), len(to)
+		if i < len(d.a) {
+			startA, startB = d.a[i].start, d.b[j].start
+		}
+
+		if edit, ok := replace(from, to, prevA, startA, prevB, startB); ok {
+			edits = append(edits, edit)
+		}
+
+		if i == len(d.a) {
+			return edits
+		}
+		prevA, prevB = d.a[i].end(), d.b[j].end()
+		i++
+		j++
+	}
+}
+
+// replace returns an edit that replaces from[a0:a1] with to[b0:b1], trimmed
+// of any common prefix or suffix. Returns false if no edit is necessary.
+func replace(from, to string, a0, a1, b0, b1 int) (report.Edit, bool) {
+	for a0 < a1 && b0 < b1 && from[a0] == to[b0] {
+		a0++
+		b0++
+	}
+	for a0 < a1 && b0 < b1 && from[a1-1] == to[b1-1] {
+		a1--
+		b1--
+	}
+	if a0 == a1 && b0 == b1 {
+		return report.Edit{}, false
+	}
+	return report.Edit{Start: a0, End: a1, Replace: to[b0:b1]}, true
+}
+
+// word is a non-whitespace run of text being diffed.
+type word struct {
+	text  string
+	start int
+}
+
+func (w word) end() int {
+	return w.start + len(w.text)
+}
+
+// words splits text into words: maximal runs of letters, digits, and
+// underscores, and individual characters of any other non-space kind.
+func words(text string) []word {
+	var out []word
+	isIdent := func(r rune) bool {
+		return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+	}
+	for i := 0; i < len(text); {
+		r, n := utf8.DecodeRuneInString(text[i:])
+		switch {
+		case unicode.IsSpace(r):
+			i += n
+		case isIdent(r):
+			start := i
+			for i < len(text) {
+				r, n := utf8.DecodeRuneInString(text[i:])
+				if !isIdent(r) {
+					break
+				}
+				i += n
+			}
+			out = append(out, word{text[start:i], start})
+		default:
+			out = append(out, word{text[i : i+n], i})
+			i += n
+		}
+	}
+	return out
+}
+
+// differ is the state for the linear-space variant of Myers' algorithm
+// described in "An O(ND) Difference Algorithm and Its Variations".
+type differ struct {
+	a, b               []word
+	changedA, changedB []bool
+
+	// Scratch space for the furthest-reaching paths along each diagonal, in
+	// the forward and backward directions.
+	fwd, bwd []int
+}
+
+// compare marks the words in a[a0:a1] and b[b0:b1] that are not part of a
+// longest common subsequence of the two as changed.
+func (d *differ) compare(a0, a1, b0, b1 int) {
+	for a0 < a1 && b0 < b1 && d.a[a0].text == d.b[b0].text {
+		a0++
+		b0++
+	}
+	for a0 < a1 && b0 < b1 && d.a[a1-1].text == d.b[b1-1].text {
+		a1--
+		b1--
+	}
+
+	switch {
+	case a0 == a1:
+		for j := b0; j < b1; j++ {
+			d.changedB[j] = true
+		}
+	case b0 == b1:
+		for i := a0; i < a1; i++ {
+			d.changedA[i] = true
+		}
+	default:
+		a, b := d.split(a0, a1, b0, b1)
+		d.compare(a0, a, b0, b)
+		d.compare(a, a1, b, b1)
+	}
+}
+
+// split finds a point on a shortest edit script from a[a0:a1] to b[b0:b1],
+// by searching for the middle snake from both ends at once.
+func (d *differ) split(a0, a1, b0, b1 int) (int, int) {
+	n, m := a1-a0, b1-b0
+	delta := n - m
+	odd := delta%2 != 0
+
+	// Diagonal k is the set of points x - y = k. For the backward search,
+	// x and y are measured from the ends of the sequences instead.
+	mid := len(d.fwd) / 2
+	fwd, bwd := d.fwd, d.bwd
+	fwd[mid+1], bwd[mid+1] = 0, 0
+
+	for cost := 0; cost <= (n+m+1)/2; cost++ {
+		for k := -cost; k <= cost; k += 2 {
+			var x int
+			if k == -cost || (k != cost && fwd[mid+k-1] < fwd[mid+k+1]) {
+				x = fwd[mid+k+1]
+			} else {
+				x = fwd[mid+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.a[a0+x].text == d.b[b0+y].text {
+				x++
+				y++
+			}
+			fwd[mid+k] = x
+
+			if r := delta - k; odd && r >= -(cost-1) && r <= cost-1 && x+bwd[mid+r] >= n {
+				return a0 + x, b0 + y
+			}
+		}
+
+		for k := -cost; k <= cost; k += 2 {
+			var x int
+			if k == -cost || (k != cost && bwd[mid+k-1] < bwd[mid+k+1]) {
+				x = bwd[mid+k+1]
+			} else {
+				x = bwd[mid+k-1] + 1
+			}
+			y := x - k
+			for x < n && y < m && d.a[a1-1-x].text == d.b[b1-1-y].text {
+				x++
+				y++
+			}
+			bwd[mid+k] = x
+
+			if r := delta - k; !odd && r >= -cost && r <= cost && x+fwd[mid+r] >= n {
+				return a1 - x, b1 - y
+			}
+		}
+	}
+
+	panic("protocompile/printer: diff did not find a middle snake")
+}
@@ -0,0 +1,78 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"math/rand/v2"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bufbuild/protocompile/experimental/report"
+)
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		from, to string
+		edits    int
+	}{
+		{"", "", 0},
+		{"a b", "a b", 0},
+		{"a  b", "a b", 1},
+		{"message  Foo{int32 x=1;}", "message Foo {\n  int32 x = 1;\n}\n", 7},
+		{"a b c d", "a c d e", 2},
+		{"x", "", 1},
+		{"", "x", 1},
+	}
+	for _, test := range tests {
+		edits := diff(test.from, test.to)
+		assert.Len(t, edits, test.edits, "%q -> %q", test.from, test.to)
+		assert.Equal(t, test.to, applyEdits(test.from, edits), "%q -> %q", test.from, test.to)
+	}
+
+	// Compare random strings over a small alphabet, so that there are many
+	// common subsequences.
+	rng := rand.New(rand.NewPCG(1, 2))
+	random := func() string {
+		var b strings.Builder
+		for range rng.IntN(40) {
+			b.WriteString([]string{"a", "b", "cd", " ", "\n", ";", "{", "}"}[rng.IntN(8)])
+		}
+		return b.String()
+	}
+	for range 2000 {
+		from, to := random(), random()
+		edits := diff(from, to)
+		for i := 1; i < len(edits); i++ {
+			assert.Less(t, edits[i-1].End, edits[i].Start, "%q -> %q", from, to)
+		}
+		assert.Equal(t, to, applyEdits(from, edits), "%q -> %q", from, to)
+	}
+}
+
+func applyEdits(text string, edits []report.Edit) string {
+	var out strings.Builder
+	prev := 0
+	for _, edit := range edits {
+		out.WriteString(text[prev:edit.Start])
+		out.WriteString(edit.Replace)
+		prev = edit.End
+	}
+	out.WriteString(text[prev:])
+	return out.String()
+}
@@ -28,7 +28,12 @@
 // newline — useful for LSP edit previews or for building output
 // incrementally one decl at a time.
 //
-// Both entry points take an [Options] value that configures the printed output:
+// [PrintEdits] and [PrintRange] return their output as a list of minimal
+// edits against the original source text, rather than as a new string.
+// PrintRange only formats the declarations that overlap a given span, for
+// editor range formatting and format-on-type.
+//
+// All entry points take an [Options] value that configures the printed output:
 //
 //   - With Options.Format = false (the zero value, round-trip mode), the
 //     file is emitted as-is. Source whitespace and comments are preserved
@@ -50,7 +55,9 @@
 //   - [Legacy] is the set of configurations that conforms to the legacy
 //     protobuf formatter.
 //
-// See the [Formatting] type for the individual knobs.
+// See the [Formatting] type for the individual knobs. Beyond layout and
+// comments, it has opt-in semantic transforms that are off in both
+// presets, such as sorting fields by number or aligning their `=` signs.
 //
 // # Editing the AST
 //
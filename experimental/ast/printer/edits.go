@@ -0,0 +1,131 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"strings"
+
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/source"
+)
+
+// PrintEdits is like [PrintFile], but returns the output as a list of edits
+// to the file's source text, rather than as a new string.
+//
+// The offsets of the edits are relative to the start of the file. The edits
+// are sorted, do not overlap, and only touch text that actually changes;
+// since formatting mostly changes whitespace, most edits only replace
+// whitespace. Applying all of them to the source text produces exactly the
+// output of PrintFile.
+func PrintEdits(options Options, file *ast.File) ([]report.Edit, error) {
+	out, err := PrintFile(options, file)
+	if err != nil {
+		return nil, err
+	}
+	return diff(file.Stream().Text(), out), nil
+}
+
+// PrintRange is like [PrintEdits], but only formats the declarations that
+// overlap span, such as for an editor's range formatting or format-on-type
+// requests.
+//
+// If span lies within the body of a single declaration, only the
+// declarations within that body that overlap span are formatted, and so on
+// recursively. The formatted region is widened to whole lines, and if span
+// does not overlap any declaration, the lines that span covers are
+// formatted instead.
+//
+// The returned edits are the subset of those returned by PrintEdits that
+// overlap the formatted region, so applying them makes that region (widened
+// to include the edits) equal to the corresponding slice of PrintFile's
+// output, and leaves the rest of the file untouched. Because it would not
+// be a local change, PrintRange never reorders declarations:
+// [Formatting.CanonicalizeFileOrder] and [Formatting.SortByNumber] are
+// ignored.
+func PrintRange(options Options, file *ast.File, span source.Span) ([]report.Edit, error) {
+	options.Formatting.CanonicalizeFileOrder = false
+	options.Formatting.SortByNumber = false
+	edits, err := PrintEdits(options, file)
+	if err != nil {
+		return nil, err
+	}
+
+	start, end := formatRegion(file, span)
+	var out []report.Edit
+	for _, edit := range edits {
+		if edit.Start <= end && edit.End >= start {
+			out = append(out, edit)
+		}
+	}
+	return out, nil
+}
+
+// formatRegion returns the region of file that [PrintRange] formats for span.
+func formatRegion(file *ast.File, span source.Span) (start, end int) {
+	text := file.Stream().Text()
+	span.Start = max(0, min(span.Start, len(text)))
+	span.End = max(span.Start, min(span.End, len(text)))
+
+	decls := file.Decls()
+	for {
+		start, end = span.Start, span.End
+
+		var overlap []ast.DeclAny
+		for decl := range seq.Values(decls) {
+			s := decl.Span()
+			if s.Start <= span.End && s.End >= span.Start {
+				overlap = append(overlap, decl)
+			}
+		}
+		if len(overlap) == 0 {
+			break
+		}
+
+		start = min(span.Start, overlap[0].Span().Start)
+		end = max(span.End, overlap[len(overlap)-1].Span().End)
+		if len(overlap) > 1 {
+			break
+		}
+
+		// Descend into the body of the declaration, if span is strictly
+		// inside of its braces.
+		var body ast.DeclBody
+		switch decl := overlap[0]; decl.Kind() {
+		case ast.DeclKindDef:
+			body = decl.AsDef().Body()
+		case ast.DeclKindBody:
+			body = decl.AsBody()
+		}
+		if body.IsZero() || body.Braces().IsLeaf() {
+			break
+		}
+		open, close := body.Braces().StartEnd() //nolint:predeclared,revive // For close.
+		if span.Start < open.LeafSpan().End || span.End > close.LeafSpan().Start {
+			break
+		}
+		decls = body.Decls()
+	}
+
+	// Snap the region to whole lines.
+	start = strings.LastIndexByte(text[:start], '\n') + 1
+	if i := strings.IndexByte(text[end:], '\n'); i >= 0 {
+		end += i
+	} else {
+		end = len(text)
+	}
+	return start, end
+}
@@ -0,0 +1,179 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/ast/printer"
+	"github.com/bufbuild/protocompile/experimental/parser"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/source"
+	"github.com/bufbuild/protocompile/internal/golden"
+)
+
+// TestPrintEdits checks that applying the edits from [printer.PrintEdits]
+// and [printer.PrintRange] to each file in testdata/format agrees with
+// [printer.PrintFile].
+func TestPrintEdits(t *testing.T) {
+	t.Parallel()
+
+	corpus := golden.Corpus{
+		Root:       "testdata/format",
+		Extensions: []string{"proto"},
+	}
+
+	corpus.Run(t, func(t *testing.T, path, text string, _ []string) {
+		file, _ := parser.Parse(path, source.NewFile(path, text), &report.Report{})
+
+		for _, formatting := range []printer.Formatting{printer.Default(), printer.Legacy()} {
+			options := printer.Options{Format: true, Formatting: formatting}
+			want, err := printer.PrintFile(options, file)
+			require.NoError(t, err)
+			edits, err := printer.PrintEdits(options, file)
+			require.NoError(t, err)
+			checkSorted(t, edits)
+			if msg := golden.CompareAndDiff(apply(text, edits), want); msg != "" {
+				t.Errorf("PrintEdits does not match PrintFile:\n%s", msg)
+			}
+
+			// Formatting each declaration individually should produce a
+			// subset of the edits for the whole file, touching only the
+			// lines of that declaration.
+			options.Formatting.CanonicalizeFileOrder = false
+			all, err := printer.PrintEdits(options, file)
+			require.NoError(t, err)
+
+			var visit func(decls seq.Indexer[ast.DeclAny])
+			visit = func(decls seq.Indexer[ast.DeclAny]) {
+				for decl := range seq.Values(decls) {
+					span := decl.Span()
+					edits, err := printer.PrintRange(options, file, span)
+					require.NoError(t, err)
+					checkSorted(t, edits)
+					for _, edit := range edits {
+						assert.Contains(t, all, edit)
+						assert.LessOrEqual(t, edit.Start, lineEnd(text, span.End), "%v", span)
+						assert.GreaterOrEqual(t, edit.End, lineStart(text, span.Start), "%v", span)
+					}
+
+					if body := decl.AsDef().Body(); !body.IsZero() {
+						visit(body.Decls())
+					}
+				}
+			}
+			visit(file.Decls())
+		}
+	})
+}
+
+func TestPrintRange(t *testing.T) {
+	t.Parallel()
+
+	text := `syntax = "proto3";
+message  A {
+int32 x=1;
+}
+message   B {
+    int32   y = 1 ;
+  int32 z = 2;
+}
+`
+	file, _ := parser.Parse("test.proto", source.NewFile("test.proto", text), &report.Report{})
+	options := printer.Options{Format: true, Formatting: printer.Default()}
+	format := func(start, end int) string {
+		edits, err := printer.PrintRange(options, file, file.Stream().Span(start, end))
+		require.NoError(t, err)
+		return apply(text, edits)
+	}
+
+	b := strings.Index(text, "message   B")
+	assert.Equal(t, `syntax = "proto3";
+message  A {
+int32 x=1;
+}
+message B {
+  int32 y = 1;
+  int32 z = 2;
+}
+`, format(b, b))
+
+	y := strings.Index(text, "int32   y")
+	assert.Equal(t, `syntax = "proto3";
+message  A {
+int32 x=1;
+}
+message   B {
+  int32 y = 1;
+  int32 z = 2;
+}
+`, format(y, y))
+
+	x := strings.Index(text, "x=1")
+	assert.Equal(t, `syntax = "proto3";
+message  A {
+  int32 x = 1;
+}
+message   B {
+    int32   y = 1 ;
+  int32 z = 2;
+}
+`, format(x, x+1))
+
+	whole, err := printer.PrintFile(options, file)
+	require.NoError(t, err)
+	assert.Equal(t, whole, format(0, len(text)))
+}
+
+// apply applies a sorted list of non-overlapping edits to text.
+func apply(text string, edits []report.Edit) string {
+	var out strings.Builder
+	prev := 0
+	for _, edit := range edits {
+		out.WriteString(text[prev:edit.Start])
+		out.WriteString(edit.Replace)
+		prev = edit.End
+	}
+	out.WriteString(text[prev:])
+	return out.String()
+}
+
+// checkSorted checks that edits are sorted and do not overlap or touch.
+func checkSorted(t *testing.T, edits []report.Edit) {
+	t.Helper()
+	for i, edit := range edits {
+		assert.LessOrEqual(t, edit.Start, edit.End)
+		if i > 0 {
+			assert.Less(t, edits[i-1].End, edit.Start)
+		}
+	}
+}
+
+func lineStart(text string, offset int) int {
+	return strings.LastIndexByte(text[:offset], '\n') + 1
+}
+
+func lineEnd(text string, offset int) int {
+	if i := strings.IndexByte(text[offset:], '\n'); i >= 0 {
+		return offset + i
+	}
+	return len(text)
+}
@@ -33,7 +33,7 @@ func (p *printer) printExpr(expr ast.ExprAny, gap gapStyle) {
 		if !tok.IsLeaf() {
 			p.printCompoundString(tok, gap)
 		} else {
-			p.printToken(tok, gap)
+			p.printTokenAs(tok, gap, p.literalText(tok))
 		}
 	case ast.ExprKindPath:
 		p.printPath(expr.AsPath().Path, gap)
@@ -109,10 +109,10 @@ func (p *printer) printCompoundString(tok token.Token, gap gapStyle) {
 	defer restore()
 
 	printParts := func(pp *printer) {
-		pp.printTokenAs(tok, gapNewline, openTok.Text())
+		pp.printTokenAs(tok, gapNewline, pp.literalText(openTok))
 		for i, part := range parts {
 			pp.emitTriviaSlot(trivia, i)
-			pp.printToken(part, gapNewline)
+			pp.printTokenAs(part, gapNewline, pp.literalText(part))
 		}
 		pp.emitRemainingTrivia(trivia, len(parts))
 
@@ -124,7 +124,7 @@ func (p *printer) printCompoundString(tok token.Token, gap gapStyle) {
 		} else {
 			pp.emitGap(gapNewline)
 		}
-		pp.push(dom.Text(closeTok.Text()))
+		pp.push(dom.Text(pp.literalText(closeTok)))
 		if hasTrivia {
 			// Rewind to the caller's state so the trailing emit
 			// uses the caller's lineToBlock.
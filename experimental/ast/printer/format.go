@@ -108,13 +108,18 @@ func sourceBlankLineBetweenFields(prev, curr ast.ExprField) bool {
 //     matching the legacy formatter's "expand if non-trivial" rule.
 //
 //   - [LayoutDynamic]: broken if and only if source had a newline between open
-//     and close, deferring width-driven breaks to [dom.Group].
+//     and close, deferring width-driven breaks to [dom.Group]. In a
+//     synthetic file there is no source layout, so scopes are treated as
+//     flat.
 //
 // Callers should OR the result with their own forceBroken signal
 // (e.g. for scope-attached comments that require expansion).
 func (p *printer) literalShouldBreak(openTok, closeTok token.Token, count int) bool {
 	switch p.options.Formatting.LiteralLayout {
 	case LayoutDynamic:
+		if p.synthetic {
+			return false
+		}
 		return !sourceWasFlat(openTok, closeTok)
 	default: // LayoutStrict
 		return count >= 2
@@ -152,8 +157,14 @@ func (p *printer) bodyShouldBreak(openTok, closeTok token.Token) bool {
 //  4. file-level options (plain before extension, alphabetically within
 //     each group)
 //  5. everything else (original order preserved)
-func sortFileDeclsForFormat(decls []ast.DeclAny) {
-	slices.SortStableFunc(decls, compareDecl)
+//
+// If normalizeStrings is set, imports are sorted by their path as
+// printed under [Formatting.NormalizeStrings], so that sorting agrees
+// with a second formatting pass.
+func sortFileDeclsForFormat(decls []ast.DeclAny, normalizeStrings bool) {
+	slices.SortStableFunc(decls, func(a, b ast.DeclAny) int {
+		return compareDecl(a, b, normalizeStrings)
+	})
 }
 
 // compareDecl compares two declarations for sorting. Declarations
@@ -161,7 +172,7 @@ func sortFileDeclsForFormat(decls []ast.DeclAny) {
 // body). Within the import and option ranks, ties are broken by
 // sort name (see [importSortName] and [optionSortName]); decls at
 // the body rank preserve their source order via the stable sort.
-func compareDecl(a, b ast.DeclAny) int {
+func compareDecl(a, b ast.DeclAny, normalizeStrings bool) int {
 	aRank, bRank := rankDecl(a), rankDecl(b)
 	if c := cmp.Compare(aRank, bRank); c != 0 {
 		return c
@@ -181,7 +192,7 @@ func compareDecl(a, b ast.DeclAny) int {
 		if c := cmp.Compare(aImpOpt, bImpOpt); c != 0 {
 			return c
 		}
-		return cmp.Compare(importSortName(aImp), importSortName(bImp))
+		return cmp.Compare(importSortName(aImp, normalizeStrings), importSortName(bImp, normalizeStrings))
 	case ast.DeclKindDef:
 		if a.AsDef().Classify() == ast.DefKindOption {
 			return cmp.Compare(optionSortName(a), optionSortName(b))
@@ -193,30 +204,41 @@ func compareDecl(a, b ast.DeclAny) int {
 }
 
 // importSortName returns the sort name for an import declaration.
-// This is the raw token text of the import path (e.g. `"foo/bar.proto"`).
-func importSortName(imp ast.DeclImport) string {
+// This is the raw token text of the import path (e.g. `"foo/bar.proto"`),
+// or its normalized text if normalizeStrings is set.
+func importSortName(imp ast.DeclImport, normalizeStrings bool) string {
 	lit := imp.ImportPath().AsLiteral()
 	if lit.IsZero() {
 		return ""
 	}
+	if normalizeStrings {
+		if text, ok := normalizeString(lit.Token); ok {
+			return text
+		}
+	}
 	return lit.Token.Text()
 }
 
 // optionSortName returns the sort name for a file-level option declaration.
 // Plain options sort before extension options by prefixing with "0" or "1".
 func optionSortName(decl ast.DeclAny) string {
-	opt := decl.AsDef().AsOption()
-	canonical := opt.Path.Canonicalized()
-	if isExtensionOption(opt) {
+	return optionPathSortName(decl.AsDef().AsOption().Path)
+}
+
+// optionPathSortName returns the sort name for an option path, shared by
+// file-level options and compact options.
+func optionPathSortName(path ast.Path) string {
+	canonical := path.Canonicalized()
+	if isExtensionPath(path) {
 		return "1" + canonical
 	}
 	return "0" + canonical
 }
 
-// isExtensionOption returns true if the option's path starts with an
+// isExtensionPath returns true if an option path starts with an
 // extension component (parenthesized path like `(foo.bar)`).
-func isExtensionOption(opt ast.DefOption) bool {
-	for pc := range opt.Path.Components() {
+func isExtensionPath(path ast.Path) bool {
+	for pc := range path.Components() {
 		return !pc.AsExtension().IsZero()
 	}
 	return false
@@ -159,6 +159,64 @@ type Formatting struct {
 	//
 	// Default: false. Legacy: true.
 	PairLeadingBlockComments bool
+
+	// The remaining knobs are semantic transforms: they rewrite more
+	// than whitespace and comment placement, but never change what the
+	// file means. All of them are off in both presets.
+
+	// SortByNumber sorts the fields and groups of each message, oneof,
+	// and extend body, and the values of each enum, by number. Other
+	// declarations in the body (options, nested types, reserved
+	// ranges, ...) keep their positions; the numbered members are
+	// permuted among the positions they already occupy. Bodies where
+	// some number is not an integer literal are left as-is.
+	//
+	// Default: false. Legacy: false.
+	SortByNumber bool
+
+	// AlignFields aligns the `=` of consecutive fields and enum values
+	// in a body, and then their trailing comments, in the style of
+	// gofmt. A run of aligned declarations is broken up by blank lines,
+	// by any other declaration, and by declarations that span several
+	// lines or contain comments between their first token and their
+	// semicolon.
+	//
+	// Default: false. Legacy: false.
+	AlignFields bool
+
+	// CanonicalizeCompactOptions sorts the entries of compact options
+	// (`[...]` on fields, enum values, and ranges) in the same order
+	// as file-level options under CanonicalizeFileOrder: plain options
+	// before extensions, alphabetically within each group. Detached
+	// comments between entries stay in place.
+	//
+	// Default: false. Legacy: false.
+	CanonicalizeCompactOptions bool
+
+	// NormalizeStrings rewrites string literals to use double quotes,
+	// and to escape only quotes, backslashes, and non-printable
+	// characters, using `\n`, `\r`, and `\t` where possible and
+	// hex or Unicode escapes otherwise. The bytes a literal denotes
+	// never change.
+	//
+	// Default: false. Legacy: false.
+	NormalizeStrings bool
+
+	// CollapseReservedRanges merges adjacent and overlapping ranges in
+	// `reserved` declarations, such as `reserved 1, 2, 3 to 5, 7;`
+	// into `reserved 1 to 5, 7;`. Merged ranges are sorted and printed
+	// in decimal. Declarations where nothing can be merged, or that
+	// contain comments between their ranges, are left as-is.
+	//
+	// Default: false. Legacy: false.
+	CollapseReservedRanges bool
+
+	// NormalizeNumbers rewrites hex and octal integer literals, such
+	// as `0x1F` and `017`, in decimal. Floats, and integers that do not
+	// fit in 64 bits, are left as-is.
+	//
+	// Default: false. Legacy: false.
+	NormalizeNumbers bool
 }
 
 // LayoutStrategy controls how a scope (a syntactic construct that can
@@ -212,6 +270,12 @@ func Legacy() Formatting {
 		NormalizeBlockComments:             true,
 		TrailingBlockCommentsOnNewLine:     true,
 		PairLeadingBlockComments:           true,
+		SortByNumber:                       false,
+		AlignFields:                        false,
+		CanonicalizeCompactOptions:         false,
+		NormalizeStrings:                   false,
+		CollapseReservedRanges:             false,
+		NormalizeNumbers:                   false,
 	}
 }
 
@@ -238,6 +302,12 @@ func Default() Formatting {
 		NormalizeBlockComments:             false,
 		TrailingBlockCommentsOnNewLine:     false,
 		PairLeadingBlockComments:           false,
+		SortByNumber:                       false,
+		AlignFields:                        false,
+		CanonicalizeCompactOptions:         false,
+		NormalizeStrings:                   false,
+		CollapseReservedRanges:             false,
+		NormalizeNumbers:                   false,
 	}
 }
 
@@ -77,6 +77,7 @@ func PrintFile(options Options, file *ast.File) (string, error) {
 
 	return dom.Render(options.domOptions(), func(push dom.Sink) {
 		p := newPrinter(options, buildTriviaIndex(file.Stream()), push)
+		p.synthetic = isSynthetic(file)
 		p.printFile(file)
 	}), nil
 }
@@ -103,6 +104,7 @@ func Print(options Options, decl ast.DeclAny) string {
 		file := decl.Context()
 		trivia := buildTriviaIndex(file.Stream())
 		p := newPrinter(options, trivia, push)
+		p.synthetic = isSynthetic(file)
 
 		// Emit the decl's preceding scope slot for top-level decls so that section
 		// comments at the decl's source position render alongside the decl. Nested
@@ -118,6 +120,11 @@ func Print(options Options, decl ast.DeclAny) string {
 	})
 }
 
+// isSynthetic returns whether file has no source text.
+func isSynthetic(file *ast.File) bool {
+	return file.Stream().File.Text() == ""
+}
+
 // fileDeclIndex returns the index of decl in file.Decls() at the top
 // level, or false if decl is not a top-level declaration.
 func fileDeclIndex(file *ast.File, decl ast.DeclAny) (int, bool) {
@@ -146,13 +153,15 @@ type printer struct {
 	// printed entity.
 	ctx context
 
-	// declSourceIdx maps a sorted-iteration index to the original
-	// source-order index for the file's top-level decls. Set during
-	// printFile when CanonicalizeFileOrder reorders decls; nil at
-	// other times (in which case sorted index = source index).
-	// Consulted by [printer.declGap] to look up the right
-	// [detachedTrivia.hasBlankBefore] entry.
-	declSourceIdx []int
+	// align is the padding to apply to the declaration currently being
+	// printed, per [Formatting.AlignFields]. Set by
+	// [printer.printScopeDecls] around each declaration.
+	align alignment
+
+	// synthetic is set when the file being printed has no source text, such
+	// as one built by package builder. Such a file has no layout to preserve,
+	// so it is laid out conventionally instead.
+	synthetic bool
 }
 
 // newPrinter constructs a printer for the given options, trivia index,
@@ -172,41 +181,20 @@ func (p *printer) printFile(file *ast.File) {
 	trivia := p.trivia.scopeTrivia(0)
 	decls := seq.Indexer[ast.DeclAny](file.Decls())
 
+	var sourceIdx []int
 	if p.options.Format && p.options.Formatting.CanonicalizeFileOrder {
 		sourceOrder := seq.ToSlice(decls)
-		// Build a lookup from each decl to its source-order index
-		// before sorting. After sortFileDeclsForFormat, the iteration
-		// order changes but trivia.hasBlankBefore is keyed by source
-		// position, so we need to translate sorted index -> source
-		// index when consulting it. Synthetic decls (no source span)
-		// map to -1 so they do not consume a slot in blankBefore.
-		srcIdxByDecl := make(map[ast.DeclAny]int, len(sourceOrder))
-		natCounter := 0
-		for _, d := range sourceOrder {
-			if d.Span().IsZero() {
-				srcIdxByDecl[d] = -1
-				continue
-			}
-			srcIdxByDecl[d] = natCounter
-			natCounter++
-		}
 		sorted := append([]ast.DeclAny(nil), sourceOrder...)
-		sortFileDeclsForFormat(sorted)
-		p.declSourceIdx = make([]int, len(sorted))
-		for i, d := range sorted {
-			if idx, ok := srcIdxByDecl[d]; ok {
-				p.declSourceIdx[i] = idx
-			} else {
-				p.declSourceIdx[i] = -1
-			}
-		}
-		defer func() { p.declSourceIdx = nil }()
+		sortFileDeclsForFormat(sorted, p.options.Formatting.NormalizeStrings)
+		// trivia.hasBlankBefore is keyed by source position, so translate
+		// sorted index -> source index when consulting it.
+		sourceIdx = sourceIndices(sourceOrder, sorted)
 		decls = seq.NewFunc(len(sorted), func(i int) ast.DeclAny {
 			return sorted[i]
 		})
 	}
 
-	p.printScopeDecls(trivia, decls, scopeFile)
+	p.printScopeDecls(trivia, decls, sourceIdx, scopeFile)
 	// In format mode, trailing file comments need a newline gap so they
 	// don't run into the last declaration's closing token. But if there
 	// are no declarations at all, emit nothing (empty file = empty output).
@@ -287,6 +275,10 @@ func (p *printer) emitTrailing(trailing []token.Token) {
 					p.push(tagNewline)
 				} else {
 					p.push(tagSpace)
+					// Align the comment with its neighbors, per
+					// [Formatting.AlignFields].
+					p.pushPadding(p.align.comment)
+					p.align.comment = 0
 				}
 				switch {
 				case rewriteToBlock && p.ctx.lineToBlock && isLine:
@@ -357,13 +349,24 @@ func (p *printer) appendPending(tokens []token.Token) {
 // AST decls may include synthetic decls (those without a source span,
 // e.g. inserted via the edit package). Synthetic decls do not consume
 // entries in trivia.slots or trivia.blankBefore, so iteration indices
-// are translated through scopeSourceIdx before consulting trivia.
+// are translated through sourceIdx before consulting trivia. If decls
+// has been reordered, sourceIdx must be provided by the caller (see
+// [sourceIndices]); if it is nil, decls is assumed to be in source order.
 func (p *printer) printScopeDecls(
 	trivia detachedTrivia,
 	decls seq.Indexer[ast.DeclAny],
+	sourceIdx []int,
 	scope scopeKind,
 ) {
-	sourceIdx := p.scopeSourceIdx(decls, scope)
+	if sourceIdx == nil {
+		sourceIdx = scopeSourceIdx(decls)
+	}
+
+	var align []alignment
+	if p.options.Format && p.options.Formatting.AlignFields && scope == scopeBody {
+		align = p.alignDecls(trivia, decls)
+	}
+
 	lastSrc := -1
 	for i := range decls.Len() {
 		src := sourceIdx[i]
@@ -376,7 +379,11 @@ func (p *printer) printScopeDecls(
 			}
 		}
 		gap := p.declGap(decls, trivia, i, src, scope)
+		if align != nil {
+			p.align = align[i]
+		}
 		p.printDecl(decls.At(i), gap)
+		p.align = alignment{}
 	}
 
 	p.emitRemainingTrivia(trivia, lastSrc+1)
@@ -384,16 +391,11 @@ func (p *printer) printScopeDecls(
 
 // scopeSourceIdx returns a slice mapping each AST decl iteration index
 // to its source decl index, or -1 if the decl is synthetic (has no
-// source span). For the file scope under CanonicalizeFileOrder, the
-// pre-computed mapping from printFile is reused.
-func (p *printer) scopeSourceIdx(decls seq.Indexer[ast.DeclAny], scope scopeKind) []int {
-	n := decls.Len()
-	if scope == scopeFile && p.declSourceIdx != nil && len(p.declSourceIdx) == n {
-		return p.declSourceIdx
-	}
-	out := make([]int, n)
+// source span).
+func scopeSourceIdx(decls seq.Indexer[ast.DeclAny]) []int {
+	out := make([]int, decls.Len())
 	counter := 0
-	for i := range n {
+	for i := range decls.Len() {
 		if decls.At(i).Span().IsZero() {
 			out[i] = -1
 			continue
@@ -404,6 +406,28 @@ func (p *printer) scopeSourceIdx(decls seq.Indexer[ast.DeclAny], scope scopeKind
 	return out
 }
 
+// sourceIndices is like [scopeSourceIdx], but for reordered, a
+// permutation of the source-order decls in original: it maps each index
+// into reordered to the source decl index of that decl in original.
+func sourceIndices(original, reordered []ast.DeclAny) []int {
+	byDecl := make(map[ast.DeclAny]int, len(original))
+	natural := scopeSourceIdx(seq.NewFunc(len(original), func(i int) ast.DeclAny {
+		return original[i]
+	}))
+	for i, d := range original {
+		byDecl[d] = natural[i]
+	}
+	out := make([]int, len(reordered))
+	for i, d := range reordered {
+		if idx, ok := byDecl[d]; ok {
+			out[i] = idx
+		} else {
+			out[i] = -1
+		}
+	}
+	return out
+}
+
 // declGap computes the gap before declaration i in a scope.
 //
 // For the first declaration (i==0), it handles flushing detached leading
@@ -414,7 +438,9 @@ func (p *printer) scopeSourceIdx(decls seq.Indexer[ast.DeclAny], scope scopeKind
 // srcIdx is the source decl index for decls.At(i), or -1 if the decl
 // is synthetic (no source span). Synthetic decls never consult
 // trivia.hasBlankBefore because they have no source position to
-// compare against.
+// compare against. Natural decls look up their blank line by their
+// position in the original source, which differs from srcIdx once
+// edits have deleted decls before them.
 func (p *printer) declGap(
 	decls seq.Indexer[ast.DeclAny],
 	trivia detachedTrivia,
@@ -454,14 +480,20 @@ func (p *printer) declGap(
 		if curr == rankImport || curr == rankOption {
 			return gapNewline
 		}
-		if srcIdx >= 0 && trivia.hasBlankBefore(srcIdx) {
+		if srcIdx >= 0 && trivia.hasBlankBefore(trivia.sourceIndex(decls.At(i))) {
+			return gapBlankline
+		}
+		// Definitions in a synthetic file have no source blank lines to
+		// preserve; separate them from what precedes them, as is
+		// conventional.
+		if p.synthetic && curr == rankBody {
 			return gapBlankline
 		}
 		return gapNewline
 	}
 
 	// Body level: preserve blank lines from the original source.
-	if srcIdx >= 0 && trivia.hasBlankBefore(srcIdx) {
+	if srcIdx >= 0 && trivia.hasBlankBefore(trivia.sourceIndex(decls.At(i))) {
 		return gapBlankline
 	}
 
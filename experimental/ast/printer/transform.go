@@ -0,0 +1,497 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer
+
+import (
+	"cmp"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/dom"
+	"github.com/bufbuild/protocompile/experimental/id"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/token"
+	"github.com/bufbuild/protocompile/experimental/token/keyword"
+	"github.com/bufbuild/protocompile/internal/ext/slicesx"
+	"github.com/bufbuild/protocompile/internal/ext/unicodex"
+)
+
+// This file implements the opt-in semantic transforms of [Formatting]:
+// transforms that change more than whitespace and comment placement, but
+// never change the meaning of the file.
+
+// bodyDecls returns the declarations of body in printing order, along with
+// the mapping from printing order to source order that
+// [printer.printScopeDecls] expects.
+//
+// Under [Formatting.SortByNumber], the numbered members of the body
+// (fields, groups, and enum values) are sorted by number. The sort only
+// permutes the members among the positions they already occupy; every
+// other declaration keeps its place. If any member's number is not an
+// integer literal, the body is left as-is.
+func (p *printer) bodyDecls(body ast.DeclBody) (seq.Indexer[ast.DeclAny], []int) {
+	decls := body.Decls()
+	if !p.options.Format || !p.options.Formatting.SortByNumber {
+		return decls, nil
+	}
+
+	type member struct {
+		decl   ast.DeclAny
+		number int64
+	}
+	var members []member
+	var positions []int
+	for i := range decls.Len() {
+		decl := decls.At(i)
+		if !isNumbered(decl) {
+			continue
+		}
+		number, ok := exprInt(decl.AsDef().Value())
+		if !ok {
+			return decls, nil
+		}
+		members = append(members, member{decl, number})
+		positions = append(positions, i)
+	}
+
+	sorted := seq.ToSlice(decls)
+	slices.SortStableFunc(members, func(a, b member) int {
+		return cmp.Compare(a.number, b.number)
+	})
+	for i, pos := range positions {
+		sorted[pos] = members[i].decl
+	}
+
+	return seq.NewFunc(len(sorted), func(i int) ast.DeclAny {
+		return sorted[i]
+	}), sourceIndices(seq.ToSlice(decls), sorted)
+}
+
+// isNumbered returns whether decl is a field, group, or enum value.
+func isNumbered(decl ast.DeclAny) bool {
+	if decl.Kind() != ast.DeclKindDef {
+		return false
+	}
+	switch decl.AsDef().Classify() {
+	case ast.DefKindField, ast.DefKindGroup, ast.DefKindEnumValue:
+		return true
+	default:
+		return false
+	}
+}
+
+// exprInt evaluates expr if it is a possibly-negated integer literal.
+func exprInt(expr ast.ExprAny) (int64, bool) {
+	negate := false
+	if prefixed := expr.AsPrefixed(); !prefixed.IsZero() {
+		if prefixed.Prefix() != keyword.Sub {
+			return 0, false
+		}
+		negate = true
+		expr = prefixed.Expr()
+	}
+
+	num := expr.AsLiteral().Token.AsNumber()
+	if num.IsZero() || !num.IsValid() || num.IsFloat() {
+		return 0, false
+	}
+	v, exact := num.Int()
+	if !exact || v > math.MaxInt64 {
+		return 0, false
+	}
+	if negate {
+		return -int64(v), true
+	}
+	return int64(v), true
+}
+
+// compactOptionEntries returns the entries of a compact options list in
+// printing order.
+//
+// Under [Formatting.CanonicalizeCompactOptions], the entries are sorted
+// the same way as file-level options under
+// [Formatting.CanonicalizeFileOrder]: plain options before extensions,
+// alphabetically within each group.
+func (p *printer) compactOptionEntries(entries ast.Commas[ast.Option]) seq.Indexer[ast.Option] {
+	if !p.options.Formatting.CanonicalizeCompactOptions {
+		return entries
+	}
+
+	sorted := seq.ToSlice(entries)
+	slices.SortStableFunc(sorted, func(a, b ast.Option) int {
+		return cmp.Compare(optionPathSortName(a.Path), optionPathSortName(b.Path))
+	})
+	return seq.NewFunc(len(sorted), func(i int) ast.Option {
+		return sorted[i]
+	})
+}
+
+// numberRange is a range of numbers in a reserved range declaration.
+type numberRange struct {
+	start, end int64
+	toMax      bool
+}
+
+// collapseRanges returns the text that replaces the ranges of r under
+// [Formatting.CollapseReservedRanges].
+//
+// Returns false if the ranges cannot be collapsed: if r is not a reserved
+// range of numbers, if any range is not made of integer literals, if there
+// are comments that the rewrite would drop, or if no two ranges are
+// adjacent or overlapping.
+func (p *printer) collapseRanges(r ast.DeclRange) (string, bool) {
+	if !p.options.Format || !p.options.Formatting.CollapseReservedRanges || !r.IsReserved() {
+		return "", false
+	}
+
+	exprs := r.Ranges()
+	if exprs.Len() < 2 || hasInteriorComments(r.Context().Stream(), r.KeywordToken(), r.Semicolon()) {
+		return "", false
+	}
+
+	ranges := make([]numberRange, 0, exprs.Len())
+	for expr := range seq.Values(exprs) {
+		var rng numberRange
+		var ok bool
+		if bounds := expr.AsRange(); !bounds.IsZero() {
+			start, end := bounds.Bounds()
+			rng.start, ok = exprInt(start)
+			if !ok {
+				return "", false
+			}
+			if end.AsPath().AsKeyword() == keyword.Max {
+				rng.toMax = true
+			} else if rng.end, ok = exprInt(end); !ok || rng.end < rng.start {
+				return "", false
+			}
+		} else {
+			rng.start, ok = exprInt(expr)
+			if !ok {
+				return "", false
+			}
+			rng.end = rng.start
+		}
+		ranges = append(ranges, rng)
+	}
+
+	slices.SortStableFunc(ranges, func(a, b numberRange) int {
+		return cmp.Compare(a.start, b.start)
+	})
+	merged := ranges[:1]
+	for _, next := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		if !last.toMax && last.end < math.MaxInt64 && next.start > last.end+1 {
+			merged = append(merged, next)
+			continue
+		}
+		last.toMax = last.toMax || next.toMax
+		last.end = max(last.end, next.end)
+	}
+	if len(merged) == exprs.Len() {
+		return "", false
+	}
+
+	var out strings.Builder
+	for i, rng := range merged {
+		if i > 0 {
+			out.WriteString(", ")
+		}
+		out.WriteString(strconv.FormatInt(rng.start, 10))
+		switch {
+		case rng.toMax:
+			out.WriteString(" to max")
+		case rng.end != rng.start:
+			out.WriteString(" to ")
+			out.WriteString(strconv.FormatInt(rng.end, 10))
+		}
+	}
+	return out.String(), true
+}
+
+// hasInteriorComments returns whether there are any comments strictly
+// between the natural tokens first and last. Returns true if either token
+// is missing or synthetic, since the tokens in between are then unknown.
+func hasInteriorComments(stream *token.Stream, first, last token.Token) bool {
+	if first.IsZero() || last.IsZero() || first.IsSynthetic() || last.IsSynthetic() {
+		return true
+	}
+	for i := first.ID() + 1; i < last.ID(); i++ {
+		if id.Wrap(stream, i).Kind() == token.Comment {
+			return true
+		}
+	}
+	return false
+}
+
+// literalText returns the text to print for a string or number literal
+// token, applying [Formatting.NormalizeStrings] and
+// [Formatting.NormalizeNumbers].
+func (p *printer) literalText(tok token.Token) string {
+	if p.options.Format {
+		switch {
+		case p.options.Formatting.NormalizeStrings && tok.Kind() == token.String:
+			if text, ok := normalizeString(tok); ok {
+				return text
+			}
+		case p.options.Formatting.NormalizeNumbers && tok.Kind() == token.Number:
+			if text, ok := normalizeNumber(tok); ok {
+				return text
+			}
+		}
+	}
+	return tok.Text()
+}
+
+// normalizeString returns the canonical spelling of a single string literal
+// token: double-quoted, with escapes only where necessary.
+//
+// Returns false for strings that are not plain quoted strings, or that
+// failed to lex.
+func normalizeString(tok token.Token) (string, bool) {
+	str := tok.AsString()
+	if str.IsZero() || str.Prefix().Len() != 0 {
+		return "", false
+	}
+	open, close := str.Quotes() //nolint:revive,predeclared // For close.
+	if open.Len() != 1 || close.Len() != 1 {
+		return "", false
+	}
+
+	// The value of a string that is the start of a compound string is the
+	// value of the whole compound string, so the value of this token alone
+	// needs to be rebuilt from its content and escapes.
+	content := str.RawContent()
+	var value strings.Builder
+	offset := content.Start
+	for esc := range seq.Values(str.Escapes()) {
+		if esc.Start < content.Start || esc.End > content.End {
+			continue
+		}
+		raw := content.File.Text()[offset:esc.Start]
+		if strings.Contains(raw, `\`) {
+			return "", false // An escape that did not lex.
+		}
+		value.WriteString(raw)
+		if esc.Rune != 0 {
+			value.WriteRune(esc.Rune)
+		} else {
+			value.WriteByte(esc.Byte)
+		}
+		offset = esc.End
+	}
+	raw := content.File.Text()[offset:content.End]
+	if strings.Contains(raw, `\`) {
+		return "", false
+	}
+	value.WriteString(raw)
+
+	return quote(value.String()), true
+}
+
+// quote quotes a string value as a Protobuf string literal.
+//
+// Printable characters are written as-is, except for quotes and
+// backslashes. Newlines, carriage returns and tabs use their usual escapes;
+// every other character is written as a hex or Unicode escape.
+func quote(value string) string {
+	var out strings.Builder
+	out.WriteByte('"')
+	for i := 0; i < len(value); {
+		r, n := utf8.DecodeRuneInString(value[i:])
+		switch {
+		case r == utf8.RuneError && n == 1:
+			fmt.Fprintf(&out, `\x%02x`, value[i])
+		case r == '"' || r == '\\':
+			out.WriteByte('\\')
+			out.WriteRune(r)
+		case r == '\n':
+			out.WriteString(`\n`)
+		case r == '\r':
+			out.WriteString(`\r`)
+		case r == '\t':
+			out.WriteString(`\t`)
+		case r < 0x80 && unicodex.NonPrint(r):
+			fmt.Fprintf(&out, `\x%02x`, r)
+		case r < 0x10000 && unicodex.NonPrint(r):
+			fmt.Fprintf(&out, `\u%04x`, r)
+		case unicodex.NonPrint(r):
+			fmt.Fprintf(&out, `\U%08x`, r)
+		default:
+			out.WriteRune(r)
+		}
+		i += n
+	}
+	out.WriteByte('"')
+	return out.String()
+}
+
+// normalizeNumber returns the decimal spelling of a hex or octal integer
+// literal token.
+//
+// Returns false for any other number, including floats, literals with
+// separators or suffixes, and integers that do not fit in 64 bits.
+func normalizeNumber(tok token.Token) (string, bool) {
+	num := tok.AsNumber()
+	if num.IsZero() || !num.IsValid() || num.IsFloat() ||
+		num.HasSeparators() || !num.Suffix().IsZero() {
+		return "", false
+	}
+	if num.Base() != 16 && !num.IsLegacyOctal() {
+		return "", false
+	}
+	v, exact := num.Int()
+	if !exact {
+		return "", false
+	}
+	return strconv.FormatUint(v, 10), true
+}
+
+// alignment is the padding applied to a declaration under
+// [Formatting.AlignFields].
+type alignment struct {
+	// The number of spaces to add before the `=` and before a trailing
+	// comment, respectively.
+	equals, comment int
+}
+
+// pushPadding pushes a space that is n columns wider than usual, if n is
+// positive. The space merges with the usual one that follows it.
+func (p *printer) pushPadding(n int) {
+	if n > 0 {
+		p.push(dom.Text(strings.Repeat(" ", n+1)))
+	}
+}
+
+// alignDecls computes the alignment of each of decls, a broken body scope,
+// under [Formatting.AlignFields].
+//
+// Decls are aligned in runs of consecutive fields or enum values that each
+// fit on one line, broken up by blank lines and any other declaration.
+// Within a run, the `=` of each declaration is aligned. Then, like gofmt,
+// the trailing comments of consecutive declarations that have them are
+// aligned.
+func (p *printer) alignDecls(trivia detachedTrivia, decls seq.Indexer[ast.DeclAny]) []alignment {
+	type line struct {
+		decl          int
+		prefix, width int
+		comment       bool
+	}
+
+	out := make([]alignment, decls.Len())
+	var run []line
+	flush := func() {
+		var prefix int
+		for _, line := range run {
+			prefix = max(prefix, line.prefix)
+		}
+		for i, line := range run {
+			pad := prefix - line.prefix
+			out[line.decl].equals = pad
+			run[i].width += pad
+		}
+
+		for lines := range slicesx.SplitFunc(run, func(_ int, l line) bool { return !l.comment }) {
+			var width int
+			for _, line := range lines {
+				width = max(width, line.width)
+			}
+			for _, line := range lines {
+				out[line.decl].comment = width - line.width
+			}
+		}
+		run = run[:0]
+	}
+
+	for i := range decls.Len() {
+		decl := decls.At(i)
+		if trivia.hasBlankBefore(trivia.sourceIndex(decl)) {
+			flush()
+		}
+		prefix, width, semi, ok := p.alignWidths(decl)
+		if !ok {
+			flush()
+			continue
+		}
+		att, _ := p.trivia.tokenTrivia(semi.ID())
+		run = append(run, line{i, prefix, width, sliceHasComment(att.trailing)})
+	}
+	flush()
+	return out
+}
+
+// alignWidths returns the width of decl up to its `=`, its total width, and
+// its semicolon, if it is a field or enum value that can be aligned.
+func (p *printer) alignWidths(decl ast.DeclAny) (prefix, width int, semi token.Token, ok bool) {
+	if decl.Kind() != ast.DeclKindDef {
+		return 0, 0, token.Zero, false
+	}
+
+	var printPrefix func(p *printer)
+	switch def := decl.AsDef(); def.Classify() {
+	case ast.DefKindField:
+		field := def.AsField()
+		if field.Equals.IsZero() {
+			return 0, 0, token.Zero, false
+		}
+		semi = field.Semicolon
+		printPrefix = func(p *printer) {
+			p.printType(field.Type, gapNone)
+			p.printPath(def.Name(), gapSpace)
+		}
+	case ast.DefKindEnumValue:
+		value := def.AsEnumValue()
+		if value.Equals.IsZero() {
+			return 0, 0, token.Zero, false
+		}
+		semi = value.Semicolon
+		printPrefix = func(p *printer) {
+			p.printPath(def.Name(), gapNone)
+		}
+	default:
+		return 0, 0, token.Zero, false
+	}
+
+	// Comments anywhere except before the declaration or after its
+	// semicolon would change its width.
+	stream := decl.Context().Stream()
+	_, first := stream.Around(decl.Span().Start)
+	if hasInteriorComments(stream, first, semi) {
+		return 0, 0, token.Zero, false
+	}
+
+	text := p.printDetached(func(p *printer) { p.printDecl(decl, gapNone) })
+	if strings.Contains(text, "\n") {
+		return 0, 0, token.Zero, false
+	}
+	return utf8.RuneCountInString(p.printDetached(printPrefix)),
+		utf8.RuneCountInString(text), semi, true
+}
+
+// printDetached renders whatever print prints using a fresh printer with no
+// trivia, as if it were the only thing on its line.
+func (p *printer) printDetached(print func(p *printer)) string {
+	options := p.options.domOptions()
+	options.OmitTrailingNewline = true
+	return dom.Render(options, func(push dom.Sink) {
+		q := newPrinter(p.options, nil, push)
+		q.synthetic = p.synthetic
+		print(q)
+	})
+}
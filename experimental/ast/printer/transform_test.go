@@ -0,0 +1,106 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package printer_test
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/bufbuild/protocompile/experimental/ast/printer"
+	"github.com/bufbuild/protocompile/experimental/parser"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/source"
+	"github.com/bufbuild/protocompile/internal/golden"
+)
+
+// TestTransforms exercises the opt-in semantic transforms of
+// [printer.Formatting] against goldens in testdata/transforms.
+//
+// Each <name>.yaml fixture defines a `source` proto, a list of
+// `transforms` naming the [printer.Formatting] fields to enable, and
+// optionally a `preset` (`default` or `legacy`) to enable them on.
+// The formatted output is compared against the <name>.yaml.txt golden;
+// it must re-parse cleanly and be idempotent under a second format pass.
+//
+// To regenerate goldens:
+//
+//	PROTOCOMPILE_REFRESH=** go test ./experimental/ast/printer/... -run TestTransforms
+func TestTransforms(t *testing.T) {
+	t.Parallel()
+
+	corpus := golden.Corpus{
+		Root:       "testdata/transforms",
+		Extensions: []string{"yaml"},
+		Refresh:    "PROTOCOMPILE_REFRESH",
+		Outputs: []golden.Output{
+			{Extension: "txt"},
+		},
+	}
+
+	corpus.Run(t, func(t *testing.T, path, text string, outputs []string) {
+		var spec struct {
+			Source     string   `yaml:"source"`
+			Preset     string   `yaml:"preset"`
+			Transforms []string `yaml:"transforms"`
+		}
+		if err := yaml.Unmarshal([]byte(text), &spec); err != nil {
+			t.Fatalf("parsing yaml spec: %v", err)
+		}
+
+		opts := printer.Options{Format: true, Formatting: printer.Default()}
+		if spec.Preset == "legacy" {
+			opts.Formatting = printer.Legacy()
+		}
+		formatting := reflect.ValueOf(&opts.Formatting).Elem()
+		for _, name := range spec.Transforms {
+			field := formatting.FieldByName(name)
+			if field.Kind() != reflect.Bool {
+				t.Fatalf("unknown transform %q", name)
+			}
+			field.SetBool(true)
+		}
+
+		errs := &report.Report{}
+		file, _ := parser.Parse(path, source.NewFile(path, spec.Source), errs)
+		for _, d := range errs.Diagnostics {
+			if d.Level() <= report.Error {
+				t.Fatalf("source does not parse: %v", d)
+			}
+		}
+
+		got, err := printer.PrintFile(opts, file)
+		if err != nil {
+			t.Fatalf("PrintFile: %v", err)
+		}
+		outputs[0] = got
+
+		errs2 := &report.Report{}
+		file2, _ := parser.Parse(path, source.NewFile(path, got), errs2)
+		for _, d := range errs2.Diagnostics {
+			if d.Level() <= report.Error {
+				t.Errorf("formatted output does not re-parse: %v", d)
+			}
+		}
+		got2, err := printer.PrintFile(opts, file2)
+		if err != nil {
+			t.Fatalf("PrintFile (idempotency): %v", err)
+		}
+		if msg := golden.CompareAndDiff(got2, got); msg != "" {
+			t.Errorf("formatting is not idempotent:\n%s", msg)
+		}
+	})
+}
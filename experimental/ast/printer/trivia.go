@@ -18,6 +18,7 @@ import (
 	"slices"
 	"strings"
 
+	"github.com/bufbuild/protocompile/experimental/ast"
 	"github.com/bufbuild/protocompile/experimental/token"
 	"github.com/bufbuild/protocompile/experimental/token/keyword"
 )
@@ -55,6 +56,11 @@ type detachedTrivia struct {
 	// blankBeforeClose is true when there was a blank line between
 	// the last declaration and the close brace of the scope.
 	blankBeforeClose bool
+
+	// starts[i] is the offset of the first token of declaration i,
+	// used to find a declaration's source index after edits have
+	// deleted or reordered declarations.
+	starts []int
 }
 
 func (t detachedTrivia) isEmpty() bool {
@@ -64,7 +70,22 @@ func (t detachedTrivia) isEmpty() bool {
 // hasBlankBefore reports whether there was a blank line before
 // declaration i in the original source.
 func (t detachedTrivia) hasBlankBefore(i int) bool {
-	return i < len(t.blankBefore) && t.blankBefore[i]
+	return i >= 0 && i < len(t.blankBefore) && t.blankBefore[i]
+}
+
+// sourceIndex returns the index of decl among the declarations of
+// this scope in the original source, or -1 if it is synthetic or was
+// not originally in this scope.
+func (t detachedTrivia) sourceIndex(decl ast.DeclAny) int {
+	span := decl.Span()
+	if span.IsZero() {
+		return -1
+	}
+	i, ok := slices.BinarySearch(t.starts, span.Start)
+	if !ok {
+		return -1
+	}
+	return i
 }
 
 // triviaHasComments reports whether any slot contains comment tokens.
@@ -171,6 +192,16 @@ func buildTriviaIndex(stream *token.Stream) *triviaIndex {
 		detached: make(map[token.ID]detachedTrivia),
 	}
 	idx.walkScope(stream.Cursor(), 0, scopeModeDecl)
+
+	// A synthetic token that supersedes a natural one (e.g. the new name of a
+	// renamed field) takes over that token's comments.
+	for tok := range stream.All() {
+		if orig := tok.Supersedes(); !orig.IsZero() {
+			if att, ok := idx.attached[orig.ID()]; ok {
+				idx.attached[tok.ID()] = att
+			}
+		}
+	}
 	return idx
 }
 
@@ -231,6 +262,7 @@ func (idx *triviaIndex) walkScope(cursor *token.Cursor, scopeID token.ID, mode s
 			blank = true
 		}
 		trivia.blankBefore = append(trivia.blankBefore, blank)
+		trivia.starts = append(trivia.starts, tok.Span().Start)
 		idx.attached[tok.ID()] = attachedTrivia{leading: attached}
 		pending = nil
 
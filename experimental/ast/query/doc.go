@@ -0,0 +1,68 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package query implements a small structural query language over Protobuf
+// ASTs, for finding declarations without writing a walker by hand.
+//
+// A query is a list of selectors separated by commas, and matches the
+// declarations that any of them match. A selector is a sequence of steps,
+// in the manner of CSS: `message field` matches fields anywhere within a
+// message, and `message > field` matches fields directly within one. Each
+// step is a kind of declaration, or `*` for any kind, followed by any number
+// of filters in brackets:
+//
+//	field[type=string][option=(validate.rules)]
+//	rpc[stream=client]
+//	message[name~=Request$] > field[label=repeated]
+//	enum > value[deprecated], service[!option]
+//
+// The kinds are syntax, edition, package, import, option, message, enum,
+// service, field, group, oneof, value (for enum values), rpc, extend,
+// reserved, and extensions. A filter tests an attribute of the declaration:
+//
+//	[attr]       the attribute is present
+//	[!attr]      the attribute is absent
+//	[attr=v]     some value of the attribute is v
+//	[attr!=v]    no value of the attribute is v
+//	[attr~=re]   some value of the attribute matches the regular expression re
+//
+// Values may be quoted with double quotes, and otherwise run up to the
+// closing bracket. The attributes are:
+//
+//	name       the declared name; for options, imports, and packages, the
+//	           path; for syntax and edition, the value
+//	full_name  the fully-qualified name of a named definition
+//	type       a field's type, without labels; map types are written
+//	           without spaces, such as map<string,int32>
+//	label      a field's labels, such as optional or repeated
+//	number     a field or enum value's number, in decimal
+//	value      an option's value; strings are unquoted
+//	option     the names of the options set on a definition, in brackets or
+//	           in its body, and every prefix of them, such as
+//	           (validate.rules) for (validate.rules).string.min_len
+//	deprecated present if the definition sets deprecated = true
+//	stream     client and/or server, for a streaming rpc
+//	input      an rpc's input type
+//	output     an rpc's output type
+//	extendee   the message that an extend block, or a field within one,
+//	           extends
+//	modifier   an import's modifiers, such as public or weak
+//
+// [Query.MatchIR] additionally matches against the lowered [ir.File]. There,
+// type, input, output, and extendee also have the resolved fully-qualified
+// name of the type as a value, full_name and number are as computed by the
+// compiler, and the feature.<name> attributes have the resolved value of
+// the google.protobuf.FeatureSet field <name>, such as
+// [feature.field_presence=EXPLICIT]. Enum values are matched by name.
+package query
@@ -0,0 +1,208 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/ir"
+	"github.com/bufbuild/protocompile/experimental/seq"
+)
+
+// symbol is what a declaration was lowered to. At most one field is set.
+type symbol struct {
+	member  ir.Member
+	ty      ir.Type
+	oneof   ir.Oneof
+	service ir.Service
+	method  ir.Method
+	extend  ir.Extend
+}
+
+// indexIR maps the declarations in a lowered file to what they were lowered
+// to.
+func indexIR(file *ir.File) map[ast.DeclAny]symbol {
+	symbols := make(map[ast.DeclAny]symbol)
+	add := func(def ast.DeclDef, sym symbol) {
+		// Groups lower to both a field and a type, and synthetic oneofs
+		// point at their field; the first one added wins.
+		if _, ok := symbols[def.AsAny()]; !ok && !def.IsZero() {
+			symbols[def.AsAny()] = sym
+		}
+	}
+
+	for member := range file.AllMembers() {
+		if !member.Parent().IsMapEntry() {
+			add(member.AST(), symbol{member: member})
+		}
+	}
+	for ty := range seq.Values(file.AllTypes()) {
+		if ty.IsMapEntry() {
+			continue
+		}
+		add(ty.AST(), symbol{ty: ty})
+		for oneof := range seq.Values(ty.Oneofs()) {
+			add(oneof.AST(), symbol{oneof: oneof})
+		}
+	}
+	for extend := range seq.Values(file.AllExtends()) {
+		add(extend.AST(), symbol{extend: extend})
+	}
+	for service := range seq.Values(file.Services()) {
+		add(service.AST(), symbol{service: service})
+		for method := range seq.Values(service.Methods()) {
+			add(method.AST(), symbol{method: method})
+		}
+	}
+	return symbols
+}
+
+func (s symbol) isZero() bool {
+	return s == symbol{}
+}
+
+// attr computes the value of an attribute, given its value computed from
+// the AST.
+func (s symbol) attr(name string, values []string) []string {
+	switch name {
+	case "full_name":
+		if name := s.fullName(); name != "" {
+			return []string{string(name)}
+		}
+
+	case "type":
+		if !s.member.IsZero() && !s.member.IsEnumValue() {
+			return appendName(values, s.member.Element().FullName())
+		}
+
+	case "number":
+		if !s.member.IsZero() {
+			return []string{strconv.Itoa(int(s.member.Number()))}
+		}
+
+	case "deprecated":
+		if deprecated, _ := s.deprecated().AsBool(); deprecated {
+			return []string{"true"}
+		}
+
+	case "input", "output":
+		if s.method.IsZero() {
+			break
+		}
+		ty, _ := s.method.Input()
+		if name == "output" {
+			ty, _ = s.method.Output()
+		}
+		return appendName(values, ty.FullName())
+
+	case "extendee":
+		switch {
+		case !s.extend.IsZero():
+			return appendName(values, s.extend.Extendee().FullName())
+		case !s.member.IsZero() && s.member.IsExtension():
+			return appendName(values, s.member.Container().FullName())
+		}
+
+	default:
+		feature, ok := strings.CutPrefix(name, featurePrefix)
+		if !ok {
+			break
+		}
+		if value, ok := valueText(s.featureSet().LookupName(feature).Value()); ok {
+			return []string{value}
+		}
+	}
+	return values
+}
+
+func (s symbol) fullName() ir.FullName {
+	switch {
+	case !s.member.IsZero():
+		return s.member.FullName()
+	case !s.ty.IsZero():
+		return s.ty.FullName()
+	case !s.oneof.IsZero():
+		return s.oneof.FullName()
+	case !s.service.IsZero():
+		return s.service.FullName()
+	case !s.method.IsZero():
+		return s.method.FullName()
+	}
+	return ""
+}
+
+func (s symbol) deprecated() ir.Value {
+	switch {
+	case !s.member.IsZero():
+		return s.member.Deprecated()
+	case !s.ty.IsZero():
+		return s.ty.Deprecated()
+	case !s.service.IsZero():
+		return s.service.Deprecated()
+	case !s.method.IsZero():
+		return s.method.Deprecated()
+	}
+	return ir.Value{}
+}
+
+func (s symbol) featureSet() ir.FeatureSet {
+	switch {
+	case !s.member.IsZero():
+		return s.member.FeatureSet()
+	case !s.ty.IsZero():
+		return s.ty.FeatureSet()
+	case !s.oneof.IsZero():
+		return s.oneof.FeatureSet()
+	case !s.service.IsZero():
+		return s.service.FeatureSet()
+	case !s.method.IsZero():
+		return s.method.FeatureSet()
+	}
+	return ir.FeatureSet{}
+}
+
+// appendName appends a resolved name to values, if it is not already there.
+func appendName(values []string, name ir.FullName) []string {
+	if name == "" || slices.Contains(values, string(name)) {
+		return values
+	}
+	return append(values, string(name))
+}
+
+// valueText formats a scalar option value.
+func valueText(v ir.Value) (string, bool) {
+	if v.IsZero() {
+		return "", false
+	}
+	if enum := v.AsEnum(); !enum.IsZero() {
+		return enum.Name(), true
+	}
+	if b, ok := v.AsBool(); ok {
+		return strconv.FormatBool(b), true
+	}
+	if n, ok := v.AsInt(); ok {
+		return strconv.FormatInt(n, 10), true
+	}
+	if n, ok := v.AsUInt(); ok {
+		return strconv.FormatUint(n, 10), true
+	}
+	if f, ok := v.AsFloat(); ok {
+		return strconv.FormatFloat(f, 'g', -1, 64), true
+	}
+	return v.AsString()
+}
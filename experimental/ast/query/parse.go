@@ -0,0 +1,299 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// kinds are the kinds of declarations a step may name.
+var kinds = []string{
+	"syntax", "edition", "package", "import", "option",
+	"message", "enum", "service", "field", "group", "oneof", "value", "rpc", "extend",
+	"reserved", "extensions",
+}
+
+// attrs are the attributes a filter may test, other than feature.<name>.
+var attrs = []string{
+	"name", "full_name", "type", "label", "number", "value", "option",
+	"deprecated", "stream", "input", "output", "extendee", "modifier",
+}
+
+// featurePrefix is the prefix of the attributes for resolved features.
+const featurePrefix = "feature."
+
+// selector is a sequence of steps, each of which must match the declaration
+// or one of its ancestors.
+type selector struct {
+	steps []step
+}
+
+// step matches a single declaration.
+type step struct {
+	kind    string // "*" for any kind.
+	child   bool   // Whether this step must match a child of the previous step.
+	filters []filter
+}
+
+// filter tests an attribute of a declaration.
+type filter struct {
+	attr  string
+	op    op
+	value string
+	re    *regexp.Regexp
+}
+
+// op is the comparison a filter makes.
+type op int
+
+const (
+	opPresent  op = iota // [attr]
+	opAbsent             // [!attr]
+	opEqual              // [attr=v]
+	opNotEqual           // [attr!=v]
+	opMatch              // [attr~=re]
+)
+
+// parser is the state for parsing a query.
+type parser struct {
+	text string
+	pos  int
+}
+
+// Parse parses a query. See the package documentation for the syntax.
+func Parse(text string) (*Query, error) {
+	p := &parser{text: text}
+	q := &Query{text: text}
+	for {
+		sel, err := p.selector()
+		if err != nil {
+			return nil, err
+		}
+		q.selectors = append(q.selectors, sel)
+
+		if p.done() {
+			return q, nil
+		}
+		if !p.eat(",") {
+			return nil, p.errorf("expected `,` or `[`, found %q", p.text[p.pos:p.pos+1])
+		}
+	}
+}
+
+// MustParse is like [Parse], but panics on error.
+func MustParse(text string) *Query {
+	q, err := Parse(text)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+func (p *parser) selector() (selector, error) {
+	var sel selector
+	var child bool
+	for !p.done() && !p.peek(",") {
+		if p.eat(">") {
+			if len(sel.steps) == 0 || child {
+				return sel, p.errorf("unexpected `>`")
+			}
+			child = true
+			continue
+		}
+
+		step, err := p.step()
+		if err != nil {
+			return sel, err
+		}
+		step.child, child = child, false
+		sel.steps = append(sel.steps, step)
+	}
+
+	switch {
+	case child:
+		return sel, p.errorf("expected a declaration kind after `>`")
+	case len(sel.steps) == 0:
+		return sel, p.errorf("expected a selector")
+	}
+	return sel, nil
+}
+
+func (p *parser) step() (step, error) {
+	step := step{kind: "*"}
+	start := p.pos
+	if !p.eat("*") {
+		if word := p.word(false); word != "" {
+			if !slices.Contains(kinds, word) {
+				p.pos = start
+				return step, p.errorf("unknown declaration kind `%s`", word)
+			}
+			step.kind = word
+		}
+	}
+
+	for p.text[p.pos:] != "" && p.text[p.pos] == '[' {
+		f, err := p.filter()
+		if err != nil {
+			return step, err
+		}
+		step.filters = append(step.filters, f)
+	}
+
+	if p.pos == start {
+		return step, p.errorf("unexpected %q", p.text[p.pos:p.pos+1])
+	}
+	return step, nil
+}
+
+func (p *parser) filter() (filter, error) {
+	var f filter
+	p.pos++ // Skip the [.
+	p.skipSpace()
+	negate := p.eat("!")
+
+	start := p.pos
+	f.attr = p.word(true)
+	switch {
+	case f.attr == "":
+		return f, p.errorf("expected an attribute name")
+	case !slices.Contains(attrs, f.attr) &&
+		(!strings.HasPrefix(f.attr, featurePrefix) || f.attr == featurePrefix):
+		p.pos = start
+		return f, p.errorf("unknown attribute `%s`", f.attr)
+	}
+
+	if p.eat("]") {
+		f.op = opPresent
+		if negate {
+			f.op = opAbsent
+		}
+		return f, nil
+	}
+	if negate {
+		return f, p.errorf("expected `]` after negated attribute")
+	}
+
+	switch {
+	case p.eat("="):
+		f.op = opEqual
+	case p.eat("!="):
+		f.op = opNotEqual
+	case p.eat("~="):
+		f.op = opMatch
+	default:
+		return f, p.errorf("expected `]`, `=`, `!=`, or `~=`")
+	}
+
+	var err error
+	p.skipSpace()
+	start = p.pos
+	if f.value, err = p.value(); err != nil {
+		return f, err
+	}
+	if !p.eat("]") {
+		return f, p.errorf("expected `]`")
+	}
+
+	if f.op == opMatch {
+		if f.re, err = regexp.Compile(f.value); err != nil {
+			p.pos = start
+			return f, p.errorf("invalid regular expression: %v", err)
+		}
+	}
+	return f, nil
+}
+
+// value parses a filter's value: either a quoted string, or everything up to
+// the closing bracket.
+func (p *parser) value() (string, error) {
+	rest := p.text[p.pos:]
+	if !strings.HasPrefix(rest, `"`) {
+		end := strings.IndexByte(rest, ']')
+		if end < 0 {
+			return "", p.errorf("expected `]`")
+		}
+		p.pos += end
+		return strings.TrimSpace(rest[:end]), nil
+	}
+
+	for i := 1; i < len(rest); i++ {
+		switch rest[i] {
+		case '\\':
+			i++
+		case '"':
+			value, err := strconv.Unquote(rest[:i+1])
+			if err != nil {
+				return "", p.errorf("invalid string: %v", err)
+			}
+			p.pos += i + 1
+			p.skipSpace()
+			return value, nil
+		}
+	}
+	return "", p.errorf("unterminated string")
+}
+
+// word parses an identifier, which may contain dots if dotted is set.
+func (p *parser) word(dotted bool) string {
+	end := strings.IndexFunc(p.text[p.pos:], func(r rune) bool {
+		return !(r == '_' || r == '.' && dotted ||
+			'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z' || '0' <= r && r <= '9')
+	})
+	if end < 0 {
+		end = len(p.text) - p.pos
+	}
+	word := p.text[p.pos : p.pos+end]
+	p.pos += end
+	return word
+}
+
+// eat consumes tok if it is next, after any whitespace.
+//
+// Whitespace after tok is not consumed, since whitespace between steps is
+// significant.
+func (p *parser) eat(tok string) bool {
+	if !p.peek(tok) {
+		return false
+	}
+	p.pos += len(tok)
+	return true
+}
+
+// peek reports whether tok is next, after any whitespace.
+func (p *parser) peek(tok string) bool {
+	p.skipSpace()
+	return strings.HasPrefix(p.text[p.pos:], tok)
+}
+
+// done reports whether the whole query has been parsed.
+func (p *parser) done() bool {
+	p.skipSpace()
+	return p.pos == len(p.text)
+}
+
+// skipSpace skips whitespace.
+func (p *parser) skipSpace() {
+	for p.pos < len(p.text) && strings.IndexByte(" \t\r\n", p.text[p.pos]) >= 0 {
+		p.pos++
+	}
+}
+
+func (p *parser) errorf(format string, args ...any) error {
+	return fmt.Errorf("query: at offset %d: %s", p.pos, fmt.Sprintf(format, args...))
+}
@@ -0,0 +1,160 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// protogrep prints the declarations in Protobuf files that match a query. See
+// package github.com/bufbuild/protocompile/experimental/ast/query for the
+// query syntax.
+//
+//	go run ./experimental/ast/query/protogrep 'rpc[stream=client]' a.proto b.proto
+//
+// With -I, the files are compiled, with imports resolved relative to the
+// given directories, and the query can also match resolved types and
+// features. File paths are then also relative to those directories.
+//
+//	go run ./experimental/ast/query/protogrep -I . 'field[feature.field_presence=IMPLICIT]' acme/v1/user.proto
+//
+// Like grep, protogrep exits with status 0 if anything matched, 1 if nothing
+// did, and 2 if there was an error, including errors in the files searched.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/bufbuild/protocompile/experimental/ast/query"
+	"github.com/bufbuild/protocompile/experimental/incremental"
+	"github.com/bufbuild/protocompile/experimental/incremental/queries"
+	"github.com/bufbuild/protocompile/experimental/ir"
+	"github.com/bufbuild/protocompile/experimental/parser"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/source"
+	"github.com/bufbuild/protocompile/internal/ext/flagx"
+)
+
+var (
+	imports []string
+	list    = flag.Bool("l", false, "only print the names of files with matches")
+)
+
+func init() {
+	flag.Func("I", "compile files, resolving imports relative to this directory; may be repeated", func(dir string) error {
+		imports = append(imports, dir)
+		return nil
+	})
+}
+
+func main() {
+	flagx.Main(func() error {
+		matched, err := grep()
+		switch {
+		case err != nil:
+			return flagx.Exit(2, err)
+		case !matched:
+			return flagx.Exit(1, nil)
+		}
+		return nil
+	})
+}
+
+// grep prints the matches for the query in the files named by the arguments,
+// and returns whether there were any.
+func grep() (matched bool, _ error) {
+	if flag.NArg() < 1 {
+		return false, fmt.Errorf("usage: protogrep [-l] [-I dir]... query file...")
+	}
+	q, err := query.Parse(flag.Arg(0))
+	if err != nil {
+		return false, err
+	}
+
+	r := new(report.Report)
+	var matches func(path string) []query.Match
+	if imports == nil {
+		matches = func(path string) []query.Match {
+			text, err := os.ReadFile(path)
+			if err != nil {
+				r.Errorf("%v", err)
+				return nil
+			}
+			file, _ := parser.Parse(path, source.NewFile(path, string(text)), r)
+			return slices.Collect(q.Match(file))
+		}
+	} else {
+		files, err := compile(flag.Args()[1:], r)
+		if err != nil {
+			return false, err
+		}
+		matches = func(path string) []query.Match {
+			if file := files[path]; file != nil {
+				return slices.Collect(q.MatchIR(file))
+			}
+			return nil
+		}
+	}
+
+	for _, path := range flag.Args()[1:] {
+		found := matches(path)
+		matched = matched || len(found) > 0
+		if *list && len(found) > 0 {
+			fmt.Println(path)
+			continue
+		}
+		for _, m := range found {
+			loc := m.Span().StartLoc()
+			line, _, _ := strings.Cut(m.Span().Text(), "\n")
+			fmt.Printf("%s:%d:%d: %s\n", path, loc.Line, loc.Column, line)
+		}
+	}
+
+	if text, errs, _ := (report.Renderer{}).RenderString(r); errs > 0 {
+		return matched, errors.New(strings.TrimSuffix(text, "\n"))
+	}
+	return matched, nil
+}
+
+// compile lowers the given files, resolving imports relative to the import
+// directories.
+func compile(paths []string, r *report.Report) (map[string]*ir.File, error) {
+	opener := new(source.Openers)
+	for _, dir := range imports {
+		*opener = append(*opener, &source.FS{FS: os.DirFS(dir)})
+	}
+	*opener = append(*opener, source.WKTs())
+
+	session := new(ir.Session)
+	qs := make([]incremental.Query[*ir.File], len(paths))
+	for i, path := range paths {
+		qs[i] = queries.IR{Opener: opener, Session: session, Path: path}
+	}
+	results, rr, err := incremental.Run(context.Background(), incremental.New(), qs...)
+	if err != nil {
+		return nil, err
+	}
+	r.Diagnostics = append(r.Diagnostics, rr.Diagnostics...)
+
+	files := make(map[string]*ir.File)
+	for i, result := range results {
+		if result.Fatal != nil {
+			r.Errorf("%s: %v", paths[i], result.Fatal)
+			continue
+		}
+		files[paths[i]] = result.Value
+	}
+	return files, nil
+}
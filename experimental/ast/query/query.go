@@ -0,0 +1,472 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"iter"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/ir"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/source"
+	"github.com/bufbuild/protocompile/experimental/token"
+	"github.com/bufbuild/protocompile/experimental/token/keyword"
+)
+
+// Query is a parsed query. See the package documentation for the syntax.
+type Query struct {
+	text      string
+	selectors []selector
+}
+
+// Match is a declaration matched by a [Query].
+type Match struct {
+	Decl ast.DeclAny
+
+	// The kind of declaration, as it is spelled in queries.
+	Kind string
+}
+
+// Span implements [source.Spanner].
+func (m Match) Span() source.Span {
+	return m.Decl.Span()
+}
+
+// String returns the text q was parsed from.
+func (q *Query) String() string {
+	return q.text
+}
+
+// Match returns the declarations in file that q matches, in the order in
+// which they appear.
+func (q *Query) Match(file *ast.File) iter.Seq[Match] {
+	return q.match(file, nil)
+}
+
+// MatchIR is like [Query.Match], but also matches against the facts that
+// lowering file computed, such as resolved types and features.
+func (q *Query) MatchIR(file *ir.File) iter.Seq[Match] {
+	return q.match(file.AST(), indexIR(file))
+}
+
+func (q *Query) match(file *ast.File, symbols map[ast.DeclAny]symbol) iter.Seq[Match] {
+	return func(yield func(Match) bool) {
+		var pkg string
+		for decl := range seq.Values(file.Decls()) {
+			if decl.Kind() == ast.DeclKindPackage {
+				pkg = decl.AsPackage().Path().Canonicalized()
+				break
+			}
+		}
+
+		w := &walker{query: q, symbols: symbols, yield: yield}
+		w.walk(file.Decls(), nil, pkg)
+	}
+}
+
+// walker is the state for matching a query against a file.
+type walker struct {
+	query   *Query
+	symbols map[ast.DeclAny]symbol
+	yield   func(Match) bool
+}
+
+// walk matches the declarations in decls and their descendants, returning
+// false once yield does.
+func (w *walker) walk(decls seq.Indexer[ast.DeclAny], parent *node, scope string) bool {
+	for decl := range seq.Values(decls) {
+		n := &node{
+			decl:   decl,
+			def:    decl.AsDef(),
+			kind:   kindOf(decl),
+			scope:  scope,
+			parent: parent,
+		}
+		if n.kind == "" {
+			continue
+		}
+		n.symbol = w.symbols[decl]
+
+		if slices.ContainsFunc(w.query.selectors, func(s selector) bool {
+			return s.matches(len(s.steps)-1, n)
+		}) && !w.yield(Match{Decl: decl, Kind: n.kind}) {
+			return false
+		}
+
+		if body := n.def.Body(); !body.IsZero() {
+			inner := scope
+			switch n.kind {
+			case "message", "group", "service":
+				inner = n.fullName()
+			}
+			if !w.walk(body.Decls(), n, inner) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// matches reports whether the first i+1 steps of s match n and its ancestors.
+func (s selector) matches(i int, n *node) bool {
+	if !s.steps[i].matches(n) {
+		return false
+	}
+	if i == 0 {
+		return true
+	}
+	if s.steps[i].child {
+		return n.parent != nil && s.matches(i-1, n.parent)
+	}
+	for p := n.parent; p != nil; p = p.parent {
+		if s.matches(i-1, p) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s step) matches(n *node) bool {
+	if s.kind != "*" && s.kind != n.kind {
+		return false
+	}
+	for _, f := range s.filters {
+		if !f.matches(n.attr(f.attr)) {
+			return false
+		}
+	}
+	return true
+}
+
+func (f filter) matches(values []string) bool {
+	switch f.op {
+	case opPresent:
+		return len(values) > 0
+	case opAbsent:
+		return len(values) == 0
+	case opEqual:
+		return slices.Contains(values, f.value)
+	case opNotEqual:
+		return !slices.Contains(values, f.value)
+	case opMatch:
+		return slices.ContainsFunc(values, f.re.MatchString)
+	}
+	return false
+}
+
+// kindOf returns the kind of a declaration, as it is spelled in queries, or
+// "" if it cannot be matched.
+func kindOf(decl ast.DeclAny) string {
+	switch decl.Kind() {
+	case ast.DeclKindSyntax:
+		if decl.AsSyntax().IsEdition() {
+			return "edition"
+		}
+		return "syntax"
+	case ast.DeclKindPackage:
+		return "package"
+	case ast.DeclKindImport:
+		return "import"
+	case ast.DeclKindRange:
+		if decl.AsRange().IsExtensions() {
+			return "extensions"
+		}
+		return "reserved"
+	case ast.DeclKindDef:
+		switch decl.AsDef().Classify() {
+		case ast.DefKindMessage:
+			return "message"
+		case ast.DefKindEnum:
+			return "enum"
+		case ast.DefKindService:
+			return "service"
+		case ast.DefKindField:
+			return "field"
+		case ast.DefKindGroup:
+			return "group"
+		case ast.DefKindOneof:
+			return "oneof"
+		case ast.DefKindEnumValue:
+			return "value"
+		case ast.DefKindMethod:
+			return "rpc"
+		case ast.DefKindExtend:
+			return "extend"
+		case ast.DefKindOption:
+			return "option"
+		}
+	}
+	return ""
+}
+
+// node is a declaration being matched, along with its ancestors.
+type node struct {
+	decl   ast.DeclAny
+	def    ast.DeclDef
+	kind   string
+	scope  string // The scope in which the declaration's name is defined.
+	parent *node
+	symbol symbol
+
+	attrs map[string][]string
+}
+
+// attr returns the values of an attribute of n.
+func (n *node) attr(name string) []string {
+	if values, ok := n.attrs[name]; ok {
+		return values
+	}
+	values := n.astAttr(name)
+	if !n.symbol.isZero() {
+		values = n.symbol.attr(name, values)
+	}
+
+	if n.attrs == nil {
+		n.attrs = make(map[string][]string)
+	}
+	n.attrs[name] = values
+	return values
+}
+
+// astAttr computes the value of an attribute from the AST alone.
+func (n *node) astAttr(name string) []string {
+	def := n.def
+	switch name {
+	case "name":
+		switch n.kind {
+		case "syntax", "edition":
+			return exprText(n.decl.AsSyntax().Value())
+		case "package":
+			return []string{n.decl.AsPackage().Path().Canonicalized()}
+		case "import":
+			return exprText(n.decl.AsImport().ImportPath())
+		case "option", "extend":
+			return []string{def.Name().Canonicalized()}
+		}
+		if name := def.Name().AsIdent(); !name.IsZero() {
+			return []string{name.Name()}
+		}
+
+	case "full_name":
+		if name := n.fullName(); name != "" {
+			return []string{name}
+		}
+
+	case "type":
+		if n.kind == "field" {
+			return []string{typeText(def.Type().RemovePrefixes())}
+		}
+
+	case "label":
+		if n.kind != "field" && n.kind != "group" {
+			break
+		}
+		var labels []string
+		for prefix := range def.Prefixes() {
+			labels = append(labels, prefix.Prefix().String())
+		}
+		return labels
+
+	case "number":
+		if n.kind != "field" && n.kind != "group" && n.kind != "value" {
+			break
+		}
+		if v, ok := evalInt(def.Value()); ok {
+			return []string{strconv.FormatInt(v, 10)}
+		}
+
+	case "value":
+		if n.kind == "option" {
+			return exprText(def.Value())
+		}
+
+	case "option":
+		var names []string
+		for opt := range seq.Values(def.Options().Entries()) {
+			names = appendPrefixes(names, opt.Path)
+		}
+		for _, opt := range n.bodyOptions() {
+			names = appendPrefixes(names, opt.Name())
+		}
+		return names
+
+	case "deprecated":
+		for opt := range seq.Values(def.Options().Entries()) {
+			if isDeprecated(opt.Path, opt.Value) {
+				return []string{"true"}
+			}
+		}
+		for _, opt := range n.bodyOptions() {
+			if isDeprecated(opt.Name(), opt.Value()) {
+				return []string{"true"}
+			}
+		}
+
+	case "stream":
+		if n.kind != "rpc" {
+			break
+		}
+		var streams []string
+		if isStream(def.Signature().Inputs()) {
+			streams = append(streams, "client")
+		}
+		if isStream(def.Signature().Outputs()) {
+			streams = append(streams, "server")
+		}
+		return streams
+
+	case "input", "output":
+		if n.kind != "rpc" {
+			break
+		}
+		types := def.Signature().Inputs()
+		if name == "output" {
+			types = def.Signature().Outputs()
+		}
+		if types.Len() > 0 {
+			return []string{typeText(types.At(0).RemovePrefixes())}
+		}
+
+	case "extendee":
+		switch {
+		case n.kind == "extend":
+			return []string{def.Name().Canonicalized()}
+		case n.parent != nil && n.parent.kind == "extend":
+			return n.parent.astAttr(name)
+		}
+
+	case "modifier":
+		if n.kind != "import" {
+			break
+		}
+		var mods []string
+		for mod := range seq.Values(n.decl.AsImport().Modifiers()) {
+			mods = append(mods, mod.String())
+		}
+		return mods
+	}
+	return nil
+}
+
+// fullName returns the fully-qualified name of a named definition, as
+// written in the AST.
+func (n *node) fullName() string {
+	switch n.kind {
+	case "message", "enum", "service", "field", "group", "oneof", "value", "rpc":
+	default:
+		return ""
+	}
+	name := n.def.Name().AsIdent()
+	if name.IsZero() {
+		return ""
+	}
+	if n.scope == "" {
+		return name.Name()
+	}
+	return n.scope + "." + name.Name()
+}
+
+// bodyOptions returns the option declarations in n's body.
+func (n *node) bodyOptions() []ast.DeclDef {
+	body := n.def.Body()
+	if body.IsZero() {
+		return nil
+	}
+	var options []ast.DeclDef
+	for decl := range seq.Values(body.Decls()) {
+		if def := decl.AsDef(); def.Classify() == ast.DefKindOption {
+			options = append(options, def)
+		}
+	}
+	return options
+}
+
+// appendPrefixes appends the canonical form of each prefix of an option
+// name to names.
+func appendPrefixes(names []string, path ast.Path) []string {
+	name := path.Canonicalized()
+	for i, r := range name {
+		if r == '.' && strings.Count(name[:i], "(") == strings.Count(name[:i], ")") {
+			names = append(names, name[:i])
+		}
+	}
+	return append(names, name)
+}
+
+// isDeprecated reports whether an option sets deprecated = true.
+func isDeprecated(name ast.Path, value ast.ExprAny) bool {
+	return name.Canonicalized() == "deprecated" && value.AsPath().AsKeyword() == keyword.True
+}
+
+// isStream reports whether a method's input or output is a stream.
+func isStream(types ast.TypeList) bool {
+	if types.Len() == 0 {
+		return false
+	}
+	for prefix := range types.At(0).Prefixes() {
+		if prefix.Prefix() == keyword.Stream {
+			return true
+		}
+	}
+	return false
+}
+
+// typeText returns the text of a type, without whitespace.
+func typeText(ty ast.TypeAny) string {
+	if path := ty.AsPath(); !path.IsZero() {
+		return path.Path.Canonicalized()
+	}
+	return strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, ty.Span().Text())
+}
+
+// exprText returns the text of an expression, unquoting strings.
+func exprText(expr ast.ExprAny) []string {
+	if expr.IsZero() {
+		return nil
+	}
+	if str := expr.AsLiteral().AsString(); !str.IsZero() {
+		return []string{str.Text()}
+	}
+	return []string{expr.Span().Text()}
+}
+
+// evalInt evaluates an integer expression, such as a field number.
+func evalInt(expr ast.ExprAny) (int64, bool) {
+	switch expr.Kind() {
+	case ast.ExprKindLiteral:
+		tok := expr.AsLiteral().Token
+		if tok.Kind() != token.Number {
+			break
+		}
+		if v, exact := tok.AsNumber().Int(); exact && v <= 1<<63-1 {
+			return int64(v), true
+		}
+	case ast.ExprKindPrefixed:
+		if prefixed := expr.AsPrefixed(); prefixed.Prefix() == keyword.Sub {
+			v, ok := evalInt(prefixed.Expr())
+			return -v, ok
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,211 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query_test
+
+import (
+	"context"
+	"iter"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bufbuild/protocompile/experimental/ast/query"
+	"github.com/bufbuild/protocompile/experimental/incremental"
+	"github.com/bufbuild/protocompile/experimental/incremental/queries"
+	"github.com/bufbuild/protocompile/experimental/ir"
+	"github.com/bufbuild/protocompile/experimental/parser"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/source"
+)
+
+const testProto = `edition = "2023";
+
+package acme.v1;
+
+import "google/protobuf/descriptor.proto";
+import "google/protobuf/timestamp.proto";
+
+extend google.protobuf.FieldOptions {
+  Rules rules = 50000;
+}
+
+message Rules {
+  int32 min_len = 1;
+}
+
+message User {
+  string id = 1 [(rules).min_len = 1];
+  string name = 2 [features.field_presence = IMPLICIT];
+  repeated string tags = 3;
+  google.protobuf.Timestamp created = 4 [deprecated = true];
+  map<string, int32> counts = 5;
+  message Address {
+    string city = 1;
+  }
+  Address address = 6;
+  oneof contact {
+    string email = 7;
+  }
+  reserved 10 to 20;
+  extensions 100 to 200;
+}
+
+enum Role {
+  ROLE_UNSPECIFIED = 0;
+  ROLE_ADMIN = 1 [deprecated = true];
+}
+
+service Users {
+  option deprecated = true;
+  rpc Get(User) returns (User);
+  rpc Watch(User) returns (stream User);
+  rpc Upload(stream User) returns (User);
+  rpc Chat(stream User) returns (stream User);
+}
+`
+
+func TestMatch(t *testing.T) {
+	t.Parallel()
+
+	file, _ := parser.Parse("test.proto", source.NewFile("test.proto", testProto), new(report.Report))
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"field[type=string]", []string{"string id", "string name", "repeated string tags", "string city", "string email"}},
+		{"field[option=(rules)]", []string{"string id"}},
+		{"field[option=(rules).min_len]", []string{"string id"}},
+		{"field[label=repeated]", []string{"repeated string tags"}},
+		{"field[type=map<string,int32>]", []string{"map<string, int32> counts"}},
+		{"field[number=3], value[number=1]", []string{"repeated string tags", "ROLE_ADMIN"}},
+		{"rpc[stream=client]", []string{"rpc Upload", "rpc Chat"}},
+		{"rpc[stream=server][stream!=client]", []string{"rpc Watch"}},
+		{"rpc[!stream]", []string{"rpc Get"}},
+		{"rpc[input=User][output=User]", []string{"rpc Get", "rpc Watch", "rpc Upload", "rpc Chat"}},
+		{"message > field[type=string]", []string{"string id", "string name", "repeated string tags", "string city"}},
+		{"message[name=User] > oneof > field", []string{"string email"}},
+		{"message message field", []string{"string city"}},
+		{"field[full_name=acme.v1.User.Address.city]", []string{"string city"}},
+		{"value[full_name=acme.v1.ROLE_ADMIN]", []string{"ROLE_ADMIN"}},
+		{"*[deprecated]", []string{"google.protobuf.Timestamp created", "ROLE_ADMIN", "service Users"}},
+		{"*[name~=^ROLE_]", []string{"ROLE_UNSPECIFIED", "ROLE_ADMIN"}},
+		{"reserved, extensions", []string{"reserved 10 to 20", "extensions 100 to 200"}},
+		{"import[name~=timestamp]", []string{`import "google/protobuf/timestamp.proto"`}},
+		{"edition[name=2023], package", []string{`edition = "2023"`, "package acme.v1"}},
+		{"service > option[name=deprecated][value=true]", []string{"option deprecated"}},
+		{"extend > field[extendee=google.protobuf.FieldOptions]", []string{"Rules rules"}},
+		{"field[type=acme.v1.User.Address]", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			t.Parallel()
+			q, err := query.Parse(tt.query)
+			require.NoError(t, err)
+			assertMatches(t, tt.want, q.Match(file))
+		})
+	}
+}
+
+func TestMatchIR(t *testing.T) {
+	t.Parallel()
+
+	opener := &source.Openers{
+		source.NewMap(map[string]*source.File{
+			"test.proto": source.NewFile("test.proto", testProto),
+		}),
+		source.WKTs(),
+	}
+	results, r, err := incremental.Run(context.Background(), incremental.New(), queries.IR{
+		Opener:  opener,
+		Session: new(ir.Session),
+		Path:    "test.proto",
+	})
+	require.NoError(t, err)
+	require.NoError(t, results[0].Fatal)
+	for _, d := range r.Diagnostics {
+		require.Greater(t, d.Level(), report.Error, "%v", d)
+	}
+	file := results[0].Value
+
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"field[type=acme.v1.User.Address]", []string{"Address address"}},
+		{"field[type=google.protobuf.Timestamp]", []string{"google.protobuf.Timestamp created"}},
+		{"field[feature.field_presence=IMPLICIT]", []string{"string name"}},
+		{"message[feature.field_presence=EXPLICIT][name=Rules]", []string{"message Rules"}},
+		{"rpc[input=acme.v1.User][stream=server]", []string{"rpc Watch", "rpc Chat"}},
+		{"field[extendee=google.protobuf.FieldOptions]", []string{"Rules rules"}},
+		{"value[full_name=acme.v1.ROLE_ADMIN]", []string{"ROLE_ADMIN"}},
+		{"*[feature.no_such_feature]", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			t.Parallel()
+			q, err := query.Parse(tt.query)
+			require.NoError(t, err)
+			assertMatches(t, tt.want, q.MatchIR(file))
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		query, err string
+	}{
+		{"", "expected a selector"},
+		{"message >", "expected a declaration kind after `>`"},
+		{"> field", "unexpected `>`"},
+		{"field,", "expected a selector"},
+		{"struct", "unknown declaration kind `struct`"},
+		{"field[size]", "unknown attribute `size`"},
+		{"field[name", "expected `]`"},
+		{"field[name<3]", "expected `]`, `=`, `!=`, or `~=`"},
+		{"field[!name=x]", "expected `]` after negated attribute"},
+		{"field[name~=(]", "invalid regular expression"},
+		{`field[name="x]`, "unterminated string"},
+		{"field)", `unexpected ")"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.query, func(t *testing.T) {
+			t.Parallel()
+			_, err := query.Parse(tt.query)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+// assertMatches checks that the matched declarations begin with the given
+// prefixes, in order.
+func assertMatches(t *testing.T, want []string, matches iter.Seq[query.Match]) {
+	t.Helper()
+	var got []string
+	for m := range matches {
+		got = append(got, m.Span().Text())
+	}
+	if !assert.Len(t, got, len(want), "%q", got) {
+		return
+	}
+	for i, text := range got {
+		assert.True(t, strings.HasPrefix(text, want[i]), "match %d: got %q, want prefix %q", i, text, want[i])
+	}
+}
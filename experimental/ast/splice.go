@@ -0,0 +1,365 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ast
+
+import (
+	"slices"
+
+	"github.com/bufbuild/protocompile/experimental/id"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/source"
+	"github.com/bufbuild/protocompile/experimental/token"
+	"github.com/bufbuild/protocompile/internal/arena"
+)
+
+// Watermark records how many nodes of each kind have been allocated in a
+// [File].
+//
+// Because nodes are allocated in the order they are parsed, watermarks taken
+// before and after parsing a declaration delimit the nodes that belong to it.
+// This is used by [File.Splice] to reuse unchanged declarations.
+type Watermark struct {
+	decls   [DeclKindRange + 1]int32
+	exprs   [ExprKindField + 1]int32
+	types   [TypeKindGeneric + 1]int32
+	options int32
+}
+
+// Watermark returns a watermark for the nodes allocated so far.
+func (n *Nodes) Watermark() Watermark {
+	f := n.File()
+	var w Watermark
+	w.decls[DeclKindEmpty] = int32(f.decls.empties.Len())
+	w.decls[DeclKindSyntax] = int32(f.decls.syntaxes.Len())
+	w.decls[DeclKindPackage] = int32(f.decls.packages.Len())
+	w.decls[DeclKindImport] = int32(f.decls.imports.Len())
+	w.decls[DeclKindDef] = int32(f.decls.defs.Len())
+	w.decls[DeclKindBody] = int32(f.decls.bodies.Len())
+	w.decls[DeclKindRange] = int32(f.decls.ranges.Len())
+	w.exprs[ExprKindError] = int32(f.exprs.errors.Len())
+	w.exprs[ExprKindPrefixed] = int32(f.exprs.prefixes.Len())
+	w.exprs[ExprKindRange] = int32(f.exprs.ranges.Len())
+	w.exprs[ExprKindArray] = int32(f.exprs.arrays.Len())
+	w.exprs[ExprKindDict] = int32(f.exprs.dicts.Len())
+	w.exprs[ExprKindField] = int32(f.exprs.fields.Len())
+	w.types[TypeKindError] = int32(f.types.errors.Len())
+	w.types[TypeKindPrefixed] = int32(f.types.prefixes.Len())
+	w.types[TypeKindGeneric] = int32(f.types.generics.Len())
+	w.options = int32(f.options.Len())
+	return w
+}
+
+// Rebase returns the watermark that w becomes when the nodes allocated after
+// from are moved to come after to instead.
+func (w Watermark) Rebase(from, to Watermark) Watermark {
+	for i := range w.decls {
+		w.decls[i] += to.decls[i] - from.decls[i]
+	}
+	for i := range w.exprs {
+		w.exprs[i] += to.exprs[i] - from.exprs[i]
+	}
+	for i := range w.types {
+		w.types[i] += to.types[i] - from.types[i]
+	}
+	w.options += to.options - from.options
+	return w
+}
+
+// SpliceArgs is arguments for [File.Splice].
+type SpliceArgs struct {
+	// The range of top-level declarations to replace, as indices into
+	// [File.Decls].
+	Start, End int
+
+	// Watermarks taken immediately before parsing the declaration at Start,
+	// and immediately after parsing the declaration at End-1.
+	Before, After Watermark
+
+	// Parse is called with the new file to construct the replacement
+	// declarations. Nodes for the declarations before Start have already been
+	// allocated; nodes for the declarations at and after End will be allocated
+	// after Parse returns.
+	Parse func(*File) []DeclAny
+}
+
+// Splice builds a new file over stream that reuses the nodes of f.
+//
+// stream must be the result of modifying the tokens of the top-level
+// declarations in the range [args.Start, args.End) of f, without modifying
+// any tokens before or after them, such as the result of [token.Stream.Splice].
+// The nodes of all other top-level declarations are copied into the new file,
+// with their token IDs and spans adjusted to match stream, and the nodes of
+// the modified declarations are replaced with those constructed by args.Parse.
+//
+// The nodes of f must not have been modified since they were parsed, and must
+// not refer to synthetic tokens.
+func (f *File) Splice(stream *token.Stream, args SpliceArgs) *File {
+	out := &File{stream: stream, path: f.path}
+
+	prefix := relocation{file: stream.File}
+	copyNodes(out, f, Watermark{}, args.Before, &prefix)
+
+	decls := args.Parse(out)
+
+	suffix := relocation{
+		file:   stream.File,
+		text:   len(stream.Text()) - len(f.stream.Text()),
+		tokens: lastToken(stream) - lastToken(f.stream),
+		nodes:  Watermark{}.Rebase(args.After, out.Nodes().Watermark()),
+	}
+	copyNodes(out, f, args.After, f.Nodes().Watermark(), &suffix)
+
+	// Rebuild the top-level declaration list. The rawDeclBody for the whole
+	// file is the first node, so it is always part of the prefix.
+	body := out.decls.bodies.Deref(1)
+	old := f.decls.bodies.Deref(1)
+	body.kinds = slices.Clone(old.kinds[:args.Start])
+	body.ptrs = slices.Clone(old.ptrs[:args.Start])
+	for _, decl := range decls {
+		seq.Append(out.Decls(), decl)
+	}
+	for i := args.End; i < len(old.kinds); i++ {
+		body.kinds = append(body.kinds, old.kinds[i])
+		body.ptrs = append(body.ptrs, suffix.decl(old.kinds[i], old.ptrs[i]))
+	}
+
+	return out
+}
+
+// lastToken returns the ID of the last natural token in stream.
+func lastToken(stream *token.Stream) token.ID {
+	last, _ := stream.Around(len(stream.Text()))
+	return last.ID()
+}
+
+// relocation describes how to adjust the nodes copied by [File.Splice].
+type relocation struct {
+	file   *source.File
+	text   int       // Added to span offsets.
+	tokens token.ID  // Added to natural token IDs.
+	nodes  Watermark // Added to node IDs, by kind.
+}
+
+// copyNodes copies the nodes of from between the watermarks start and end into
+// to, applying r to each one.
+func copyNodes(to, from *File, start, end Watermark, r *relocation) {
+	copyArena(&to.decls.empties, &from.decls.empties, start.decls[DeclKindEmpty], end.decls[DeclKindEmpty], func(v *rawDeclEmpty) {
+		v.semi = r.token(v.semi)
+	})
+	copyArena(&to.decls.syntaxes, &from.decls.syntaxes, start.decls[DeclKindSyntax], end.decls[DeclKindSyntax], func(v *rawDeclSyntax) {
+		v.value = r.expr(v.value)
+		v.keyword = r.token(v.keyword)
+		v.equals = r.token(v.equals)
+		v.semi = r.token(v.semi)
+		v.options = r.options(v.options)
+	})
+	copyArena(&to.decls.packages, &from.decls.packages, start.decls[DeclKindPackage], end.decls[DeclKindPackage], func(v *rawDeclPackage) {
+		v.keyword = r.token(v.keyword)
+		v.path = r.path(v.path)
+		v.semi = r.token(v.semi)
+		v.options = r.options(v.options)
+	})
+	copyArena(&to.decls.imports, &from.decls.imports, start.decls[DeclKindImport], end.decls[DeclKindImport], func(v *rawDeclImport) {
+		v.keyword = r.token(v.keyword)
+		v.semi = r.token(v.semi)
+		v.modifiers = slices.Clone(v.modifiers)
+		for i := range v.modifiers {
+			v.modifiers[i] = r.token(v.modifiers[i])
+		}
+		v.importPath = r.expr(v.importPath)
+		v.options = r.options(v.options)
+	})
+	copyArena(&to.decls.defs, &from.decls.defs, start.decls[DeclKindDef], end.decls[DeclKindDef], func(v *rawDeclDef) {
+		v.ty = r.ty(v.ty)
+		v.name = r.path(v.name)
+		if v.signature != nil {
+			sig := *v.signature
+			sig.input = r.typeList(sig.input)
+			sig.output = r.typeList(sig.output)
+			sig.returns = r.token(sig.returns)
+			v.signature = &sig
+		}
+		v.equals = r.token(v.equals)
+		v.value = r.expr(v.value)
+		v.options = r.options(v.options)
+		v.body = id.ID[DeclBody](shift(int32(v.body), r.nodes.decls[DeclKindBody]))
+		v.semi = r.token(v.semi)
+		v.corrupt = false // Recomputed by legalization.
+	})
+	copyArena(&to.decls.bodies, &from.decls.bodies, start.decls[DeclKindBody], end.decls[DeclKindBody], func(v *rawDeclBody) {
+		v.braces = r.token(v.braces)
+		v.kinds = slices.Clone(v.kinds)
+		v.ptrs = slices.Clone(v.ptrs)
+		for i := range v.ptrs {
+			v.ptrs[i] = r.decl(v.kinds[i], v.ptrs[i])
+		}
+	})
+	copyArena(&to.decls.ranges, &from.decls.ranges, start.decls[DeclKindRange], end.decls[DeclKindRange], func(v *rawDeclRange) {
+		v.keyword = r.token(v.keyword)
+		v.args = r.exprs(v.args)
+		v.options = r.options(v.options)
+		v.semi = r.token(v.semi)
+	})
+
+	copyArena(&to.exprs.errors, &from.exprs.errors, start.exprs[ExprKindError], end.exprs[ExprKindError], func(v *rawExprError) {
+		*v = rawExprError(r.span(source.Span(*v)))
+	})
+	copyArena(&to.exprs.prefixes, &from.exprs.prefixes, start.exprs[ExprKindPrefixed], end.exprs[ExprKindPrefixed], func(v *rawExprPrefixed) {
+		v.prefix = r.token(v.prefix)
+		v.expr = r.expr(v.expr)
+	})
+	copyArena(&to.exprs.ranges, &from.exprs.ranges, start.exprs[ExprKindRange], end.exprs[ExprKindRange], func(v *rawExprRange) {
+		v.start = r.expr(v.start)
+		v.end = r.expr(v.end)
+		v.to = r.token(v.to)
+	})
+	copyArena(&to.exprs.arrays, &from.exprs.arrays, start.exprs[ExprKindArray], end.exprs[ExprKindArray], func(v *rawExprArray) {
+		v.brackets = r.token(v.brackets)
+		v.args = r.exprs(v.args)
+	})
+	copyArena(&to.exprs.dicts, &from.exprs.dicts, start.exprs[ExprKindDict], end.exprs[ExprKindDict], func(v *rawExprDict) {
+		v.braces = r.token(v.braces)
+		v.fields = slices.Clone(v.fields)
+		for i := range v.fields {
+			v.fields[i].Value = id.ID[ExprField](shift(int32(v.fields[i].Value), r.nodes.exprs[ExprKindField]))
+			v.fields[i].Comma = r.token(v.fields[i].Comma)
+		}
+	})
+	copyArena(&to.exprs.fields, &from.exprs.fields, start.exprs[ExprKindField], end.exprs[ExprKindField], func(v *rawExprField) {
+		v.key = r.expr(v.key)
+		v.value = r.expr(v.value)
+		v.colon = r.token(v.colon)
+	})
+
+	copyArena(&to.types.errors, &from.types.errors, start.types[TypeKindError], end.types[TypeKindError], func(v *rawTypeError) {
+		*v = rawTypeError(r.span(source.Span(*v)))
+	})
+	copyArena(&to.types.prefixes, &from.types.prefixes, start.types[TypeKindPrefixed], end.types[TypeKindPrefixed], func(v *rawTypePrefixed) {
+		v.prefix = r.token(v.prefix)
+		v.ty = r.ty(v.ty)
+	})
+	copyArena(&to.types.generics, &from.types.generics, start.types[TypeKindGeneric], end.types[TypeKindGeneric], func(v *rawTypeGeneric) {
+		v.path = r.path(v.path)
+		v.args = r.typeList(v.args)
+	})
+
+	copyArena(&to.options, &from.options, start.options, end.options, func(v *rawCompactOptions) {
+		v.brackets = r.token(v.brackets)
+		v.options = slices.Clone(v.options)
+		for i := range v.options {
+			opt := &v.options[i].Value
+			opt.path = r.path(opt.path)
+			opt.equals = r.token(opt.equals)
+			opt.value = r.expr(opt.value)
+			v.options[i].Comma = r.token(v.options[i].Comma)
+		}
+	})
+}
+
+// copyArena copies the values of from with indices in [start, end) onto to.
+func copyArena[T any](to, from *arena.Arena[T], start, end int32, relocate func(*T)) {
+	for i := start; i < end; i++ {
+		v := *from.Deref(arena.Pointer[T](i + 1))
+		relocate(&v)
+		to.New(v)
+	}
+}
+
+func (r *relocation) token(t token.ID) token.ID {
+	if t > 0 {
+		t += r.tokens
+	}
+	return t
+}
+
+func shift(p, delta int32) int32 {
+	if p == 0 {
+		return 0
+	}
+	return p + delta
+}
+
+func (r *relocation) span(s source.Span) source.Span {
+	if s.File == nil {
+		return s
+	}
+	return source.Span{File: r.file, Start: s.Start + r.text, End: s.End + r.text}
+}
+
+func (r *relocation) path(p PathID) PathID {
+	return PathID{start: r.token(p.start), end: r.token(p.end)}
+}
+
+func (r *relocation) options(p id.ID[CompactOptions]) id.ID[CompactOptions] {
+	return id.ID[CompactOptions](shift(int32(p), r.nodes.options))
+}
+
+func (r *relocation) decl(k DeclKind, p id.ID[DeclAny]) id.ID[DeclAny] {
+	if k <= DeclKindInvalid || int(k) >= len(r.nodes.decls) {
+		return p
+	}
+	return id.ID[DeclAny](shift(int32(p), r.nodes.decls[k]))
+}
+
+func (r *relocation) expr(e id.Dyn[ExprAny, ExprKind]) id.Dyn[ExprAny, ExprKind] {
+	lo, hi := e.Raw()
+	switch k := e.Kind(); k {
+	case ExprKindInvalid:
+		return e
+	case ExprKindPath:
+		return id.NewDynFromRaw[ExprAny, ExprKind](
+			int32(r.token(token.ID(lo))),
+			int32(r.token(token.ID(hi))),
+		)
+	case ExprKindLiteral:
+		return id.NewDyn(k, literalID(r.token(literalToken(id.ID[ExprAny](hi)))))
+	default:
+		return id.NewDyn(k, id.ID[ExprAny](shift(hi, r.nodes.exprs[k])))
+	}
+}
+
+func (r *relocation) exprs(args []withComma[id.Dyn[ExprAny, ExprKind]]) []withComma[id.Dyn[ExprAny, ExprKind]] {
+	args = slices.Clone(args)
+	for i := range args {
+		args[i].Value = r.expr(args[i].Value)
+		args[i].Comma = r.token(args[i].Comma)
+	}
+	return args
+}
+
+func (r *relocation) ty(t id.Dyn[TypeAny, TypeKind]) id.Dyn[TypeAny, TypeKind] {
+	lo, hi := t.Raw()
+	switch k := t.Kind(); k {
+	case TypeKindInvalid:
+		return t
+	case TypeKindPath:
+		return id.NewDynFromRaw[TypeAny, TypeKind](
+			int32(r.token(token.ID(lo))),
+			int32(r.token(token.ID(hi))),
+		)
+	default:
+		return id.NewDyn(k, id.ID[TypeAny](shift(hi, r.nodes.types[k])))
+	}
+}
+
+func (r *relocation) typeList(l rawTypeList) rawTypeList {
+	l.brackets = r.token(l.brackets)
+	l.args = slices.Clone(l.args)
+	for i := range l.args {
+		l.args[i].Value = r.ty(l.args[i].Value)
+		l.args[i].Comma = r.token(l.args[i].Comma)
+	}
+	return l
+}
@@ -0,0 +1,429 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ast
+
+import (
+	"fmt"
+	"iter"
+
+	"github.com/bufbuild/protocompile/experimental/source"
+)
+
+// Node is an AST node visited by [Walk].
+//
+// Walk visits, and [Cursor.Node] returns, nodes of the following types:
+// [*File], [DeclAny], [ExprAny], [TypeAny], [Path], [CompactOptions], and
+// [Option]. The bodies of definitions are visited as [DeclAny]s of kind
+// [DeclKindBody].
+type Node interface {
+	source.Spanner
+}
+
+// Cursor describes the node being visited by [Walk], and where it is in the
+// tree.
+//
+// The same Cursor is reused for every node in a walk, so it must not be
+// retained after the callback it was passed to returns.
+type Cursor struct {
+	node    Node
+	set     func(Node) Node
+	parents []Node
+}
+
+// Node returns the node being visited.
+func (c *Cursor) Node() Node {
+	return c.node
+}
+
+// Parent returns the parent of the node being visited, or nil if it is the
+// root of the walk.
+func (c *Cursor) Parent() Node {
+	if len(c.parents) == 0 {
+		return nil
+	}
+	return c.parents[len(c.parents)-1]
+}
+
+// Ancestors returns the ancestors of the node being visited, starting with
+// its parent and ending with the root of the walk.
+func (c *Cursor) Ancestors() iter.Seq[Node] {
+	return func(yield func(Node) bool) {
+		for i := len(c.parents) - 1; i >= 0; i-- {
+			if !yield(c.parents[i]) {
+				return
+			}
+		}
+	}
+}
+
+// Depth returns the number of ancestors of the node being visited.
+func (c *Cursor) Depth() int {
+	return len(c.parents)
+}
+
+// CanReplace returns whether [Cursor.Replace] may be called for the node
+// being visited.
+//
+// The root of a walk cannot be replaced, and neither can paths other than
+// the names of definitions. The path and value of an [Option] cannot be
+// replaced individually; replace the whole option instead.
+func (c *Cursor) CanReplace() bool {
+	return c.set != nil
+}
+
+// Replace replaces the node being visited in its parent.
+//
+// n must be the same kind of node as the one it replaces: a declaration, an
+// expression, a type, a path, compact options, or an option. Concrete node
+// types, such as [DeclDef] or [ExprLiteral], are converted with their AsAny
+// methods. If Replace is called before a node's children are visited, the
+// children of n are visited instead.
+//
+// Panics if n is the wrong kind of node, or if the node cannot be replaced;
+// see [Cursor.CanReplace].
+func (c *Cursor) Replace(n Node) {
+	if c.set == nil {
+		panic(fmt.Sprintf("protocompile/ast: cannot replace %T at this position", c.node))
+	}
+	c.node = c.set(n)
+}
+
+// Walk visits root and its descendants in depth-first order, in the order in
+// which they appear in the source.
+//
+// pre is called before a node's children are visited. If it returns false,
+// the node's children are skipped, and post is not called for it. post is
+// called after a node's children are visited. If it returns false, the walk
+// stops. Either may be nil.
+//
+// The callbacks may rewrite the tree with [Cursor.Replace].
+func Walk(root Node, pre, post func(*Cursor) bool) {
+	w := &walker{pre: pre, post: post}
+	w.walk(normalize(root), nil)
+}
+
+// walker is the state for [Walk].
+type walker struct {
+	pre, post func(*Cursor) bool
+	cursor    Cursor
+}
+
+// walk visits n, returning false if the walk should stop.
+func (w *walker) walk(n Node, set func(Node) Node) bool {
+	c := &w.cursor
+	c.node, c.set = n, set
+	if w.pre != nil && !w.pre(c) {
+		return true
+	}
+	n = c.node
+
+	c.parents = append(c.parents, n)
+	for child, set := range children(n) {
+		if !w.walk(child, set) {
+			return false
+		}
+	}
+	c.parents = c.parents[:len(c.parents)-1]
+
+	c.node, c.set = n, set
+	return w.post == nil || w.post(c)
+}
+
+// children returns the children of a node, and a function for replacing
+// each one, which is nil if it cannot be replaced.
+func children(n Node) iter.Seq2[Node, func(Node) Node] {
+	return func(yield func(Node, func(Node) Node) bool) {
+		switch n := n.(type) {
+		case *File:
+			walkDecls(n.Decls(), yield)
+
+		case DeclAny:
+			declChildren(n, yield)
+
+		case ExprAny:
+			exprChildren(n, yield)
+
+		case TypeAny:
+			switch n.Kind() {
+			case TypeKindPath:
+				_ = yieldPath(n.AsPath().Path, nil, yield)
+			case TypeKindPrefixed:
+				ty := n.AsPrefixed()
+				_ = yieldType(ty.Type(), ty.SetType, yield)
+			case TypeKindGeneric:
+				ty := n.AsGeneric()
+				_ = yieldPath(ty.Path(), nil, yield) &&
+					walkTypes(ty.Args(), yield)
+			}
+
+		case CompactOptions:
+			entries := n.Entries()
+			for i := 0; i < entries.Len(); i++ {
+				if !yield(entries.At(i), func(n Node) Node {
+					opt := asOption(n)
+					entries.SetAt(i, opt)
+					return opt
+				}) {
+					return
+				}
+			}
+
+		case Option:
+			// Options are values, so replacing their children requires
+			// replacing the option in its parent.
+			_ = yieldPath(n.Path, nil, yield) &&
+				yieldExpr(n.Value, nil, yield)
+		}
+	}
+}
+
+func declChildren(decl DeclAny, yield func(Node, func(Node) Node) bool) {
+	switch decl.Kind() {
+	case DeclKindSyntax:
+		d := decl.AsSyntax()
+		_ = yieldExpr(d.Value(), d.SetValue, yield) &&
+			yieldOptions(d.Options(), d.SetOptions, yield)
+
+	case DeclKindPackage:
+		d := decl.AsPackage()
+		_ = yieldPath(d.Path(), nil, yield) &&
+			yieldOptions(d.Options(), d.SetOptions, yield)
+
+	case DeclKindImport:
+		d := decl.AsImport()
+		_ = yieldExpr(d.ImportPath(), d.SetImportPath, yield) &&
+			yieldOptions(d.Options(), d.SetOptions, yield)
+
+	case DeclKindBody:
+		walkDecls(decl.AsBody().Decls(), yield)
+
+	case DeclKindRange:
+		d := decl.AsRange()
+		_ = walkExprs(d.Ranges(), yield) &&
+			yieldOptions(d.Options(), d.SetOptions, yield)
+
+	case DeclKindDef:
+		d := decl.AsDef()
+		switch d.Classify() {
+		case DefKindField, DefKindGroup, DefKindInvalid:
+			// For other definitions, the type is just the keyword; see
+			// [DeclDef.Type].
+			if !yieldType(d.Type(), d.SetType, yield) {
+				return
+			}
+		}
+		if !yieldPath(d.Name(), d.SetName, yield) {
+			return
+		}
+		if sig := d.Signature(); !sig.IsZero() {
+			if !walkTypes(sig.Inputs(), yield) || !walkTypes(sig.Outputs(), yield) {
+				return
+			}
+		}
+		_ = yieldExpr(d.Value(), d.SetValue, yield) &&
+			yieldOptions(d.Options(), d.SetOptions, yield) &&
+			yieldNode(d.Body(), func(n Node) Node {
+				body := asDecl(n).AsBody()
+				if body.IsZero() {
+					panic(fmt.Sprintf("protocompile/ast: cannot replace a body with %T", n))
+				}
+				d.SetBody(body)
+				return body.AsAny()
+			}, yield)
+	}
+}
+
+func exprChildren(expr ExprAny, yield func(Node, func(Node) Node) bool) {
+	switch expr.Kind() {
+	case ExprKindPath:
+		_ = yieldPath(expr.AsPath().Path, nil, yield)
+
+	case ExprKindPrefixed:
+		e := expr.AsPrefixed()
+		_ = yieldExpr(e.Expr(), e.SetExpr, yield)
+
+	case ExprKindRange:
+		e := expr.AsRange()
+		start, end := e.Bounds()
+		_ = yieldExpr(start, func(start ExprAny) {
+			_, end := e.Bounds()
+			e.SetBounds(start, end)
+		}, yield) && yieldExpr(end, func(end ExprAny) {
+			start, _ := e.Bounds()
+			e.SetBounds(start, end)
+		}, yield)
+
+	case ExprKindArray:
+		_ = walkExprs(expr.AsArray().Elements(), yield)
+
+	case ExprKindDict:
+		elems := expr.AsDict().Elements()
+		for i := 0; i < elems.Len(); i++ {
+			if !yieldNode(elems.At(i), func(n Node) Node {
+				field := asExpr(n).AsField()
+				if field.IsZero() {
+					panic(fmt.Sprintf("protocompile/ast: cannot replace a dictionary field with %T", n))
+				}
+				elems.SetAt(i, field)
+				return field.AsAny()
+			}, yield) {
+				return
+			}
+		}
+
+	case ExprKindField:
+		e := expr.AsField()
+		_ = yieldExpr(e.Key(), e.SetKey, yield) &&
+			yieldExpr(e.Value(), e.SetValue, yield)
+	}
+}
+
+// walkDecls yields the elements of a list of declarations.
+func walkDecls(list interface {
+	Len() int
+	At(int) DeclAny
+	SetAt(int, DeclAny)
+}, yield func(Node, func(Node) Node) bool,
+) bool {
+	for i := 0; i < list.Len(); i++ {
+		if !yieldNode(list.At(i), func(n Node) Node {
+			decl := asDecl(n)
+			list.SetAt(i, decl)
+			return decl
+		}, yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// walkExprs yields the elements of a list of expressions.
+func walkExprs(list Commas[ExprAny], yield func(Node, func(Node) Node) bool) bool {
+	for i := 0; i < list.Len(); i++ {
+		if !yieldExpr(list.At(i), func(e ExprAny) { list.SetAt(i, e) }, yield) {
+			return false
+		}
+	}
+	return true
+}
+
+// walkTypes yields the elements of a list of types.
+func walkTypes(list TypeList, yield func(Node, func(Node) Node) bool) bool {
+	for i := 0; i < list.Len(); i++ {
+		if !yieldType(list.At(i), func(ty TypeAny) { list.SetAt(i, ty) }, yield) {
+			return false
+		}
+	}
+	return true
+}
+
+func yieldExpr(expr ExprAny, set func(ExprAny), yield func(Node, func(Node) Node) bool) bool {
+	var replace func(Node) Node
+	if set != nil {
+		replace = func(n Node) Node {
+			expr := asExpr(n)
+			set(expr)
+			return expr
+		}
+	}
+	return yieldNode(expr, replace, yield)
+}
+
+func yieldType(ty TypeAny, set func(TypeAny), yield func(Node, func(Node) Node) bool) bool {
+	return yieldNode(ty, func(n Node) Node {
+		ty := asType(n)
+		set(ty)
+		return ty
+	}, yield)
+}
+
+func yieldPath(path Path, set func(Path), yield func(Node, func(Node) Node) bool) bool {
+	var replace func(Node) Node
+	if set != nil {
+		replace = func(n Node) Node {
+			path, ok := n.(Path)
+			if !ok {
+				panic(fmt.Sprintf("protocompile/ast: cannot replace a path with %T", n))
+			}
+			set(path)
+			return path
+		}
+	}
+	return yieldNode(path, replace, yield)
+}
+
+func yieldOptions(options CompactOptions, set func(CompactOptions), yield func(Node, func(Node) Node) bool) bool {
+	return yieldNode(options, func(n Node) Node {
+		options, ok := n.(CompactOptions)
+		if !ok {
+			panic(fmt.Sprintf("protocompile/ast: cannot replace compact options with %T", n))
+		}
+		set(options)
+		return options
+	}, yield)
+}
+
+// yieldNode yields n, unless it is zero.
+func yieldNode[N interface {
+	Node
+	IsZero() bool
+}](n N, set func(Node) Node, yield func(Node, func(Node) Node) bool) bool {
+	if n.IsZero() {
+		return true
+	}
+	return yield(normalize(n), set)
+}
+
+// normalize converts concrete node types into the type-erased types that
+// [Walk] visits.
+func normalize(n Node) Node {
+	switch n := n.(type) {
+	case interface{ AsAny() DeclAny }:
+		return n.AsAny()
+	case interface{ AsAny() ExprAny }:
+		return n.AsAny()
+	case interface{ AsAny() TypeAny }:
+		return n.AsAny()
+	}
+	return n
+}
+
+func asDecl(n Node) DeclAny {
+	if decl, ok := normalize(n).(DeclAny); ok {
+		return decl
+	}
+	panic(fmt.Sprintf("protocompile/ast: cannot replace a declaration with %T", n))
+}
+
+func asExpr(n Node) ExprAny {
+	if expr, ok := normalize(n).(ExprAny); ok {
+		return expr
+	}
+	panic(fmt.Sprintf("protocompile/ast: cannot replace an expression with %T", n))
+}
+
+func asType(n Node) TypeAny {
+	if ty, ok := normalize(n).(TypeAny); ok {
+		return ty
+	}
+	panic(fmt.Sprintf("protocompile/ast: cannot replace a type with %T", n))
+}
+
+func asOption(n Node) Option {
+	if opt, ok := n.(Option); ok {
+		return opt
+	}
+	panic(fmt.Sprintf("protocompile/ast: cannot replace an option with %T", n))
+}
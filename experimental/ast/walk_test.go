@@ -0,0 +1,223 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ast_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/parser"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/source"
+	"github.com/bufbuild/protocompile/experimental/token"
+)
+
+const walkProto = `syntax = "proto3";
+package a.b;
+message M {
+  map<string, int32> m = 1 [(x) = {a: [1, 2]}];
+  reserved 2 to 3;
+}
+service S {
+  rpc R(stream M) returns (M);
+}
+`
+
+func TestWalk(t *testing.T) {
+	t.Parallel()
+
+	file, _ := parser.Parse("test.proto", source.NewFile("test.proto", walkProto), new(report.Report))
+
+	var got []string
+	ast.Walk(file, func(c *ast.Cursor) bool {
+		got = append(got, strings.Repeat("  ", c.Depth())+describe(c.Node()))
+		return true
+	}, nil)
+
+	assert.Equal(t, []string{
+		"file",
+		`  decl syntax = "proto3";`,
+		`    expr "proto3"`,
+		"  decl package a.b;",
+		"    path a.b",
+		"  decl message M {",
+		"    path M",
+		"    decl {",
+		"      decl map<string, int32> m = 1 [(x) = {a: [1, 2]}];",
+		"        type map<string, int32>",
+		"          path map",
+		"          type string",
+		"            path string",
+		"          type int32",
+		"            path int32",
+		"        path m",
+		"        expr 1",
+		"        options [(x) = {a: [1, 2]}]",
+		"          option (x) = {a: [1, 2]}",
+		"            path (x)",
+		"            expr {a: [1, 2]}",
+		"              expr a: [1, 2]",
+		"                expr a",
+		"                  path a",
+		"                expr [1, 2]",
+		"                  expr 1",
+		"                  expr 2",
+		"      decl reserved 2 to 3;",
+		"        expr 2 to 3",
+		"          expr 2",
+		"          expr 3",
+		"  decl service S {",
+		"    path S",
+		"    decl {",
+		"      decl rpc R(stream M) returns (M);",
+		"        path R",
+		"        type stream M",
+		"          type M",
+		"            path M",
+		"        type M",
+		"          path M",
+	}, got)
+}
+
+func TestWalkSkipAndStop(t *testing.T) {
+	t.Parallel()
+
+	file, _ := parser.Parse("test.proto", source.NewFile("test.proto", walkProto), new(report.Report))
+
+	// Skipping a node's children also skips post for it.
+	var pre, post int
+	ast.Walk(file, func(c *ast.Cursor) bool {
+		pre++
+		_, isDecl := c.Node().(ast.DeclAny)
+		return c.Parent() == nil || !isDecl
+	}, func(*ast.Cursor) bool {
+		post++
+		return true
+	})
+	assert.Equal(t, 5, pre) // The file and its four declarations.
+	assert.Equal(t, 1, post)
+
+	// Stopping the walk from post.
+	var names []string
+	ast.Walk(file, nil, func(c *ast.Cursor) bool {
+		if path, ok := c.Node().(ast.Path); ok {
+			names = append(names, path.Canonicalized())
+			return len(names) < 3
+		}
+		return true
+	})
+	assert.Equal(t, []string{"a.b", "M", "map"}, names)
+}
+
+func TestWalkAncestors(t *testing.T) {
+	t.Parallel()
+
+	file, _ := parser.Parse("test.proto", source.NewFile("test.proto", walkProto), new(report.Report))
+
+	var chain []string
+	ast.Walk(file, func(c *ast.Cursor) bool {
+		if path, ok := c.Node().(ast.Path); ok && path.Canonicalized() == "(x)" {
+			for n := range c.Ancestors() {
+				chain = append(chain, strings.Fields(describe(n))[0])
+			}
+			return false
+		}
+		return true
+	}, nil)
+	assert.Equal(t, []string{"option", "options", "decl", "decl", "decl", "file"}, chain)
+}
+
+func TestRewrite(t *testing.T) {
+	t.Parallel()
+
+	file, _ := parser.Parse("test.proto", source.NewFile("test.proto", walkProto), new(report.Report))
+	stream := file.Stream()
+
+	// Renumber every integer, and swap the bounds of every range.
+	ast.Walk(file, func(c *ast.Cursor) bool {
+		expr, ok := c.Node().(ast.ExprAny)
+		if !ok {
+			return true
+		}
+		if lit := expr.AsLiteral(); !lit.IsZero() {
+			if v, ok := lit.AsNumber().Int(); ok {
+				c.Replace(ast.ExprLiteral{File: file, Token: stream.NewInt(v * 10)})
+			}
+		}
+		if rng := expr.AsRange(); !rng.IsZero() {
+			start, end := rng.Bounds()
+			c.Replace(file.Nodes().NewExprRange(ast.ExprRangeArgs{
+				Start: end,
+				To:    rng.Keyword(),
+				End:   start,
+			}))
+		}
+		return true
+	}, nil)
+
+	// Synthetic tokens have no spans, so check the numbers' text.
+	var got []string
+	ast.Walk(file, func(c *ast.Cursor) bool {
+		if expr, ok := c.Node().(ast.ExprAny); ok && expr.AsLiteral().Kind() == token.Number {
+			got = append(got, expr.AsLiteral().Text())
+		}
+		return true
+	}, nil)
+	assert.Equal(t, []string{"10", "10", "20", "30", "20"}, got)
+
+	assert.Panics(t, func() {
+		ast.Walk(file, func(c *ast.Cursor) bool {
+			if _, ok := c.Node().(ast.ExprAny); ok {
+				c.Replace(ast.TypeAny{})
+			}
+			return true
+		}, nil)
+	})
+	assert.Panics(t, func() {
+		ast.Walk(file, func(c *ast.Cursor) bool {
+			c.Replace(file)
+			return true
+		}, nil)
+	})
+}
+
+// describe summarizes a node visited by [ast.Walk].
+func describe(n ast.Node) string {
+	var kind string
+	switch n.(type) {
+	case *ast.File:
+		return "file"
+	case ast.DeclAny:
+		kind = "decl"
+	case ast.ExprAny:
+		kind = "expr"
+	case ast.TypeAny:
+		kind = "type"
+	case ast.Path:
+		kind = "path"
+	case ast.CompactOptions:
+		kind = "options"
+	case ast.Option:
+		kind = "option"
+	default:
+		panic(fmt.Sprintf("unexpected node %T", n))
+	}
+	text, _, _ := strings.Cut(n.Span().Text(), "\n")
+	return kind + " " + text
+}
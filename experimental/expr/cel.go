@@ -0,0 +1,360 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr
+
+import (
+	"github.com/bufbuild/protocompile/experimental/internal/errtoken"
+	"github.com/bufbuild/protocompile/experimental/internal/lexer"
+	"github.com/bufbuild/protocompile/experimental/internal/taxa"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
+	"github.com/bufbuild/protocompile/experimental/source"
+	"github.com/bufbuild/protocompile/experimental/token"
+	"github.com/bufbuild/protocompile/experimental/token/keyword"
+	"github.com/bufbuild/protocompile/internal/ext/slicesx"
+)
+
+// lexCEL is a lexer for CEL.
+var lexCEL = lexer.Lexer{
+	OnKeyword: func(k keyword.Keyword) lexer.OnKeyword {
+		switch k {
+		case keyword.Comment:
+			return lexer.LineComment
+		case keyword.LComment, keyword.RComment:
+			// CEL does not have block comments.
+			return lexer.DiscardKeyword
+		case keyword.LParen, keyword.LBracket, keyword.LBrace,
+			keyword.RParen, keyword.RBracket, keyword.RBrace:
+			return lexer.BracketKeyword
+		default:
+			if k.IsCEL() {
+				return lexer.SoftKeyword
+			}
+			return lexer.DiscardKeyword
+		}
+	},
+
+	IsAffix: func(affix string, kind token.Kind, suffix bool) bool {
+		switch kind {
+		case token.Number:
+			return suffix && slicesx.Among(affix, "u", "U")
+		case token.String:
+			return !suffix && slicesx.Among(affix,
+				"r", "R", "b", "B",
+				"rb", "rB", "Rb", "RB", "br", "bR", "Br", "BR",
+			)
+		default:
+			return false
+		}
+	},
+
+	NumberCanStartWithDot: true,
+	RequireASCIIIdent:     true,
+	EscapeExtended:        true,
+	EscapeAsk:             true,
+	EscapeOctal:           true,
+	EscapeUppercaseX:      true,
+	EscapeOldStyleUnicode: true,
+}
+
+// ParseCEL parses the CEL expression in span, and returns it.
+//
+// If span is part of a larger file, such as the contents of a string literal
+// in a Protobuf file, only the text in span is parsed, but the spans of the
+// resulting expression point into the larger file. Diagnostics are written
+// to r.
+//
+// CEL is mapped onto this package's expression AST as follows:
+//   - Literals and identifiers are [Token]s.
+//   - Unary, binary and member selection (`.`) operators are [Op]s. A leading
+//     `.` on an identifier is a unary [Op].
+//   - The conditional operator `a ? b : c` is an [Op] with operator `?`,
+//     whose right-hand side is an [Op] with operator `:`.
+//   - Function calls, method calls (whose callee is a member selection),
+//     indexing, and message construction are [Call]s, with `()`, `[]`, and
+//     `{}` brackets respectively.
+//   - List and map literals are [Record]s with `[]` and `{}` brackets; map
+//     entries are [Param]s with a [Param.Name].
+//   - Parenthesized expressions are replaced with the expression they wrap.
+//
+// The returned expression is never zero, but may contain [Error]s if the
+// expression is malformed.
+func ParseCEL(span source.Span, r *report.Report) Expr {
+	stream := lexCEL.LexSpan(span, r)
+	p := &celParser{
+		Nodes:  New(stream).Nodes(),
+		Report: r,
+		eof:    span.File.Span(span.End, span.End),
+	}
+
+	c := stream.Cursor()
+	if c.Done() {
+		p.Error(errtoken.Unexpected{
+			What:  span,
+			Where: taxa.Expr.In(),
+			Want:  taxa.Expr.AsSet(),
+			Got:   taxa.EOF,
+		})
+		return p.NewError(span).AsAny()
+	}
+
+	e := p.expr(c)
+	if tok := c.Next(); !tok.IsZero() && !isUnmatched(tok) {
+		p.Error(errtoken.Unexpected{What: tok, Where: taxa.Expr.After(), Prev: e})
+	}
+	return e
+}
+
+// celParser is the state for [ParseCEL].
+type celParser struct {
+	*Nodes
+	*report.Report
+
+	eof source.Span // The end of the expression, which may not be the end of its file.
+}
+
+// celPrecedence is the binary operators of CEL, from least to greatest
+// precedence.
+var celPrecedence = [][]keyword.Keyword{
+	{keyword.Pipes},
+	{keyword.Amps},
+	{
+		keyword.Eq, keyword.Ne, keyword.Lt, keyword.Le, keyword.Gt, keyword.Ge,
+		keyword.In,
+	},
+	{keyword.Add, keyword.Sub},
+	{keyword.Mul, keyword.Div, keyword.Rem},
+}
+
+// expr parses a full expression, including conditionals.
+//
+// Grammar:
+//
+//	Expr := binary (`?` binary `:` Expr)?
+func (p *celParser) expr(c *token.Cursor) Expr {
+	cond := p.binary(c, 0)
+	ask := c.Peek()
+	if ask.Keyword() != keyword.Ask {
+		return cond
+	}
+	c.Next()
+
+	then := p.binary(c, 0)
+	colon := c.Peek()
+	if colon.Keyword() != keyword.Colon {
+		return p.unexpected(c, taxa.Expr.In(), taxa.Noun(keyword.Colon))
+	}
+	c.Next()
+
+	return p.NewOp(OpArgs{
+		Left: cond,
+		Op:   ask,
+		Right: p.NewOp(OpArgs{
+			Left:  then,
+			Op:    colon,
+			Right: p.expr(c),
+		}).AsAny(),
+	}).AsAny()
+}
+
+// binary parses binary operators with the given precedence or greater.
+func (p *celParser) binary(c *token.Cursor, prec int) Expr {
+	if prec == len(celPrecedence) {
+		return p.unary(c)
+	}
+
+	left := p.binary(c, prec+1)
+	for {
+		op := c.Peek()
+		if !slicesx.Among(op.Keyword(), celPrecedence[prec]...) {
+			return left
+		}
+		c.Next()
+
+		left = p.NewOp(OpArgs{
+			Left:  left,
+			Op:    op,
+			Right: p.binary(c, prec+1),
+		}).AsAny()
+	}
+}
+
+// unary parses prefix operators.
+//
+// Grammar:
+//
+//	unary := (`!` | `-`)* member
+func (p *celParser) unary(c *token.Cursor) Expr {
+	op := c.Peek()
+	switch op.Keyword() {
+	case keyword.Bang, keyword.Sub:
+		c.Next()
+		return p.NewOp(OpArgs{Op: op, Right: p.unary(c)}).AsAny()
+	default:
+		return p.member(c)
+	}
+}
+
+// member parses postfix operators: member selection, calls, indexing and
+// message construction.
+//
+// Grammar:
+//
+//	member := primary (`.` Ident | `(` Params `)` | `[` Expr `]` | `{` Params `}`)*
+func (p *celParser) member(c *token.Cursor) Expr {
+	e := p.primary(c)
+	for {
+		tok := c.Peek()
+		switch tok.Keyword() {
+		case keyword.Dot:
+			c.Next()
+			name := c.Peek()
+			if name.Kind() != token.Ident {
+				return p.unexpected(c, taxa.Noun(keyword.Dot).After(), taxa.Ident)
+			}
+			c.Next()
+
+			e = p.NewOp(OpArgs{
+				Left:  e,
+				Op:    tok,
+				Right: Token{ExprContext: p.Context(), Token: name}.AsAny(),
+			}).AsAny()
+
+		case keyword.Parens, keyword.Brackets, keyword.Braces:
+			c.Next()
+			args := p.params(tok, tok.Keyword() == keyword.Braces)
+			if tok.Keyword() == keyword.Brackets && args.Len() != 1 {
+				p.Errorf("expected exactly one index").Apply(
+					report.Snippet(tok),
+					report.Tag(rtags.SyntaxError),
+				)
+			}
+			e = p.NewCall(CallArgs{Callee: e, Args: args}).AsAny()
+
+		default:
+			return e
+		}
+	}
+}
+
+// primary parses a literal, identifier, or bracketed expression.
+//
+// Grammar:
+//
+//	primary := `.`? Ident | Number | String | `(` Expr `)` | `[` Params `]` | `{` Params `}`
+func (p *celParser) primary(c *token.Cursor) Expr {
+	tok := c.Peek()
+	switch {
+	case tok.IsZero():
+		return p.unexpected(c, taxa.Expr.In(), taxa.Expr)
+
+	case tok.Kind() == token.Ident, tok.Kind() == token.Number, tok.Kind() == token.String:
+		c.Next()
+		return Token{ExprContext: p.Context(), Token: tok}.AsAny()
+
+	case tok.Keyword() == keyword.Dot:
+		// A leading dot, which makes the name that follows fully qualified.
+		c.Next()
+		name := c.Peek()
+		if name.Kind() != token.Ident {
+			return p.unexpected(c, taxa.Noun(keyword.Dot).After(), taxa.Ident)
+		}
+		c.Next()
+		return p.NewOp(OpArgs{Op: tok, Right: Token{ExprContext: p.Context(), Token: name}.AsAny()}).AsAny()
+
+	case tok.Keyword() == keyword.Parens && !tok.IsLeaf():
+		c.Next()
+		inner := tok.Children()
+		if inner.Done() {
+			return p.unexpected(inner, taxa.Expr.In(), taxa.Expr)
+		}
+		e := p.expr(inner)
+		if next := inner.Next(); !next.IsZero() && !isUnmatched(next) {
+			p.Error(errtoken.Unexpected{What: next, Where: taxa.Expr.After(), Prev: e})
+		}
+		return e
+
+	case (tok.Keyword() == keyword.Brackets || tok.Keyword() == keyword.Braces) && !tok.IsLeaf():
+		c.Next()
+		args := p.params(tok, tok.Keyword() == keyword.Braces)
+		return p.NewRecord(RecordArgs{Entries: args}).AsAny()
+
+	default:
+		c.Next()
+		if !isUnmatched(tok) {
+			p.Error(errtoken.Unexpected{
+				What:  tok,
+				Where: taxa.Expr.In(),
+				Want:  taxa.Expr.AsSet(),
+			})
+		}
+		return p.NewError(tok.Span()).AsAny()
+	}
+}
+
+// params parses the comma-separated contents of a bracketed token tree. If
+// named is set, each entry is a key-value pair separated by a colon.
+func (p *celParser) params(brackets token.Token, named bool) Params {
+	params := p.NewParams(ParamsArgs{Brackets: brackets})
+	c := brackets.Children()
+	for !c.Done() {
+		var param Param
+		param.Expr = p.expr(c)
+		if named {
+			colon := c.Peek()
+			if colon.Keyword() != keyword.Colon {
+				p.unexpected(c, taxa.Expr.After(), taxa.Noun(keyword.Colon))
+				break
+			}
+			c.Next()
+			param.Name, param.Colon = param.Expr, colon
+			param.Expr = p.expr(c)
+		}
+
+		comma := c.Peek()
+		if comma.Keyword() != keyword.Comma {
+			params.AppendComma(param, token.Zero)
+			if !c.Done() {
+				p.unexpected(c, taxa.Expr.After(), taxa.Noun(keyword.Comma))
+			}
+			break
+		}
+		c.Next()
+		params.AppendComma(param, comma)
+	}
+	return params
+}
+
+// unexpected diagnoses the next token in c, or the end of the tokens in c,
+// as unexpected, and returns an [Error] for it. Consumes the token, if there
+// is one.
+func (p *celParser) unexpected(c *token.Cursor, where taxa.Place, want ...taxa.Noun) Expr {
+	err := errtoken.UnexpectedEOF(c, where)
+	if tok := c.Next(); !tok.IsZero() {
+		err = errtoken.Unexpected{What: tok, Where: where}
+	} else if err.Got == taxa.EOF {
+		err.What = p.eof
+	}
+	err.Want = taxa.NewSet(want...)
+	p.Error(err)
+	return p.NewError(err.What.Span()).AsAny()
+}
+
+// isUnmatched returns whether tok is a delimiter without a match, which the
+// lexer has already diagnosed.
+func isUnmatched(tok token.Token) bool {
+	_, _, fused := tok.Keyword().Brackets()
+	return fused != keyword.Unknown && tok.IsLeaf()
+}
@@ -0,0 +1,151 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bufbuild/protocompile/experimental/expr"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/source"
+)
+
+func TestParseCEL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		text, want string
+	}{
+		// Precedence and associativity.
+		{"a || b && c", "(|| a (&& b c))"},
+		{"a && b || c", "(|| (&& a b) c)"},
+		{"a + b * c == d", "(== (+ a (* b c)) d)"},
+		{"a - b - c", "(- (- a b) c)"},
+		{"(a + b) * c", "(* (+ a b) c)"},
+		{"x < y && y <= z", "(&& (< x y) (<= y z))"},
+		{"a in [1, 2]", "(in a [1 2])"},
+		{"!a.b", "(! (. a b))"},
+		{"-x * y", "(* (- x) y)"},
+		{"a ? b : c ? d : e", "(? a (: b (? c (: d e))))"},
+		{".pkg.Msg", "(. (. pkg) Msg)"},
+
+		// Calls, indexing, literals, and macros, which parse as calls.
+		{"size(this) > 0", "(> (call( size this) 0)"},
+		{"this.all(x, x > 0)", "(call( (. this all) x (> x 0))"},
+		{"has(this.name)", "(call( has (. this name))"},
+		{"this.items.exists_one(i, i.id == 1)", "(call( (. (. this items) exists_one) i (== (. i id) 1))"},
+		{"a[0]", "(call[ a 0)"},
+		{"Msg{f: 1}", "(call{ Msg f:1)"},
+		{"{'a': 1, 'b': 2}", "{'a':1 'b':2}"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			t.Parallel()
+
+			r := new(report.Report)
+			e := expr.ParseCEL(span(tt.text), r)
+			assert.Empty(t, r.Diagnostics)
+			assert.Equal(t, tt.want, sexpr(e))
+		})
+	}
+}
+
+func TestParseCELErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		text, want, err string
+	}{
+		{"", "<error>", "unexpected end-of-file"},
+		{"a +", "(+ a <error>)", "unexpected end-of-file"},
+		{"a b", "a", "unexpected identifier"},
+		{"f(a,, b)", "(call( f a <error>)", "unexpected `,`"},
+		{"1 +* 2", "(+ 1 <error>)", "unexpected `*`"},
+		{"(a", "a", "unmatched `(`"},
+		{"a.", "<error>", "unexpected end-of-file after `.`"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			t.Parallel()
+
+			r := new(report.Report)
+			e := expr.ParseCEL(span(tt.text), r)
+			assert.False(t, e.IsZero())
+			assert.Equal(t, tt.want, sexpr(e))
+			if assert.NotEmpty(t, r.Diagnostics) {
+				assert.Contains(t, r.Diagnostics[0].Message(), tt.err)
+			}
+		})
+	}
+}
+
+// span returns a span for a CEL expression embedded in a Protobuf string,
+// as it would appear in a buf.validate rule.
+func span(text string) source.Span {
+	const prefix = `option (rule) = "`
+	file := source.NewFile("test.proto", prefix+text+`";`)
+	return file.Span(len(prefix), len(prefix)+len(text))
+}
+
+// sexpr formats an expression as an S-expression.
+func sexpr(e expr.Expr) string {
+	switch e.Kind() {
+	case expr.KindToken:
+		return e.AsToken().Text()
+	case expr.KindError:
+		return "<error>"
+	case expr.KindOp:
+		op := e.AsOp()
+		if op.IsUnary() {
+			return fmt.Sprintf("(%s %s)", op.OperatorToken().Text(), sexpr(op.Right()))
+		}
+		return fmt.Sprintf("(%s %s %s)", op.OperatorToken().Text(), sexpr(op.Left()), sexpr(op.Right()))
+	case expr.KindCall:
+		call := e.AsCall()
+		args := params(call.Args())
+		return fmt.Sprintf("(call%s %s%s)", call.Brackets().Text()[:1], sexpr(call.Callee()), prefix(" ", args))
+	case expr.KindRecord:
+		record := e.AsRecord()
+		open := record.Brackets().Text()[:1]
+		close := map[string]string{"[": "]", "{": "}"}[open]
+		return open + params(record.Entries()) + close
+	default:
+		return e.Kind().String()
+	}
+}
+
+func params(ps expr.Params) string {
+	var out []string
+	for p := range seq.Values(ps) {
+		if p.Name.IsZero() {
+			out = append(out, sexpr(p.Expr))
+		} else {
+			out = append(out, sexpr(p.Name)+":"+sexpr(p.Expr))
+		}
+	}
+	return strings.Join(out, " ")
+}
+
+func prefix(p, s string) string {
+	if s == "" {
+		return ""
+	}
+	return p + s
+}
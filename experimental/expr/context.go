@@ -149,6 +149,15 @@ func (n *Nodes) NewOp(args OpArgs) Op {
 	})))
 }
 
+// NewParams constructs a new, empty [Params] in this context.
+func (n *Nodes) NewParams(args ParamsArgs) Params {
+	n.panicIfNotOurs(args.Brackets)
+
+	return id.Wrap(n.Context(), id.ID[Params](n.Context().params.NewCompressed(rawParams{
+		brackets: args.Brackets.ID(),
+	})))
+}
+
 // NewRecord constructs a new [Record] in this context.
 func (n *Nodes) NewRecord(args RecordArgs) Record {
 	n.panicIfNotOurs(args.Entries)
@@ -160,6 +160,9 @@ func (e Expr) AsToken() Token {
 
 // Span implements [source.Spanner].
 func (e Expr) Span() source.Span {
+	if e.IsZero() {
+		return source.Span{}
+	}
 	return source.Join(
 		e.AsBlock(),
 		e.AsCall(),
@@ -100,11 +100,14 @@ func (e If) Else() If {
 func (e If) SetElse(then If) {
 	if !e.IsZero() {
 		e.Context().Nodes().panicIfNotOurs(then)
-		e.Raw().else_ = e.ID()
+		e.Raw().else_ = then.ID()
 	}
 }
 
 // Span implements [source.Spanner].
 func (e If) Span() source.Span {
+	if e.IsZero() {
+		return source.Span{}
+	}
 	return source.Join(e.ElseToken(), e.IfToken(), e.Condition(), e.Block(), e.Else())
 }
@@ -0,0 +1,38 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package expr_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bufbuild/protocompile/experimental/expr"
+	"github.com/bufbuild/protocompile/experimental/source"
+	"github.com/bufbuild/protocompile/experimental/token"
+)
+
+func TestIfSetElse(t *testing.T) {
+	t.Parallel()
+
+	ctx := expr.New(&token.Stream{File: source.NewFile("test", "")})
+	first := ctx.Nodes().NewIf(expr.IfArgs{})
+	second := ctx.Nodes().NewIf(expr.IfArgs{})
+	assert.True(t, first.Else().IsZero())
+
+	first.SetElse(second)
+	assert.Equal(t, second, first.Else())
+	assert.True(t, second.Else().IsZero())
+}
@@ -19,12 +19,66 @@ import (
 	"strings"
 	"unicode"
 
+	"github.com/bufbuild/protocompile/experimental/ast"
 	"github.com/bufbuild/protocompile/experimental/id"
+	"github.com/bufbuild/protocompile/experimental/source"
 	"github.com/bufbuild/protocompile/experimental/token"
 	"github.com/bufbuild/protocompile/experimental/token/keyword"
 	"github.com/bufbuild/protocompile/internal/ext/slicesx"
 )
 
+// Comments are the comments attached to a declaration, as they appear in its
+// SourceCodeInfo location. Comment markers are removed, but the text is
+// otherwise unmodified.
+type Comments struct {
+	Leading  string
+	Trailing string
+	Detached []string
+}
+
+// CommentMap attributes the comments in a file to its declarations, according
+// to the rules protoc uses to build SourceCodeInfo.
+type CommentMap struct {
+	stream  *token.Stream
+	tracker commentTracker
+}
+
+// NewCommentMap attributes the comments in file.
+func NewCommentMap(file *ast.File) *CommentMap {
+	m := &CommentMap{stream: file.Stream()}
+	m.tracker.attributeComments(m.stream.Cursor())
+	return m
+}
+
+// Comments returns the comments attached to the declaration with the given
+// span, which must be in the file m was built from.
+//
+// Leading and detached comments are attributed to the first token of the
+// span, and trailing comments to the last. If the last token is a closing
+// brace, trailing comments are attributed to the opening brace instead, as
+// protoc does.
+func (m *CommentMap) Comments(s source.Spanner) Comments {
+	var c Comments
+	span := source.GetSpan(s)
+	if m == nil || span.IsZero() {
+		return c
+	}
+
+	_, start := m.stream.Around(span.Start)
+	if leading := m.tracker.attributed[start.ID()]; leading != nil {
+		c.Leading = leading.leadingComment()
+		c.Detached = leading.detachedComments()
+	}
+
+	end, _ := m.stream.Around(span.End)
+	// Check the start of a fused token.
+	end, _ = end.StartEnd()
+	if trailing := m.tracker.attributed[end.ID()]; trailing != nil {
+		c.Trailing = trailing.trailingComment()
+	}
+	return c
+}
+
 // commentTracker is used to track and attribute comments in a token stream. All attributed
 // comments are stored in [commentTracker].attributed for easy look-up by [token.ID].
 type commentTracker struct {
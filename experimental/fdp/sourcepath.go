@@ -34,7 +34,7 @@ type debug struct {
 	proto *descriptorpb.SourceCodeInfo
 	extns *descriptorv1.SourceCodeInfoExtension
 
-	commentTracker *commentTracker
+	commentMap *CommentMap
 
 	suppressed bool
 }
@@ -53,8 +53,7 @@ func (d *debug) init(file *ir.File) {
 		d.proto,
 		descriptorv1.E_BufSourceCodeInfoExtension, d.extns)
 
-	d.commentTracker = new(commentTracker)
-	d.commentTracker.attributeComments(file.AST().Stream().Cursor())
+	d.commentMap = NewCommentMap(file.AST())
 }
 
 // in pushes a new path component for the duration of the function passed to
@@ -109,27 +108,15 @@ func (d *debug) maybeComments(s source.Spanner, comments bool, path ...int32) {
 		return
 	}
 
-	stream := d.file.AST().Stream()
-
-	_, start := stream.Around(span.Start)
-	leading := d.commentTracker.attributed[start.ID()]
-	if leading != nil {
-		if leadingComment := leading.leadingComment(); leadingComment != "" {
-			loc.LeadingComments = addr(leadingComment)
-		}
-		if detachedComments := leading.detachedComments(); len(detachedComments) > 0 {
-			loc.LeadingDetachedComments = detachedComments
-		}
+	c := d.commentMap.Comments(span)
+	if c.Leading != "" {
+		loc.LeadingComments = addr(c.Leading)
 	}
-
-	end, _ := stream.Around(span.End)
-	// Check the start of a fused token.
-	end, _ = end.StartEnd()
-	trailing := d.commentTracker.attributed[end.ID()]
-	if trailing != nil {
-		if trailingComment := trailing.trailingComment(); trailingComment != "" {
-			loc.TrailingComments = addr(trailingComment)
-		}
+	if len(c.Detached) > 0 {
+		loc.LeadingDetachedComments = c.Detached
+	}
+	if c.Trailing != "" {
+		loc.TrailingComments = addr(c.Trailing)
 	}
 }
 
@@ -0,0 +1,32 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package ide implements editor features over lowered Protobuf files, for
+// use by language servers and similar tools.
+//
+// [SemanticTokens] classifies the tokens of a file for syntax highlighting,
+// using the file's IR to tell apart names that the grammar alone cannot,
+// such as a type name that refers to a message versus one that refers to an
+// enum. [Outline] produces the hierarchy of a file's declarations, for
+// displaying a document outline or breadcrumbs. [HoverAt] describes the
+// name at a position: what it refers to, its documentation comments and
+// features, and, for option names, the value being set.
+//
+// Positions are reported in whatever [length.Unit] the caller asks for;
+// language servers will typically want [length.UTF16]. Lines and columns
+// are 1-indexed, as elsewhere in protocompile, so they must be decremented
+// before being sent to an LSP client.
+package ide
+
+//go:generate go run github.com/bufbuild/protocompile/internal/enum kind.yaml
@@ -0,0 +1,346 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ide
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/protocompile/experimental/fdp"
+	"github.com/bufbuild/protocompile/experimental/ir"
+	"github.com/bufbuild/protocompile/experimental/ir/presence"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/source"
+	"github.com/bufbuild/protocompile/experimental/source/length"
+	"github.com/bufbuild/protocompile/experimental/token"
+	"github.com/bufbuild/protocompile/internal/tags"
+)
+
+// Hover is information about a name in a file, for display when the user
+// hovers over it. See [HoverAt].
+type Hover struct {
+	// The fully-qualified name of what the name refers to, and what kind of
+	// thing that is. For a component of an option name, this is the field
+	// that the component names.
+	Name ir.FullName
+	Kind Kind
+
+	// The span of the name that was hovered over, and its range in the units
+	// passed to [HoverAt].
+	Span  source.Span
+	Range Range
+
+	// The definition the name refers to, as written, such as
+	// "repeated string tags = 3", and the span of its name, which may be in
+	// another file. Both are empty for packages and scalar types.
+	Declaration string
+	Definition  source.Span
+
+	// The comments attached to the definition.
+	Comments fdp.Comments
+
+	// The type of a field or extension, including one that is set by an
+	// option.
+	Type string
+	// The presence of a field or extension.
+	Presence presence.Kind
+	// The values of the editions features relevant to the definition,
+	// including inherited ones.
+	Features   []FeatureValue
+	Deprecated bool
+
+	// Whether the name is part of an option name. If so, Value is the value
+	// that the option sets the field to, formatted as in the text format.
+	Option bool
+	Value  string
+}
+
+// FeatureValue is the value of an editions feature.
+type FeatureValue struct {
+	// The name of the feature, such as "field_presence", and its value, such
+	// as "EXPLICIT".
+	Name, Value string
+	// Whether the feature was set on the definition itself, rather than
+	// inherited from an enclosing scope or the edition's defaults.
+	Explicit bool
+}
+
+// HoverAt returns information about the name at the given byte offset in
+// file. Returns false if there is no name there, or if it could not be
+// resolved.
+//
+// Language servers can convert LSP positions into offsets with
+// [source.File.InverseLocation]. The range of the hovered-over name is
+// measured in units.
+func HoverAt(file *ir.File, offset int, units length.Unit) (Hover, bool) {
+	c := classify(file)
+	before, after := file.AST().Stream().Around(offset)
+	for _, tok := range []token.Token{after, before} {
+		class, ok := c.classes[tok.ID()]
+		if tok.IsZero() || !ok || class.target == (target{}) {
+			continue
+		}
+
+		h := newHover(file, class)
+		h.Span = tok.Span()
+		h.Range = NewRange(h.Span, units)
+		return h, true
+	}
+	return Hover{}, false
+}
+
+// newHover builds the hover for a classified name.
+func newHover(file *ir.File, class class) Hover {
+	h := Hover{Kind: class.kind}
+	t := class.target
+	if t.pkg != "" {
+		h.Name = t.pkg
+		return h
+	}
+
+	e := t.entity
+	h.Name = e.fullName()
+	def := e.def()
+	if sym := file.FindSymbol(h.Name); !sym.IsZero() {
+		h.Definition = sym.Definition()
+		h.Deprecated, _ = sym.Deprecated().AsBool()
+	} else {
+		h.Definition = def.Name().Span()
+		h.Deprecated = e.deprecated()
+	}
+	if !def.IsZero() {
+		h.Declaration = text(source.Join(def.Stem(), def.Signature(), def.Equals(), def.Value()))
+		h.Comments = fdp.NewCommentMap(def.Context()).Comments(def)
+	}
+
+	if m := e.member; !m.IsZero() && !m.IsEnumValue() {
+		h.Type = typeName(m.Element())
+		h.Presence = presenceOf(m)
+	}
+	if !t.value.IsZero() {
+		h.Option = true
+		h.Value = formatValue(t.value)
+		return h
+	}
+
+	fs := e.featureSet()
+	for _, name := range features(e) {
+		if f := fs.LookupName(name); !f.IsZero() {
+			h.Features = append(h.Features, FeatureValue{
+				Name:     name,
+				Value:    formatValue(f.Value()),
+				Explicit: f.IsExplicit(),
+			})
+		}
+	}
+	return h
+}
+
+// features returns the names of the standard features that are relevant to e.
+func features(e entity) []string {
+	switch {
+	case !e.member.IsZero() && !e.member.IsEnumValue():
+		names := []string{"field_presence"}
+		elem := e.member.Element()
+		switch {
+		case e.member.IsMap():
+		case e.member.IsRepeated():
+			names = append(names, "repeated_field_encoding")
+		}
+		switch {
+		case elem.IsPredeclared() && elem.Name() == "string":
+			names = append(names, "utf8_validation")
+		case elem.IsMessage() && !elem.IsMapEntry():
+			names = append(names, "message_encoding")
+		}
+		return names
+	case e.ty.IsMessage():
+		return []string{"json_format"}
+	case e.ty.IsEnum():
+		return []string{"enum_type", "json_format"}
+	}
+	return nil
+}
+
+// presenceOf returns the presence of m. In editions, the presence of a
+// singular field is determined by the field_presence feature, rather than by
+// its syntax.
+func presenceOf(m ir.Member) presence.Kind {
+	p := m.Presence()
+	if p != presence.Explicit || !m.Context().Syntax().IsEdition() ||
+		m.IsExtension() || m.Element().IsMessage() {
+		return p
+	}
+
+	switch v, _ := m.FeatureSet().LookupName("field_presence").Value().AsInt(); v {
+	case tags.FeatureSet_FieldPresence_Implicit:
+		return presence.Implicit
+	case tags.FeatureSet_FieldPresence_LegacyRequired:
+		return presence.Required
+	}
+	return p
+}
+
+// typeName returns the name of a field's type, as it would be written.
+func typeName(ty ir.Type) string {
+	if ty.IsMapEntry() && ty.Members().Len() == 2 {
+		return fmt.Sprintf("map<%s, %s>",
+			typeName(ty.Members().At(0).Element()),
+			typeName(ty.Members().At(1).Element()))
+	}
+	return string(ty.FullName())
+}
+
+// formatValue formats an option value in the text format.
+func formatValue(v ir.Value) string {
+	elems := v.Elements()
+	if elems.Len() == 1 {
+		return formatElement(elems.At(0))
+	}
+
+	values := make([]string, 0, elems.Len())
+	for elem := range seq.Values(elems) {
+		values = append(values, formatElement(elem))
+	}
+	return "[" + strings.Join(values, ", ") + "]"
+}
+
+func formatElement(e ir.Element) string {
+	if enum := e.AsEnum(); !enum.IsZero() {
+		return enum.Name()
+	}
+	if b, ok := e.AsBool(); ok {
+		return strconv.FormatBool(b)
+	}
+	if n, ok := e.AsInt(); ok {
+		return strconv.FormatInt(n, 10)
+	}
+	if n, ok := e.AsUInt(); ok {
+		return strconv.FormatUint(n, 10)
+	}
+	if f, ok := e.AsFloat(); ok {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	if s, ok := e.AsString(); ok {
+		return strconv.Quote(s)
+	}
+
+	msg := e.AsMessage()
+	if msg.IsZero() {
+		return ""
+	}
+	var fields []string
+	for v := range msg.Fields() {
+		name := v.Field().Name()
+		if v.Field().IsExtension() {
+			name = "[" + string(v.Field().FullName()) + "]"
+		}
+		fields = append(fields, name+": "+formatValue(v))
+	}
+	if len(fields) == 0 {
+		return "{}"
+	}
+	return "{ " + strings.Join(fields, ", ") + " }"
+}
+
+// Markdown renders h as Markdown, in the manner of hovers in other languages'
+// editor integrations: the declaration in a code block, followed by its
+// comments and other details.
+func (h Hover) Markdown() string {
+	var out strings.Builder
+	if h.Declaration != "" {
+		fmt.Fprintf(&out, "```proto\n%s\n```\n\n", h.Declaration)
+	}
+
+	noun := noun(h.Kind)
+	if h.Option {
+		noun = "option"
+	}
+	fmt.Fprintf(&out, "%s `%s`", noun, h.Name)
+	if h.Option && h.Value != "" {
+		fmt.Fprintf(&out, " = `%s`", h.Value)
+	}
+	if h.Deprecated {
+		out.WriteString(" (deprecated)")
+	}
+	out.WriteString("\n")
+
+	for _, comment := range []string{h.Comments.Leading, h.Comments.Trailing} {
+		if comment = markdownComment(comment); comment != "" {
+			fmt.Fprintf(&out, "\n%s\n", comment)
+		}
+	}
+
+	var details []string
+	if h.Type != "" {
+		details = append(details, fmt.Sprintf("type: `%s`", h.Type))
+	}
+	if h.Presence != presence.Unknown {
+		details = append(details, "presence: "+strings.ToLower(h.Presence.String()))
+	}
+	for _, f := range h.Features {
+		detail := fmt.Sprintf("`%s`: `%s`", f.Name, f.Value)
+		if f.Explicit {
+			detail += " (set here)"
+		}
+		details = append(details, detail)
+	}
+	if len(details) > 0 {
+		out.WriteString("\n")
+		for _, detail := range details {
+			fmt.Fprintf(&out, "- %s\n", detail)
+		}
+	}
+	return out.String()
+}
+
+// noun returns the name of a kind of definition, for use in prose.
+func noun(kind Kind) string {
+	switch kind {
+	case KindPackage:
+		return "package"
+	case KindMessage:
+		return "message"
+	case KindEnum:
+		return "enum"
+	case KindScalar:
+		return "scalar type"
+	case KindService:
+		return "service"
+	case KindField:
+		return "field"
+	case KindEnumValue:
+		return "enum value"
+	case KindMethod:
+		return "method"
+	case KindOneof:
+		return "oneof"
+	case KindExtension:
+		return "extension"
+	default:
+		return "option"
+	}
+}
+
+// markdownComment prepares comment text for inclusion in Markdown, by
+// removing the indentation that would otherwise render as a code block.
+func markdownComment(comment string) string {
+	lines := strings.Split(strings.TrimSpace(comment), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,153 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ide_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bufbuild/protocompile/experimental/ide"
+	"github.com/bufbuild/protocompile/experimental/ir/presence"
+	"github.com/bufbuild/protocompile/experimental/source/length"
+)
+
+func TestHover(t *testing.T) {
+	t.Parallel()
+
+	file := lower(t, testProto)
+	hover := func(t *testing.T, before, name string) ide.Hover {
+		t.Helper()
+		offset := strings.Index(testProto, before+name)
+		require.GreaterOrEqual(t, offset, 0, "%q not in test file", before+name)
+		h, ok := ide.HoverAt(file, offset+len(before)+len(name)/2, length.UTF16)
+		require.True(t, ok)
+		assert.Equal(t, name, h.Span.Text())
+		return h
+	}
+
+	t.Run("message", func(t *testing.T) {
+		t.Parallel()
+		h := hover(t, "message ", "User")
+		assert.Equal(t, "acme.v1.User", string(h.Name))
+		assert.Equal(t, ide.KindMessage, h.Kind)
+		assert.Equal(t, "message User", h.Declaration)
+		assert.Equal(t, " A user.\nOf the service. ", h.Comments.Leading)
+		assert.Equal(t, 18, h.Range.Start.Line)
+		assert.Equal(t, []ide.FeatureValue{{Name: "json_format", Value: "ALLOW"}}, h.Features)
+	})
+
+	t.Run("field", func(t *testing.T) {
+		t.Parallel()
+		h := hover(t, "int32 ", "min_len")
+		assert.Equal(t, "acme.v1.Rules.min_len", string(h.Name))
+		assert.Equal(t, "int32 min_len = 1", h.Declaration)
+		assert.Equal(t, " The minimum length.", h.Comments.Trailing)
+		assert.Equal(t, "int32", h.Type)
+		assert.Equal(t, presence.Explicit, h.Presence)
+		assert.False(t, h.Option)
+	})
+
+	t.Run("features", func(t *testing.T) {
+		t.Parallel()
+		h := hover(t, "string ", "name")
+		assert.Equal(t, presence.Implicit, h.Presence)
+		assert.Equal(t, []ide.FeatureValue{
+			{Name: "field_presence", Value: "IMPLICIT", Explicit: true},
+			{Name: "utf8_validation", Value: "VERIFY"},
+		}, h.Features)
+	})
+
+	t.Run("deprecated", func(t *testing.T) {
+		t.Parallel()
+		h := hover(t, "Timestamp ", "created")
+		assert.True(t, h.Deprecated)
+		assert.Equal(t, "google.protobuf.Timestamp", h.Type)
+		assert.Equal(t, "```proto\n"+
+			"google.protobuf.Timestamp created = 3\n"+
+			"```\n"+
+			"\n"+
+			"field `acme.v1.User.created` (deprecated)\n"+
+			"\n"+
+			"- type: `google.protobuf.Timestamp`\n"+
+			"- presence: explicit\n"+
+			"- `field_presence`: `EXPLICIT`\n"+
+			"- `message_encoding`: `LENGTH_PREFIXED`\n",
+			h.Markdown())
+	})
+
+	t.Run("reference", func(t *testing.T) {
+		t.Parallel()
+		h := hover(t, "protobuf.", "Timestamp")
+		assert.Equal(t, "google.protobuf.Timestamp", string(h.Name))
+		assert.True(t, strings.HasSuffix(h.Definition.Path(), "google/protobuf/timestamp.proto"))
+		assert.Equal(t, "Timestamp", h.Definition.Text())
+		assert.Contains(t, h.Comments.Leading, "A Timestamp represents a point in time")
+	})
+
+	t.Run("package", func(t *testing.T) {
+		t.Parallel()
+		h := hover(t, "package acme.", "v1")
+		assert.Equal(t, "acme.v1", string(h.Name))
+		assert.Equal(t, ide.KindPackage, h.Kind)
+		assert.Equal(t, "package `acme.v1`\n", h.Markdown())
+	})
+
+	t.Run("option", func(t *testing.T) {
+		t.Parallel()
+		h := hover(t, "(rules).", "min_len")
+		assert.True(t, h.Option)
+		assert.Equal(t, "acme.v1.Rules.min_len", string(h.Name))
+		assert.Equal(t, "int32", h.Type)
+		assert.Equal(t, "1", h.Value)
+	})
+
+	t.Run("extension option", func(t *testing.T) {
+		t.Parallel()
+		h := hover(t, "(", "rules")
+		assert.True(t, h.Option)
+		assert.Equal(t, ide.KindExtension, h.Kind)
+		assert.Equal(t, "acme.v1.rules", string(h.Name))
+		assert.Equal(t, "acme.v1.Rules", h.Type)
+		assert.Equal(t, "{ min_len: 1 }", h.Value)
+		assert.Equal(t, "```proto\n"+
+			"Rules rules = 50000\n"+
+			"```\n"+
+			"\n"+
+			"option `acme.v1.rules` = `{ min_len: 1 }`\n"+
+			"\n"+
+			"- type: `acme.v1.Rules`\n"+
+			"- presence: explicit\n",
+			h.Markdown())
+	})
+
+	t.Run("descriptor option", func(t *testing.T) {
+		t.Parallel()
+		h := hover(t, "option ", "deprecated")
+		assert.True(t, h.Option)
+		assert.Equal(t, "google.protobuf.EnumOptions.deprecated", string(h.Name))
+		assert.Equal(t, "bool", h.Type)
+		assert.Equal(t, "true", h.Value)
+		assert.Contains(t, h.Comments.Leading, "Is this enum deprecated?")
+	})
+
+	t.Run("nothing", func(t *testing.T) {
+		t.Parallel()
+		_, ok := ide.HoverAt(file, strings.Index(testProto, "\n\npackage"), length.UTF16)
+		assert.False(t, ok)
+	})
+}
@@ -0,0 +1,246 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ide_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bufbuild/protocompile/experimental/ide"
+	"github.com/bufbuild/protocompile/experimental/incremental"
+	"github.com/bufbuild/protocompile/experimental/incremental/queries"
+	"github.com/bufbuild/protocompile/experimental/ir"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/source"
+	"github.com/bufbuild/protocompile/experimental/source/length"
+)
+
+const testProto = `edition = "2023";
+
+package acme.v1;
+
+import "google/protobuf/descriptor.proto";
+import "google/protobuf/timestamp.proto";
+
+extend google.protobuf.FieldOptions {
+  Rules rules = 50000;
+}
+
+message Rules {
+  int32 min_len = 1; // The minimum length.
+}
+
+/* A user.
+   Of the service. */
+message User {
+  string id = 1 [(rules).min_len = 1];
+  string name = 2 [features.field_presence = IMPLICIT];
+  google.protobuf.Timestamp created = 3 [deprecated = true];
+  map<string, Role> roles = 4;
+  message Address {
+    string city = 1;
+  }
+  User.Address address = 5;
+  oneof contact {
+    string email = 6;
+  }
+  reserved 10 to max;
+  reserved legacy;
+}
+
+enum Role {
+  option deprecated = true;
+  ROLE_UNSPECIFIED = 0;
+  ROLE_ADMIN = 1 [deprecated = true];
+}
+
+service Users {
+  rpc Get(User) returns (stream User);
+}
+`
+
+func TestSemanticTokens(t *testing.T) {
+	t.Parallel()
+
+	file := lower(t, testProto)
+	tokens := ide.SemanticTokens(file, length.UTF16)
+
+	// Render each line of the file as its classified tokens.
+	var got []string
+	for _, tok := range tokens {
+		entry := fmt.Sprintf("%d:%d %s %v", tok.Start.Line, tok.Start.Column, tok.Span.Text(), tok.Kind)
+		if tok.Modifiers.Has(ide.Declaration) {
+			entry += " decl"
+		}
+		if tok.Modifiers.Has(ide.Deprecated) {
+			entry += " deprecated"
+		}
+		assert.Equal(t, len(tok.Span.Text()), tok.Length, "%s", entry)
+		got = append(got, entry)
+	}
+
+	for _, want := range []string{
+		`1:1 edition KindKeyword`,
+		`1:11 "2023" KindString`,
+		`3:9 acme KindPackage`,
+		`3:14 v1 KindPackage`,
+		`8:8 google KindPackage`,
+		`8:15 protobuf KindPackage`,
+		`8:24 FieldOptions KindMessage`,
+		`9:3 Rules KindMessage`,
+		`9:9 rules KindExtension decl`,
+		`9:17 50000 KindNumber`,
+		`16:1 /* A user. KindComment`,
+		`17:1    Of the service. */ KindComment`,
+		`18:9 User KindMessage decl`,
+		`19:3 string KindScalar`,
+		`19:19 rules KindExtension`,
+		`19:26 min_len KindOption`,
+		`20:20 features KindOption`,
+		`20:29 field_presence KindOption`,
+		`20:46 IMPLICIT KindEnumValue`,
+		`21:19 Timestamp KindMessage`,
+		`21:29 created KindField decl deprecated`,
+		`21:42 deprecated KindOption`,
+		`21:55 true KindKeyword`,
+		`22:3 map KindKeyword`,
+		`22:7 string KindScalar`,
+		`22:15 Role KindEnum deprecated`,
+		`26:3 User KindMessage`,
+		`26:8 Address KindMessage`,
+		`27:9 contact KindOneof decl`,
+		`30:15 to KindKeyword`,
+		`30:18 max KindKeyword`,
+		`31:12 legacy KindField`,
+		`34:6 Role KindEnum decl deprecated`,
+		`36:3 ROLE_UNSPECIFIED KindEnumValue decl`,
+		`37:3 ROLE_ADMIN KindEnumValue decl deprecated`,
+		`41:7 Get KindMethod decl`,
+		`41:11 User KindMessage`,
+		`41:17 returns KindKeyword`,
+		`41:26 stream KindKeyword`,
+		`41:33 User KindMessage`,
+	} {
+		assert.Contains(t, got, want)
+	}
+	for _, entry := range got {
+		assert.NotContains(t, entry, "{", "punctuation should not be classified")
+	}
+}
+
+func TestSemanticTokensUnits(t *testing.T) {
+	t.Parallel()
+
+	file := lower(t, "syntax = \"proto3\";\nmessage M { string a = 1 [json_name = \"猫猫\"]; int32 x = 2; }\n")
+	find := func(units length.Unit, text string) ide.SemanticToken {
+		for _, tok := range ide.SemanticTokens(file, units) {
+			if tok.Span.Text() == text {
+				return tok
+			}
+		}
+		return ide.SemanticToken{}
+	}
+
+	for _, tt := range []struct {
+		units          length.Unit
+		column, length int
+	}{
+		{length.Bytes, 39, 8},
+		{length.UTF16, 39, 4},
+		{length.TermWidth, 39, 6},
+	} {
+		s := find(tt.units, `"猫猫"`)
+		assert.Equal(t, tt.column, s.Start.Column, "%v", tt.units)
+		assert.Equal(t, tt.length, s.Length, "%v", tt.units)
+		x := find(tt.units, "x")
+		assert.Equal(t, 2, x.Start.Line)
+		assert.Equal(t, tt.column+tt.length+9, x.Start.Column, "%v", tt.units)
+	}
+}
+
+func TestOutline(t *testing.T) {
+	t.Parallel()
+
+	file := lower(t, testProto)
+	var got []string
+	var render func(items []ide.OutlineItem, depth int)
+	render = func(items []ide.OutlineItem, depth int) {
+		for _, item := range items {
+			entry := fmt.Sprintf("%s%v %s", strings.Repeat("  ", depth), item.Kind, item.Name)
+			if item.Detail != "" {
+				entry += " (" + item.Detail + ")"
+			}
+			if item.Deprecated {
+				entry += " deprecated"
+			}
+			entry += fmt.Sprintf(" %d:%d-%d:%d @%d:%d",
+				item.Range.Start.Line, item.Range.Start.Column,
+				item.Range.End.Line, item.Range.End.Column,
+				item.Selection.Start.Line, item.Selection.Start.Column)
+			got = append(got, entry)
+			render(item.Children, depth+1)
+		}
+	}
+	render(ide.Outline(file, length.UTF16), 0)
+
+	assert.Equal(t, []string{
+		"KindPackage acme.v1 3:1-3:17 @3:9",
+		"KindExtend google.protobuf.FieldOptions 8:1-10:2 @8:8",
+		"  KindExtension rules (Rules) 9:3-9:23 @9:9",
+		"KindMessage Rules 12:1-14:2 @12:9",
+		"  KindField min_len (int32) 13:3-13:21 @13:9",
+		"KindMessage User 18:1-32:2 @18:9",
+		"  KindField id (string) 19:3-19:39 @19:10",
+		"  KindField name (string) 20:3-20:56 @20:10",
+		"  KindField created (google.protobuf.Timestamp) deprecated 21:3-21:61 @21:29",
+		"  KindField roles (map<string, Role>) 22:3-22:31 @22:21",
+		"  KindMessage Address 23:3-25:4 @23:11",
+		"    KindField city (string) 24:5-24:21 @24:12",
+		"  KindField address (User.Address) 26:3-26:28 @26:16",
+		"  KindOneof contact 27:3-29:4 @27:9",
+		"    KindField email (string) 28:5-28:22 @28:12",
+		"KindEnum Role deprecated 34:1-38:2 @34:6",
+		"  KindEnumValue ROLE_UNSPECIFIED (0) 36:3-36:24 @36:3",
+		"  KindEnumValue ROLE_ADMIN (1) deprecated 37:3-37:38 @37:3",
+		"KindService Users 40:1-42:2 @40:9",
+		"  KindMethod Get ((User) returns (stream User)) 41:3-41:39 @41:7",
+	}, got)
+}
+
+func lower(t *testing.T, text string) *ir.File {
+	t.Helper()
+	opener := &source.Openers{
+		source.NewMap(map[string]*source.File{
+			"test.proto": source.NewFile("test.proto", text),
+		}),
+		source.WKTs(),
+	}
+	results, r, err := incremental.Run(context.Background(), incremental.New(), queries.IR{
+		Opener:  opener,
+		Session: new(ir.Session),
+		Path:    "test.proto",
+	})
+	require.NoError(t, err)
+	require.NoError(t, results[0].Fatal)
+	for _, d := range r.Diagnostics {
+		require.Greater(t, d.Level(), report.Error, "%v", d)
+	}
+	return results[0].Value
+}
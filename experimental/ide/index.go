@@ -0,0 +1,206 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ide
+
+import (
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/ir"
+	"github.com/bufbuild/protocompile/experimental/seq"
+)
+
+// entity is what a definition was lowered to. At most one field is set.
+type entity struct {
+	member  ir.Member
+	ty      ir.Type
+	oneof   ir.Oneof
+	service ir.Service
+	method  ir.Method
+	extend  ir.Extend
+}
+
+// index maps the definitions in a lowered file to what they were lowered to.
+func index(file *ir.File) map[ast.DeclAny]entity {
+	entities := make(map[ast.DeclAny]entity)
+	add := func(def ast.DeclDef, e entity) {
+		// Groups lower to both a field and a type, and synthetic oneofs
+		// point at their field; the first one added wins.
+		if _, ok := entities[def.AsAny()]; !ok && !def.IsZero() {
+			entities[def.AsAny()] = e
+		}
+	}
+
+	for member := range file.AllMembers() {
+		if !member.Parent().IsMapEntry() {
+			add(member.AST(), entity{member: member})
+		}
+	}
+	for ty := range seq.Values(file.AllTypes()) {
+		if ty.IsMapEntry() {
+			continue
+		}
+		add(ty.AST(), entity{ty: ty})
+		for oneof := range seq.Values(ty.Oneofs()) {
+			add(oneof.AST(), entity{oneof: oneof})
+		}
+	}
+	for extend := range seq.Values(file.AllExtends()) {
+		add(extend.AST(), entity{extend: extend})
+	}
+	for service := range seq.Values(file.Services()) {
+		add(service.AST(), entity{service: service})
+		for method := range seq.Values(service.Methods()) {
+			add(method.AST(), entity{method: method})
+		}
+	}
+	return entities
+}
+
+// kind returns the kind of name def declares, preferring what it was lowered
+// to over its syntax.
+func (e entity) kind(def ast.DeclDef) Kind {
+	switch {
+	case !e.member.IsZero() && def.Classify() != ast.DefKindGroup:
+		switch {
+		case e.member.IsEnumValue():
+			return KindEnumValue
+		case e.member.IsExtension():
+			return KindExtension
+		default:
+			return KindField
+		}
+	case !e.ty.IsZero():
+		return typeKind(e.ty)
+	}
+
+	switch def.Classify() {
+	case ast.DefKindMessage, ast.DefKindGroup:
+		// A group's name is spelled like, and is the name of, its type.
+		return KindMessage
+	case ast.DefKindEnum:
+		return KindEnum
+	case ast.DefKindService:
+		return KindService
+	case ast.DefKindExtend:
+		return KindExtend
+	case ast.DefKindField:
+		return KindField
+	case ast.DefKindOneof:
+		return KindOneof
+	case ast.DefKindEnumValue:
+		return KindEnumValue
+	case ast.DefKindMethod:
+		return KindMethod
+	}
+	return KindInvalid
+}
+
+// deprecated returns whether e is marked as deprecated.
+func (e entity) deprecated() bool {
+	var v ir.Value
+	switch {
+	case !e.member.IsZero():
+		v = e.member.Deprecated()
+	case !e.ty.IsZero():
+		v = e.ty.Deprecated()
+	case !e.service.IsZero():
+		v = e.service.Deprecated()
+	case !e.method.IsZero():
+		v = e.method.Deprecated()
+	}
+	deprecated, _ := v.AsBool()
+	return deprecated
+}
+
+// typeKind returns the kind of name that refers to ty.
+func typeKind(ty ir.Type) Kind {
+	switch {
+	case ty.IsPredeclared():
+		return KindScalar
+	case ty.IsEnum():
+		return KindEnum
+	default:
+		return KindMessage
+	}
+}
+
+// options returns the options set on e.
+func (e entity) options() ir.MessageValue {
+	switch {
+	case !e.member.IsZero():
+		return e.member.Options()
+	case !e.ty.IsZero():
+		return e.ty.Options()
+	case !e.oneof.IsZero():
+		return e.oneof.Options()
+	case !e.service.IsZero():
+		return e.service.Options()
+	case !e.method.IsZero():
+		return e.method.Options()
+	}
+	return ir.MessageValue{}
+}
+
+// def returns the definition e was lowered from.
+func (e entity) def() ast.DeclDef {
+	switch {
+	case !e.member.IsZero():
+		return e.member.AST()
+	case !e.ty.IsZero():
+		return e.ty.AST()
+	case !e.oneof.IsZero():
+		return e.oneof.AST()
+	case !e.service.IsZero():
+		return e.service.AST()
+	case !e.method.IsZero():
+		return e.method.AST()
+	case !e.extend.IsZero():
+		return e.extend.AST()
+	}
+	return ast.DeclDef{}
+}
+
+// fullName returns the fully-qualified name of e.
+func (e entity) fullName() ir.FullName {
+	switch {
+	case !e.member.IsZero():
+		return e.member.FullName()
+	case !e.ty.IsZero():
+		return e.ty.FullName()
+	case !e.oneof.IsZero():
+		return e.oneof.FullName()
+	case !e.service.IsZero():
+		return e.service.FullName()
+	case !e.method.IsZero():
+		return e.method.FullName()
+	}
+	return ""
+}
+
+// featureSet returns the editions features of e.
+func (e entity) featureSet() ir.FeatureSet {
+	switch {
+	case !e.member.IsZero():
+		return e.member.FeatureSet()
+	case !e.ty.IsZero():
+		return e.ty.FeatureSet()
+	case !e.oneof.IsZero():
+		return e.oneof.FeatureSet()
+	case !e.service.IsZero():
+		return e.service.FeatureSet()
+	case !e.method.IsZero():
+		return e.method.FeatureSet()
+	}
+	return ir.FeatureSet{}
+}
@@ -0,0 +1,89 @@
+// Code generated by github.com/bufbuild/protocompile/internal/enum kind.yaml. DO NOT EDIT.
+
+package ide
+
+import (
+	"fmt"
+	"iter"
+)
+
+// Kind classifies a token for syntax highlighting, or an entry in a file's
+// outline.
+type Kind int8
+
+const (
+	KindInvalid   Kind = iota
+	KindKeyword        // A keyword, such as message or optional.
+	KindPackage        // A package name, or a component of one.
+	KindMessage        // The name of a message type.
+	KindEnum           // The name of an enum type.
+	KindScalar         // The name of a scalar type, such as int32.
+	KindService        // The name of a service.
+	KindField          // The name of a field.
+	KindEnumValue      // The name of an enum value.
+	KindMethod         // The name of a service method.
+	KindOneof          // The name of a oneof.
+	KindExtend         // An extend block. Only appears in outlines.
+	KindExtension      // The name of an extension, including in an option name.
+	KindOption         // A component of an option name that is not an extension.
+	KindNumber         // A number literal.
+	KindString         // A string literal.
+	KindComment        // A comment.
+)
+
+// String implements [fmt.Stringer].
+func (v Kind) String() string {
+	if int(v) < 0 || int(v) > len(_table_Kind_String) {
+		return fmt.Sprintf("Kind(%v)", int(v))
+	}
+	return _table_Kind_String[v]
+}
+
+// GoString implements [fmt.GoStringer].
+func (v Kind) GoString() string {
+	if int(v) < 0 || int(v) > len(_table_Kind_GoString) {
+		return fmt.Sprintf("ide.Kind(%v)", int(v))
+	}
+	return _table_Kind_GoString[v]
+}
+
+var _table_Kind_String = [...]string{
+	KindInvalid:   "KindInvalid",
+	KindKeyword:   "KindKeyword",
+	KindPackage:   "KindPackage",
+	KindMessage:   "KindMessage",
+	KindEnum:      "KindEnum",
+	KindScalar:    "KindScalar",
+	KindService:   "KindService",
+	KindField:     "KindField",
+	KindEnumValue: "KindEnumValue",
+	KindMethod:    "KindMethod",
+	KindOneof:     "KindOneof",
+	KindExtend:    "KindExtend",
+	KindExtension: "KindExtension",
+	KindOption:    "KindOption",
+	KindNumber:    "KindNumber",
+	KindString:    "KindString",
+	KindComment:   "KindComment",
+}
+
+var _table_Kind_GoString = [...]string{
+	KindInvalid:   "ide.KindInvalid",
+	KindKeyword:   "ide.KindKeyword",
+	KindPackage:   "ide.KindPackage",
+	KindMessage:   "ide.KindMessage",
+	KindEnum:      "ide.KindEnum",
+	KindScalar:    "ide.KindScalar",
+	KindService:   "ide.KindService",
+	KindField:     "ide.KindField",
+	KindEnumValue: "ide.KindEnumValue",
+	KindMethod:    "ide.KindMethod",
+	KindOneof:     "ide.KindOneof",
+	KindExtend:    "ide.KindExtend",
+	KindExtension: "ide.KindExtension",
+	KindOption:    "ide.KindOption",
+	KindNumber:    "ide.KindNumber",
+	KindString:    "ide.KindString",
+	KindComment:   "ide.KindComment",
+}
+var _ iter.Seq[int] // Mark iter as used.
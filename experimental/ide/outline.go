@@ -0,0 +1,145 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ide
+
+import (
+	"strings"
+
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/ir"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/source"
+	"github.com/bufbuild/protocompile/experimental/source/length"
+)
+
+// Range is a range of a file, measured in some [length.Unit].
+type Range struct {
+	Start, End source.Location
+}
+
+// NewRange returns the range of span, measured in units.
+func NewRange(span source.Span, units length.Unit) Range {
+	if span.IsZero() {
+		return Range{}
+	}
+	return Range{
+		Start: span.File.Location(span.Start, units),
+		End:   span.File.Location(span.End, units),
+	}
+}
+
+// OutlineItem is an entry in the outline of a file. See [Outline].
+type OutlineItem struct {
+	// The name of the declaration, as written. For extend blocks, this is
+	// the name of the extendee.
+	Name string
+	// Additional information to display alongside the name, such as the
+	// type of a field or the number of an enum value. May be empty.
+	Detail string
+
+	Kind       Kind
+	Deprecated bool
+
+	// The range of the whole declaration, including its body, and the range
+	// of just its name.
+	Range, Selection Range
+
+	// The declarations nested in this one.
+	Children []OutlineItem
+}
+
+// Outline returns the hierarchy of declarations in file: its package,
+// messages, enums, services, and extend blocks, and the members of each.
+//
+// Options, imports, and reserved ranges are not included. Positions are
+// measured in units.
+func Outline(file *ir.File, units length.Unit) []OutlineItem {
+	o := &outliner{entities: index(file), units: units}
+	return o.decls(file.AST().Decls())
+}
+
+// outliner is the state for [Outline].
+type outliner struct {
+	entities map[ast.DeclAny]entity
+	units    length.Unit
+}
+
+func (o *outliner) decls(decls seq.Indexer[ast.DeclAny]) []OutlineItem {
+	var items []OutlineItem
+	for decl := range seq.Values(decls) {
+		switch decl.Kind() {
+		case ast.DeclKindPackage:
+			path := decl.AsPackage().Path()
+			if path.IsZero() {
+				continue
+			}
+			items = append(items, OutlineItem{
+				Name:      path.Canonicalized(),
+				Kind:      KindPackage,
+				Range:     NewRange(decl.Span(), o.units),
+				Selection: NewRange(path.Span(), o.units),
+			})
+
+		case ast.DeclKindBody:
+			// Stray braces; their contents belong to the enclosing scope.
+			items = append(items, o.decls(decl.AsBody().Decls())...)
+
+		case ast.DeclKindDef:
+			if item, ok := o.def(decl.AsDef()); ok {
+				items = append(items, item)
+			}
+		}
+	}
+	return items
+}
+
+func (o *outliner) def(def ast.DeclDef) (OutlineItem, bool) {
+	e := o.entities[def.AsAny()]
+	item := OutlineItem{
+		Name:       def.Name().Canonicalized(),
+		Kind:       e.kind(def),
+		Deprecated: e.deprecated(),
+		Range:      NewRange(def.Span(), o.units),
+		Selection:  NewRange(def.Name().Span(), o.units),
+	}
+	if item.Kind == KindInvalid || def.Name().IsZero() {
+		return OutlineItem{}, false
+	}
+
+	switch def.Classify() {
+	case ast.DefKindExtend:
+		item.Kind = KindExtend
+	case ast.DefKindField, ast.DefKindGroup:
+		item.Detail = text(def.Type())
+	case ast.DefKindEnumValue:
+		item.Detail = text(def.Value())
+	case ast.DefKindMethod:
+		item.Detail = text(def.Signature())
+	}
+
+	if body := def.Body(); !body.IsZero() {
+		item.Children = o.decls(body.Decls())
+	}
+	return item, true
+}
+
+// text returns the text of a node, with runs of whitespace collapsed.
+func text(node source.Spanner) string {
+	span := source.GetSpan(node)
+	if span.IsZero() {
+		return ""
+	}
+	return strings.Join(strings.Fields(span.Text()), " ")
+}
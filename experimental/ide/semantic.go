@@ -0,0 +1,405 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ide
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/ir"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/source"
+	"github.com/bufbuild/protocompile/experimental/source/length"
+	"github.com/bufbuild/protocompile/experimental/token"
+	"github.com/bufbuild/protocompile/experimental/token/keyword"
+)
+
+// Modifiers is a set of additional properties of a [SemanticToken].
+type Modifiers uint8
+
+const (
+	// The token is the name in a declaration, rather than a reference to it.
+	Declaration Modifiers = 1 << iota
+	// The token names, or refers to, something that is deprecated.
+	Deprecated
+)
+
+// Has returns whether m contains all of the modifiers in want.
+func (m Modifiers) Has(want Modifiers) bool {
+	return m&want == want
+}
+
+// SemanticToken is a classified token in a file.
+type SemanticToken struct {
+	// The text of the token.
+	Span source.Span
+
+	// Where the token starts, and its length, in the units passed to
+	// [SemanticTokens]. A token never spans more than one line.
+	Start  source.Location
+	Length int
+
+	Kind      Kind
+	Modifiers Modifiers
+}
+
+// SemanticTokens classifies the tokens of file for syntax highlighting.
+//
+// The returned tokens are in the order they appear in the file. Punctuation
+// and tokens that cannot be classified, such as unresolved names, are not
+// included. Tokens that span multiple lines, such as block comments, are
+// split into one token per line, since many editors cannot display
+// multi-line tokens. Positions are measured in units.
+func SemanticTokens(file *ir.File, units length.Unit) []SemanticToken {
+	c := classify(file)
+	var tokens []SemanticToken
+	for tok := range file.AST().Stream().All() {
+		if tok.IsSynthetic() {
+			// Synthetic tokens come after all of the natural ones.
+			break
+		}
+		class, ok := c.classes[tok.ID()]
+		if !ok {
+			class, ok = classifyLexical(tok)
+		}
+		if !ok {
+			continue
+		}
+		tokens = appendLines(tokens, tok.LeafSpan(), class, units)
+	}
+	return tokens
+}
+
+// class is the classification of a single token.
+type class struct {
+	kind Kind
+	mods Modifiers
+
+	// What the token refers to, if it could be resolved.
+	target target
+}
+
+// target is what a name refers to.
+type target struct {
+	// The definition named, if any.
+	entity entity
+	// For names of packages, the package.
+	pkg ir.FullName
+	// For option names, the value that the option sets.
+	value ir.Value
+}
+
+// classifier classifies tokens whose meaning depends on where they appear,
+// and resolves the names among them.
+type classifier struct {
+	file     *ir.File
+	entities map[ast.DeclAny]entity
+	classes  map[token.ID]class
+}
+
+// classify classifies the tokens of file.
+func classify(file *ir.File) *classifier {
+	c := &classifier{
+		file:     file,
+		entities: index(file),
+		classes:  make(map[token.ID]class),
+	}
+	ast.Walk(file.AST(), c.pre, nil)
+	return c
+}
+
+// set classifies tok, unless it has already been classified. Because the
+// AST is walked in pre-order, classifications made by a parent take
+// precedence over those made by its children.
+func (c *classifier) set(tok token.Token, class class) {
+	if tok.IsZero() {
+		return
+	}
+	if _, ok := c.classes[tok.ID()]; !ok {
+		c.classes[tok.ID()] = class
+	}
+}
+
+func (c *classifier) pre(cursor *ast.Cursor) bool {
+	switch node := cursor.Node().(type) {
+	case ast.DeclAny:
+		switch node.Kind() {
+		case ast.DeclKindPackage:
+			c.packageName(node.AsPackage().Path())
+		case ast.DeclKindDef:
+			def := node.AsDef()
+			if def.Classify() == ast.DefKindOption {
+				c.optionName(def.Name(), c.options(cursor))
+				break
+			}
+			c.def(def)
+		}
+
+	case ast.Option:
+		c.optionName(node.Path, c.options(cursor))
+
+	case ast.ExprAny:
+		c.expr(node, cursor.Parent())
+	}
+	return true
+}
+
+// def classifies the names in a definition.
+func (c *classifier) def(def ast.DeclDef) {
+	e := c.entities[def.AsAny()]
+	switch def.Classify() {
+	case ast.DefKindExtend:
+		c.typeName(def.Name(), e.extend.Extendee())
+		return
+
+	case ast.DefKindField, ast.DefKindGroup:
+		c.fieldType(def.Type(), e.member)
+
+	case ast.DefKindMethod:
+		sig := def.Signature()
+		input, _ := e.method.Input()
+		output, _ := e.method.Output()
+		if sig.Inputs().Len() == 1 {
+			c.typeName(typePath(sig.Inputs().At(0)), input)
+		}
+		if sig.Outputs().Len() == 1 {
+			c.typeName(typePath(sig.Outputs().At(0)), output)
+		}
+	}
+
+	mods := Declaration
+	if e.deprecated() {
+		mods |= Deprecated
+	}
+	if kind := e.kind(def); kind != KindInvalid {
+		c.set(def.Name().AsIdent(), class{kind, mods, target{entity: e}})
+	}
+}
+
+// fieldType classifies the type of a field.
+func (c *classifier) fieldType(ty ast.TypeAny, member ir.Member) {
+	ty = ty.RemovePrefixes()
+	elem := member.Element()
+	if g := ty.AsGeneric(); !g.IsZero() {
+		// The element of a map field is its entry type, whose key and value
+		// are the type arguments.
+		key, value := g.AsMap()
+		if key.IsZero() || !elem.IsMapEntry() || elem.Members().Len() != 2 {
+			return
+		}
+		c.typeName(typePath(key), elem.Members().At(0).Element())
+		c.typeName(typePath(value), elem.Members().At(1).Element())
+		return
+	}
+	c.typeName(typePath(ty), elem)
+}
+
+// typeName classifies a path that was resolved to ty.
+//
+// The last component names ty itself. The others name the packages and
+// messages that ty is nested in, which are found by walking up ty's
+// fully-qualified name.
+func (c *classifier) typeName(path ast.Path, ty ir.Type) {
+	if path.IsZero() || ty.IsZero() {
+		return
+	}
+
+	components := slices.Collect(path.Components())
+	name := ty.FullName()
+	for i := len(components) - 1; i >= 0; i-- {
+		tok := components[i].AsIdent()
+		if i == len(components)-1 {
+			e := entity{ty: ty}
+			var mods Modifiers
+			if e.deprecated() {
+				mods |= Deprecated
+			}
+			c.set(tok, class{typeKind(ty), mods, target{entity: e}})
+			continue
+		}
+
+		name = name.Parent()
+		if name == "" {
+			break
+		}
+		switch sym := c.file.FindSymbol(name); sym.Kind() {
+		case ir.SymbolKindMessage:
+			c.set(tok, class{kind: KindMessage, target: target{entity: entity{ty: sym.AsType()}}})
+		case ir.SymbolKindPackage:
+			c.set(tok, class{kind: KindPackage, target: target{pkg: name}})
+		}
+	}
+}
+
+// packageName classifies the name in a package declaration.
+func (c *classifier) packageName(path ast.Path) {
+	var name ir.FullName
+	for pc := range path.Components() {
+		name = name.Append(pc.AsIdent().Text())
+		c.set(pc.AsIdent(), class{kind: KindPackage, target: target{pkg: name}})
+	}
+}
+
+// options returns the options message that the option being visited sets.
+func (c *classifier) options(cursor *ast.Cursor) ir.MessageValue {
+	for node := range cursor.Ancestors() {
+		switch node := node.(type) {
+		case *ast.File:
+			return c.file.Options()
+		case ast.DeclAny:
+			switch node.Kind() {
+			case ast.DeclKindBody:
+				continue
+			case ast.DeclKindDef:
+				return c.entities[node].options()
+			}
+			return ir.MessageValue{}
+		}
+	}
+	return ir.MessageValue{}
+}
+
+// optionName classifies the name of an option that sets a field of opts.
+// Extension names, which appear in parentheses, are distinguished from other
+// components.
+func (c *classifier) optionName(path ast.Path, opts ir.MessageValue) {
+	for pc := range path.Components() {
+		value := field(opts, pc)
+		opts = value.AsMessage()
+
+		mods := Modifiers(0)
+		if deprecated, _ := value.Field().Deprecated().AsBool(); deprecated {
+			mods |= Deprecated
+		}
+		t := target{entity: entity{member: value.Field()}, value: value}
+		if ext := pc.AsExtension(); !ext.IsZero() {
+			for pc := range ext.Components() {
+				c.set(pc.AsIdent(), class{KindExtension, mods, t})
+			}
+			continue
+		}
+		c.set(pc.AsIdent(), class{KindOption, mods, t})
+	}
+}
+
+// expr classifies the names in an expression.
+func (c *classifier) expr(expr ast.ExprAny, parent ast.Node) {
+	switch expr.Kind() {
+	case ast.ExprKindField:
+		// Keys in message literals are field names, or extension names in
+		// brackets.
+		key := expr.AsField().Key()
+		if path := key.AsPath(); !path.IsZero() {
+			for pc := range path.Components() {
+				c.set(pc.AsIdent(), class{kind: KindField})
+			}
+		}
+		for elem := range seq.Values(key.AsArray().Elements()) {
+			for pc := range elem.AsPath().Components() {
+				c.set(pc.AsIdent(), class{kind: KindExtension})
+			}
+		}
+
+	case ast.ExprKindPath:
+		tok := expr.AsPath().AsIdent()
+		if tok.Keyword().IsReservedWord() {
+			// true, false, inf, max, and so on.
+			break
+		}
+		kind := KindEnumValue
+		if decl, ok := parent.(ast.DeclAny); ok && decl.Kind() == ast.DeclKindRange {
+			kind = KindField // A reserved name.
+		}
+		c.set(tok, class{kind: kind})
+	}
+}
+
+// field returns the value of the field of msg named by pc.
+func field(msg ir.MessageValue, pc ast.PathComponent) ir.Value {
+	if msg.IsZero() {
+		return ir.Value{}
+	}
+
+	ext := pc.AsExtension()
+	name := ir.FullName(ext.Canonicalized()).ToRelative()
+	for value := range msg.Fields() {
+		field := value.Field()
+		switch {
+		case ext.IsZero():
+			if !field.IsExtension() && field.Name() == pc.AsIdent().Text() {
+				return value
+			}
+		case field.IsExtension():
+			// The name may be partially qualified.
+			fqn := field.FullName().ToRelative()
+			if fqn == name || strings.HasSuffix(string(fqn), "."+string(name)) {
+				return value
+			}
+		}
+	}
+	return ir.Value{}
+}
+
+// typePath returns the path that names ty, if it is a plain reference to a
+// type.
+func typePath(ty ast.TypeAny) ast.Path {
+	return ty.RemovePrefixes().AsPath().Path
+}
+
+// classifyLexical classifies a token that was not classified by where it
+// appears, based on its lexical category.
+func classifyLexical(tok token.Token) (class, bool) {
+	switch tok.Kind() {
+	case token.Comment:
+		return class{kind: KindComment}, true
+	case token.String:
+		return class{kind: KindString}, true
+	case token.Number:
+		return class{kind: KindNumber}, true
+	case token.Ident, token.Keyword:
+		if tok.Keyword() != keyword.Unknown && !tok.Keyword().IsPunctuation() {
+			return class{kind: KindKeyword}, true
+		}
+	}
+	return class{}, false
+}
+
+// appendLines appends a token for each line of span, not including line
+// endings.
+func appendLines(tokens []SemanticToken, span source.Span, class class, units length.Unit) []SemanticToken {
+	file := span.File
+	for start := span.Start; start < span.End; {
+		_, next := file.LineOffsets(file.LineByOffset(start) + 1)
+		next = min(next, span.End)
+		end := next
+		for end > start && (file.Text()[end-1] == '\n' || file.Text()[end-1] == '\r') {
+			end--
+		}
+
+		if end > start {
+			loc := file.Location(start, units)
+			tokens = append(tokens, SemanticToken{
+				Span:      file.Span(start, end),
+				Start:     loc,
+				Length:    file.Location(end, units).Column - loc.Column,
+				Kind:      class.kind,
+				Modifiers: class.mods,
+			})
+		}
+		start = next
+	}
+	return tokens
+}
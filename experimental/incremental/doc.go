@@ -58,6 +58,16 @@ those dependencies' dependencies. ([Run], however, returns all transitive errors
 that depended on that query to be discarded and require recomputing. This can be
 used e.g. to mark a file as changed and require that everything that that file
 depended on is recomputed. See [Executor.Evict].
+
+Recomputing everything downstream of an evicted query is often wasteful: a
+whitespace-only edit to a file may not change anything its dependents care
+about. Queries can implement [EqualQuery] to tell the executor how to compare
+their outputs. If a recomputed query produces a value equal to its previous
+one, it is treated as unchanged, and queries that depend on it (and only on
+unchanged queries) reuse their previous results without being re-executed.
+
+Long-running processes can bound the memory used by memoized results with
+[WithMaxEntries] and [WithMaxBytes]; see [Executor.Trim].
 */
 //nolint:dupword  // "that that" is grammatical!
 package incremental
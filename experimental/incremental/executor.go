@@ -28,6 +28,7 @@ import (
 
 	"github.com/bufbuild/protocompile/experimental/report"
 	"github.com/bufbuild/protocompile/internal"
+	"github.com/bufbuild/protocompile/internal/ext/slicesx"
 )
 
 // Executor is a caching executor for incremental queries.
@@ -38,11 +39,14 @@ type Executor struct {
 	dirty         sync.RWMutex
 
 	// TODO: Evaluate alternatives. sync.Map is pretty bad at having predictable
-	// performance, and we may want to add eviction to keep memoization costs
-	// in a long-running process (like, say, a language server) down.
-	// See https://github.com/dgraph-io/ristretto as a potential alternative.
+	// performance. See https://github.com/dgraph-io/ristretto as a potential
+	// alternative.
 	tasks sync.Map // [any, *task]
 
+	// Results of evicted queries that may allow a future recomputation of
+	// that query to be cut off early. See [EqualQuery].
+	priors sync.Map // [any, *prior]
+
 	sema *semaphore.Weighted
 
 	// The [time.Duration] to wait before running the GC when debug mode is on. See docs for
@@ -50,6 +54,12 @@ type Executor struct {
 	evictGCDeadline time.Duration
 
 	counter atomic.Uint64 // Used for generating sequence IDs for Result.Unchanged.
+
+	// Memory limits, and book-keeping for enforcing them. See [WithMaxEntries]
+	// and [WithMaxBytes].
+	maxEntries, maxBytes int64
+	entries, bytes       atomic.Int64
+	clock                atomic.Uint64 // Used for tracking task recency.
 }
 
 // ExecutorOption is an option func for [New].
@@ -91,6 +101,29 @@ func WithDebugEvict(wait time.Duration) ExecutorOption {
 	return func(e *Executor) { e.evictGCDeadline = wait }
 }
 
+// WithMaxEntries sets the maximum number of memoized query results that the
+// executor will retain. Zero means no limit, which is the default.
+//
+// Previous results retained for early cutoff after [Executor.Evict] count
+// towards this limit.
+//
+// See [Executor.Trim] for how the executor picks which results to evict.
+func WithMaxEntries(n int) ExecutorOption {
+	return func(e *Executor) { e.maxEntries = int64(n) }
+}
+
+// WithMaxBytes sets the approximate number of bytes of memoized query results
+// that the executor will retain. Zero means no limit, which is the default.
+//
+// The size of a result is measured by [SizedQuery]; results of queries that
+// do not implement it do not count towards this limit. Previous results
+// retained for early cutoff after [Executor.Evict] do.
+//
+// See [Executor.Trim] for how the executor picks which results to evict.
+func WithMaxBytes(n int64) ExecutorOption {
+	return func(e *Executor) { e.maxBytes = n }
+}
+
 // Keys returns a snapshot of the keys of which queries are present (and
 // memoized) in an Executor.
 //
@@ -128,6 +161,10 @@ var runExecutorKey, timingsKey byte
 // Note: this function really wants to be a method of [Executor], but it isn't
 // because it's generic.
 func Run[T any](ctx context.Context, e *Executor, queries ...Query[T]) ([]Result[T], *report.Report, error) {
+	// This must be deferred before the unlock below, so that it runs after we
+	// are no longer holding the dirty lock.
+	defer e.tryTrim()
+
 	e.dirty.RLock()
 	defer e.dirty.RUnlock()
 
@@ -211,6 +248,12 @@ func WithTimings(ctx context.Context, timings map[any]time.Duration) context.Con
 // Evict marks query keys as invalid, requiring those queries, and their
 // dependencies, to be recomputed. keys that are not cached are ignored.
 //
+// If any of the evicted queries implement [EqualQuery], the executor will
+// remember their previous results, so that when they are recomputed, queries
+// that depend on them may be reused instead of re-executed. These results are
+// retained until the corresponding query is next executed, or until they are
+// dropped by [Executor.Trim].
+//
 // This function cannot execute in parallel with calls to [Run], and will take
 // an exclusive lock (note that [Run] calls themselves can be run in parallel).
 func (e *Executor) Evict(keys ...any) {
@@ -237,20 +280,51 @@ func (e *Executor) EvictWithCleanup(keys []any, cleanup func()) {
 	e.dirty.Lock()
 	defer e.dirty.Unlock()
 
-	var evicted []weak.Pointer[task]
+	e.evict(tasks, true)
+
+	if cleanup != nil {
+		cleanup()
+	}
+}
+
+// evict removes the given tasks, and every task that transitively depends on
+// them, from the executor. If priors is set, results that may enable early
+// cutoff are retained; see [Executor.savePriors].
+//
+// The caller must hold the dirty lock exclusively.
+func (e *Executor) evict(tasks []*task, priors bool) {
+	// Maps each evicted task to whether it was evicted directly, as opposed
+	// to being evicted because one of its dependencies was.
+	evicted := make(map[*task]bool, len(tasks))
+	for _, t := range tasks {
+		evicted[t] = true
+	}
+
+	var weaks []weak.Pointer[task]
 	logEvictionDebug := internal.Debug && e.evictGCDeadline > 0
 	for n := len(tasks); n > 0; n = len(tasks) {
 		next := tasks[n-1]
 		tasks = tasks[:n-1]
 
 		for k := range next.callers.Range {
-			tasks = append(tasks, k.(*task)) //nolint:errcheck
+			caller := k.(*task) //nolint:errcheck
+			if _, ok := evicted[caller]; !ok {
+				evicted[caller] = false
+				tasks = append(tasks, caller)
+			}
 		}
 
 		// Remove the task from the map. Syncronized by the dirty lock.
 		t, _ := e.tasks.LoadAndDelete(next.query.Key())
+		if t == nil {
+			continue
+		}
+		if r := next.result.Load(); r != nil && closed(r.done) {
+			e.entries.Add(-1)
+			e.bytes.Add(-next.size)
+		}
 		if logEvictionDebug {
-			evicted = append(evicted, weak.Make(t.(*task))) //nolint:errcheck
+			weaks = append(weaks, weak.Make(t.(*task))) //nolint:errcheck
 		}
 
 		// Remove the task from the callers of its deps.
@@ -259,14 +333,18 @@ func (e *Executor) EvictWithCleanup(keys []any, cleanup func()) {
 		}
 	}
 
-	if evicted != nil {
+	if priors {
+		e.savePriors(evicted)
+	}
+
+	if weaks != nil {
 		go func() {
 			time.Sleep(e.evictGCDeadline)
 			runtime.GC()
-			evicted = slices.DeleteFunc(evicted, func(e weak.Pointer[task]) bool {
+			weaks = slices.DeleteFunc(weaks, func(e weak.Pointer[task]) bool {
 				return e.Value() == nil
 			})
-			for _, e := range evicted {
+			for _, e := range weaks {
 				internal.DebugLog(
 					[]any{"exec %p", e},
 					"EvictWithCleanup",
@@ -277,10 +355,191 @@ func (e *Executor) EvictWithCleanup(keys []any, cleanup func()) {
 			}
 		}()
 	}
+}
 
-	if cleanup != nil {
-		cleanup()
+// Trim evicts memoized results until the executor is within the limits set
+// by [WithMaxEntries] and [WithMaxBytes].
+//
+// Only results which no other memoized query depends on are candidates for
+// eviction, since evicting anything else would also evict its dependents.
+// Evicting a result may, in turn, make its dependencies into candidates. Of
+// the candidates, the least recently used result is evicted first. Results of
+// queries that are pinned by [PinnedQuery] are never evicted by Trim.
+//
+// Trim also drops every previous result retained for early cutoff (see
+// [Executor.Evict]). Without limits, these are otherwise only released when
+// their query is executed again, so callers that evict queries which they may
+// never run again should call Trim once they are done with them.
+//
+// [Run] also trims automatically before returning, unless another call to
+// [Run] is in progress, but only once a limit is exceeded; it then drops
+// previous results oldest first, and only evicts memoized results if that is
+// not enough. Like [Executor.Evict], this function cannot execute in parallel
+// with calls to [Run], and will take an exclusive lock.
+func (e *Executor) Trim() {
+	e.dirty.Lock()
+	defer e.dirty.Unlock()
+
+	e.priors.Range(func(k, _ any) bool {
+		e.takePrior(k)
+		return true
+	})
+	if e.overLimit() {
+		e.trim()
+	}
+}
+
+// tryTrim evicts memoized results until the executor is within its limits,
+// like [Executor.Trim], but does nothing if it cannot immediately take the
+// dirty lock. Previous results are kept unless a limit is exceeded.
+func (e *Executor) tryTrim() {
+	if !e.overLimit() || !e.dirty.TryLock() {
+		return
+	}
+
+	defer e.dirty.Unlock()
+	e.trim()
+}
+
+// overLimit returns whether this executor's memory limits are exceeded.
+func (e *Executor) overLimit() bool {
+	return (e.maxEntries > 0 && e.entries.Load() > e.maxEntries) ||
+		(e.maxBytes > 0 && e.bytes.Load() > e.maxBytes)
+}
+
+// trim implements [Executor.Trim].
+//
+// The caller must hold the dirty lock exclusively.
+func (e *Executor) trim() {
+	var old slicesx.Heap[uint64, any]
+	e.priors.Range(func(k, v any) bool {
+		old.Insert(v.(*prior).verified, k) //nolint:errcheck
+		return true
+	})
+	for old.Len() > 0 && e.overLimit() {
+		_, key := old.Pop()
+		e.takePrior(key)
+	}
+
+	var lru slicesx.Heap[uint64, *task]
+	e.tasks.Range(func(_, v any) bool {
+		if t := v.(*task); t.evictable() { //nolint:errcheck
+			lru.Insert(t.used.Load(), t)
+		}
+		return true
+	})
+
+	var deps []*task
+	for lru.Len() > 0 && e.overLimit() {
+		_, next := lru.Pop()
+
+		deps = deps[:0]
+		for k := range next.deps.Range {
+			deps = append(deps, k.(*task)) //nolint:errcheck
+		}
+
+		e.evict([]*task{next}, false)
+		for _, dep := range deps {
+			if dep.evictable() {
+				lru.Insert(dep.used.Load(), dep)
+			}
+		}
+	}
+}
+
+// retain records that t has completed with the given result, for the purposes
+// of enforcing memory limits.
+func (e *Executor) retain(t *task, r *result) {
+	t.used.Store(e.clock.Add(1))
+	if t.query.size != nil && r.Fatal == nil {
+		t.size = t.query.size(r.Value)
 	}
+
+	e.entries.Add(1)
+	e.bytes.Add(t.size)
+}
+
+// savePriors records the results of evicted tasks which may allow for early
+// cutoff when they are recomputed.
+//
+// A task is worth remembering if it can be compared to its recomputed value,
+// or if it was evicted indirectly and every evicted task it depends on is
+// itself worth remembering: in that case, if none of its dependencies turn
+// out to have changed, its previous result can be reused wholesale.
+func (e *Executor) savePriors(evicted map[*task]bool) {
+	reusable := func(t *task, capable func(*task) bool) bool {
+		if evicted[t] {
+			return false
+		}
+		for k := range t.deps.Range {
+			dep := k.(*task) //nolint:errcheck
+			if _, ok := evicted[dep]; ok && !capable(dep) {
+				return false
+			}
+		}
+		return true
+	}
+
+	memo := make(map[*task]bool, len(evicted))
+	var capable func(*task) bool
+	capable = func(t *task) bool {
+		if v, ok := memo[t]; ok {
+			return v
+		}
+		memo[t] = false // Break cycles in invalid query graphs.
+		v := t.query.equal != nil || reusable(t, capable)
+		memo[t] = v
+		return v
+	}
+
+	verified := e.counter.Load()
+	for t := range evicted {
+		r := t.result.Load()
+		if r == nil || !closed(r.done) || !capable(t) {
+			continue
+		}
+
+		prior := &prior{
+			Result:      r.Result,
+			runID:       r.runID,
+			verified:    verified,
+			diagnostics: t.report.Diagnostics,
+			reusable:    reusable(t, capable),
+			size:        t.size,
+		}
+		for k := range t.deps.Range {
+			prior.deps = append(prior.deps, k.(*task).query) //nolint:errcheck
+		}
+		e.putPrior(t.query.Key(), prior)
+	}
+}
+
+// putPrior records a prior result for the given key, replacing any existing
+// one.
+func (e *Executor) putPrior(key any, p *prior) {
+	if old, ok := e.priors.Swap(key, p); ok {
+		e.releasePrior(old.(*prior)) //nolint:errcheck
+	}
+	e.entries.Add(1)
+	e.bytes.Add(p.size)
+}
+
+// takePrior removes and returns the prior result for the given key, if there
+// is one.
+func (e *Executor) takePrior(key any) *prior {
+	p, ok := e.priors.LoadAndDelete(key)
+	if !ok {
+		return nil
+	}
+	prev := p.(*prior) //nolint:errcheck
+	e.releasePrior(prev)
+	return prev
+}
+
+// releasePrior removes a prior result from the executor's memory accounting.
+func (e *Executor) releasePrior(p *prior) {
+	e.entries.Add(-1)
+	e.bytes.Add(-p.size)
 }
 
 // getTask returns a task pointer for the given key and whether it was found.
@@ -213,6 +213,61 @@ func TestTimings(t *testing.T) {
 	})
 }
 
+func TestEarlyCutoff(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ctx := t.Context()
+	exec := incremental.New(
+		incremental.WithParallelism(4),
+	)
+
+	cell := &Mutable{Value: 2}
+	run := func() incremental.Result[string] {
+		results, _, err := incremental.Run(ctx, exec, Describe{cell})
+		require.NoError(t, err)
+		return results[0]
+	}
+
+	r := run()
+	assert.Equal("even", r.Value)
+	assert.True(r.Changed)
+	assert.Equal(int32(1), cell.describes.Load())
+
+	// Parity is recomputed and compares equal, so Describe is not re-executed.
+	exec.EvictWithCleanup([]any{Cell{cell}}, func() { cell.Value = 4 })
+	r = run()
+	assert.Equal("even", r.Value)
+	assert.False(r.Changed)
+	assert.Equal(int32(2), cell.parities.Load())
+	assert.Equal(int32(1), cell.describes.Load())
+
+	exec.EvictWithCleanup([]any{Cell{cell}}, func() { cell.Value = 5 })
+	r = run()
+	assert.Equal("odd", r.Value)
+	assert.True(r.Changed)
+	assert.Equal(int32(3), cell.parities.Load())
+	assert.Equal(int32(2), cell.describes.Load())
+
+	// Evicting Parity directly always re-executes it, but its dependents can
+	// still be cut off.
+	exec.Evict(Parity{cell})
+	r = run()
+	assert.Equal("odd", r.Value)
+	assert.False(r.Changed)
+	assert.Equal(int32(4), cell.parities.Load())
+	assert.Equal(int32(2), cell.describes.Load())
+
+	// A dependency recomputed in some earlier Run still counts as changed.
+	exec.EvictWithCleanup([]any{Cell{cell}}, func() { cell.Value = 6 })
+	_, _, err := incremental.Run(ctx, exec, Parity{cell})
+	require.NoError(t, err)
+	r = run()
+	assert.Equal("even", r.Value)
+	assert.True(r.Changed)
+	assert.Equal(int32(3), cell.describes.Load())
+}
+
 // ParseInt is a fallible query that parses an integer.
 type ParseInt struct {
 	Input string
@@ -298,3 +353,67 @@ func (w Wait) Execute(t *incremental.Task) (struct{}, error) {
 		slicesx.Transform(w.Children, func(w Wait) incremental.Query[struct{}] { return w })...)
 	return struct{}{}, err
 }
+
+// Mutable is state shared by the queries used to test early cutoff.
+type Mutable struct {
+	Value int
+
+	parities, describes atomic.Int32
+}
+
+// Cell is a query that returns the current value of a [Mutable].
+type Cell struct {
+	*Mutable
+}
+
+func (c Cell) Key() any {
+	return c
+}
+
+func (c Cell) Execute(_ *incremental.Task) (int, error) {
+	return c.Value, nil
+}
+
+// Parity is a query that returns whether a [Cell] is odd, and supports early
+// cutoff.
+type Parity struct {
+	*Mutable
+}
+
+func (p Parity) Key() any {
+	return p
+}
+
+func (p Parity) Execute(t *incremental.Task) (bool, error) {
+	p.parities.Add(1)
+	r, err := incremental.Resolve(t, Cell(p))
+	if err != nil {
+		return false, err
+	}
+	return r[0].Value%2 == 1, nil
+}
+
+func (Parity) Equal(a, b bool) bool {
+	return a == b
+}
+
+// Describe is a query that depends on a [Parity].
+type Describe struct {
+	*Mutable
+}
+
+func (d Describe) Key() any {
+	return d
+}
+
+func (d Describe) Execute(t *incremental.Task) (string, error) {
+	d.describes.Add(1)
+	r, err := incremental.Resolve(t, Parity(d))
+	if err != nil {
+		return "", err
+	}
+	if r[0].Value {
+		return "odd", nil
+	}
+	return "even", nil
+}
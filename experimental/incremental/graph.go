@@ -0,0 +1,180 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package incremental
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/bufbuild/protocompile/experimental/report"
+)
+
+// Graph is a snapshot of the dependency graph of the queries memoized by an
+// [Executor]. See [Executor.Graph].
+//
+// A Graph can be serialized with encoding/json, or rendered with [Graph.DOT].
+type Graph struct {
+	// The queries in the graph, sorted by key.
+	Nodes []GraphNode `json:"nodes"`
+}
+
+// GraphNode is a query in a [Graph].
+type GraphNode struct {
+	// The query's key, formatted with %#v, as in [Executor.Keys].
+	Key string `json:"key"`
+	// The query's type, formatted with %T.
+	Type string `json:"type"`
+
+	// Whether this query is still being executed. If set, the fields below
+	// are unspecified, except for Deps and Callers.
+	Pending bool `json:"pending,omitempty"`
+
+	// How long executing this query took, excluding its dependencies. See
+	// [Result.Elapsed].
+	Elapsed time.Duration `json:"elapsed"`
+	// The query's fatal error, if any.
+	Fatal string `json:"fatal,omitempty"`
+	// The number of error and warning diagnostics generated directly by this
+	// query.
+	Errors   int `json:"errors,omitempty"`
+	Warnings int `json:"warnings,omitempty"`
+	// The number of times this query's result was requested after it was
+	// first computed.
+	Hits uint64 `json:"hits"`
+
+	// Indices in [Graph].Nodes of the queries this query depends on, and of
+	// the queries that depend on it.
+	Deps    []int `json:"deps,omitempty"`
+	Callers []int `json:"callers,omitempty"`
+}
+
+// Graph returns a snapshot of this executor's dependency graph.
+//
+// This function may be called concurrently with [Run], in which case the
+// snapshot may include queries that are still executing. These are marked
+// as pending.
+func (e *Executor) Graph() *Graph {
+	type entry struct {
+		key  string
+		task *task
+	}
+	var entries []entry
+	e.tasks.Range(func(k, t any) bool {
+		entries = append(entries, entry{fmt.Sprintf("%#v", k), t.(*task)}) //nolint:errcheck
+		return true
+	})
+	slices.SortFunc(entries, func(a, b entry) int { return strings.Compare(a.key, b.key) })
+
+	index := make(map[*task]int, len(entries))
+	for i, entry := range entries {
+		index[entry.task] = i
+	}
+
+	graph := &Graph{Nodes: make([]GraphNode, len(entries))}
+	for i, entry := range entries {
+		t := entry.task
+		node := &graph.Nodes[i]
+		node.Key = entry.key
+		node.Type = fmt.Sprintf("%T", t.query.Underlying())
+		node.Hits = t.hits.Load()
+
+		for k := range t.deps.Range {
+			if dep, ok := index[k.(*task)]; ok { //nolint:errcheck
+				node.Deps = append(node.Deps, dep)
+			}
+		}
+		for k := range t.callers.Range {
+			if caller, ok := index[k.(*task)]; ok { //nolint:errcheck
+				node.Callers = append(node.Callers, caller)
+			}
+		}
+		slices.Sort(node.Deps)
+		slices.Sort(node.Callers)
+
+		r := t.result.Load()
+		if r == nil || !closed(r.done) {
+			node.Pending = true
+			continue
+		}
+
+		node.Elapsed = r.Elapsed
+		if r.Fatal != nil {
+			node.Fatal = r.Fatal.Error()
+		}
+		for _, d := range t.report.Diagnostics {
+			switch d.Level() {
+			case report.ICE, report.Error:
+				node.Errors++
+			case report.Warning:
+				node.Warnings++
+			}
+		}
+	}
+
+	return graph
+}
+
+// DOT renders this graph in the Graphviz DOT language.
+//
+// Each query is drawn as a box labeled with its type, key, and timing
+// information, with an edge to each of its dependencies. Queries with fatal
+// errors are drawn in red, those with error diagnostics in orange, and pending
+// queries with a dashed outline.
+func (g *Graph) DOT() string {
+	var out strings.Builder
+	out.WriteString("digraph queries {\n")
+	out.WriteString("  node [shape=box];\n")
+	for i, node := range g.Nodes {
+		label := []string{node.Type, node.Key}
+		var attrs []string
+		switch {
+		case node.Pending:
+			label = append(label, "pending")
+			attrs = append(attrs, "style=dashed")
+		default:
+			label = append(label, fmt.Sprintf("%v, %d hits", node.Elapsed, node.Hits))
+			if node.Fatal != "" {
+				label = append(label, "fatal: "+node.Fatal)
+				attrs = append(attrs, "color=red")
+			} else if node.Errors > 0 {
+				attrs = append(attrs, "color=orange")
+			}
+		}
+
+		for j, line := range label {
+			label[j] = dotEscape(line)
+		}
+		attrs = append([]string{`label="` + strings.Join(label, `\n`) + `"`}, attrs...)
+		fmt.Fprintf(&out, "  n%d [%s];\n", i, strings.Join(attrs, ", "))
+	}
+	for i, node := range g.Nodes {
+		for _, dep := range node.Deps {
+			fmt.Fprintf(&out, "  n%d -> n%d;\n", i, dep)
+		}
+	}
+	out.WriteString("}\n")
+	return out.String()
+}
+
+// dotEscape escapes a string for use inside of a quoted DOT string.
+func dotEscape(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		`"`, `\"`,
+		"\n", `\n`,
+	).Replace(s)
+}
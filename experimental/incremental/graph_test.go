@@ -0,0 +1,86 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package incremental_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bufbuild/protocompile/experimental/incremental"
+)
+
+func TestGraph(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ctx := t.Context()
+	exec := incremental.New(
+		incremental.WithParallelism(4),
+	)
+
+	_, _, err := incremental.Run(ctx, exec, Sum{"1,oops,1"}, Sum{"-1"})
+	require.NoError(t, err)
+
+	graph := exec.Graph()
+	keys := make([]string, len(graph.Nodes))
+	for i, node := range graph.Nodes {
+		keys[i] = node.Key
+		assert.False(node.Pending, node.Key)
+	}
+	assert.Equal(exec.Keys(), keys)
+	assert.Equal([]string{
+		`incremental_test.ParseInt{Input:"-1"}`,
+		`incremental_test.ParseInt{Input:"1"}`,
+		`incremental_test.ParseInt{Input:"oops"}`,
+		`incremental_test.Root{}`,
+		`incremental_test.Sum{Input:"-1"}`,
+		`incremental_test.Sum{Input:"1,oops,1"}`,
+	}, keys)
+
+	parseNeg, parseOne, parseOops, root, sumNeg, sum :=
+		graph.Nodes[0], graph.Nodes[1], graph.Nodes[2], graph.Nodes[3], graph.Nodes[4], graph.Nodes[5]
+
+	assert.Equal("incremental_test.Sum", sum.Type)
+	assert.Equal([]int{1, 2}, sum.Deps)
+	assert.Empty(sum.Callers)
+	assert.Equal([]int{4}, parseNeg.Callers)
+	assert.Equal([]int{0, 1, 2}, root.Callers)
+
+	assert.Equal("negative value: -1", parseNeg.Fatal)
+	assert.Equal("negative value: -1", sumNeg.Fatal)
+	assert.Equal(1, parseOops.Errors)
+	assert.Zero(parseOne.Errors)
+
+	// ParseInt{"1"} is requested twice by the same Sum, and Root is requested
+	// by all three ParseInts.
+	assert.Equal(uint64(1), parseOne.Hits)
+	assert.Equal(uint64(2), root.Hits)
+
+	dot := graph.DOT()
+	assert.Contains(dot, `n5 [label="incremental_test.Sum\nincremental_test.Sum{Input:\"1,oops,1\"}\n`)
+	assert.Contains(dot, "n5 -> n1;\n")
+	assert.Contains(dot, "n2 -> n3;\n")
+	assert.Regexp(`n4 \[label=".*\\nfatal: negative value: -1", color=red\];`, dot)
+	assert.Regexp(`n2 \[label=".*", color=orange\];`, dot)
+
+	data, err := json.Marshal(graph)
+	require.NoError(t, err)
+	var decoded incremental.Graph
+	require.NoError(t, json.Unmarshal(data, &decoded))
+	assert.Equal(graph, &decoded)
+}
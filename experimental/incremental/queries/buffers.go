@@ -0,0 +1,78 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queries
+
+import (
+	"github.com/bufbuild/protocompile/experimental/incremental"
+	"github.com/bufbuild/protocompile/experimental/source"
+)
+
+// Buffers updates the files in a [source.Overlay], such as unsaved editor
+// buffers, and evicts the [File] queries that read them from an
+// [incremental.Executor].
+//
+// Each update is applied atomically with the eviction, so a concurrent call to
+// [incremental.Run] never observes a new buffer alongside stale query results
+// computed from the old one, or vice-versa.
+type Buffers struct {
+	Executor *incremental.Executor
+	Overlay  *source.Overlay
+
+	// The Opener used in the queries to evict, as in [Invalidator].
+	// If nil, Overlay is used.
+	Opener source.Opener
+}
+
+// Set overlays path with the given text and version, and evicts the queries
+// that depend on it.
+//
+// Returns false if the update was ignored because the overlay already has a
+// newer version of path. See [source.Overlay.Set].
+func (b *Buffers) Set(path, text string, version int64) bool {
+	if prev, ok := b.Overlay.Version(path); ok && prev >= version {
+		return false
+	}
+
+	var ok bool
+	b.Executor.EvictWithCleanup([]any{b.key(path)}, func() {
+		ok = b.Overlay.Set(path, text, version)
+	})
+	return ok
+}
+
+// Clear removes the overlay for path, and evicts the queries that depend on
+// it, so that they will read path from the overlay's base Opener instead.
+//
+// Returns false if path was not overlaid.
+func (b *Buffers) Clear(path string) bool {
+	if _, ok := b.Overlay.Version(path); !ok {
+		return false
+	}
+
+	var ok bool
+	b.Executor.EvictWithCleanup([]any{b.key(path)}, func() {
+		ok = b.Overlay.Clear(path)
+	})
+	return ok
+}
+
+// key returns the key of the File query for path.
+func (b *Buffers) key(path string) any {
+	opener := b.Opener
+	if opener == nil {
+		opener = b.Overlay
+	}
+	return fileKey(opener, path)
+}
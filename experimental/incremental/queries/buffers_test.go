@@ -0,0 +1,60 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queries_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bufbuild/protocompile/experimental/incremental"
+	"github.com/bufbuild/protocompile/experimental/incremental/queries"
+	"github.com/bufbuild/protocompile/experimental/ir"
+	"github.com/bufbuild/protocompile/experimental/source"
+)
+
+func TestBuffersVersion(t *testing.T) {
+	t.Parallel()
+
+	exec := incremental.New()
+	overlay := &source.Overlay{Base: source.WKTs()}
+	buffers := &queries.Buffers{Executor: exec, Overlay: overlay}
+	query := queries.IR{Opener: overlay, Session: new(ir.Session), Path: "a.proto"}
+
+	version := func() int64 {
+		results, _, err := incremental.Run(t.Context(), exec, query)
+		require.NoError(t, err)
+		require.NoError(t, results[0].Fatal)
+		v, ok := results[0].Value.AST().Stream().File.Version()
+		require.True(t, ok)
+		return v
+	}
+
+	const text = "syntax = \"proto3\";\npackage p;\nmessage A {}\n"
+	require.True(t, buffers.Set("a.proto", text, 1))
+	assert.Equal(t, int64(1), version())
+
+	// Setting the same text at a new version must not be cut off early.
+	require.True(t, buffers.Set("a.proto", text, 2))
+	assert.Equal(t, int64(2), version())
+
+	// Out-of-order updates are ignored.
+	assert.False(t, buffers.Set("a.proto", text+"message B {}\n", 1))
+	assert.Equal(t, int64(2), version())
+
+	require.True(t, buffers.Set("a.proto", text+"message B {}\n", 3))
+	assert.Equal(t, int64(3), version())
+}
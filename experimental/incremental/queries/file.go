@@ -17,6 +17,7 @@ package queries
 import (
 	"github.com/bufbuild/protocompile/experimental/incremental"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/source"
 )
 
@@ -34,7 +35,10 @@ type File struct {
 	ReportError bool
 }
 
-var _ incremental.Query[*source.File] = File{}
+var (
+	_ incremental.EqualQuery[*source.File] = File{}
+	_ incremental.SizedQuery[*source.File] = File{}
+)
 
 // Key implements [incremental.Query].
 //
@@ -47,6 +51,15 @@ func (f File) Key() any {
 	return f
 }
 
+// fileKey returns the key to evict in order to invalidate the File queries
+// for path that use opener.
+//
+// File queries with ReportError set depend on the corresponding query without
+// it, so only the latter needs to be evicted.
+func fileKey(opener source.Opener, path string) any {
+	return File{Opener: opener, Path: path}.Key()
+}
+
 // Execute implements [incremental.Query].
 func (f File) Execute(t *incremental.Task) (*source.File, error) {
 	if !f.ReportError {
@@ -67,6 +80,7 @@ func (f File) Execute(t *incremental.Task) (*source.File, error) {
 	err = r[0].Fatal
 	if err != nil {
 		t.Report().Errorf("%v", err).Apply(
+			report.Tag(rtags.FileNotFound),
 			report.InFile(f.Path),
 		)
 		return nil, err
@@ -74,3 +88,32 @@ func (f File) Execute(t *incremental.Task) (*source.File, error) {
 
 	return r[0].Value, nil
 }
+
+// Equal implements [incremental.EqualQuery].
+//
+// Two files are equal if they have the same path, version, and contents. This
+// allows queries that depend on a file to be reused when it is evicted but its
+// contents did not actually change.
+//
+// The version is compared so that a file overlaid with the same text at a new
+// version is not cut off, since otherwise queries depending on it would
+// continue to report the old version.
+func (File) Equal(a, b *source.File) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	av, aok := a.Version()
+	bv, bok := b.Version()
+	return a.Path() == b.Path() && av == bv && aok == bok && a.Text() == b.Text()
+}
+
+// Size implements [incremental.SizedQuery].
+func (f File) Size(file *source.File) int64 {
+	if f.ReportError || file == nil {
+		// Queries with ReportError set return the result of the corresponding
+		// query without it, so counting it again would double-count it.
+		return 0
+	}
+	return int64(len(file.Text()))
+}
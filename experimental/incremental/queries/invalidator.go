@@ -0,0 +1,71 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queries
+
+import (
+	"github.com/bufbuild/protocompile/experimental/incremental"
+	"github.com/bufbuild/protocompile/experimental/source"
+)
+
+// Invalidator evicts [File] queries from an [incremental.Executor] when the
+// files served by a [source.Watcher] change.
+//
+// This includes files that were deleted, and files that did not exist when
+// they were last opened but which have since been created, so that queries
+// which failed because of a missing file are re-executed once it appears.
+type Invalidator struct {
+	Executor *incremental.Executor
+	Watcher  *source.Watcher
+
+	// The Opener used in the queries to evict. This is usually an Opener
+	// that delegates to Watcher, such as a [source.Openers]. If nil, Watcher
+	// is used.
+	Opener source.Opener
+}
+
+// Poll checks all of the files the watcher has opened for changes, evicts the
+// corresponding queries, and returns the paths of the files that changed.
+//
+// See [source.Watcher.Poll].
+func (i *Invalidator) Poll() []string {
+	return i.evict(i.Watcher.Poll())
+}
+
+// Notify is like [Invalidator.Poll], but only checks the given paths, such
+// as those reported by a filesystem notification.
+//
+// See [source.Watcher.Check].
+func (i *Invalidator) Notify(paths ...string) []string {
+	return i.evict(i.Watcher.Check(paths...))
+}
+
+// evict evicts the File queries for the given paths.
+func (i *Invalidator) evict(paths []string) []string {
+	if len(paths) == 0 {
+		return nil
+	}
+
+	opener := i.Opener
+	if opener == nil {
+		opener = i.Watcher
+	}
+
+	keys := make([]any, len(paths))
+	for j, path := range paths {
+		keys[j] = fileKey(opener, path)
+	}
+	i.Executor.Evict(keys...)
+	return paths
+}
@@ -0,0 +1,81 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queries_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bufbuild/protocompile/experimental/incremental"
+	"github.com/bufbuild/protocompile/experimental/incremental/queries"
+	"github.com/bufbuild/protocompile/experimental/ir"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/source"
+)
+
+func TestInvalidator(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	write := func(path, text string) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(text), 0o600))
+	}
+	write("a.proto", "syntax = \"proto3\";\npackage p;\nimport \"b.proto\";\nmessage A { B b = 1; }\n")
+
+	exec := incremental.New()
+	watcher := &source.Watcher{FS: os.DirFS(dir)}
+	opener := &source.Openers{watcher, source.WKTs()}
+	invalidator := &queries.Invalidator{Executor: exec, Watcher: watcher, Opener: opener}
+	query := queries.Link{
+		Opener:    opener,
+		Session:   new(ir.Session),
+		Workspace: source.NewWorkspace("a.proto"),
+	}
+
+	errors := func() (messages []string) {
+		_, r, err := incremental.Run(t.Context(), exec, query)
+		require.NoError(t, err)
+		for _, d := range r.Diagnostics {
+			if d.Level() <= report.Error {
+				messages = append(messages, d.Message())
+			}
+		}
+		return messages
+	}
+
+	missing := []string{"imported file does not exist", "cannot find `B` in this scope"}
+	assert.Equal(t, missing, errors())
+	assert.Empty(t, invalidator.Poll())
+
+	// Creating the missing import makes it resolve.
+	write("b.proto", "syntax = \"proto3\";\npackage p;\nmessage B {}\n")
+	assert.Equal(t, []string{"b.proto"}, invalidator.Poll())
+	assert.Empty(t, errors())
+
+	// Editing it so that it no longer defines B is noticed by Notify.
+	write("b.proto", "syntax = \"proto3\";\npackage p;\nmessage C {}\n")
+	assert.Empty(t, invalidator.Notify("a.proto"))
+	assert.Equal(t, []string{"b.proto"}, invalidator.Notify("a.proto", "b.proto"))
+	assert.Equal(t, []string{"cannot find `B` in this scope"}, errors())
+
+	// Deleting it brings back the original error.
+	require.NoError(t, os.Remove(filepath.Join(dir, "b.proto")))
+	assert.Equal(t, []string{"b.proto"}, invalidator.Poll())
+	assert.Equal(t, missing, errors())
+}
@@ -72,6 +72,8 @@ func (l Link) Execute(t *incremental.Task) ([]*ir.File, error) {
 
 	// Symbols are already deduplicated among imported files during the IR queries.
 	ir.DedupExportedSymbols(t.Report(), files...)
+	// Unknown symbols may be defined in another file of the workspace.
+	ir.SuggestImports(t.Report(), files...)
 	// Extension numbers are not deduped among imports during the IR queries, so all imported
 	// files are added to this check. We avoid adding duplicated imported files.
 	seen := make(map[string]*ir.File, len(files))
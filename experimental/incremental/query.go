@@ -50,6 +50,54 @@ type Query[T any] interface {
 	Execute(*Task) (value T, fatal error)
 }
 
+// EqualQuery is a [Query] that can compare two of its outputs for equality.
+//
+// When a query implementing this interface is recomputed after an eviction
+// (either because it was evicted directly or because one of its dependencies
+// was), the [Executor] compares the new output with the previous one. If they
+// are equal, the result is marked as unchanged, which allows the executor to
+// reuse the memoized results of any queries that depend on it, instead of
+// re-executing them. This is sometimes called "early cutoff".
+//
+// Equal is only called when neither output had a fatal error. It must be a
+// pure function of its arguments, and should be considerably cheaper than
+// the dependents whose execution it avoids. Queries whose outputs are large
+// may wish to compare a precomputed fingerprint instead.
+type EqualQuery[T any] interface {
+	Query[T]
+
+	// Equal returns whether a and b are equivalent outputs of this query.
+	Equal(a, b T) bool
+}
+
+// SizedQuery is a [Query] that can estimate how much memory its outputs use.
+//
+// This is used by [Executor]s configured with [WithMaxBytes] to decide when
+// memoized results should be evicted. Queries that do not implement this
+// interface are treated as having size zero.
+type SizedQuery[T any] interface {
+	Query[T]
+
+	// Size returns the approximate size of value, in bytes. This should
+	// include memory reachable from value that is not shared with the outputs
+	// of other queries.
+	Size(value T) int64
+}
+
+// PinnedQuery is a [Query] which may ask to never be evicted by an
+// [Executor]'s memory limits.
+//
+// Pinned queries can still be evicted explicitly with [Executor.Evict]. Note
+// that pinning a query also keeps all of its dependencies alive, since
+// evicting a dependency requires evicting everything that depends on it.
+type PinnedQuery[T any] interface {
+	Query[T]
+
+	// Pinned returns whether this query should be exempt from eviction due to
+	// memory limits.
+	Pinned() bool
+}
+
 // ErrCycle is an error due to cyclic dependencies.
 type ErrCycle = cycle.Error[*AnyQuery]
 
@@ -87,10 +135,16 @@ func (q ZeroQuery[T]) Execute(_ *Task) (T, error) {
 	return zero, nil
 }
 
+// Equal implements [EqualQuery].
+func (q ZeroQuery[T]) Equal(_, _ T) bool { return true }
+
 // AnyQuery is a [Query] that has been type-erased.
 type AnyQuery struct {
 	actual, key any
 	execute     func(*Task) (any, error)
+	equal       func(a, b any) bool // Nil if actual is not an EqualQuery.
+	size        func(any) int64     // Nil if actual is not a SizedQuery.
+	pinned      bool
 }
 
 // AsAny type-erases a [Query].
@@ -108,11 +162,30 @@ func AsAny[T any](q Query[T]) *AnyQuery {
 		return q
 	}
 
-	return &AnyQuery{
+	erased := &AnyQuery{
 		actual:  q,
 		key:     q.Key(),
 		execute: func(t *Task) (any, error) { return q.Execute(t) },
 	}
+	if eq, ok := q.(EqualQuery[T]); ok {
+		erased.equal = func(a, b any) bool {
+			// Values may be nil if T is an interface type, so we can't use
+			// an unchecked type assertion here.
+			x, _ := a.(T)
+			y, _ := b.(T)
+			return eq.Equal(x, y)
+		}
+	}
+	if sq, ok := q.(SizedQuery[T]); ok {
+		erased.size = func(v any) int64 {
+			x, _ := v.(T)
+			return sq.Size(x)
+		}
+	}
+	if pq, ok := q.(PinnedQuery[T]); ok {
+		erased.pinned = pq.Pinned()
+	}
+	return erased
 }
 
 // Underlying returns the original, non-AnyQuery query this query was
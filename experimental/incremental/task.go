@@ -331,6 +331,16 @@ type task struct {
 	// task is pending.
 	result atomic.Pointer[result]
 	report report.Report
+
+	// Book-keeping for [Executor.Trim]. used is the value of the executor's
+	// clock when this task was last accessed, and size is the size of its
+	// result as reported by [SizedQuery].
+	used atomic.Uint64
+	size int64
+
+	// The number of times this task's result was requested after it was
+	// started. See [Executor.Graph].
+	hits atomic.Uint64
 }
 
 // Results wraps a sequence of [Result]s and provides some convenience methods for
@@ -371,6 +381,9 @@ type Result[T any] struct {
 	// cached result (provided by the caller of [Run] in some way) and the value
 	// of [Changed] to only perform a partial mutation instead of a complete
 	// merge of the queries.
+	//
+	// If the query implements [EqualQuery], this is false when the query was
+	// recomputed but produced a value equal to its previous one.
 	Changed bool
 
 	// How long calculating this query took, excluding any queries it executed.
@@ -385,6 +398,9 @@ type result struct {
 	// computed. If it is equal to the ID of the current Run, it was computed
 	// during the current call. Otherwise, it is cached from a previous Run.
 	//
+	// If this result was cut off early (see [EqualQuery]), this is instead the
+	// ID of the Run that computed the equivalent prior result.
+	//
 	// Proof of correctness. As long as any Runs are ongoing, it is not possible
 	// for queries to be evicted, so once a query is calculated, its runID is
 	// fixed. Suppose two Runs race the same query. One of them will win as the
@@ -399,6 +415,27 @@ type result struct {
 	done  chan struct{}
 }
 
+// prior is the result of an evicted task, retained for early cutoff.
+type prior struct {
+	Result[any]
+
+	runID       uint64
+	diagnostics []report.Diagnostic
+
+	// The dependencies of the evicted task, and the ID of the last Run before
+	// it was evicted. Any dependency whose result's runID is not greater than
+	// verified has not changed since this result was computed.
+	deps     []*AnyQuery
+	verified uint64
+
+	// Whether this result may be reused as-is if none of its dependencies have
+	// changed. This is false for tasks that were evicted directly.
+	reusable bool
+
+	// The size of the result, for the purposes of [WithMaxBytes].
+	size int64
+}
+
 // start executes a query in the context of some task and records the result by
 // calling done.
 //
@@ -410,6 +447,8 @@ func (t *task) start(caller *Task, q *AnyQuery, sync bool, done func(*result)) (
 	if r != nil && closed(r.done) {
 		caller.logf("cache hit", "%[1]T/%[1]v", q.Underlying())
 		caller.timer.record(q.Key(), 0)
+		t.used.Store(caller.exec.clock.Add(1))
+		t.hits.Add(1)
 		done(r)
 		return false
 	}
@@ -477,6 +516,7 @@ func (t *task) run(caller *Task, q *AnyQuery, async bool) (output *result) {
 	output = t.result.Load()
 	if output != nil {
 		if closed(output.done) {
+			t.hits.Add(1)
 			return output
 		}
 		return t.waitUntilDone(caller, output, q, async)
@@ -538,6 +578,7 @@ func (t *task) run(caller *Task, q *AnyQuery, async bool) (output *result) {
 
 		if output != nil && !closed(output.done) {
 			callee.logf("done", "%[1]T/%[1]v", q.Underlying())
+			callee.exec.retain(t, output)
 			close(output.done)
 		}
 	}()
@@ -554,19 +595,68 @@ func (t *task) run(caller *Task, q *AnyQuery, async bool) (output *result) {
 		defer caller.transferFrom(callee)
 	}
 
+	prev := callee.exec.takePrior(q.Key())
+	if prev != nil && prev.reusable && callee.unchanged(prev) {
+		callee.logf("reusing", "%[1]T/%[1]v", q.Underlying())
+		output.Result = prev.Result
+		output.runID = prev.runID
+		t.report.Diagnostics = prev.diagnostics
+		callee.timer.record(q.Key(), 0)
+		return output
+	}
+
 	callee.logf("executing", "%[1]T/%[1]v", q.Underlying())
+	callee.stopwatch.Reset()
 	callee.stopwatch.Start()
 	output.Value, output.Fatal = t.query.Execute(callee)
 	output.Elapsed = callee.stopwatch.Stop()
 	output.runID = callee.runID
+	if prev != nil && t.query.equal != nil &&
+		prev.Fatal == nil && output.Fatal == nil &&
+		t.query.equal(prev.Value, output.Value) {
+		callee.logf("unchanged", "%[1]T/%[1]v", q.Underlying())
+		output.runID = prev.runID
+	}
 	callee.timer.record(q.Key(), output.Elapsed)
 	callee.logf("returning", "%[1]T/%[1]v, took %v", q.Underlying(), output.Elapsed)
 
 	return output
 }
 
+// unchanged resolves the dependencies of an evicted task, and returns whether
+// none of them have changed since prev was computed, in which case prev may be
+// reused as the result of this task.
+//
+// If this returns false, the dependency edges added while checking are
+// removed, since re-executing the query may depend on different queries.
+func (t *Task) unchanged(prev *prior) bool {
+	_, err := Resolve(t, slicesx.Transform(prev.deps, func(q *AnyQuery) Query[any] { return q })...)
+	ok := err == nil
+	for _, q := range prev.deps {
+		if !ok {
+			break
+		}
+		dep, found := t.exec.getTask(q.Key())
+		if !found {
+			ok = false
+			break
+		}
+		r := dep.result.Load()
+		ok = r != nil && closed(r.done) && r.runID <= prev.verified
+	}
+
+	if !ok {
+		for k := range t.task.deps.Range {
+			k.(*task).callers.Delete(t.task) //nolint:errcheck
+		}
+		t.task.deps.Clear()
+	}
+	return ok
+}
+
 // waitUntilDone waits for this task to be completed by another goroutine.
 func (t *task) waitUntilDone(caller *Task, output *result, q *AnyQuery, async bool) *result {
+	t.hits.Add(1)
 	if err := t.checkCycle(caller, q); err != nil {
 		output.Fatal = err
 		return output
@@ -599,6 +689,20 @@ func (t *task) waitUntilDone(caller *Task, output *result, q *AnyQuery, async bo
 	return t.result.Load()
 }
 
+// evictable returns whether this task is a candidate for [Executor.Trim].
+func (t *task) evictable() bool {
+	if t.query.pinned {
+		return false
+	}
+	if r := t.result.Load(); r == nil || !closed(r.done) {
+		return false
+	}
+	for range t.callers.Range {
+		return false
+	}
+	return true
+}
+
 // underlying returns the tasks query underlying key.
 func (t *task) underlying() any {
 	if t != nil {
@@ -0,0 +1,173 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package incremental_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bufbuild/protocompile/experimental/incremental"
+)
+
+func TestTrimEntries(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ctx := t.Context()
+	exec := incremental.New(
+		incremental.WithParallelism(4),
+		incremental.WithMaxEntries(4),
+	)
+
+	_, _, err := incremental.Run(ctx, exec, Sum{"1,2"})
+	require.NoError(t, err)
+	assert.Equal([]string{
+		`incremental_test.ParseInt{Input:"1"}`,
+		`incremental_test.ParseInt{Input:"2"}`,
+		`incremental_test.Root{}`,
+		`incremental_test.Sum{Input:"1,2"}`,
+	}, exec.Keys())
+
+	// The older sum is evicted first, followed by one of its dependencies,
+	// which are not depended on by anything else. Root is never evicted,
+	// because the newer sum depends on it.
+	result, _, err := incremental.Run(ctx, exec, Sum{"3"})
+	require.NoError(t, err)
+	assert.Equal(3, result[0].Value)
+
+	keys := exec.Keys()
+	assert.Len(keys, 4)
+	assert.Subset(keys, []string{
+		`incremental_test.ParseInt{Input:"3"}`,
+		`incremental_test.Root{}`,
+		`incremental_test.Sum{Input:"3"}`,
+	})
+	assert.NotContains(keys, `incremental_test.Sum{Input:"1,2"}`)
+}
+
+func TestTrimBytes(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ctx := t.Context()
+	exec := incremental.New(
+		incremental.WithMaxBytes(15),
+	)
+
+	run := func(b Blob) {
+		_, _, err := incremental.Run(ctx, exec, b)
+		require.NoError(t, err)
+	}
+
+	run(Blob{Name: "a", Bytes: 10})
+	assert.Equal([]string{`"a"`}, exec.Keys())
+
+	run(Blob{Name: "b", Bytes: 10, Pin: true})
+	assert.Equal([]string{`"b"`}, exec.Keys())
+
+	// The only candidate for eviction is the query we just ran.
+	run(Blob{Name: "c", Bytes: 10})
+	assert.Equal([]string{`"b"`}, exec.Keys())
+
+	run(Blob{Name: "d", Bytes: 5})
+	assert.Equal([]string{`"b"`, `"d"`}, exec.Keys())
+
+	// Explicit eviction ignores pinning.
+	exec.Evict("b")
+	assert.Equal([]string{`"d"`}, exec.Keys())
+}
+
+func TestTrimPriors(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ctx := t.Context()
+	exec := incremental.New(
+		incremental.WithMaxEntries(3),
+	)
+
+	cell := &Mutable{Value: 2}
+	_, _, err := incremental.Run(ctx, exec, Describe{cell})
+	require.NoError(t, err)
+	assert.Equal(int32(1), cell.describes.Load())
+
+	// Evicting the cell retains prior results for Parity and Describe, which
+	// count towards the limit, so running unrelated queries drops them
+	// instead of evicting anything else.
+	exec.Evict(Cell{cell})
+	assert.Empty(exec.Keys())
+
+	for _, name := range []string{"a", "b", "c"} {
+		_, _, err = incremental.Run(ctx, exec, Blob{Name: name})
+		require.NoError(t, err)
+	}
+	assert.Equal([]string{`"a"`, `"b"`, `"c"`}, exec.Keys())
+
+	// With its prior result gone, Describe must be re-executed.
+	results, _, err := incremental.Run(ctx, exec, Describe{cell})
+	require.NoError(t, err)
+	assert.Equal("even", results[0].Value)
+	assert.Equal(int32(2), cell.describes.Load())
+}
+
+func TestTrimPriorsWithoutLimits(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	ctx := t.Context()
+	exec := incremental.New()
+
+	cell := &Mutable{Value: 2}
+	_, _, err := incremental.Run(ctx, exec, Describe{cell})
+	require.NoError(t, err)
+
+	// Without limits, running other queries keeps the prior results retained
+	// by evicting the cell, but an explicit Trim drops them.
+	exec.Evict(Cell{cell})
+	_, _, err = incremental.Run(ctx, exec, Blob{Name: "a", Bytes: 1 << 20})
+	require.NoError(t, err)
+	exec.Trim()
+	assert.Equal([]string{`"a"`}, exec.Keys())
+
+	results, _, err := incremental.Run(ctx, exec, Describe{cell})
+	require.NoError(t, err)
+	assert.Equal("even", results[0].Value)
+	assert.Equal(int32(2), cell.describes.Load())
+}
+
+// Blob is a query which produces a value of a particular size.
+type Blob struct {
+	Name  string
+	Bytes int64
+	Pin   bool
+}
+
+func (b Blob) Key() any {
+	return b.Name
+}
+
+func (b Blob) Execute(_ *incremental.Task) (int64, error) {
+	return b.Bytes, nil
+}
+
+func (Blob) Size(v int64) int64 {
+	return v
+}
+
+func (b Blob) Pinned() bool {
+	return b.Pin
+}
@@ -43,11 +43,12 @@ type ToProtoOptions struct {
 // ToProto converts this AST into a Protobuf representation, which may be
 // serialized.
 //
-// Note that package ast does not support deserialization from this proto;
-// instead, you will need to re-parse the text file included in the message.
-// This is because the AST is much richer than what is stored in this message;
-// the message only provides enough information for further semantic analysis
-// and diagnostic generation, but not for pretty-printing.
+// Note that the AST is much richer than what is stored in this message; the
+// message only provides enough information for further semantic analysis and
+// diagnostic generation, but not for pretty-printing. To deserialize it, use
+// [github.com/bufbuild/protocompile/experimental/parser.Decode], which
+// relexes the text file and uses the spans in the message to recover the
+// rest of the AST. This requires that spans not be omitted.
 //
 // Panics if the AST contains a cycle (e.g. a message that contains itself as
 // a nested message). Parsed ASTs will never contain cycles, but users may
@@ -98,7 +99,7 @@ func (c *protoEncoder) checkCycle(v source.Spanner) func() {
 		if c.stackMap != nil {
 			delete(c.stackMap, v)
 		} else {
-			c.stack = c.stack[len(c.stack)-1:]
+			c.stack = c.stack[:len(c.stack)-1]
 		}
 	}
 }
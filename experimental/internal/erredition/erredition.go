@@ -20,6 +20,7 @@ import (
 	"github.com/bufbuild/protocompile/experimental/ast"
 	"github.com/bufbuild/protocompile/experimental/ast/syntax"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/source"
 )
 
@@ -41,6 +42,7 @@ func (e TooOld) Diagnose(d *report.Diagnostic) {
 
 	d.Apply(
 		report.Message("`%s` is not supported in %s", e.What, e.Current.Name()),
+		report.Tag(rtags.RequiresNewerEdition),
 		report.Snippet(e.Where),
 		report.Snippetf(e.Decl.Value(), "%s specified here", kind),
 	)
@@ -69,13 +71,14 @@ func (e TooNew) Diagnose(d *report.Diagnostic) {
 		kind = "edition"
 	}
 
-	err := "not supported"
+	err, tag := "not supported", rtags.RemovedInEdition
 	if !e.isRemoved() {
-		err = "deprecated"
+		err, tag = "deprecated", rtags.DeprecatedInEdition
 	}
 
 	d.Apply(
 		report.Message("`%s` is %s in %s", e.What, err, e.Current.Name()),
+		report.Tag(tag),
 		report.Snippet(e.Where),
 		report.Snippetf(e.Decl.Value(), "%s specified here", kind),
 	)
@@ -16,6 +16,7 @@ package errtoken
 
 import (
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/source"
 	"github.com/bufbuild/protocompile/experimental/token/keyword"
 )
@@ -36,7 +37,10 @@ type Unmatched struct {
 
 // Diagnose implements [report.Diagnose].
 func (e Unmatched) Diagnose(d *report.Diagnostic) {
-	d.Apply(report.Message("encountered unmatched `%s` delimiter", e.Span.Text()))
+	d.Apply(
+		report.Message("encountered unmatched `%s` delimiter", e.Span.Text()),
+		report.Tag(rtags.UnmatchedDelimiter),
+	)
 
 	left, right, _ := e.Keyword.Brackets()
 
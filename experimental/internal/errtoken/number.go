@@ -19,6 +19,7 @@ import (
 
 	"github.com/bufbuild/protocompile/experimental/internal/taxa"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/source"
 	"github.com/bufbuild/protocompile/experimental/token"
 	"github.com/bufbuild/protocompile/internal/ext/unicodex"
@@ -31,6 +32,8 @@ type InvalidNumber struct {
 
 // Diagnose implements [report.Diagnose].
 func (e InvalidNumber) Diagnose(d *report.Diagnostic) {
+	d.Apply(report.Tag(rtags.InvalidNumber))
+
 	// Check for an extra decimal point in the mantissa.
 	mant := e.Token.AsNumber().Mantissa()
 	first := strings.Index(mant.Text(), ".")
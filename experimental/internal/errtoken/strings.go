@@ -23,6 +23,7 @@ import (
 
 	"github.com/bufbuild/protocompile/experimental/internal/taxa"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/source"
 	"github.com/bufbuild/protocompile/experimental/token"
 )
@@ -40,6 +41,7 @@ func (e ImpureString) Diagnose(d *report.Diagnostic) {
 	quote := e.Token.Text()[0]
 	d.Apply(
 		report.Message("non-canonical string literal %s", e.Where.String()),
+		report.Tag(rtags.NonCanonicalLiteral),
 		report.Snippet(e.Token),
 		report.SuggestEdits(e.Token, "replace it with a canonical string", report.Edit{
 			Start: 0, End: e.Token.Span().Len(),
@@ -65,7 +67,10 @@ type InvalidEscape struct {
 
 // Diagnose implements [report.Diagnose].
 func (e InvalidEscape) Diagnose(d *report.Diagnostic) {
-	d.Apply(report.Message("invalid escape sequence"))
+	d.Apply(
+		report.Message("invalid escape sequence"),
+		report.Tag(rtags.InvalidEscape),
+	)
 
 	text := e.Span.Text()
 
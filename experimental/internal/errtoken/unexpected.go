@@ -20,6 +20,7 @@ import (
 	"github.com/bufbuild/protocompile/experimental/internal/just"
 	"github.com/bufbuild/protocompile/experimental/internal/taxa"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/source"
 	"github.com/bufbuild/protocompile/experimental/token"
 	"github.com/bufbuild/protocompile/internal/ext/iterx"
@@ -94,6 +95,7 @@ func (e Unexpected) Diagnose(d *report.Diagnostic) {
 
 	d.Apply(
 		report.Message("%v", message),
+		report.Tag(rtags.SyntaxError),
 		snippet,
 		report.Snippetf(e.Prev, "previous %v is here", e.Where.Subject()),
 	)
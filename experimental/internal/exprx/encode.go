@@ -254,11 +254,11 @@ func (c *protoEncoder) op(e expr.Op) *exprpb.Op {
 
 	case keyword.Comma:
 		op = exprpb.Op_COMMA
-	case keyword.Or:
+	case keyword.Or, keyword.Pipes:
 		op = exprpb.Op_OR
-	case keyword.And:
+	case keyword.And, keyword.Amps:
 		op = exprpb.Op_AND
-	case keyword.Not:
+	case keyword.Not, keyword.Bang:
 		op = exprpb.Op_NOT
 
 	case keyword.Eq:
@@ -21,6 +21,7 @@ import (
 	"unicode/utf8"
 
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/source"
 	"github.com/bufbuild/protocompile/experimental/token"
 	"github.com/bufbuild/protocompile/experimental/token/keyword"
@@ -97,7 +98,26 @@ type Lexer struct {
 // Lex runs lexical analysis on file and returns a new token stream as a result.
 func (l *Lexer) Lex(file *source.File, r *report.Report) *token.Stream {
 	stream := &token.Stream{File: file}
-	loop(&lexer{Lexer: l, Stream: stream, Report: r})
+	loop(&lexer{Lexer: l, Stream: stream, Report: r, end: len(file.Text())})
+	return stream
+}
+
+// LexSpan is like [Lexer.Lex], but only lexes the text within span. This is
+// useful for lexing a language embedded in another, such as CEL inside of a
+// Protobuf string literal.
+//
+// The returned stream is over the whole file span belongs to, so its tokens'
+// spans are spans within that file. The text before and after span is
+// represented by a single [token.Space] on either side.
+func (l *Lexer) LexSpan(span source.Span, r *report.Report) *token.Stream {
+	stream := &token.Stream{File: span.File}
+	if span.Start > 0 {
+		stream.Push(span.Start, token.Space)
+	}
+	loop(&lexer{Lexer: l, Stream: stream, Report: r, cursor: span.Start, end: span.End})
+	if rest := len(span.File.Text()) - span.End; rest > 0 {
+		stream.Push(rest, token.Space)
+	}
 	return stream
 }
 
@@ -108,6 +128,7 @@ type lexer struct {
 	*report.Report
 
 	cursor, count int
+	end           int // The offset at which to stop lexing.
 	braces        []token.ID
 	scratch       []byte
 	scratchInt    big.Int
@@ -130,6 +151,7 @@ func (l *lexer) keyword(length int, kind token.Kind, kw keyword.Keyword) token.T
 		l.badBytes = 0
 
 		l.Errorf("unrecognized token").Apply(
+			report.Tag(rtags.UnrecognizedToken),
 			report.Snippet(tok),
 		)
 	}
@@ -140,7 +162,7 @@ func (l *lexer) keyword(length int, kind token.Kind, kw keyword.Keyword) token.T
 
 // rest returns the remaining unlexed text.
 func (l *lexer) rest() string {
-	return l.Text()[l.cursor:]
+	return l.Text()[l.cursor:l.end]
 }
 
 // done returns whether or not we're done lexing runes.
@@ -24,6 +24,7 @@ import (
 	"github.com/bufbuild/protocompile/experimental/internal/errtoken"
 	"github.com/bufbuild/protocompile/experimental/internal/tokenmeta"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/token"
 	"github.com/bufbuild/protocompile/experimental/token/keyword"
 	"github.com/bufbuild/protocompile/internal/ext/stringsx"
@@ -39,7 +40,7 @@ func loop(l *lexer) {
 		)
 	})
 
-	if !lexPrelude(l) {
+	if l.cursor == 0 && !lexPrelude(l) {
 		return
 	}
 
@@ -210,6 +211,7 @@ func loop(l *lexer) {
 				// characters (e.g. combining marks).
 				tok := l.push(len(rawIdent), token.Unrecognized)
 				l.Errorf("unrecognized token").Apply(
+					report.Tag(rtags.UnrecognizedToken),
 					report.Snippet(tok),
 					report.Debugf("%v, %v, %q", tok.ID(), tok.Span(), tok.Text()),
 				)
@@ -233,6 +235,7 @@ func loop(l *lexer) {
 			// Legalize non-ASCII runes.
 			if l.RequireASCIIIdent && !unicodex.IsASCIIIdent(tok.Text()) {
 				l.Errorf("non-ASCII identifiers are not allowed").Apply(
+					report.Tag(rtags.NonASCIIIdent),
 					report.Snippet(tok),
 				)
 			}
@@ -264,6 +267,7 @@ func lexPrelude(l *lexer) bool {
 	// the case.
 	if len(l.Text()) > MaxFileSize {
 		l.Errorf("files larger than 2GB (%d bytes) are not supported", MaxFileSize).Apply(
+			report.Tag(rtags.FileTooLarge),
 			report.InFile(l.Path()),
 		)
 		return false
@@ -280,6 +284,7 @@ func lexPrelude(l *lexer) bool {
 	ascii16 := len(l.Text()) >= 2 && (l.Text()[0] == 0 || l.Text()[1] == 0)
 	if bom16 || ascii16 {
 		l.Errorf("input appears to be encoded with UTF-16").Apply(
+			report.Tag(rtags.InvalidEncoding),
 			report.InFile(l.Path()),
 			report.Notef("Protobuf files must be UTF-8 encoded"),
 		)
@@ -306,6 +311,7 @@ func lexPrelude(l *lexer) bool {
 		// This diagnostic is for cases where this file appears to be corrupt.
 		// We pick 20% non-UTF-8 as the threshold to show this error.
 		l.Errorf("input appears to be encoded with UTF-8, but found invalid byte").Apply(
+			report.Tag(rtags.InvalidEncoding),
 			report.Snippet(l.Span(idx, idx+1)),
 			report.Notef("non-UTF-8 byte occurs at offset %d (%#x)", idx, idx),
 			report.Notef("Protobuf files must be UTF-8 encoded"),
@@ -313,6 +319,7 @@ func lexPrelude(l *lexer) bool {
 		return false
 	default:
 		l.Errorf("input appears to be a binary file").Apply(
+			report.Tag(rtags.InvalidEncoding),
 			report.InFile(l.Path()),
 			report.Notef("non-UTF-8 byte occurs at offset %d (%#x)", idx, idx),
 			report.Notef("Protobuf files must be UTF-8 encoded"),
@@ -473,6 +480,7 @@ func fuseStrings(l *lexer) {
 
 				if !prefix.IsZero() && overall.Text() != prefix.Text() {
 					l.Errorf("implicitly-concatenated string has incompatible prefix").Apply(
+						report.Tag(rtags.IncompatibleStringPrefix),
 						report.Snippet(prefix),
 						report.Snippetf(overall, "must match this prefix"),
 					)
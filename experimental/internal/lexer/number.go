@@ -219,10 +219,11 @@ func lexNumber(l *lexer) token.Token {
 	case result.big != nil:
 		token.MutateMeta[tokenmeta.Number](tok).Big = new(decimal.Decimal).ReuseInt(result.big)
 
-	case base == 10 && !result.hasThousands:
+	case base == 10 && !result.hasThousands && suffix == "":
 		// We explicitly do not call SetValue for the most common case of base
 		// 10 integers, because that is handled for us on-demand in AsInt. This
-		// is a memory consumption optimization.
+		// is a memory consumption optimization. This does not apply to
+		// integers with a suffix, which already have metadata.
 
 	default:
 		token.MutateMeta[tokenmeta.Number](tok).Word = result.small
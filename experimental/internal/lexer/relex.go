@@ -0,0 +1,98 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lexer
+
+import (
+	"unicode/utf8"
+
+	"github.com/bufbuild/protocompile/experimental/id"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/source"
+	"github.com/bufbuild/protocompile/experimental/token"
+	"github.com/bufbuild/protocompile/experimental/token/keyword"
+	"github.com/bufbuild/protocompile/internal/ext/slicesx"
+)
+
+// Relex updates a stream to account for an edit to its text.
+//
+// stream must have been produced by l.Lex without diagnostics, and file must
+// be the result of applying edit to stream's text. Rather than lexing all of
+// file, Relex finds the innermost bracket-delimited token tree whose contents
+// contain the edit, lexes just that tree, and splices it into a copy of
+// stream. The result is identical to calling l.Lex on file.
+//
+// Returns the tree in stream that was replaced. Returns false if the edit is
+// not inside of a tree, or if the text of the tree no longer lexes as a single
+// tree with the same delimiters and without diagnostics; in that case, the
+// caller should lex file from scratch instead.
+func (l *Lexer) Relex(stream *token.Stream, file *source.File, edit report.Edit) (*token.Stream, token.Token, bool) {
+	if edit.Start <= 0 || edit.Start > edit.End || edit.End > len(stream.Text()) ||
+		len(file.Text()) > MaxFileSize {
+		return nil, token.Zero, false
+	}
+
+	tree := enclosingTree(stream, edit)
+	if tree.IsZero() {
+		return nil, token.Zero, false
+	}
+
+	open, close := tree.StartEnd() //nolint:predeclared,revive // For close.
+	start := open.LeafSpan().Start
+	end := close.LeafSpan().End + len(file.Text()) - len(stream.Text())
+	if !utf8.ValidString(file.Text()[start:end]) {
+		// The prelude would have rejected the whole file.
+		return nil, token.Zero, false
+	}
+
+	r := new(report.Report)
+	with := l.LexSpan(file.Span(start, end), r)
+	if len(r.Diagnostics) > 0 {
+		return nil, token.Zero, false
+	}
+
+	spliced, ok := stream.Splice(file, tree, with)
+	if !ok {
+		return nil, token.Zero, false
+	}
+	return spliced, tree, true
+}
+
+// enclosingTree finds the innermost bracket-delimited token tree in stream
+// whose open and close tokens lie outside of edit.
+func enclosingTree(stream *token.Stream, edit report.Edit) token.Token {
+	tok, _ := stream.Around(edit.Start)
+	for !tok.IsZero() {
+		open, close := tok.StartEnd() //nolint:predeclared,revive // For close.
+		switch {
+		case tok.IsLeaf():
+		case tok == open:
+			// This is an opener whose closer is after the edit start.
+			if slicesx.Among(tok.Keyword(), keyword.Parens, keyword.Brackets, keyword.Braces) &&
+				open.LeafSpan().End <= edit.Start &&
+				close.LeafSpan().Start >= edit.End {
+				return tok
+			}
+		default:
+			// This is a closer before the edit; skip over its tree.
+			tok = open
+		}
+
+		if tok.ID() == 1 {
+			break
+		}
+		tok = id.Wrap(stream, tok.ID()-1)
+	}
+	return token.Zero
+}
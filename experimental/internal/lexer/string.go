@@ -22,6 +22,7 @@ import (
 	"github.com/bufbuild/protocompile/experimental/internal/errtoken"
 	"github.com/bufbuild/protocompile/experimental/internal/tokenmeta"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/source"
 	"github.com/bufbuild/protocompile/experimental/token"
 	"github.com/bufbuild/protocompile/internal/ext/unicodex"
@@ -108,6 +109,7 @@ func lexString(l *lexer, sigil string) {
 		}
 
 		l.Errorf("unterminated string literal").Apply(
+			report.Tag(rtags.UnterminatedString),
 			report.Snippetf(tok, "expected to be terminated by `%s`", quote),
 			note,
 		)
@@ -131,6 +133,7 @@ func lexStringContent(l *lexer) (sc stringContent) {
 	case r == 0:
 		esc := l.spanFrom(l.cursor - utf8.RuneLen(r))
 		l.Errorf("unescaped NUL bytes are not permitted in string literals").Apply(
+			report.Tag(rtags.InvalidStringChar),
 			report.Snippet(esc),
 			report.SuggestEdits(esc, "replace it with `\\0` or `\\x00`", report.Edit{
 				Start:   0,
@@ -150,6 +153,7 @@ func lexStringContent(l *lexer) (sc stringContent) {
 		// exactly C's).
 		nl := l.spanFrom(l.cursor - utf8.RuneLen(r))
 		l.Errorf("unescaped newlines are not permitted in string literals").Apply(
+			report.Tag(rtags.InvalidStringChar),
 			report.Snippet(nl),
 			report.Helpf("consider splitting this into adjacent string literals; Protobuf will automatically concatenate them"),
 		)
@@ -168,6 +172,7 @@ func lexStringContent(l *lexer) (sc stringContent) {
 
 		esc := l.spanFrom(l.cursor - utf8.RuneLen(r))
 		l.Warnf("non-printable character in string literal").Apply(
+			report.Tag(rtags.NonPrintableChar),
 			report.Snippet(esc),
 			report.SuggestEdits(esc, "consider escaping it", report.Edit{
 				Start:   0,
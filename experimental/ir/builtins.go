@@ -20,6 +20,7 @@ import (
 
 	"github.com/bufbuild/protocompile/experimental/id"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/internal/arena"
 	"github.com/bufbuild/protocompile/internal/intern"
 )
@@ -240,6 +241,7 @@ func resolveBuiltins(file *File, r *report.Report) {
 		if sym.Kind() != kind.kind {
 			if !isOptionalBuiltinField(tyField) {
 				r.Errorf("`%s` is missing required symbol `%s`", file.Path(), file.session.intern.Value(id)).Apply(
+					report.Tag(rtags.MissingBuiltin),
 					report.Snippet(file.AST()),
 					report.Helpf("the descriptor.proto supplied to the compiler does not declare this %s; "+
 						"it may be vendored from a version that predates this symbol, or may be genuinely corrupt", kind.kind.noun()),
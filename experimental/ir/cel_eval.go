@@ -0,0 +1,1317 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ir
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"google.golang.org/protobuf/reflect/protoreflect"
+
+	"github.com/bufbuild/protocompile/experimental/ast/predeclared"
+	"github.com/bufbuild/protocompile/experimental/expr"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/token"
+	"github.com/bufbuild/protocompile/experimental/token/keyword"
+)
+
+// EvalCEL evaluates a CEL expression, as produced by [expr.ParseCEL], with
+// the standard CEL semantics, plus the strings extension and protovalidate's
+// functions.
+//
+// vars binds the expression's variables, such as `this`. Values are
+// represented as Go values:
+//
+//   - null is nil.
+//   - bool, int, uint, double, string and bytes are bool, int64, uint64,
+//     float64, string and []byte. Other Go integer and floating-point types
+//     are accepted in vars.
+//   - google.protobuf.Duration and google.protobuf.Timestamp are
+//     [time.Duration] and [time.Time].
+//   - Lists are []any and maps are map[any]any.
+//   - Messages are [MessageValue]s or [protoreflect.Message]s. Unset message
+//     fields of a [MessageValue] evaluate to a zero [MessageValue].
+//   - Types, as produced by `type()`, are [CELType]s.
+//
+// Evaluation errors are returned as a [*CELError].
+func EvalCEL(e expr.Expr, vars map[string]any) (any, error) {
+	ev := &celEvaluator{vars: make([]celBinding, 0, len(vars))}
+	for name, value := range vars {
+		ev.vars = append(ev.vars, celBinding{name, celNormalize(value)})
+	}
+	return ev.eval(e)
+}
+
+// CELType is the value of a CEL type, such as the result of `type(x)`.
+type CELType string
+
+// CELError is an error that occurs while evaluating a CEL expression, such as
+// division by zero or a reference to a missing map key.
+type CELError struct {
+	Expr    expr.Expr // The subexpression whose evaluation failed.
+	Message string
+
+	unbound bool // Set if the error is due to an unbound variable.
+}
+
+// Error implements [error].
+func (e *CELError) Error() string {
+	return e.Message
+}
+
+// Diagnose implements [report.Diagnose].
+func (e *CELError) Diagnose(d *report.Diagnostic) {
+	d.Apply(
+		report.Message("%s", e.Message),
+		report.Snippet(e.Expr),
+		report.Tag(rtags.InvalidCEL),
+	)
+}
+
+// celBinding is a variable bound during evaluation.
+type celBinding struct {
+	name  string
+	value any
+}
+
+// celEvaluator is the state for evaluating a CEL expression.
+type celEvaluator struct {
+	vars []celBinding
+
+	// If not nil, resolves names that are not variables, such as enum values.
+	resolve func(name string) (any, bool)
+}
+
+// celObject is a message that CEL can select fields of.
+type celObject interface {
+	typeName() string
+	fieldNames() []string
+	// field returns the value of the field with the given name, and whether
+	// such a field exists.
+	field(name string) (any, bool)
+	// has returns whether the field with the given name is set, and whether
+	// such a field exists.
+	has(name string) (set, ok bool)
+	// message returns the message that was passed into the evaluator.
+	message() any
+}
+
+func (ev *celEvaluator) errorf(e expr.Expr, format string, args ...any) *CELError {
+	return &CELError{Expr: e, Message: fmt.Sprintf(format, args...)}
+}
+
+// eval evaluates an expression.
+func (ev *celEvaluator) eval(e expr.Expr) (any, error) {
+	v, err := ev.value(e)
+	if obj, ok := v.(celObject); ok {
+		v = obj.message()
+	}
+	return v, err
+}
+
+// value evaluates an expression, without converting messages back into the
+// representation used by [EvalCEL]'s callers.
+func (ev *celEvaluator) value(e expr.Expr) (any, error) {
+	switch e.Kind() {
+	case expr.KindToken:
+		return ev.token(e.AsToken())
+	case expr.KindOp:
+		return ev.op(e.AsOp())
+	case expr.KindCall:
+		return ev.call(e.AsCall())
+	case expr.KindRecord:
+		return ev.record(e.AsRecord())
+	default:
+		return nil, ev.errorf(e, "invalid expression")
+	}
+}
+
+// token evaluates a literal or identifier.
+func (ev *celEvaluator) token(tok expr.Token) (any, error) {
+	switch tok.Kind() {
+	case token.Number:
+		n := tok.AsNumber()
+		switch {
+		case n.IsFloat():
+			v, _ := n.Float()
+			return v, nil
+		case n.Suffix().Text() != "":
+			v, ok := n.Int()
+			if !ok {
+				return nil, ev.errorf(tok.AsAny(), "integer literal out of range")
+			}
+			return v, nil
+		default:
+			v, ok := n.Int()
+			if !ok || v > math.MaxInt64 {
+				return nil, ev.errorf(tok.AsAny(), "integer literal out of range")
+			}
+			return int64(v), nil
+		}
+
+	case token.String:
+		s := tok.AsString()
+		if strings.ContainsAny(s.Prefix().Text(), "bB") {
+			return []byte(s.Text()), nil
+		}
+		return s.Text(), nil
+	}
+
+	switch name := tok.Text(); name {
+	case "true", "false":
+		return name == "true", nil
+	case "null":
+		return nil, nil
+	default:
+		return ev.reference(tok.AsAny(), name)
+	}
+}
+
+// reference evaluates a possibly-qualified name.
+func (ev *celEvaluator) reference(e expr.Expr, name string) (any, error) {
+	for i := len(ev.vars) - 1; i >= 0; i-- {
+		if ev.vars[i].name == name {
+			return ev.vars[i].value, nil
+		}
+	}
+	if celTypeNames[name] {
+		return CELType(name), nil
+	}
+	if ev.resolve != nil {
+		if v, ok := ev.resolve(name); ok {
+			return v, nil
+		}
+	}
+
+	err := ev.errorf(e, "undeclared reference to `%s`", name)
+	err.unbound = true
+	return nil, err
+}
+
+// op evaluates an operator.
+func (ev *celEvaluator) op(op expr.Op) (any, error) {
+	switch op.Operator() {
+	case keyword.Dot:
+		return ev.selection(op)
+	case keyword.Ask:
+		return ev.conditional(op)
+	case keyword.Amps, keyword.Pipes:
+		return ev.logical(op)
+	}
+
+	if op.IsUnary() {
+		// Negative literals are folded, so that the most negative int can be
+		// written.
+		if tok := op.Right().AsToken(); op.Operator() == keyword.Sub &&
+			tok.Kind() == token.Number && !tok.AsNumber().IsFloat() && tok.AsNumber().Suffix().Text() == "" {
+			if v, ok := tok.AsNumber().Int(); ok && v == 1<<63 {
+				return int64(math.MinInt64), nil
+			}
+		}
+
+		v, err := ev.value(op.Right())
+		if err != nil {
+			return nil, err
+		}
+		switch v := v.(type) {
+		case bool:
+			if op.Operator() == keyword.Bang {
+				return !v, nil
+			}
+		case int64:
+			if op.Operator() == keyword.Sub {
+				if v == math.MinInt64 {
+					return nil, ev.errorf(op.AsAny(), "integer overflow")
+				}
+				return -v, nil
+			}
+		case float64:
+			if op.Operator() == keyword.Sub {
+				return -v, nil
+			}
+		}
+		return nil, ev.errorf(op.AsAny(), "no such overload: `%s%s`",
+			op.OperatorToken().Text(), celTypeOf(v))
+	}
+
+	l, err := ev.value(op.Left())
+	if err != nil {
+		return nil, err
+	}
+	r, err := ev.value(op.Right())
+	if err != nil {
+		return nil, err
+	}
+
+	switch op.Operator() {
+	case keyword.Eq:
+		return celEqual(l, r), nil
+	case keyword.Ne:
+		return !celEqual(l, r), nil
+	case keyword.Lt, keyword.Le, keyword.Gt, keyword.Ge:
+		c, ok := celCompare(l, r)
+		if !ok {
+			break
+		}
+		switch op.Operator() {
+		case keyword.Lt:
+			return c < 0, nil
+		case keyword.Le:
+			return c <= 0, nil
+		case keyword.Gt:
+			return c > 0, nil
+		default:
+			return c >= 0, nil
+		}
+	case keyword.In:
+		switch r := r.(type) {
+		case []any:
+			return slices.ContainsFunc(r, func(v any) bool { return celEqual(l, v) }), nil
+		case map[any]any:
+			_, ok := celMapGet(r, l)
+			return ok, nil
+		}
+	default:
+		v, err := celArith(op.Operator(), l, r)
+		if err != "" {
+			return nil, ev.errorf(op.AsAny(), "%s", err)
+		}
+		if v != nil {
+			return v, nil
+		}
+	}
+
+	return nil, ev.errorf(op.AsAny(), "no such overload: `%s %s %s`",
+		celTypeOf(l), op.OperatorToken().Text(), celTypeOf(r))
+}
+
+// logical evaluates `&&` and `||`, which are commutative with respect to
+// errors: `false && error` and `error && false` are both false.
+func (ev *celEvaluator) logical(op expr.Op) (any, error) {
+	short := op.Operator() == keyword.Pipes
+
+	var first error
+	for _, e := range []expr.Expr{op.Left(), op.Right()} {
+		v, err := ev.value(e)
+		if err == nil {
+			b, ok := v.(bool)
+			if !ok {
+				err = ev.errorf(e, "expected `bool`, found `%s`", celTypeOf(v))
+			} else if b == short {
+				return short, nil
+			}
+		}
+		if first == nil {
+			first = err
+		}
+	}
+	if first != nil {
+		return nil, first
+	}
+	return !short, nil
+}
+
+// conditional evaluates `a ? b : c`.
+func (ev *celEvaluator) conditional(op expr.Op) (any, error) {
+	v, err := ev.value(op.Left())
+	if err != nil {
+		return nil, err
+	}
+	cond, ok := v.(bool)
+	if !ok {
+		return nil, ev.errorf(op.Left(), "expected `bool`, found `%s`", celTypeOf(v))
+	}
+
+	branches := op.Right().AsOp()
+	if branches.IsZero() || branches.Operator() != keyword.Colon {
+		return nil, ev.errorf(op.Right(), "invalid expression")
+	}
+	if cond {
+		return ev.value(branches.Left())
+	}
+	return ev.value(branches.Right())
+}
+
+// selection evaluates a field selection or qualified name.
+func (ev *celEvaluator) selection(op expr.Op) (any, error) {
+	if name, first, ok := qualifiedName(op.AsAny()); ok && !ev.bound(first) {
+		return ev.reference(op.AsAny(), name)
+	}
+
+	v, err := ev.value(op.Left())
+	if err != nil {
+		return nil, err
+	}
+	name := op.Right().AsToken().Text()
+
+	switch v := v.(type) {
+	case celObject:
+		if field, ok := v.field(name); ok {
+			return field, nil
+		}
+		return nil, ev.errorf(op.AsAny(), "message `%s` has no field named `%s`", v.typeName(), name)
+	case map[any]any:
+		if value, ok := v[name]; ok {
+			return value, nil
+		}
+		return nil, ev.errorf(op.AsAny(), "no such key: `%s`", name)
+	}
+	return nil, ev.errorf(op.AsAny(), "cannot select field `%s` of `%s`", name, celTypeOf(v))
+}
+
+// bound returns whether a variable is bound.
+func (ev *celEvaluator) bound(name string) bool {
+	return slices.ContainsFunc(ev.vars, func(b celBinding) bool { return b.name == name })
+}
+
+// record evaluates a list or map literal.
+func (ev *celEvaluator) record(rec expr.Record) (any, error) {
+	entries := rec.Entries()
+	if rec.Brackets().Keyword() == keyword.Brackets {
+		list := make([]any, 0, entries.Len())
+		for i := range entries.Len() {
+			v, err := ev.value(entries.At(i).Expr)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, v)
+		}
+		return list, nil
+	}
+
+	m := make(map[any]any, entries.Len())
+	for i := range entries.Len() {
+		entry := entries.At(i)
+		k, err := ev.value(entry.Name)
+		if err != nil {
+			return nil, err
+		}
+		switch k.(type) {
+		case bool, int64, uint64, string:
+		default:
+			return nil, ev.errorf(entry.Name, "unsupported map key type `%s`", celTypeOf(k))
+		}
+		if _, ok := celMapGet(m, k); ok {
+			return nil, ev.errorf(entry.Name, "duplicate map key")
+		}
+		v, err := ev.value(entry.Expr)
+		if err != nil {
+			return nil, err
+		}
+		m[k] = v
+	}
+	return m, nil
+}
+
+// call evaluates a function call, index or message construction.
+func (ev *celEvaluator) call(call expr.Call) (any, error) {
+	switch call.Brackets().Keyword() {
+	case keyword.Brackets:
+		return ev.index(call)
+	case keyword.Braces:
+		return nil, ev.errorf(call.AsAny(), "message construction is not supported")
+	}
+
+	var (
+		name string
+		recv expr.Expr
+	)
+	switch callee := call.Callee(); callee.Kind() {
+	case expr.KindToken:
+		name = callee.AsToken().Text()
+	case expr.KindOp:
+		if op := callee.AsOp(); op.Operator() == keyword.Dot && !op.IsUnary() {
+			name, recv = op.Right().AsToken().Text(), op.Left()
+		}
+	}
+	if name == "" {
+		return nil, ev.errorf(call.Callee(), "expression is not a function")
+	}
+
+	if v, ok, err := ev.macro(call, name, recv); ok {
+		return v, err
+	}
+
+	if ns, first, ok := qualifiedName(recv); ok && !ev.bound(first) {
+		if _, ok := celFunctions[ns+"."+name]; ok {
+			name, recv = ns+"."+name, expr.Expr{}
+		}
+	}
+
+	var args []any
+	if !recv.IsZero() {
+		v, err := ev.value(recv)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+	for i := range call.Args().Len() {
+		v, err := ev.value(call.Args().At(i).Expr)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, v)
+	}
+
+	v, err := celCall(name, !recv.IsZero(), args)
+	if err != nil {
+		return nil, ev.errorf(call.AsAny(), "%s", err)
+	}
+	return v, nil
+}
+
+// index evaluates `a[b]`.
+func (ev *celEvaluator) index(call expr.Call) (any, error) {
+	v, err := ev.value(call.Callee())
+	if err != nil {
+		return nil, err
+	}
+	if call.Args().Len() != 1 {
+		return nil, ev.errorf(call.AsAny(), "invalid expression")
+	}
+	idx, err := ev.value(call.Args().At(0).Expr)
+	if err != nil {
+		return nil, err
+	}
+
+	switch v := v.(type) {
+	case []any:
+		var i int64
+		switch idx := idx.(type) {
+		case int64:
+			i = idx
+		case uint64:
+			i = int64(min(idx, math.MaxInt64))
+		case float64:
+			if idx != math.Trunc(idx) {
+				return nil, ev.errorf(call.AsAny(), "unsupported index `%v`", idx)
+			}
+			i = int64(idx)
+		default:
+			return nil, ev.errorf(call.AsAny(), "unsupported index type `%s`", celTypeOf(idx))
+		}
+		if i < 0 || i >= int64(len(v)) {
+			return nil, ev.errorf(call.AsAny(), "index out of range: %d", i)
+		}
+		return v[i], nil
+	case map[any]any:
+		if value, ok := celMapGet(v, idx); ok {
+			return value, nil
+		}
+		return nil, ev.errorf(call.AsAny(), "no such key: `%v`", celFormat(idx))
+	}
+	return nil, ev.errorf(call.AsAny(), "cannot index into `%s`", celTypeOf(v))
+}
+
+// macro evaluates one of CEL's macros. Returns false if this is not a call to
+// a macro.
+func (ev *celEvaluator) macro(call expr.Call, name string, recv expr.Expr) (any, bool, error) {
+	args := call.Args()
+	if recv.IsZero() {
+		if name != "has" || args.Len() != 1 {
+			return nil, false, nil
+		}
+
+		arg := args.At(0).Expr
+		op := arg.AsOp()
+		if op.IsZero() || op.Operator() != keyword.Dot || op.IsUnary() {
+			return nil, true, ev.errorf(arg, "argument to `has` must be a field selection")
+		}
+		v, err := ev.value(op.Left())
+		if err != nil {
+			return nil, true, err
+		}
+		field := op.Right().AsToken().Text()
+		switch v := v.(type) {
+		case celObject:
+			if set, ok := v.has(field); ok {
+				return set, true, nil
+			}
+			return nil, true, ev.errorf(arg, "message `%s` has no field named `%s`", v.typeName(), field)
+		case map[any]any:
+			_, ok := v[field]
+			return ok, true, nil
+		}
+		return nil, true, ev.errorf(arg, "cannot select field `%s` of `%s`", field, celTypeOf(v))
+	}
+
+	switch name {
+	case "all", "exists", "exists_one", "filter":
+		if args.Len() != 2 {
+			return nil, false, nil
+		}
+	case "map":
+		if args.Len() != 2 && args.Len() != 3 {
+			return nil, false, nil
+		}
+	default:
+		return nil, false, nil
+	}
+
+	v := args.At(0).Expr.AsToken()
+	if v.Kind() != token.Ident {
+		return nil, true, ev.errorf(args.At(0).Expr, "first argument to `%s` must be a variable name", name)
+	}
+
+	target, err := ev.value(recv)
+	if err != nil {
+		return nil, true, err
+	}
+	var elems []any
+	switch target := target.(type) {
+	case []any:
+		elems = target
+	case map[any]any:
+		for k := range target {
+			elems = append(elems, k)
+		}
+		slices.SortFunc(elems, func(a, b any) int {
+			c, _ := celCompare(a, b)
+			return c
+		})
+	default:
+		return nil, true, ev.errorf(recv, "`%s` requires a list or map, found `%s`", name, celTypeOf(target))
+	}
+
+	ev.vars = append(ev.vars, celBinding{name: v.Text()})
+	defer func() { ev.vars = ev.vars[:len(ev.vars)-1] }()
+	bind := func(elem any) { ev.vars[len(ev.vars)-1].value = elem }
+	test := func(e expr.Expr) (bool, error) {
+		v, err := ev.value(e)
+		if err != nil {
+			return false, err
+		}
+		b, ok := v.(bool)
+		if !ok {
+			return false, ev.errorf(e, "expected `bool`, found `%s`", celTypeOf(v))
+		}
+		return b, nil
+	}
+
+	pred := args.At(1).Expr
+	switch name {
+	case "all", "exists":
+		// Like `&&` and `||`, these are commutative with respect to errors.
+		short := name == "exists"
+		var first error
+		for _, elem := range elems {
+			bind(elem)
+			b, err := test(pred)
+			if err == nil && b == short {
+				return short, true, nil
+			}
+			if first == nil {
+				first = err
+			}
+		}
+		if first != nil {
+			return nil, true, first
+		}
+		return !short, true, nil
+
+	case "exists_one":
+		n := 0
+		for _, elem := range elems {
+			bind(elem)
+			b, err := test(pred)
+			if err != nil {
+				return nil, true, err
+			}
+			if b {
+				n++
+			}
+		}
+		return n == 1, true, nil
+
+	case "filter":
+		out := []any{}
+		for _, elem := range elems {
+			bind(elem)
+			b, err := test(pred)
+			if err != nil {
+				return nil, true, err
+			}
+			if b {
+				out = append(out, elem)
+			}
+		}
+		return out, true, nil
+
+	default: // map
+		out := []any{}
+		for _, elem := range elems {
+			bind(elem)
+			if args.Len() == 3 {
+				b, err := test(pred)
+				if err != nil {
+					return nil, true, err
+				}
+				if !b {
+					continue
+				}
+			}
+			v, err := ev.value(args.At(args.Len() - 1).Expr)
+			if err != nil {
+				return nil, true, err
+			}
+			out = append(out, v)
+		}
+		return out, true, nil
+	}
+}
+
+// celNormalize converts a value passed to [EvalCEL] into the representation
+// the evaluator uses.
+func celNormalize(v any) any {
+	switch v := v.(type) {
+	case MessageValue:
+		return irObject{ty: v.Type(), value: v}
+	case protoreflect.Message:
+		return reflectObject{v}
+	case protoreflect.ProtoMessage:
+		return reflectObject{v.ProtoReflect()}
+	case protoreflect.EnumNumber:
+		return int64(v)
+	case []any:
+		out := make([]any, len(v))
+		for i, e := range v {
+			out[i] = celNormalize(e)
+		}
+		return out
+	case map[any]any:
+		out := make(map[any]any, len(v))
+		for k, e := range v {
+			out[celNormalize(k)] = celNormalize(e)
+		}
+		return out
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if _, ok := v.(time.Duration); !ok {
+			return rv.Int()
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint()
+	case reflect.Float32, reflect.Float64:
+		return rv.Float()
+	}
+	return v
+}
+
+// celTypeOf returns the name of the CEL type of a value.
+func celTypeOf(v any) CELType {
+	switch v := v.(type) {
+	case nil:
+		return "null_type"
+	case bool:
+		return "bool"
+	case int64:
+		return "int"
+	case uint64:
+		return "uint"
+	case float64:
+		return "double"
+	case string:
+		return "string"
+	case []byte:
+		return "bytes"
+	case time.Duration:
+		return "google.protobuf.Duration"
+	case time.Time:
+		return "google.protobuf.Timestamp"
+	case []any:
+		return "list"
+	case map[any]any:
+		return "map"
+	case celObject:
+		return CELType(v.typeName())
+	case CELType:
+		return "type"
+	default:
+		return CELType(fmt.Sprintf("%T", v))
+	}
+}
+
+// celFormat formats a value for error messages and `string()`.
+func celFormat(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case []byte:
+		return string(v)
+	case time.Duration:
+		return strconv.FormatFloat(v.Seconds(), 'f', -1, 64) + "s"
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// celEqual implements CEL's `==`. Values of different types are unequal,
+// except that numbers are compared by value.
+func celEqual(a, b any) bool {
+	if c, ok := celCompareNumbers(a, b); ok {
+		return c == 0
+	}
+
+	switch a := a.(type) {
+	case nil:
+		return b == nil
+	case []byte:
+		b, ok := b.([]byte)
+		return ok && bytes.Equal(a, b)
+	case time.Time:
+		b, ok := b.(time.Time)
+		return ok && a.Equal(b)
+	case []any:
+		b, ok := b.([]any)
+		return ok && slices.EqualFunc(a, b, celEqual)
+	case map[any]any:
+		b, ok := b.(map[any]any)
+		if !ok || len(a) != len(b) {
+			return false
+		}
+		for k, v := range a {
+			w, ok := celMapGet(b, k)
+			if !ok || !celEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	case celObject:
+		b, ok := b.(celObject)
+		if !ok || a.typeName() != b.typeName() {
+			return false
+		}
+		for _, name := range a.fieldNames() {
+			v, _ := a.field(name)
+			w, _ := b.field(name)
+			if !celEqual(v, w) {
+				return false
+			}
+		}
+		return true
+	case bool, string, time.Duration, CELType:
+		return a == b
+	}
+	return false
+}
+
+// celCompareNumbers compares two numbers of possibly different types.
+func celCompareNumbers(a, b any) (int, bool) {
+	switch a := a.(type) {
+	case int64:
+		switch b := b.(type) {
+		case int64:
+			return celCmp(a, b), true
+		case uint64:
+			if a < 0 {
+				return -1, true
+			}
+			return celCmp(uint64(a), b), true
+		case float64:
+			return celCmpFloat(float64(a), b), true
+		}
+	case uint64:
+		switch b := b.(type) {
+		case int64:
+			c, ok := celCompareNumbers(b, a)
+			return -c, ok
+		case uint64:
+			return celCmp(a, b), true
+		case float64:
+			return celCmpFloat(float64(a), b), true
+		}
+	case float64:
+		switch b := b.(type) {
+		case int64:
+			return celCmpFloat(a, float64(b)), true
+		case uint64:
+			return celCmpFloat(a, float64(b)), true
+		case float64:
+			return celCmpFloat(a, b), true
+		}
+	}
+	return 0, false
+}
+
+func celCmp[T int64 | uint64 | string | time.Duration](a, b T) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// celCmpFloat compares floats; comparisons involving NaN are treated as
+// unequal, so that every ordering on them is false except `!=`.
+func celCmpFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	case a == b:
+		return 0
+	default:
+		return 2
+	}
+}
+
+// celCompare implements CEL's ordering. Returns false if the values cannot be
+// ordered.
+func celCompare(a, b any) (int, bool) {
+	if c, ok := celCompareNumbers(a, b); ok {
+		if c == 2 {
+			// NaN: make every ordering false.
+			return 0, false
+		}
+		return c, true
+	}
+
+	switch a := a.(type) {
+	case bool:
+		if b, ok := b.(bool); ok {
+			switch {
+			case a == b:
+				return 0, true
+			case b:
+				return -1, true
+			default:
+				return 1, true
+			}
+		}
+	case string:
+		if b, ok := b.(string); ok {
+			return celCmp(a, b), true
+		}
+	case []byte:
+		if b, ok := b.([]byte); ok {
+			return bytes.Compare(a, b), true
+		}
+	case time.Duration:
+		if b, ok := b.(time.Duration); ok {
+			return celCmp(a, b), true
+		}
+	case time.Time:
+		if b, ok := b.(time.Time); ok {
+			return a.Compare(b), true
+		}
+	}
+	return 0, false
+}
+
+// celMapGet looks up a key in a map, treating numeric keys of different
+// types as the same key.
+func celMapGet(m map[any]any, k any) (any, bool) {
+	if v, ok := m[k]; ok {
+		return v, true
+	}
+	switch k := k.(type) {
+	case int64:
+		if k >= 0 {
+			v, ok := m[uint64(k)]
+			return v, ok
+		}
+	case uint64:
+		if k <= math.MaxInt64 {
+			v, ok := m[int64(k)]
+			return v, ok
+		}
+	case float64:
+		if k == math.Trunc(k) {
+			if v, ok := celMapGet(m, int64(k)); ok {
+				return v, true
+			}
+			if k >= 0 {
+				return celMapGet(m, uint64(k))
+			}
+		}
+	}
+	return nil, false
+}
+
+// celArith implements arithmetic operators. Returns nil if there is no
+// overload for the operands, and a non-empty error message if the operation
+// fails.
+func celArith(op keyword.Keyword, l, r any) (any, string) {
+	switch l := l.(type) {
+	case int64:
+		r, ok := r.(int64)
+		if !ok {
+			return nil, ""
+		}
+		var v int64
+		switch op {
+		case keyword.Add:
+			v = l + r
+			if (v > l) != (r > 0) {
+				return nil, "integer overflow"
+			}
+		case keyword.Sub:
+			v = l - r
+			if (v < l) != (r > 0) {
+				return nil, "integer overflow"
+			}
+		case keyword.Mul:
+			v = l * r
+			if l != 0 && (v/l != r || (l == -1 && r == math.MinInt64)) {
+				return nil, "integer overflow"
+			}
+		case keyword.Div, keyword.Rem:
+			switch {
+			case r == 0:
+				return nil, "division by zero"
+			case l == math.MinInt64 && r == -1:
+				return nil, "integer overflow"
+			case op == keyword.Div:
+				v = l / r
+			default:
+				v = l % r
+			}
+		default:
+			return nil, ""
+		}
+		return v, ""
+
+	case uint64:
+		r, ok := r.(uint64)
+		if !ok {
+			return nil, ""
+		}
+		switch op {
+		case keyword.Add:
+			if l+r < l {
+				return nil, "unsigned integer overflow"
+			}
+			return l + r, ""
+		case keyword.Sub:
+			if r > l {
+				return nil, "unsigned integer overflow"
+			}
+			return l - r, ""
+		case keyword.Mul:
+			if l != 0 && (l*r)/l != r {
+				return nil, "unsigned integer overflow"
+			}
+			return l * r, ""
+		case keyword.Div, keyword.Rem:
+			if r == 0 {
+				return nil, "division by zero"
+			}
+			if op == keyword.Div {
+				return l / r, ""
+			}
+			return l % r, ""
+		}
+
+	case float64:
+		r, ok := r.(float64)
+		if !ok {
+			return nil, ""
+		}
+		switch op {
+		case keyword.Add:
+			return l + r, ""
+		case keyword.Sub:
+			return l - r, ""
+		case keyword.Mul:
+			return l * r, ""
+		case keyword.Div:
+			return l / r, ""
+		}
+
+	case string:
+		if r, ok := r.(string); ok && op == keyword.Add {
+			return l + r, ""
+		}
+
+	case []byte:
+		if r, ok := r.([]byte); ok && op == keyword.Add {
+			return slices.Concat(l, r), ""
+		}
+
+	case []any:
+		if r, ok := r.([]any); ok && op == keyword.Add {
+			return slices.Concat(l, r), ""
+		}
+
+	case time.Duration:
+		switch r := r.(type) {
+		case time.Duration:
+			switch op {
+			case keyword.Add:
+				return l + r, ""
+			case keyword.Sub:
+				return l - r, ""
+			}
+		case time.Time:
+			if op == keyword.Add {
+				return r.Add(l), ""
+			}
+		}
+
+	case time.Time:
+		switch r := r.(type) {
+		case time.Duration:
+			switch op {
+			case keyword.Add:
+				return l.Add(r), ""
+			case keyword.Sub:
+				return l.Add(-r), ""
+			}
+		case time.Time:
+			if op == keyword.Sub {
+				return l.Sub(r), ""
+			}
+		}
+	}
+	return nil, ""
+}
+
+// irObject is a [MessageValue] being evaluated by CEL.
+type irObject struct {
+	ty    Type
+	value MessageValue // May be zero, if the message is not set.
+}
+
+func (o irObject) typeName() string { return string(o.ty.FullName()) }
+
+func (o irObject) message() any { return o.value }
+
+func (o irObject) fieldNames() []string {
+	var names []string
+	for m := range seq.Values(o.ty.Members()) {
+		names = append(names, m.Name())
+	}
+	return names
+}
+
+func (o irObject) has(name string) (set, ok bool) {
+	m := o.ty.MemberByName(name)
+	if m.IsZero() {
+		return false, false
+	}
+	v := o.value.Field(m)
+	return !v.IsZero() && v.Field() == m && v.Elements().Len() > 0, true
+}
+
+func (o irObject) field(name string) (any, bool) {
+	m := o.ty.MemberByName(name)
+	if m.IsZero() {
+		return nil, false
+	}
+	v := o.value.Field(m)
+	if v.Field() != m {
+		// This is a different member of the same oneof.
+		v = Value{}
+	}
+
+	switch {
+	case m.IsMap():
+		out := make(map[any]any)
+		k, val := m.Element().EntryFields()
+		for elem := range seq.Values(v.Elements()) {
+			entry := irObject{ty: m.Element(), value: elem.AsMessage()}
+			key, _ := entry.field(k.Name())
+			out[key], _ = entry.field(val.Name())
+		}
+		return out, true
+	case m.IsRepeated():
+		out := []any{}
+		for elem := range seq.Values(v.Elements()) {
+			out = append(out, irElement(m.Element(), elem))
+		}
+		return out, true
+	case v.Elements().Len() == 0:
+		return irDefault(m.Element()), true
+	default:
+		return irElement(m.Element(), v.Elements().At(0)), true
+	}
+}
+
+// irElement converts a single element of a field's value of type ty.
+func irElement(ty Type, elem Element) any {
+	if ty.IsMessage() {
+		obj := irObject{ty: ty, value: elem.AsMessage()}
+		return celWellKnown(obj, obj.typeName(), true)
+	}
+	switch {
+	case ty.Predeclared().IsFloat():
+		v, _ := elem.AsFloat()
+		return v
+	case ty.Predeclared().IsUnsigned():
+		v, _ := elem.AsUInt()
+		return v
+	case ty.Predeclared() == predeclared.Bool:
+		v, _ := elem.AsBool()
+		return v
+	case ty.Predeclared() == predeclared.String:
+		v, _ := elem.AsString()
+		return v
+	case ty.Predeclared() == predeclared.Bytes:
+		v, _ := elem.AsString()
+		return []byte(v)
+	default:
+		v, _ := elem.AsInt()
+		return v
+	}
+}
+
+// irDefault returns the value of an unset field of type ty.
+func irDefault(ty Type) any {
+	if ty.IsMessage() {
+		obj := irObject{ty: ty}
+		return celWellKnown(obj, obj.typeName(), false)
+	}
+	switch {
+	case ty.Predeclared().IsFloat():
+		return float64(0)
+	case ty.Predeclared().IsUnsigned():
+		return uint64(0)
+	case ty.Predeclared() == predeclared.Bool:
+		return false
+	case ty.Predeclared() == predeclared.String:
+		return ""
+	case ty.Predeclared() == predeclared.Bytes:
+		return []byte(nil)
+	default:
+		return int64(0)
+	}
+}
+
+// reflectObject is a [protoreflect.Message] being evaluated by CEL.
+type reflectObject struct {
+	protoreflect.Message
+}
+
+func (o reflectObject) typeName() string { return string(o.Descriptor().FullName()) }
+
+func (o reflectObject) message() any { return o.Message }
+
+func (o reflectObject) fieldNames() []string {
+	fields := o.Descriptor().Fields()
+	names := make([]string, fields.Len())
+	for i := range names {
+		names[i] = string(fields.Get(i).Name())
+	}
+	return names
+}
+
+func (o reflectObject) has(name string) (set, ok bool) {
+	fd := o.Descriptor().Fields().ByName(protoreflect.Name(name))
+	if fd == nil {
+		return false, false
+	}
+	return o.Has(fd), true
+}
+
+func (o reflectObject) field(name string) (any, bool) {
+	fd := o.Descriptor().Fields().ByName(protoreflect.Name(name))
+	if fd == nil {
+		return nil, false
+	}
+	v := o.Get(fd)
+
+	switch {
+	case fd.IsMap():
+		out := make(map[any]any)
+		v.Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			out[reflectValue(fd.MapKey(), k.Value(), true)] = reflectValue(fd.MapValue(), v, true)
+			return true
+		})
+		return out, true
+	case fd.IsList():
+		list := v.List()
+		out := make([]any, list.Len())
+		for i := range out {
+			out[i] = reflectValue(fd, list.Get(i), true)
+		}
+		return out, true
+	default:
+		return reflectValue(fd, v, o.Has(fd)), true
+	}
+}
+
+// reflectValue converts a single value of a field.
+func reflectValue(fd protoreflect.FieldDescriptor, v protoreflect.Value, set bool) any {
+	switch fd.Kind() {
+	case protoreflect.BoolKind:
+		return v.Bool()
+	case protoreflect.EnumKind:
+		return int64(v.Enum())
+	case protoreflect.Int32Kind, protoreflect.Sint32Kind, protoreflect.Sfixed32Kind,
+		protoreflect.Int64Kind, protoreflect.Sint64Kind, protoreflect.Sfixed64Kind:
+		return v.Int()
+	case protoreflect.Uint32Kind, protoreflect.Fixed32Kind,
+		protoreflect.Uint64Kind, protoreflect.Fixed64Kind:
+		return v.Uint()
+	case protoreflect.FloatKind, protoreflect.DoubleKind:
+		return v.Float()
+	case protoreflect.StringKind:
+		return v.String()
+	case protoreflect.BytesKind:
+		return v.Bytes()
+	default:
+		obj := reflectObject{v.Message()}
+		return celWellKnown(obj, obj.typeName(), set)
+	}
+}
+
+// celWellKnown converts a message of one of the well-known types that CEL
+// treats as another type. Other messages are returned as-is.
+func celWellKnown(obj celObject, name string, set bool) any {
+	get := func(field string) any {
+		v, _ := obj.field(field)
+		return v
+	}
+	integer := func(field string) int64 {
+		v, _ := get(field).(int64)
+		return v
+	}
+
+	switch ty, ok := celWrappers[FullName(name)]; {
+	case !ok:
+		return obj
+	case ty.kind == celTimestamp:
+		return time.Unix(integer("seconds"), integer("nanos")).UTC()
+	case ty.kind == celDuration:
+		return time.Duration(integer("seconds"))*time.Second + time.Duration(integer("nanos"))
+	case ty.nullable:
+		if !set {
+			return nil
+		}
+		return get("value")
+	default:
+		return obj
+	}
+}
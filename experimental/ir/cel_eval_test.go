@@ -0,0 +1,253 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ir_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/descriptorpb"
+
+	"github.com/bufbuild/protocompile/experimental/expr"
+	"github.com/bufbuild/protocompile/experimental/incremental"
+	"github.com/bufbuild/protocompile/experimental/incremental/queries"
+	"github.com/bufbuild/protocompile/experimental/ir"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/source"
+)
+
+func TestEvalCEL(t *testing.T) {
+	t.Parallel()
+
+	this := &descriptorpb.FieldDescriptorProto{
+		Name:   proto.String("foo_bar"),
+		Number: proto.Int32(5),
+		Label:  descriptorpb.FieldDescriptorProto_LABEL_REPEATED.Enum(),
+	}
+	vars := map[string]any{
+		"this": this,
+		"now":  time.Date(2026, 3, 14, 15, 9, 26, 0, time.UTC),
+		"tags": []any{"a", "b", "c"},
+	}
+
+	tests := []struct {
+		expr string
+		want any
+		err  string
+	}{
+		{expr: "1 + 2 * 3", want: int64(7)},
+		{expr: "-9223372036854775808", want: int64(-9223372036854775808)},
+		{expr: "9223372036854775807 + 1", err: "integer overflow"},
+		{expr: "7u / 2u", want: uint64(3)},
+		{expr: "1 / 0", err: "division by zero"},
+		{expr: "1.5 * 2.0", want: 3.0},
+		{expr: "1 == 1.0 && 1u < 2 && 2.5 > 2", want: true},
+		{expr: "'ab' + 'cd'", want: "abcd"},
+		{expr: "b'ab' + b'cd'", want: []byte("abcd")},
+		{expr: "[1, 2] + [3]", want: []any{int64(1), int64(2), int64(3)}},
+		{expr: "{'a': 1, 'b': 2}['b']", want: int64(2)},
+		{expr: "{'a': 1}.c", err: "no such key"},
+		{expr: "[1, 2][2]", err: "index out of range"},
+		{expr: "2 in [1, 2] && !('c' in {'a': 1})", want: true},
+		{expr: "true ? 'yes' : 'no'", want: "yes"},
+		{expr: "false && 1 / 0 == 1", want: false},
+		{expr: "1 / 0 == 1 || true", want: true},
+		{expr: "1 + 'a'", err: "no such overload"},
+		{expr: "undefined", err: "undeclared reference to `undefined`"},
+
+		{expr: "size('héllo') == 5 && 'héllo'.size() == 5", want: true},
+		{expr: "'foo@bar.com'.isEmail() && !'foo@'.isEmail()", want: true},
+		{expr: "'example.com'.isHostname() && !'-a.com'.isHostname()", want: true},
+		{expr: "'10.0.0.1'.isIp(4) && !'10.0.0.1'.isIp(6) && '::1'.isIp()", want: true},
+		{expr: "'10.0.0.0/8'.isIpPrefix(4, true) && !'10.0.0.1/8'.isIpPrefix(true)", want: true},
+		{expr: "'https://example.com/x'.isUri() && !'/x'.isUri() && '/x'.isUriRef()", want: true},
+		{expr: "'example.com:80'.isHostAndPort(true) && !'example.com'.isHostAndPort(true)", want: true},
+		{expr: "'a,b,c'.split(',').join('-')", want: "a-b-c"},
+		{expr: "'Hello'.lowerAscii().startsWith('he')", want: true},
+		{expr: "b'xyz'.startsWith(b'x') && b'xyz'.contains(b'y') && !b'xyz'.endsWith(b'y')", want: true},
+		{expr: "'abc'.reverse()", want: "cba"},
+		{expr: "'hello'.substring(1, 3) + 'hello'.charAt(4)", want: "elo"},
+		{expr: "'hello'.indexOf('l') + 'hello'.lastIndexOf('l')", want: int64(5)},
+		{expr: "'abc123'.matches('^[a-z]+[0-9]+$')", want: true},
+		{expr: "'x'.matches('(')", err: "invalid regular expression"},
+
+		{expr: "math.greatest(1, 3u, 2.5)", want: uint64(3)},
+		{expr: "math.least([4, -1, 2])", want: int64(-1)},
+		{expr: "math.greatest(1, 'a')", err: "no such overload"},
+
+		{expr: "int('42') + int(3.9)", want: int64(45)},
+		{expr: "uint(-1)", err: "out of range"},
+		{expr: "string(1.5) + string(true)", want: "1.5true"},
+		{expr: "duration('1h30m') > duration('90s')", want: true},
+		{expr: "timestamp('2026-03-14T00:00:00Z') < now", want: true},
+		{expr: "now.getFullYear() == 2026 && now.getMonth() == 2 && now.getDate() == 14", want: true},
+		{expr: "now - timestamp('2026-03-14T15:09:00Z')", want: 26 * time.Second},
+		{expr: "type(1) == int && type('') == string", want: true},
+
+		{expr: "tags.all(t, t.size() == 1)", want: true},
+		{expr: "tags.exists(t, t == 'b') && tags.exists_one(t, t > 'a' && t < 'c')", want: true},
+		{expr: "tags.filter(t, t != 'b')", want: []any{"a", "c"}},
+		{expr: "tags.map(t, t + t)", want: []any{"aa", "bb", "cc"}},
+		{expr: "tags.map(t, t != 'a', t)", want: []any{"b", "c"}},
+		{expr: "{'x': 1, 'y': 2}.all(k, k.size() == 1)", want: true},
+		{expr: "tags.unique() && ![1, 1].unique()", want: true},
+
+		{expr: "this.name + ':' + string(this.number)", want: "foo_bar:5"},
+		{expr: "this.label == 3 && this.type == 1", want: true},
+		{expr: "has(this.name) && !has(this.type_name)", want: true},
+		{expr: "this.options.deprecated", want: false},
+		{expr: "this.nope", err: "has no field named `nope`"},
+		{expr: "this", want: this.ProtoReflect()},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			t.Parallel()
+
+			r := new(report.Report)
+			e := expr.ParseCEL(source.NewFile("test.cel", tt.expr).Span(0, len(tt.expr)), r)
+			require.Empty(t, r.Diagnostics)
+
+			got, err := ir.EvalCEL(e, vars)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEvalCELOptions(t *testing.T) {
+	t.Parallel()
+
+	const text = `syntax = "proto2";
+package p;
+
+import "google/protobuf/descriptor.proto";
+import "google/protobuf/duration.proto";
+import "google/protobuf/timestamp.proto";
+import "google/protobuf/wrappers.proto";
+
+enum Kind {
+  KIND_UNSPECIFIED = 0;
+  KIND_SOME = 2;
+}
+
+message Rule {
+  optional string name = 1;
+  repeated int32 nums = 2;
+  map<string, int32> counts = 3;
+  optional Rule child = 4;
+  optional Kind kind = 5;
+  optional bytes data = 6;
+  optional google.protobuf.Duration ttl = 7;
+  optional google.protobuf.Timestamp at = 8;
+  optional google.protobuf.Int32Value limit = 9;
+  optional google.protobuf.StringValue label = 10;
+  oneof choice {
+    string a = 11;
+    int64 b = 12;
+  }
+}
+
+extend google.protobuf.MessageOptions {
+  optional Rule rule = 50000;
+}
+
+message M {
+  option (rule) = {
+    name: "x"
+    nums: [1, 2, 3]
+    counts: [{key: "k", value: 7}]
+    child: {name: "y"}
+    kind: KIND_SOME
+    data: "\x01\x02"
+    ttl: {seconds: 90}
+    limit: {value: 4}
+    b: 42
+  };
+}
+`
+	results, r, err := incremental.Run(t.Context(), incremental.New(), queries.IR{
+		Opener: &source.Openers{
+			source.NewMap(map[string]*source.File{"test.proto": source.NewFile("test.proto", text)}),
+			source.WKTs(),
+		},
+		Session: new(ir.Session),
+		Path:    "test.proto",
+	})
+	require.NoError(t, err)
+	require.Empty(t, r.Diagnostics)
+	file := results[0].Value
+
+	var m ir.Type
+	for ty := range seq.Values(file.Types()) {
+		if ty.Name() == "M" {
+			m = ty
+		}
+	}
+	rule := m.Options().Field(file.Extensions().At(0)).Elements().At(0).AsMessage()
+	vars := map[string]any{"this": rule}
+
+	tests := []struct {
+		expr string
+		want any
+		err  string
+	}{
+		{expr: "this.name", want: "x"},
+		{expr: "this.nums", want: []any{int64(1), int64(2), int64(3)}},
+		{expr: "this.nums.all(n, n > 0) && 2 in this.nums", want: true},
+		{expr: "this.counts['k']", want: int64(7)},
+		{expr: "this.child.name", want: "y"},
+		{expr: "has(this.child) && !has(this.child.child)", want: true},
+		{expr: "this.child.child.name", want: ""},
+		{expr: "this.child.nums.size()", want: int64(0)},
+		{expr: "this.kind", want: int64(2)},
+		{expr: "this.data", want: []byte{1, 2}},
+		{expr: "this.ttl", want: 90 * time.Second},
+		{expr: "this.ttl > duration('1m')", want: true},
+		{expr: "this.at", want: time.Unix(0, 0).UTC()},
+		{expr: "this.limit", want: int64(4)},
+		{expr: "this.label", want: nil},
+		{expr: "this.b", want: int64(42)},
+		{expr: "this.a", want: ""},
+		{expr: "has(this.b) && !has(this.a)", want: true},
+		{expr: "this.nope", err: "has no field named `nope`"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			t.Parallel()
+
+			r := new(report.Report)
+			e := expr.ParseCEL(source.NewFile("test.cel", tt.expr).Span(0, len(tt.expr)), r)
+			require.Empty(t, r.Diagnostics)
+
+			got, err := ir.EvalCEL(e, vars)
+			if tt.err != "" {
+				assert.ErrorContains(t, err, tt.err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
@@ -0,0 +1,701 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ir
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"net/netip"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// errCELNoOverload is returned by [celCall] when no overload of a function
+// accepts its arguments.
+var errCELNoOverload = errors.New("no such overload")
+
+// celCall calls a function or method. If method is set, args[0] is the
+// receiver.
+func celCall(name string, method bool, args []any) (any, error) {
+	var recv any
+	if method {
+		recv = args[0]
+	}
+
+	switch name {
+	case "size":
+		if len(args) == 1 {
+			switch v := args[0].(type) {
+			case string:
+				return int64(len([]rune(v))), nil
+			case []byte:
+				return int64(len(v)), nil
+			case []any:
+				return int64(len(v)), nil
+			case map[any]any:
+				return int64(len(v)), nil
+			}
+		}
+	case "dyn":
+		if !method && len(args) == 1 {
+			return args[0], nil
+		}
+	case "type":
+		if !method && len(args) == 1 {
+			return celTypeOf(args[0]), nil
+		}
+	case "int", "uint", "double", "string", "bytes", "bool", "duration", "timestamp":
+		if !method && len(args) == 1 {
+			return celConvert(name, args[0])
+		}
+	case "math.greatest", "math.least":
+		if method || len(args) == 0 {
+			break
+		}
+		if list, ok := args[0].([]any); ok && len(args) == 1 {
+			args = list
+		}
+		return celExtremum(name == "math.greatest", args)
+	case "matches":
+		s, re, ok := celStrings2(args)
+		if !ok {
+			break
+		}
+		r, err := regexp.Compile(re)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %w", err)
+		}
+		return r.MatchString(s), nil
+	}
+
+	if !method {
+		return nil, fmt.Errorf("%w: `%s(%s)`", errCELNoOverload, name, celArgTypes(args))
+	}
+
+	args = args[1:]
+	var (
+		v   any
+		err error
+		ok  = true
+	)
+	switch recv := recv.(type) {
+	case string:
+		v, err, ok = celStringMethod(recv, name, args)
+	case []any:
+		v, err, ok = celListMethod(recv, name, args)
+	case []byte:
+		v, ok = celBytesMethod(recv, name, args)
+	case float64:
+		v, ok = celDoubleMethod(recv, name, args)
+	case time.Time:
+		v, err, ok = celTimestampMethod(recv, name, args)
+	case time.Duration:
+		v, ok = celDurationMethod(recv, name, args)
+	default:
+		ok = false
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: `%s.%s(%s)`", errCELNoOverload, celTypeOf(recv), name, celArgTypes(args))
+	}
+	return v, err
+}
+
+// celExtremum returns the greatest or least of a non-empty list of numbers.
+func celExtremum(greatest bool, args []any) (any, error) {
+	if len(args) == 0 {
+		return nil, errors.New("no arguments")
+	}
+	best := args[0]
+	for _, v := range args {
+		c, ok := celCompareNumbers(v, best)
+		if !ok {
+			return nil, fmt.Errorf("%w: `%s`", errCELNoOverload, celTypeOf(v))
+		}
+		if (greatest && c > 0) || (!greatest && c < 0) {
+			best = v
+		}
+	}
+	return best, nil
+}
+
+func celArgTypes(args []any) string {
+	types := make([]string, len(args))
+	for i, arg := range args {
+		types[i] = string(celTypeOf(arg))
+	}
+	return strings.Join(types, ", ")
+}
+
+func celStrings2(args []any) (a, b string, ok bool) {
+	if len(args) != 2 {
+		return "", "", false
+	}
+	a, ok1 := args[0].(string)
+	b, ok2 := args[1].(string)
+	return a, b, ok1 && ok2
+}
+
+// celConvert implements CEL's type conversion functions.
+func celConvert(to string, v any) (any, error) {
+	fail := func() (any, error) {
+		return nil, fmt.Errorf("%w: `%s(%s)`", errCELNoOverload, to, celTypeOf(v))
+	}
+	outOfRange := func() (any, error) {
+		return nil, fmt.Errorf("`%s(%s)` is out of range", to, celFormat(v))
+	}
+
+	switch to {
+	case "int":
+		switch v := v.(type) {
+		case int64:
+			return v, nil
+		case uint64:
+			if v > math.MaxInt64 {
+				return outOfRange()
+			}
+			return int64(v), nil
+		case float64:
+			if math.IsNaN(v) || v <= math.MinInt64 || v >= math.MaxInt64 {
+				return outOfRange()
+			}
+			return int64(v), nil
+		case string:
+			n, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("cannot convert %q to `int`", v)
+			}
+			return n, nil
+		case time.Time:
+			return v.Unix(), nil
+		}
+	case "uint":
+		switch v := v.(type) {
+		case int64:
+			if v < 0 {
+				return outOfRange()
+			}
+			return uint64(v), nil
+		case uint64:
+			return v, nil
+		case float64:
+			if math.IsNaN(v) || v < 0 || v >= math.MaxUint64 {
+				return outOfRange()
+			}
+			return uint64(v), nil
+		case string:
+			n, err := strconv.ParseUint(v, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("cannot convert %q to `uint`", v)
+			}
+			return n, nil
+		}
+	case "double":
+		switch v := v.(type) {
+		case int64:
+			return float64(v), nil
+		case uint64:
+			return float64(v), nil
+		case float64:
+			return v, nil
+		case string:
+			f, err := strconv.ParseFloat(v, 64)
+			if err != nil {
+				return nil, fmt.Errorf("cannot convert %q to `double`", v)
+			}
+			return f, nil
+		}
+	case "string":
+		switch v := v.(type) {
+		case string:
+			return v, nil
+		case []byte:
+			if !utf8.Valid(v) {
+				return nil, errors.New("bytes are not valid UTF-8")
+			}
+			return string(v), nil
+		case bool, int64, uint64, float64, time.Duration, time.Time:
+			return celFormat(v), nil
+		}
+	case "bytes":
+		switch v := v.(type) {
+		case []byte:
+			return v, nil
+		case string:
+			return []byte(v), nil
+		}
+	case "bool":
+		switch v := v.(type) {
+		case bool:
+			return v, nil
+		case string:
+			switch v {
+			case "1", "t", "true", "TRUE", "True":
+				return true, nil
+			case "0", "f", "false", "FALSE", "False":
+				return false, nil
+			}
+			return nil, fmt.Errorf("cannot convert %q to `bool`", v)
+		}
+	case "duration":
+		switch v := v.(type) {
+		case time.Duration:
+			return v, nil
+		case string:
+			d, err := time.ParseDuration(v)
+			if err != nil {
+				return nil, fmt.Errorf("cannot convert %q to `google.protobuf.Duration`", v)
+			}
+			return d, nil
+		}
+	case "timestamp":
+		switch v := v.(type) {
+		case time.Time:
+			return v, nil
+		case int64:
+			return time.Unix(v, 0).UTC(), nil
+		case string:
+			t, err := time.Parse(time.RFC3339Nano, v)
+			if err != nil {
+				return nil, fmt.Errorf("cannot convert %q to `google.protobuf.Timestamp`", v)
+			}
+			return t, nil
+		}
+	}
+	return fail()
+}
+
+// celStringMethod implements methods on strings.
+func celStringMethod(s, name string, args []any) (v any, err error, ok bool) {
+	str := func(i int) string {
+		s, _ := args[i].(string)
+		return s
+	}
+	integer := func(i int) int64 {
+		n, _ := args[i].(int64)
+		return n
+	}
+	check := func(kinds ...string) bool {
+		if len(args) != len(kinds) {
+			return false
+		}
+		for i, kind := range kinds {
+			if celTypeOf(args[i]) != CELType(kind) {
+				return false
+			}
+		}
+		return true
+	}
+	runes := []rune(s)
+	inRange := func(i int64) error {
+		if i < 0 || i > int64(len(runes)) {
+			return fmt.Errorf("index out of range: %d", i)
+		}
+		return nil
+	}
+
+	switch {
+	case name == "size" && check():
+		return int64(len(runes)), nil, true
+	case name == "contains" && check("string"):
+		return strings.Contains(s, str(0)), nil, true
+	case name == "startsWith" && check("string"):
+		return strings.HasPrefix(s, str(0)), nil, true
+	case name == "endsWith" && check("string"):
+		return strings.HasSuffix(s, str(0)), nil, true
+	case name == "matches" && check("string"):
+		v, err := celCall("matches", false, []any{s, str(0)})
+		return v, err, true
+	case name == "lowerAscii" && check():
+		return strings.Map(func(r rune) rune {
+			if r >= 'A' && r <= 'Z' {
+				return r + 'a' - 'A'
+			}
+			return r
+		}, s), nil, true
+	case name == "upperAscii" && check():
+		return strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' {
+				return r + 'A' - 'a'
+			}
+			return r
+		}, s), nil, true
+	case name == "trim" && check():
+		return strings.TrimSpace(s), nil, true
+	case name == "reverse" && check():
+		slices.Reverse(runes)
+		return string(runes), nil, true
+	case name == "charAt" && check("int"):
+		i := integer(0)
+		if err := inRange(i); err != nil {
+			return nil, err, true
+		}
+		if i == int64(len(runes)) {
+			return "", nil, true
+		}
+		return string(runes[i]), nil, true
+	case (name == "indexOf" || name == "lastIndexOf") && (check("string") || check("string", "int")):
+		sub := []rune(str(0))
+		start, end := int64(0), int64(len(runes))
+		if len(args) == 2 {
+			if err := inRange(integer(1)); err != nil {
+				return nil, err, true
+			}
+			if name == "indexOf" {
+				start = integer(1)
+			} else {
+				end = integer(1) + int64(len(sub))
+			}
+		}
+		if name == "indexOf" {
+			for i := start; i+int64(len(sub)) <= int64(len(runes)); i++ {
+				if slices.Equal(runes[i:i+int64(len(sub))], sub) {
+					return i, nil, true
+				}
+			}
+		} else {
+			for i := min(end, int64(len(runes))) - int64(len(sub)); i >= 0; i-- {
+				if slices.Equal(runes[i:i+int64(len(sub))], sub) {
+					return i, nil, true
+				}
+			}
+		}
+		return int64(-1), nil, true
+	case name == "replace" && (check("string", "string") || check("string", "string", "int")):
+		n := -1
+		if len(args) == 3 {
+			n = int(integer(2))
+		}
+		return strings.Replace(s, str(0), str(1), n), nil, true
+	case name == "split" && (check("string") || check("string", "int")):
+		n := -1
+		if len(args) == 2 {
+			n = int(integer(1))
+		}
+		out := []any{}
+		for _, part := range strings.SplitN(s, str(0), n) {
+			out = append(out, part)
+		}
+		return out, nil, true
+	case name == "substring" && (check("int") || check("int", "int")):
+		start, end := integer(0), int64(len(runes))
+		if len(args) == 2 {
+			end = integer(1)
+		}
+		if err := inRange(start); err != nil {
+			return nil, err, true
+		}
+		if err := inRange(end); err != nil {
+			return nil, err, true
+		}
+		if start > end {
+			return nil, fmt.Errorf("invalid substring range: %d > %d", start, end), true
+		}
+		return string(runes[start:end]), nil, true
+
+	case name == "isEmail" && check():
+		return celIsEmail(s), nil, true
+	case name == "isHostname" && check():
+		return celIsHostname(s), nil, true
+	case name == "isIp" && (check() || check("int")):
+		var version int64
+		if len(args) == 1 {
+			version = integer(0)
+		}
+		return celIsIP(s, version), nil, true
+	case name == "isIpPrefix" && (check() || check("int") || check("bool") || check("int", "bool")):
+		var (
+			version int64
+			strict  bool
+		)
+		for _, arg := range args {
+			switch arg := arg.(type) {
+			case int64:
+				version = arg
+			case bool:
+				strict = arg
+			}
+		}
+		return celIsIPPrefix(s, version, strict), nil, true
+	case name == "isUri" && check():
+		return celIsURI(s, true), nil, true
+	case name == "isUriRef" && check():
+		return celIsURI(s, false), nil, true
+	case name == "isHostAndPort" && check("bool"):
+		portRequired, _ := args[0].(bool)
+		return celIsHostAndPort(s, portRequired), nil, true
+	}
+	return nil, nil, false
+}
+
+// celListMethod implements methods on lists.
+func celListMethod(list []any, name string, args []any) (v any, err error, ok bool) {
+	switch {
+	case name == "size" && len(args) == 0:
+		return int64(len(list)), nil, true
+	case name == "unique" && len(args) == 0:
+		for i, a := range list {
+			for _, b := range list[:i] {
+				if celEqual(a, b) {
+					return false, nil, true
+				}
+			}
+		}
+		return true, nil, true
+	case name == "join" && len(args) <= 1:
+		var sep string
+		if len(args) == 1 {
+			if sep, ok = args[0].(string); !ok {
+				return nil, nil, false
+			}
+		}
+		parts := make([]string, len(list))
+		for i, elem := range list {
+			s, ok := elem.(string)
+			if !ok {
+				return nil, fmt.Errorf("`join` requires a list of strings, found `%s`", celTypeOf(elem)), true
+			}
+			parts[i] = s
+		}
+		return strings.Join(parts, sep), nil, true
+	}
+	return nil, nil, false
+}
+
+// celBytesMethod implements methods on bytes.
+func celBytesMethod(b []byte, name string, args []any) (any, bool) {
+	if len(args) != 1 {
+		return nil, false
+	}
+	arg, ok := args[0].([]byte)
+	if !ok {
+		return nil, false
+	}
+	switch name {
+	case "contains":
+		return bytes.Contains(b, arg), true
+	case "startsWith":
+		return bytes.HasPrefix(b, arg), true
+	case "endsWith":
+		return bytes.HasSuffix(b, arg), true
+	}
+	return nil, false
+}
+
+// celDoubleMethod implements methods on doubles.
+func celDoubleMethod(f float64, name string, args []any) (any, bool) {
+	switch {
+	case name == "isNan" && len(args) == 0:
+		return math.IsNaN(f), true
+	case name == "isInf" && len(args) == 0:
+		return math.IsInf(f, 0), true
+	case name == "isInf" && len(args) == 1:
+		sign, ok := args[0].(int64)
+		return math.IsInf(f, int(max(-1, min(sign, 1)))), ok
+	}
+	return nil, false
+}
+
+// celTimestampMethod implements the accessors on timestamps.
+func celTimestampMethod(t time.Time, name string, args []any) (v any, err error, ok bool) {
+	if len(args) > 1 {
+		return nil, nil, false
+	}
+	t = t.UTC()
+	if len(args) == 1 {
+		tz, ok := args[0].(string)
+		if !ok {
+			return nil, nil, false
+		}
+		loc, err := celTimeZone(tz)
+		if err != nil {
+			return nil, err, true
+		}
+		t = t.In(loc)
+	}
+
+	switch name {
+	case "getFullYear":
+		return int64(t.Year()), nil, true
+	case "getMonth":
+		return int64(t.Month()) - 1, nil, true
+	case "getDate":
+		return int64(t.Day()), nil, true
+	case "getDayOfMonth":
+		return int64(t.Day()) - 1, nil, true
+	case "getDayOfWeek":
+		return int64(t.Weekday()), nil, true
+	case "getDayOfYear":
+		return int64(t.YearDay()) - 1, nil, true
+	case "getHours":
+		return int64(t.Hour()), nil, true
+	case "getMinutes":
+		return int64(t.Minute()), nil, true
+	case "getSeconds":
+		return int64(t.Second()), nil, true
+	case "getMilliseconds":
+		return int64(t.Nanosecond() / int(time.Millisecond)), nil, true
+	}
+	return nil, nil, false
+}
+
+// celTimeZone parses a time zone name or a fixed offset, such as "-08:00".
+func celTimeZone(tz string) (*time.Location, error) {
+	if tz != "" && (tz[0] == '+' || tz[0] == '-') {
+		if t, err := time.Parse("-07:00", tz); err == nil {
+			_, offset := t.Zone()
+			return time.FixedZone(tz, offset), nil
+		}
+	}
+	loc, err := time.LoadLocation(tz)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone %q", tz)
+	}
+	return loc, nil
+}
+
+// celDurationMethod implements the accessors on durations.
+func celDurationMethod(d time.Duration, name string, args []any) (any, bool) {
+	if len(args) != 0 {
+		return nil, false
+	}
+	switch name {
+	case "getHours":
+		return int64(d / time.Hour), true
+	case "getMinutes":
+		return int64(d / time.Minute), true
+	case "getSeconds":
+		return int64(d / time.Second), true
+	case "getMilliseconds":
+		return int64(d / time.Millisecond), true
+	}
+	return nil, false
+}
+
+// celIsHostname implements protovalidate's `isHostname()`.
+func celIsHostname(s string) bool {
+	s = strings.TrimSuffix(s, ".")
+	if s == "" || len(s) > 253 {
+		return false
+	}
+
+	labels := strings.Split(s, ".")
+	for _, label := range labels {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, c := range []byte(label) {
+			if !isASCIIAlnum(c) && c != '-' {
+				return false
+			}
+		}
+	}
+
+	// The last label must not be all digits, so that IPv4 addresses are not
+	// hostnames.
+	last := labels[len(labels)-1]
+	return strings.ContainsFunc(last, func(r rune) bool { return r < '0' || r > '9' })
+}
+
+// celIsEmail implements protovalidate's `isEmail()`, which follows the HTML
+// specification's definition of a valid email address.
+func celIsEmail(s string) bool {
+	local, domain, ok := strings.Cut(s, "@")
+	if !ok || local == "" || len(local) > 64 || !celIsHostname(domain) ||
+		strings.HasSuffix(domain, ".") {
+		return false
+	}
+	for _, c := range []byte(local) {
+		if !isASCIIAlnum(c) && !strings.ContainsRune(".!#$%&'*+/=?^_`{|}~-", rune(c)) {
+			return false
+		}
+	}
+	return true
+}
+
+// celIsIP implements protovalidate's `isIp()`. A version of zero accepts
+// either IPv4 or IPv6.
+func celIsIP(s string, version int64) bool {
+	addr, err := netip.ParseAddr(s)
+	if err != nil || addr.Zone() != "" {
+		return false
+	}
+	switch version {
+	case 0:
+		return true
+	case 4:
+		return addr.Is4()
+	case 6:
+		return addr.Is6()
+	default:
+		return false
+	}
+}
+
+// celIsIPPrefix implements protovalidate's `isIpPrefix()`. If strict is set,
+// the prefix's host bits must be zero.
+func celIsIPPrefix(s string, version int64, strict bool) bool {
+	prefix, err := netip.ParsePrefix(s)
+	if err != nil || !celIsIP(prefix.Addr().String(), version) {
+		return false
+	}
+	return !strict || prefix.Masked() == prefix
+}
+
+// celIsURI implements protovalidate's `isUri()` and `isUriRef()`.
+func celIsURI(s string, absolute bool) bool {
+	u, err := url.Parse(s)
+	if err != nil || strings.ContainsAny(s, " \t\n\\") {
+		return false
+	}
+	return !absolute || u.Scheme != ""
+}
+
+// celIsHostAndPort implements protovalidate's `isHostAndPort()`.
+func celIsHostAndPort(s string, portRequired bool) bool {
+	host, port := s, ""
+	if i := strings.LastIndexByte(s, ':'); i >= 0 && !strings.HasSuffix(s, "]") &&
+		(strings.HasPrefix(s, "[") || strings.Count(s, ":") == 1) {
+		host, port = s[:i], s[i+1:]
+		if port == "" {
+			return false
+		}
+	}
+
+	if port != "" {
+		n, err := strconv.ParseUint(port, 10, 16)
+		if err != nil || (len(port) > 1 && port[0] == '0') || n > math.MaxUint16 {
+			return false
+		}
+	} else if portRequired {
+		return false
+	}
+
+	if v6, ok := strings.CutPrefix(host, "["); ok {
+		v6, ok = strings.CutSuffix(v6, "]")
+		return ok && celIsIP(v6, 6)
+	}
+	return celIsHostname(host) || celIsIP(host, 4)
+}
+
+func isASCIIAlnum(c byte) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
@@ -0,0 +1,315 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ir
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/internal/taxa"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/source"
+	"github.com/bufbuild/protocompile/internal/ext/cmpx"
+)
+
+// DiagnoseDeadDefinitions takes a report, a workspace, and lowered *[File]s,
+// and warns about every message, enum, enum value and extension defined in
+// the workspace that is never referenced from a field, method, option value
+// or extension in any of the files.
+//
+// files may include files outside of the workspace, such as its
+// dependencies: references from them count, but definitions in them are not
+// diagnosed. If ws is nil, every file is treated as part of the workspace.
+//
+// References from within a definition to itself, such as a recursive message
+// field, do not count. Definitions that are reachable from a service, or from
+// one of the given roots, are never diagnosed. A definition is reachable from
+// another if the latter refers to it, either directly or through any of the
+// definitions nested within it. Roots may name any definition, or a package,
+// in which case every definition in that package is a root.
+//
+// A definition that contains a referenced or reachable definition is itself
+// referenced or reachable, since it cannot be deleted without breaking that
+// reference.
+//
+// The first value of an enum is never diagnosed, because it is the enum's
+// default value and cannot be deleted.
+//
+// Diagnostics are provided only for the outermost dead definition; for
+// example, an unreferenced message's unreferenced nested types are not
+// diagnosed separately.
+func DiagnoseDeadDefinitions(r *report.Report, ws source.Workspace, roots []FullName, files ...*File) {
+	g := new(refGraph)
+	g.referenced = make(map[FullName]bool)
+	for _, file := range files {
+		g.addFile(file)
+	}
+	slices.SortFunc(g.edges, cmpx.Key(func(e refEdge) FullName { return e.from }))
+
+	live := g.reachable(files, roots)
+	// Every definition that contains a live one.
+	containsLive := make(map[FullName]bool)
+	for name := range live {
+		for ; name != "" && !containsLive[name]; name = name.Parent() {
+			containsLive[name] = true
+		}
+	}
+
+	isDead := func(chain ...FullName) bool {
+		if g.referenced[chain[0]] || containsLive[chain[0]] {
+			return false
+		}
+		for _, name := range chain {
+			if live[name] {
+				return false
+			}
+		}
+		return true
+	}
+
+	var inWorkspace map[string]bool
+	if ws != nil {
+		inWorkspace = make(map[string]bool)
+		for _, path := range ws.Paths() {
+			inWorkspace[path] = true
+		}
+	}
+
+	for _, file := range files {
+		if inWorkspace != nil && !inWorkspace[file.Path()] {
+			continue
+		}
+
+		dead := make(map[Type]bool)
+		// scope returns the names of the scopes t is nested in, including t
+		// itself, and whether any of them is already dead.
+		scope := func(t Type) (chain []FullName, inDead bool) {
+			for ; !t.IsZero(); t = t.Parent() {
+				chain = append(chain, t.FullName())
+				inDead = inDead || dead[t]
+			}
+			for pkg := file.Package(); pkg != ""; pkg = pkg.Parent() {
+				chain = append(chain, pkg)
+			}
+			return chain, inDead
+		}
+
+		// AllTypes always lists a type before the types nested in it.
+		for ty := range seq.Values(file.AllTypes()) {
+			if ty.IsMapEntry() {
+				continue
+			}
+
+			chain, inDead := scope(ty)
+			if inDead || !isDead(chain...) {
+				continue
+			}
+
+			dead[ty] = true
+			r.Warn(errDeadDefinition{def: ty.AST(), noun: ty.noun(), name: ty.FullName()})
+		}
+
+		for ty := range seq.Values(file.AllTypes()) {
+			if !ty.IsEnum() {
+				continue
+			}
+
+			chain, inDead := scope(ty)
+			if inDead {
+				continue
+			}
+
+			for i, value := range seq.All(ty.Members()) {
+				if i == 0 {
+					continue
+				}
+
+				if isDead(append([]FullName{value.FullName()}, chain...)...) {
+					r.Warn(errDeadDefinition{def: value.AST(), noun: value.noun(), name: value.FullName()})
+				}
+			}
+		}
+
+		for extn := range seq.Values(file.AllExtensions()) {
+			chain, inDead := scope(extn.Parent())
+			if inDead {
+				continue
+			}
+
+			if isDead(append([]FullName{extn.FullName()}, chain...)...) {
+				r.Warn(errDeadDefinition{def: extn.AST(), noun: extn.noun(), name: extn.FullName()})
+			}
+		}
+	}
+}
+
+// refGraph is a graph of references between definitions, used by
+// [DiagnoseDeadDefinitions].
+type refGraph struct {
+	// Every reference, sorted by the definition the reference occurs in.
+	edges []refEdge
+	// All definitions referenced by something other than themselves, and
+	// the definitions they are nested in.
+	referenced map[FullName]bool
+}
+
+type refEdge struct {
+	from, to FullName
+}
+
+// ref records a reference to the definition named to, from within the
+// definition named from.
+func (g *refGraph) ref(from, to FullName) {
+	if to == "" {
+		return
+	}
+
+	g.edges = append(g.edges, refEdge{from, to})
+
+	// A reference to a nested definition is also a reference to the
+	// definitions it is nested in, except those the reference occurs in.
+	for ; to != "" && !within(from, to); to = to.Parent() {
+		g.referenced[to] = true
+	}
+}
+
+// addFile records all of the references in the given file.
+func (g *refGraph) addFile(file *File) {
+	g.options(file.Package(), file.Options())
+
+	for ty := range seq.Values(file.AllTypes()) {
+		g.options(ty.FullName(), ty.Options())
+		for oneof := range seq.Values(ty.Oneofs()) {
+			g.options(oneof.FullName(), oneof.Options())
+		}
+		for rr := range seq.Values(ty.ExtensionRanges()) {
+			g.options(ty.FullName(), rr.Options())
+		}
+	}
+
+	for m := range file.AllMembers() {
+		g.options(m.FullName(), m.Options())
+
+		if m.IsExtension() {
+			g.ref(m.FullName(), m.Container().FullName())
+		}
+		if elem := m.Element(); !elem.IsZero() && !elem.IsPredeclared() {
+			g.ref(m.FullName(), elem.FullName())
+		}
+	}
+
+	for s := range seq.Values(file.Services()) {
+		g.options(s.FullName(), s.Options())
+
+		for m := range seq.Values(s.Methods()) {
+			g.options(m.FullName(), m.Options())
+
+			in, _ := m.Input()
+			out, _ := m.Output()
+			g.ref(m.FullName(), in.FullName())
+			g.ref(m.FullName(), out.FullName())
+		}
+	}
+}
+
+// options records all of the references in an option value.
+func (g *refGraph) options(from FullName, value MessageValue) {
+	for field := range value.Fields() {
+		if field.Field().IsExtension() {
+			g.ref(from, field.Field().FullName())
+		}
+
+		for elem := range seq.Values(field.Elements()) {
+			if enum := elem.AsEnum(); !enum.IsZero() {
+				g.ref(from, enum.FullName())
+			} else if msg := elem.AsMessage(); !msg.IsZero() {
+				if concrete := msg.Concrete(); concrete != msg {
+					// This is an Any with an explicit type URL.
+					g.ref(from, concrete.Type().FullName())
+					msg = concrete
+				}
+				g.options(from, msg)
+			}
+		}
+	}
+}
+
+// reachable returns the set of definitions reachable from the services in
+// files and the given roots.
+func (g *refGraph) reachable(files []*File, roots []FullName) map[FullName]bool {
+	var queue []FullName
+	for _, root := range roots {
+		queue = append(queue, root.ToRelative())
+	}
+	for _, file := range files {
+		for s := range seq.Values(file.Services()) {
+			queue = append(queue, s.FullName())
+		}
+	}
+
+	live := make(map[FullName]bool)
+	for len(queue) > 0 {
+		name := queue[len(queue)-1]
+		queue = queue[:len(queue)-1]
+		if live[name] {
+			continue
+		}
+		live[name] = true
+
+		// Follow every edge from name, or from anything nested in it. Because
+		// the edges are sorted, these form a contiguous range: everything in
+		// it starts with name, followed by either nothing or a ".", because no
+		// identifier contains a character that sorts before ".".
+		start, _ := slices.BinarySearchFunc(g.edges, name, func(e refEdge, n FullName) int {
+			return strings.Compare(string(e.from), string(n))
+		})
+		for _, e := range g.edges[start:] {
+			if !within(e.from, name) {
+				break
+			}
+			queue = append(queue, e.to)
+		}
+	}
+	return live
+}
+
+// within returns whether the definition named inner is nested in the one named
+// outer, or is the same definition.
+func within(inner, outer FullName) bool {
+	rest, ok := strings.CutPrefix(string(inner), string(outer))
+	return ok && (rest == "" || rest[0] == '.')
+}
+
+// errDeadDefinition diagnoses a definition that is never referenced.
+type errDeadDefinition struct {
+	def  ast.DeclDef
+	noun taxa.Noun
+	name FullName
+}
+
+func (e errDeadDefinition) Diagnose(d *report.Diagnostic) {
+	d.Apply(
+		report.Message("%s `%s` is never used", e.noun, e.name),
+		report.Snippet(e.def.Name()),
+		report.SuggestEdits(e.def, "delete it", report.Edit{
+			Start: 0, End: e.def.Span().Len(),
+		}),
+		report.Helpf("nothing in the workspace refers to this %s, and it is not reachable from a service or root", e.noun),
+		report.Tag(rtags.DeadDefinition),
+	)
+}
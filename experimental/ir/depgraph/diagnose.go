@@ -0,0 +1,216 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package depgraph
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/bufbuild/protocompile/experimental/ir"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
+)
+
+// DiagnoseCycles reports a warning for each set of messages that contain each
+// other through their fields, directly or indirectly.
+//
+// Each set of mutually recursive messages is reported once, along with the
+// shortest cycle through the one whose name sorts first.
+func (g *Graph) DiagnoseCycles(r *report.Report) {
+	fields := make(map[string][]Edge)
+	for _, edge := range g.edges {
+		if edge.Kind != EdgeKindField || !g.isMessage(edge.From) || !g.isMessage(edge.To) {
+			continue
+		}
+		fields[edge.From] = append(fields[edge.From], edge)
+	}
+
+	for _, scc := range components(g.nodes, fields) {
+		start := slices.Min(scc)
+		cycle := shortestCycle(start, scc, fields)
+		if len(cycle) > 0 {
+			r.Warn(errRecursiveMessage{cycle: cycle})
+		}
+	}
+}
+
+// DiagnoseLayering reports an error for each import that crosses from one
+// package to another, where that dependency is not allowed.
+//
+// allowed maps each package to the packages it may import; allowing a
+// package also allows its sub-packages. Only imports from packages that are
+// keys of allowed are checked, and files may always import other files in
+// the same package. Note that imports of well-known types, such as
+// google.protobuf, must be allowed explicitly.
+func (g *Graph) DiagnoseLayering(r *report.Report, allowed map[ir.FullName][]ir.FullName) {
+	for _, edge := range g.edges {
+		if edge.Kind != EdgeKindImport {
+			continue
+		}
+		from, _ := g.Node(edge.From)
+		to, _ := g.Node(edge.To)
+		deps, ok := allowed[from.Package]
+		if !ok || from.Package == to.Package || inPackages(to.Package, deps) {
+			continue
+		}
+		r.Error(errLayeringViolation{import_: edge, from: from.Package, to: to.Package, allowed: deps})
+	}
+}
+
+func (g *Graph) isMessage(name string) bool {
+	node, _ := g.Node(name)
+	return node.Kind == NodeKindMessage
+}
+
+// components returns the strongly connected components of the graph with the
+// given edges that contain a cycle, using Tarjan's algorithm.
+func components(nodes []Node, edges map[string][]Edge) [][]string {
+	type state struct {
+		index, low int
+		onStack    bool
+	}
+	var (
+		states = make(map[string]*state)
+		stack  []string
+		out    [][]string
+	)
+
+	var visit func(string)
+	visit = func(name string) {
+		s := &state{index: len(states), low: len(states), onStack: true}
+		states[name] = s
+		stack = append(stack, name)
+
+		for _, edge := range edges[name] {
+			next, ok := states[edge.To]
+			switch {
+			case !ok:
+				visit(edge.To)
+				s.low = min(s.low, states[edge.To].low)
+			case next.onStack:
+				s.low = min(s.low, next.index)
+			}
+		}
+		if s.low != s.index {
+			return
+		}
+
+		i := slices.Index(stack, name)
+		scc := slices.Clone(stack[i:])
+		stack = stack[:i]
+		for _, name := range scc {
+			states[name].onStack = false
+		}
+
+		selfLoop := slices.ContainsFunc(edges[name], func(e Edge) bool { return e.To == name })
+		if len(scc) > 1 || selfLoop {
+			out = append(out, scc)
+		}
+	}
+
+	for _, node := range nodes {
+		if _, ok := states[node.Name]; !ok {
+			visit(node.Name)
+		}
+	}
+	return out
+}
+
+// shortestCycle returns the edges of the shortest cycle from start back to
+// itself that stays within scc.
+func shortestCycle(start string, scc []string, edges map[string][]Edge) []Edge {
+	prev := make(map[string]Edge)
+	queue := []string{start}
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		for _, edge := range edges[name] {
+			if !slices.Contains(scc, edge.To) {
+				continue
+			}
+			if edge.To == start {
+				cycle := []Edge{edge}
+				for at := name; at != start; at = prev[at].From {
+					cycle = append(cycle, prev[at])
+				}
+				slices.Reverse(cycle)
+				return cycle
+			}
+			if _, seen := prev[edge.To]; !seen {
+				prev[edge.To] = edge
+				queue = append(queue, edge.To)
+			}
+		}
+	}
+	return nil
+}
+
+// errRecursiveMessage diagnoses a cycle of messages that contain each other.
+type errRecursiveMessage struct {
+	cycle []Edge // The edges of the cycle, starting at the first message.
+}
+
+func (e errRecursiveMessage) Diagnose(d *report.Diagnostic) {
+	if len(e.cycle) == 1 {
+		d.Apply(report.Message("message `%s` contains itself", e.cycle[0].From))
+	} else {
+		names := make([]string, len(e.cycle))
+		for i, edge := range e.cycle {
+			names[i] = fmt.Sprintf("`%s`", edge.From)
+		}
+		d.Apply(report.Message("messages %s and %s contain each other",
+			strings.Join(names[:len(names)-1], ", "), names[len(names)-1]))
+	}
+
+	for _, edge := range e.cycle {
+		if !edge.Span.IsZero() {
+			d.Apply(report.Snippetf(edge.Span, "`%s` refers to `%s` here", edge.From, edge.To))
+		}
+	}
+	d.Apply(
+		report.Helpf("recursive messages are permitted, but some code generators and schema tools cannot represent them"),
+		report.Tag(rtags.RecursiveMessage),
+	)
+}
+
+// errLayeringViolation diagnoses an import of a package that its importer is
+// not allowed to depend on.
+type errLayeringViolation struct {
+	import_  Edge
+	from, to ir.FullName
+	allowed  []ir.FullName
+}
+
+func (e errLayeringViolation) Diagnose(d *report.Diagnostic) {
+	if e.to == "" {
+		d.Apply(report.Message("package `%s` may not depend on a file with no package", e.from))
+	} else {
+		d.Apply(report.Message("package `%s` may not depend on package `%s`", e.from, e.to))
+	}
+	d.Apply(report.Snippetf(e.import_.Span, "imported here"))
+
+	if len(e.allowed) == 0 {
+		d.Apply(report.Helpf("`%s` may only import files in the same package", e.from))
+	} else {
+		names := make([]string, len(e.allowed))
+		for i, pkg := range e.allowed {
+			names[i] = fmt.Sprintf("`%s`", pkg)
+		}
+		d.Apply(report.Helpf("`%s` may only import files in the same package, or in %s",
+			e.from, strings.Join(names, ", ")))
+	}
+	d.Apply(report.Tag(rtags.LayeringViolation))
+}
@@ -0,0 +1,31 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package depgraph builds graphs of the dependencies between Protobuf
+// definitions, for visualizing and checking the architecture of a schema.
+//
+// [New] builds a [Graph] from a set of lowered files. Its nodes are the
+// files, and the messages, enums, extensions, and methods in them. Its edges
+// record which types each definition depends on: the types of a message's
+// fields, the input and output of a method, the message an extension extends,
+// and the types of the custom options set on a definition. Edges between
+// files record their imports.
+//
+// A graph can be narrowed with [Graph.Filter], and rendered with
+// [Graph.DOT], [Graph.Mermaid], or as JSON. [Graph.DiagnoseCycles] and
+// [Graph.DiagnoseLayering] check the graph for recursive messages and for
+// imports that cross package boundaries in directions they should not.
+package depgraph
+
+//go:generate go run github.com/bufbuild/protocompile/internal/enum kinds.yaml
@@ -0,0 +1,228 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package depgraph
+
+import (
+	"encoding/json"
+	"fmt"
+	"iter"
+	"strconv"
+	"strings"
+
+	"github.com/bufbuild/protocompile/experimental/ir"
+)
+
+// DOT renders g in the Graphviz DOT language.
+//
+// Nodes are grouped into clusters by package, and labeled with their names
+// relative to their package. Imports are drawn dashed, and options dotted.
+func (g *Graph) DOT() string {
+	var out strings.Builder
+	out.WriteString("digraph {\n  rankdir=LR;\n")
+
+	for pkg, nodes := range g.packages() {
+		indent := "  "
+		if pkg != "" {
+			fmt.Fprintf(&out, "  subgraph %s {\n    label=%s;\n",
+				strconv.Quote("cluster_"+string(pkg)), strconv.Quote(string(pkg)))
+			indent = "    "
+		}
+		for _, node := range nodes {
+			fmt.Fprintf(&out, "%s%s [label=%s, shape=%s];\n", indent,
+				strconv.Quote(node.Name), strconv.Quote(node.label()), dotShapes[node.Kind])
+		}
+		if pkg != "" {
+			out.WriteString("  }\n")
+		}
+	}
+
+	for _, edge := range g.edges {
+		var attrs []string
+		if label := edge.label(); label != "" {
+			attrs = append(attrs, "label="+strconv.Quote(label))
+		}
+		switch edge.Kind {
+		case EdgeKindImport:
+			attrs = append(attrs, "style=dashed")
+		case EdgeKindOption:
+			attrs = append(attrs, "style=dotted")
+		}
+		fmt.Fprintf(&out, "  %s -> %s", strconv.Quote(edge.From), strconv.Quote(edge.To))
+		if len(attrs) > 0 {
+			fmt.Fprintf(&out, " [%s]", strings.Join(attrs, ", "))
+		}
+		out.WriteString(";\n")
+	}
+
+	out.WriteString("}\n")
+	return out.String()
+}
+
+var dotShapes = [...]string{
+	NodeKindInvalid:   "box",
+	NodeKindFile:      "note",
+	NodeKindMessage:   "box",
+	NodeKindEnum:      "ellipse",
+	NodeKindExtension: "parallelogram",
+	NodeKindMethod:    "cds",
+}
+
+// Mermaid renders g as a Mermaid flowchart.
+//
+// Nodes are grouped into subgraphs by package, and labeled with their names
+// relative to their package. Imports and options are drawn dotted.
+func (g *Graph) Mermaid() string {
+	var out strings.Builder
+	out.WriteString("flowchart LR\n")
+
+	// Mermaid identifiers cannot contain most punctuation, so nodes and
+	// subgraphs are numbered instead.
+	ids := make(map[string]string)
+	var subgraphs int
+	for pkg, nodes := range g.packages() {
+		indent := "  "
+		if pkg != "" {
+			fmt.Fprintf(&out, "  subgraph p%d [%s]\n", subgraphs, mermaidQuote(string(pkg)))
+			subgraphs++
+			indent = "    "
+		}
+		for _, node := range nodes {
+			id := fmt.Sprintf("n%d", len(ids))
+			ids[node.Name] = id
+			shape := mermaidShapes[node.Kind]
+			fmt.Fprintf(&out, "%s%s%s%s%s\n", indent, id, shape[0], mermaidQuote(node.label()), shape[1])
+		}
+		if pkg != "" {
+			out.WriteString("  end\n")
+		}
+	}
+
+	for _, edge := range g.edges {
+		arrow := "-->"
+		switch edge.Kind {
+		case EdgeKindImport, EdgeKindOption:
+			arrow = "-.->"
+		}
+		if label := edge.label(); label != "" {
+			arrow += "|" + mermaidQuote(label) + "|"
+		}
+		fmt.Fprintf(&out, "  %s %s %s\n", ids[edge.From], arrow, ids[edge.To])
+	}
+	return out.String()
+}
+
+var mermaidShapes = [...][2]string{
+	NodeKindInvalid:   {"[", "]"},
+	NodeKindFile:      {"[/", "/]"},
+	NodeKindMessage:   {"[", "]"},
+	NodeKindEnum:      {"([", "])"},
+	NodeKindExtension: {">", "]"},
+	NodeKindMethod:    {"{{", "}}"},
+}
+
+// mermaidQuote quotes a label for Mermaid, which does not support escaping
+// quotes within them, only HTML entities.
+func mermaidQuote(s string) string {
+	return `"` + strings.ReplaceAll(s, `"`, "#quot;") + `"`
+}
+
+// MarshalJSON implements [json.Marshaler].
+//
+// The graph is encoded as an object with "nodes" and "edges" arrays. Each
+// node has a "name", "kind", and "package", and the "file" it is defined in;
+// each edge has a "from", "to", "kind", and "label". Empty fields are
+// omitted.
+func (g *Graph) MarshalJSON() ([]byte, error) {
+	type jsonNode struct {
+		Name    string `json:"name"`
+		Kind    string `json:"kind"`
+		Package string `json:"package,omitempty"`
+		File    string `json:"file,omitempty"`
+	}
+	type jsonEdge struct {
+		From  string `json:"from"`
+		To    string `json:"to"`
+		Kind  string `json:"kind"`
+		Label string `json:"label,omitempty"`
+	}
+
+	graph := struct {
+		Nodes []jsonNode `json:"nodes"`
+		Edges []jsonEdge `json:"edges"`
+	}{
+		Nodes: make([]jsonNode, 0, len(g.nodes)),
+		Edges: make([]jsonEdge, 0, len(g.edges)),
+	}
+	for _, node := range g.nodes {
+		var file string
+		if node.Kind != NodeKindFile && !node.Span.IsZero() {
+			file = node.Span.Path()
+		}
+		graph.Nodes = append(graph.Nodes, jsonNode{
+			Name: node.Name, Kind: node.Kind.String(),
+			Package: string(node.Package), File: file,
+		})
+	}
+	for _, edge := range g.edges {
+		graph.Edges = append(graph.Edges, jsonEdge{
+			From: edge.From, To: edge.To, Kind: edge.Kind.String(),
+			Label: edge.Label,
+		})
+	}
+	return json.Marshal(graph)
+}
+
+// packages groups the nodes of g by package, in the order each package first
+// appears.
+func (g *Graph) packages() iter.Seq2[ir.FullName, []Node] {
+	var order []ir.FullName
+	byPackage := make(map[ir.FullName][]Node)
+	for _, node := range g.nodes {
+		if _, ok := byPackage[node.Package]; !ok {
+			order = append(order, node.Package)
+		}
+		byPackage[node.Package] = append(byPackage[node.Package], node)
+	}
+
+	return func(yield func(ir.FullName, []Node) bool) {
+		for _, pkg := range order {
+			if !yield(pkg, byPackage[pkg]) {
+				return
+			}
+		}
+	}
+}
+
+// label returns the label to display for n: its name relative to its
+// package.
+func (n Node) label() string {
+	if n.Kind == NodeKindFile || n.Package == "" {
+		return n.Name
+	}
+	return strings.TrimPrefix(n.Name, string(n.Package)+".")
+}
+
+// label returns the label to display for e.
+func (e Edge) label() string {
+	switch e.Kind {
+	case EdgeKindField:
+		return e.Label
+	case EdgeKindOption:
+		return "(" + e.Label + ")"
+	case EdgeKindInput, EdgeKindOutput, EdgeKindExtendee:
+		return e.Kind.String()
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package depgraph
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/bufbuild/protocompile/experimental/ir"
+)
+
+// Filter selects part of a [Graph]. See [Graph.Filter].
+type Filter struct {
+	// The packages whose nodes to select. A package also selects its
+	// sub-packages: "acme" selects both "acme" and "acme.v1".
+	Packages []ir.FullName
+
+	// The names of additional nodes to select.
+	Roots []string
+
+	// How many edges away from the selected nodes to follow their
+	// dependencies. If zero, only the selected nodes are kept; if negative,
+	// all of their transitive dependencies are.
+	Depth int
+}
+
+// Filter returns the subgraph of g selected by f: the nodes that f selects,
+// together with the nodes they depend on up to f.Depth edges away, and the
+// edges between them.
+//
+// If f selects no packages or roots, it selects every node.
+func (g *Graph) Filter(f Filter) *Graph {
+	if len(f.Packages) == 0 && len(f.Roots) == 0 {
+		return g
+	}
+
+	// Breadth-first search from the selected nodes, one layer per depth.
+	keep := make(map[string]bool)
+	var layer []string
+	for _, node := range g.nodes {
+		if inPackages(node.Package, f.Packages) || slices.Contains(f.Roots, node.Name) {
+			keep[node.Name] = true
+			layer = append(layer, node.Name)
+		}
+	}
+
+	deps := make(map[string][]string)
+	for _, edge := range g.edges {
+		deps[edge.From] = append(deps[edge.From], edge.To)
+	}
+	for depth := 0; len(layer) > 0 && (f.Depth < 0 || depth < f.Depth); depth++ {
+		var next []string
+		for _, name := range layer {
+			for _, dep := range deps[name] {
+				if !keep[dep] {
+					keep[dep] = true
+					next = append(next, dep)
+				}
+			}
+		}
+		layer = next
+	}
+
+	out := &Graph{index: make(map[string]int)}
+	for _, node := range g.nodes {
+		if keep[node.Name] {
+			out.add(node)
+		}
+	}
+	for _, edge := range g.edges {
+		if keep[edge.From] && keep[edge.To] {
+			out.edges = append(out.edges, edge)
+		}
+	}
+	return out
+}
+
+// inPackages returns whether pkg is one of pkgs, or a sub-package of one.
+func inPackages(pkg ir.FullName, pkgs []ir.FullName) bool {
+	for _, want := range pkgs {
+		want = want.ToRelative()
+		rest, ok := strings.CutPrefix(string(pkg), string(want))
+		if ok && (rest == "" || rest[0] == '.') {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,256 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package depgraph
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/bufbuild/protocompile/experimental/ir"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/source"
+)
+
+// Graph is a graph of dependencies between definitions and files. See [New].
+type Graph struct {
+	nodes []Node
+	edges []Edge
+	index map[string]int // Indices into nodes, by name.
+}
+
+// Node is a definition or a file in a [Graph].
+type Node struct {
+	// The fully-qualified name of the definition, or the path of the file.
+	Name    string
+	Kind    NodeKind
+	Package ir.FullName
+
+	// The span of the definition's name. Zero for files.
+	Span source.Span
+}
+
+// Edge is a dependency of one [Node] on another.
+type Edge struct {
+	// The names of the nodes that depend on each other: From depends on To.
+	From, To string
+	Kind     EdgeKind
+
+	// Describes the dependency in more detail: for fields, the name of the
+	// field, and for options, the name of the extension. May be empty.
+	Label string
+
+	// Where the dependency is written, such as the type of a field, or an
+	// import declaration.
+	Span source.Span
+}
+
+// New builds a graph of the dependencies of the definitions in files.
+//
+// Every definition in files is a node, as are the definitions they depend on
+// in other files. Only the options that are extensions are included as
+// dependencies, since every file can use the options in descriptor.proto.
+// Maps are treated as a dependency on their value type; the synthetic types
+// for their entries are not included. There is at most one edge of each kind
+// between two nodes: if a message has several fields of the same type, only
+// the first is recorded.
+func New(files ...*ir.File) *Graph {
+	b := &builder{
+		g:     &Graph{index: make(map[string]int)},
+		edges: make(map[edgeKey]bool),
+	}
+	for _, file := range files {
+		b.file(file)
+	}
+
+	slices.SortFunc(b.g.edges, func(a, b Edge) int {
+		return cmp.Or(
+			cmp.Compare(a.From, b.From),
+			cmp.Compare(a.To, b.To),
+			cmp.Compare(a.Kind, b.Kind),
+		)
+	})
+	return b.g
+}
+
+// Nodes returns the nodes of g, in the order they were added.
+func (g *Graph) Nodes() []Node {
+	return g.nodes
+}
+
+// Edges returns the edges of g, sorted by the names of the nodes they
+// connect.
+func (g *Graph) Edges() []Edge {
+	return g.edges
+}
+
+// Node returns the node with the given name.
+func (g *Graph) Node(name string) (Node, bool) {
+	i, ok := g.index[name]
+	if !ok {
+		return Node{}, false
+	}
+	return g.nodes[i], true
+}
+
+// add adds a node to g, unless it already has a node with the same name.
+func (g *Graph) add(node Node) {
+	if _, ok := g.index[node.Name]; ok {
+		return
+	}
+	g.index[node.Name] = len(g.nodes)
+	g.nodes = append(g.nodes, node)
+}
+
+// builder is the state for [New].
+type builder struct {
+	g     *Graph
+	edges map[edgeKey]bool
+}
+
+type edgeKey struct {
+	from, to string
+	kind     EdgeKind
+}
+
+// edge adds an edge to the graph, unless it is a duplicate of an existing one.
+func (b *builder) edge(edge Edge) {
+	key := edgeKey{edge.From, edge.To, edge.Kind}
+	if b.edges[key] {
+		return
+	}
+	b.edges[key] = true
+	b.g.edges = append(b.g.edges, edge)
+}
+
+func (b *builder) file(file *ir.File) {
+	b.g.add(Node{Name: file.Path(), Kind: NodeKindFile, Package: file.Package()})
+	for imp := range seq.Values(file.Imports()) {
+		if !imp.Direct {
+			continue
+		}
+		b.g.add(Node{Name: imp.Path(), Kind: NodeKindFile, Package: imp.Package()})
+		b.edge(Edge{
+			From: file.Path(), To: imp.Path(), Kind: EdgeKindImport,
+			Span: imp.Decl.ImportPath().Span(),
+		})
+	}
+	b.options(file.Path(), file.Options())
+
+	for ty := range seq.Values(file.AllTypes()) {
+		if ty.IsMapEntry() {
+			continue
+		}
+		from := b.ty(ty)
+		b.options(from, ty.Options())
+		for oneof := range seq.Values(ty.Oneofs()) {
+			b.options(from, oneof.Options())
+		}
+		for member := range seq.Values(ty.Members()) {
+			b.options(from, member.Options())
+			if ty.IsMessage() {
+				b.field(from, member)
+			}
+		}
+	}
+
+	for extn := range seq.Values(file.AllExtensions()) {
+		from := string(extn.FullName())
+		b.g.add(Node{
+			Name: from, Kind: NodeKindExtension, Package: file.Package(),
+			Span: extn.AST().Name().Span(),
+		})
+		if extendee := extn.Container(); !extendee.IsZero() {
+			b.edge(Edge{
+				From: from, To: b.ty(extendee), Kind: EdgeKindExtendee,
+				Span: extn.Extend().AST().Name().Span(),
+			})
+		}
+		b.field(from, extn)
+		b.options(from, extn.Options())
+	}
+
+	for service := range seq.Values(file.Services()) {
+		for method := range seq.Values(service.Methods()) {
+			from := string(method.FullName())
+			b.g.add(Node{
+				Name: from, Kind: NodeKindMethod, Package: file.Package(),
+				Span: method.AST().Name().Span(),
+			})
+
+			sig := method.AST().Signature()
+			in, _ := method.Input()
+			out, _ := method.Output()
+			if !in.IsZero() {
+				b.edge(Edge{
+					From: from, To: b.ty(in), Kind: EdgeKindInput,
+					Span: sig.Inputs().Span(),
+				})
+			}
+			if !out.IsZero() {
+				b.edge(Edge{
+					From: from, To: b.ty(out), Kind: EdgeKindOutput,
+					Span: sig.Outputs().Span(),
+				})
+			}
+			b.options(from, method.Options())
+		}
+	}
+}
+
+// ty adds a node for a message or enum type, and returns its name.
+func (b *builder) ty(ty ir.Type) string {
+	kind := NodeKindMessage
+	if ty.IsEnum() {
+		kind = NodeKindEnum
+	}
+	b.g.add(Node{
+		Name: string(ty.FullName()), Kind: kind, Package: ty.Context().Package(),
+		Span: ty.AST().Name().Span(),
+	})
+	return string(ty.FullName())
+}
+
+// field adds an edge for the type of a field or extension.
+func (b *builder) field(from string, field ir.Member) {
+	elem := field.Element()
+	if elem.IsMapEntry() && elem.Members().Len() == 2 {
+		elem = elem.Members().At(1).Element()
+	}
+	if elem.IsZero() || elem.IsPredeclared() {
+		return
+	}
+	b.edge(Edge{
+		From: from, To: b.ty(elem), Kind: EdgeKindField,
+		Label: field.Name(),
+		Span:  field.TypeAST().Span(),
+	})
+}
+
+// options adds edges for the custom options set in value.
+func (b *builder) options(from string, value ir.MessageValue) {
+	for field := range value.Fields() {
+		extn := field.Field()
+		if !extn.IsExtension() {
+			continue
+		}
+		if elem := extn.Element(); !elem.IsZero() && !elem.IsPredeclared() {
+			b.edge(Edge{
+				From: from, To: b.ty(elem), Kind: EdgeKindOption,
+				Label: string(extn.FullName()),
+				Span:  field.KeyAST().Span(),
+			})
+		}
+	}
+}
@@ -0,0 +1,276 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package depgraph_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bufbuild/protocompile/experimental/incremental"
+	"github.com/bufbuild/protocompile/experimental/incremental/queries"
+	"github.com/bufbuild/protocompile/experimental/ir"
+	"github.com/bufbuild/protocompile/experimental/ir/depgraph"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
+	"github.com/bufbuild/protocompile/experimental/source"
+)
+
+var testFiles = map[string]string{
+	"acme/common/v1/money.proto": `syntax = "proto3";
+package acme.common.v1;
+
+message Money {
+  string currency = 1;
+  int64 units = 2;
+}
+`,
+	"acme/api/v1/api.proto": `syntax = "proto3";
+package acme.api.v1;
+
+message Request {}
+`,
+	"acme/billing/v1/billing.proto": `syntax = "proto3";
+package acme.billing.v1;
+
+import "acme/api/v1/api.proto";
+import "acme/common/v1/money.proto";
+import "google/protobuf/descriptor.proto";
+
+extend google.protobuf.MessageOptions {
+  Rules rules = 50000;
+}
+
+message Rules {
+  bool strict = 1;
+}
+
+message Invoice {
+  option (rules).strict = true;
+  acme.common.v1.Money total = 1;
+  repeated Line lines = 2;
+  map<string, acme.common.v1.Money> fees = 3;
+  Status status = 4;
+  acme.api.v1.Request request = 5;
+}
+
+message Line {
+  Invoice invoice = 1;
+  Line next = 2;
+}
+
+enum Status {
+  STATUS_UNSPECIFIED = 0;
+}
+
+service Billing {
+  rpc Get(Invoice) returns (Line);
+}
+`,
+}
+
+func TestNew(t *testing.T) {
+	t.Parallel()
+
+	g := build(t)
+	assert.Equal(t, []string{
+		"acme.billing.v1.Billing.Get -input-> acme.billing.v1.Invoice",
+		"acme.billing.v1.Billing.Get -output-> acme.billing.v1.Line",
+		"acme.billing.v1.Invoice -field(request)-> acme.api.v1.Request",
+		"acme.billing.v1.Invoice -field(lines)-> acme.billing.v1.Line",
+		"acme.billing.v1.Invoice -option(acme.billing.v1.rules)-> acme.billing.v1.Rules",
+		"acme.billing.v1.Invoice -field(status)-> acme.billing.v1.Status",
+		"acme.billing.v1.Invoice -field(total)-> acme.common.v1.Money",
+		"acme.billing.v1.Line -field(invoice)-> acme.billing.v1.Invoice",
+		"acme.billing.v1.Line -field(next)-> acme.billing.v1.Line",
+		"acme.billing.v1.rules -field(rules)-> acme.billing.v1.Rules",
+		"acme.billing.v1.rules -extendee-> google.protobuf.MessageOptions",
+		"acme/billing/v1/billing.proto -import-> acme/api/v1/api.proto",
+		"acme/billing/v1/billing.proto -import-> acme/common/v1/money.proto",
+		"acme/billing/v1/billing.proto -import-> google/protobuf/descriptor.proto",
+	}, edges(g))
+
+	node, ok := g.Node("acme.billing.v1.Invoice")
+	require.True(t, ok)
+	assert.Equal(t, depgraph.NodeKindMessage, node.Kind)
+	assert.Equal(t, ir.FullName("acme.billing.v1"), node.Package)
+	assert.Equal(t, "Invoice", node.Span.Text())
+
+	node, ok = g.Node("google.protobuf.MessageOptions")
+	require.True(t, ok)
+	assert.Equal(t, ir.FullName("google.protobuf"), node.Package)
+}
+
+func TestFilter(t *testing.T) {
+	t.Parallel()
+
+	g := build(t)
+
+	filtered := g.Filter(depgraph.Filter{Packages: []ir.FullName{"acme.common"}})
+	assert.Equal(t, []string{"acme/common/v1/money.proto", "acme.common.v1.Money"}, names(filtered))
+	assert.Empty(t, edges(filtered))
+
+	filtered = g.Filter(depgraph.Filter{Roots: []string{"acme.billing.v1.Billing.Get"}, Depth: 1})
+	assert.Equal(t, []string{
+		"acme.billing.v1.Invoice",
+		"acme.billing.v1.Line",
+		"acme.billing.v1.Billing.Get",
+	}, names(filtered))
+
+	filtered = g.Filter(depgraph.Filter{Roots: []string{"acme.billing.v1.Line"}, Depth: -1})
+	assert.Equal(t, []string{
+		"acme.api.v1.Request",
+		"acme.billing.v1.Rules",
+		"acme.billing.v1.Invoice",
+		"acme.common.v1.Money",
+		"acme.billing.v1.Line",
+		"acme.billing.v1.Status",
+	}, names(filtered))
+}
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+
+	g := build(t).Filter(depgraph.Filter{Roots: []string{"acme.billing.v1.Billing.Get"}, Depth: 1})
+
+	assert.Equal(t, `digraph {
+  rankdir=LR;
+  subgraph "cluster_acme.billing.v1" {
+    label="acme.billing.v1";
+    "acme.billing.v1.Invoice" [label="Invoice", shape=box];
+    "acme.billing.v1.Line" [label="Line", shape=box];
+    "acme.billing.v1.Billing.Get" [label="Billing.Get", shape=cds];
+  }
+  "acme.billing.v1.Billing.Get" -> "acme.billing.v1.Invoice" [label="input"];
+  "acme.billing.v1.Billing.Get" -> "acme.billing.v1.Line" [label="output"];
+  "acme.billing.v1.Invoice" -> "acme.billing.v1.Line" [label="lines"];
+  "acme.billing.v1.Line" -> "acme.billing.v1.Invoice" [label="invoice"];
+  "acme.billing.v1.Line" -> "acme.billing.v1.Line" [label="next"];
+}
+`, g.DOT())
+
+	assert.Equal(t, `flowchart LR
+  subgraph p0 ["acme.billing.v1"]
+    n0["Invoice"]
+    n1["Line"]
+    n2{{"Billing.Get"}}
+  end
+  n2 -->|"input"| n0
+  n2 -->|"output"| n1
+  n0 -->|"lines"| n1
+  n1 -->|"invoice"| n0
+  n1 -->|"next"| n1
+`, g.Mermaid())
+
+	data, err := json.Marshal(build(t).Filter(depgraph.Filter{Packages: []ir.FullName{"acme.common"}}))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{
+		"nodes": [
+			{"name": "acme/common/v1/money.proto", "kind": "file", "package": "acme.common.v1"},
+			{"name": "acme.common.v1.Money", "kind": "message", "package": "acme.common.v1", "file": "acme/common/v1/money.proto"}
+		],
+		"edges": []
+	}`, string(data))
+}
+
+func TestDiagnoseCycles(t *testing.T) {
+	t.Parallel()
+
+	r := new(report.Report)
+	build(t).DiagnoseCycles(r)
+	require.Len(t, r.Diagnostics, 1)
+
+	d := r.Diagnostics[0]
+	assert.True(t, d.Is(rtags.RecursiveMessage))
+	assert.Equal(t, report.Warning, d.Level())
+	assert.Equal(t, "messages `acme.billing.v1.Invoice` and `acme.billing.v1.Line` contain each other", d.Message())
+}
+
+func TestDiagnoseLayering(t *testing.T) {
+	t.Parallel()
+
+	g := build(t)
+
+	r := new(report.Report)
+	g.DiagnoseLayering(r, map[ir.FullName][]ir.FullName{
+		"acme.billing.v1": {"acme.common", "google.protobuf"},
+		"acme.common.v1":  nil,
+	})
+	require.Len(t, r.Diagnostics, 1)
+
+	d := r.Diagnostics[0]
+	assert.True(t, d.Is(rtags.LayeringViolation))
+	assert.Equal(t, report.Error, d.Level())
+	assert.Equal(t, "package `acme.billing.v1` may not depend on package `acme.api.v1`", d.Message())
+
+	r = new(report.Report)
+	g.DiagnoseLayering(r, map[ir.FullName][]ir.FullName{
+		"acme.billing.v1": {"acme", "google.protobuf"},
+	})
+	assert.Empty(t, r.Diagnostics)
+}
+
+// build links testFiles and returns their dependency graph.
+func build(t *testing.T) *depgraph.Graph {
+	t.Helper()
+
+	sources := make(map[string]*source.File)
+	var paths []string
+	for path, text := range testFiles {
+		sources[path] = source.NewFile(path, text)
+		paths = append(paths, path)
+	}
+	slices.Sort(paths)
+
+	results, r, err := incremental.Run(t.Context(), incremental.New(), queries.Link{
+		Opener:    &source.Openers{source.NewMap(sources), source.WKTs()},
+		Session:   new(ir.Session),
+		Workspace: source.NewWorkspace(paths...),
+	})
+	require.NoError(t, err)
+	require.NoError(t, results[0].Fatal)
+	for _, d := range r.Diagnostics {
+		require.Greater(t, d.Level(), report.Error, "%v", d)
+	}
+
+	files := slices.DeleteFunc(results[0].Value, func(f *ir.File) bool {
+		return f == nil || sources[f.Path()] == nil
+	})
+	return depgraph.New(files...)
+}
+
+func names(g *depgraph.Graph) []string {
+	var out []string
+	for _, node := range g.Nodes() {
+		out = append(out, node.Name)
+	}
+	return out
+}
+
+func edges(g *depgraph.Graph) []string {
+	var out []string
+	for _, edge := range g.Edges() {
+		kind := edge.Kind.String()
+		if edge.Label != "" {
+			kind += "(" + edge.Label + ")"
+		}
+		out = append(out, fmt.Sprintf("%s -%s-> %s", edge.From, kind, edge.To))
+	}
+	return out
+}
@@ -0,0 +1,104 @@
+// Code generated by github.com/bufbuild/protocompile/internal/enum kinds.yaml. DO NOT EDIT.
+
+package depgraph
+
+import (
+	"fmt"
+	"iter"
+)
+
+// NodeKind is the kind of definition a [Node] represents.
+type NodeKind int8
+
+const (
+	NodeKindInvalid NodeKind = iota
+	NodeKindFile
+	NodeKindMessage
+	NodeKindEnum
+	NodeKindExtension
+	NodeKindMethod
+)
+
+// String implements [fmt.Stringer].
+func (v NodeKind) String() string {
+	if int(v) < 0 || int(v) > len(_table_NodeKind_String) {
+		return fmt.Sprintf("NodeKind(%v)", int(v))
+	}
+	return _table_NodeKind_String[v]
+}
+
+// GoString implements [fmt.GoStringer].
+func (v NodeKind) GoString() string {
+	if int(v) < 0 || int(v) > len(_table_NodeKind_GoString) {
+		return fmt.Sprintf("depgraph.NodeKind(%v)", int(v))
+	}
+	return _table_NodeKind_GoString[v]
+}
+
+// EdgeKind is the kind of dependency an [Edge] represents.
+type EdgeKind int8
+
+const (
+	EdgeKindInvalid  EdgeKind = iota
+	EdgeKindImport            // A file imports another file.
+	EdgeKindField             // A message or extension has a field of some type.
+	EdgeKindInput             // A method takes some type as input.
+	EdgeKindOutput            // A method returns some type as output.
+	EdgeKindExtendee          // An extension extends some message.
+	EdgeKindOption            // A definition sets a custom option of some type.
+)
+
+// String implements [fmt.Stringer].
+func (v EdgeKind) String() string {
+	if int(v) < 0 || int(v) > len(_table_EdgeKind_String) {
+		return fmt.Sprintf("EdgeKind(%v)", int(v))
+	}
+	return _table_EdgeKind_String[v]
+}
+
+// GoString implements [fmt.GoStringer].
+func (v EdgeKind) GoString() string {
+	if int(v) < 0 || int(v) > len(_table_EdgeKind_GoString) {
+		return fmt.Sprintf("depgraph.EdgeKind(%v)", int(v))
+	}
+	return _table_EdgeKind_GoString[v]
+}
+
+var _table_NodeKind_String = [...]string{
+	NodeKindInvalid:   "invalid",
+	NodeKindFile:      "file",
+	NodeKindMessage:   "message",
+	NodeKindEnum:      "enum",
+	NodeKindExtension: "extension",
+	NodeKindMethod:    "method",
+}
+
+var _table_NodeKind_GoString = [...]string{
+	NodeKindInvalid:   "depgraph.NodeKindInvalid",
+	NodeKindFile:      "depgraph.NodeKindFile",
+	NodeKindMessage:   "depgraph.NodeKindMessage",
+	NodeKindEnum:      "depgraph.NodeKindEnum",
+	NodeKindExtension: "depgraph.NodeKindExtension",
+	NodeKindMethod:    "depgraph.NodeKindMethod",
+}
+
+var _table_EdgeKind_String = [...]string{
+	EdgeKindInvalid:  "invalid",
+	EdgeKindImport:   "import",
+	EdgeKindField:    "field",
+	EdgeKindInput:    "input",
+	EdgeKindOutput:   "output",
+	EdgeKindExtendee: "extendee",
+	EdgeKindOption:   "option",
+}
+
+var _table_EdgeKind_GoString = [...]string{
+	EdgeKindInvalid:  "depgraph.EdgeKindInvalid",
+	EdgeKindImport:   "depgraph.EdgeKindImport",
+	EdgeKindField:    "depgraph.EdgeKindField",
+	EdgeKindInput:    "depgraph.EdgeKindInput",
+	EdgeKindOutput:   "depgraph.EdgeKindOutput",
+	EdgeKindExtendee: "depgraph.EdgeKindExtendee",
+	EdgeKindOption:   "depgraph.EdgeKindOption",
+}
+var _ iter.Seq[int] // Mark iter as used.
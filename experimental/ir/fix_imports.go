@@ -0,0 +1,151 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ir
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/source"
+)
+
+// unresolvedRef is a reference to a symbol that could not be found among a
+// file's transitive imports. See [SuggestImports].
+type unresolvedRef struct {
+	scope, name FullName
+	span        source.Span
+	accept      func(SymbolKind) bool
+}
+
+// SuggestImports takes a report and the given *[File]s of a workspace, and
+// for each [rtags.UnknownSymbol] diagnostic for a symbol that is defined in
+// one of the files but not imported, adds a copy of that diagnostic to the
+// report that suggests importing the right file.
+//
+// Lowering a single file cannot know about the other files in its workspace,
+// so this is called by queries.Link on its own report. The copies are marked
+// with [report.Supersede], so when the report is canonicalized, as reports
+// returned by incremental.Run are, they replace the diagnostics they copy.
+// Calling this more than once on the same report has no further effect.
+//
+// Diagnostics for symbols that are transitively imported already carry such a
+// suggestion.
+func SuggestImports(r *report.Report, files ...*File) {
+	defs := make(map[FullName]Symbol)
+	for _, file := range files {
+		for sym := range seq.Values(file.ExportedSymbols()) {
+			if sym.Kind() != SymbolKindPackage && sym.Context() == file {
+				defs[sym.FullName()] = sym
+			}
+		}
+	}
+
+	for _, file := range files {
+		for _, ref := range file.unresolved {
+			sym := ref.lookup(defs)
+			if sym.IsZero() || sym.Context() == file {
+				continue
+			}
+
+			path := sym.Context().Path()
+			help := fmt.Sprintf("`%s` is defined in %q", sym.FullName(), path)
+			suggested := slices.ContainsFunc(r.Diagnostics, func(d report.Diagnostic) bool {
+				return d.Is(rtags.UnknownSymbol) && d.Primary() == ref.span &&
+					slices.Contains(d.Help(), help)
+			})
+			if suggested {
+				continue
+			}
+
+			r.Errorf("cannot find `%s` in this scope", ref.name).Apply(
+				report.Tag(rtags.UnknownSymbol),
+				report.Snippetf(ref.span, "not visible in this scope"),
+				report.Snippetf(sym.Definition(), "found in unimported file"),
+				suggestImport(file.AST(), path, ref.name),
+				report.Helpf("%s", help),
+				report.Supersede,
+			)
+		}
+	}
+}
+
+// lookup looks up this reference among the given symbols, following
+// Protobuf's scoping rules: the name is resolved relative to each enclosing
+// scope in turn, starting at the innermost.
+func (ref unresolvedRef) lookup(defs map[FullName]Symbol) Symbol {
+	accept := func(name FullName) (Symbol, bool) {
+		sym, ok := defs[name]
+		return sym, ok && (ref.accept == nil || ref.accept(sym.Kind()))
+	}
+
+	if ref.name.Absolute() {
+		sym, _ := accept(ref.name.ToRelative())
+		return sym
+	}
+
+	for scope := ref.scope; ; scope = scope.Parent() {
+		if sym, ok := accept(scope.Append(string(ref.name))); ok {
+			return sym
+		}
+		if scope == "" {
+			return Symbol{}
+		}
+	}
+}
+
+// suggestImport returns a suggestion to add an import of path to file, in
+// order to bring name into scope.
+//
+// The import is placed in canonical position: imports are kept sorted by path,
+// and the first import goes after the package declaration.
+func suggestImport(file *ast.File, path string, name FullName) report.DiagnosticOption {
+	var before, after ast.DeclImport
+	for imp := range file.Imports() {
+		if imp.ImportPath().AsLiteral().AsString().Text() > path {
+			before = imp
+			break
+		}
+		after = imp
+	}
+
+	var offset int
+	replace := fmt.Sprintf("import %q;", path)
+	switch {
+	case !before.IsZero():
+		offset = before.Span().Start
+		replace += "\n"
+	case !after.IsZero():
+		offset = after.Span().End
+		replace = "\n" + replace
+	case !file.Package().IsZero():
+		offset = file.Package().Span().End
+		replace = "\n\n" + replace
+	case !file.Syntax().IsZero():
+		offset = file.Syntax().Span().End
+		replace = "\n\n" + replace
+	default:
+		replace += "\n\n"
+	}
+
+	return report.SuggestEdits(
+		file.Span().File.Span(offset, offset),
+		fmt.Sprintf("bring `%s` into scope", name),
+		report.Edit{Replace: replace},
+	)
+}
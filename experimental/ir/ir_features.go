@@ -87,6 +87,21 @@ func (fs FeatureSet) Lookup(field Member) Feature {
 	return fs.LookupCustom(Member{}, field)
 }
 
+// LookupName looks up a feature by the name of its google.protobuf.FeatureSet
+// member, such as "field_presence".
+//
+// Returns zero if there is no such feature.
+func (fs FeatureSet) LookupName(name string) Feature {
+	if fs.IsZero() {
+		return Feature{}
+	}
+	field := fs.Context().builtins().FeatureSet.MemberByName(name)
+	if field.IsZero() {
+		return Feature{}
+	}
+	return fs.Lookup(field)
+}
+
 // LookupCustom looks up a custom feature in the given extension's field.
 func (fs FeatureSet) LookupCustom(extension, field Member) Feature {
 	if fs.IsZero() {
@@ -77,6 +77,10 @@ type File struct {
 
 	dpBuiltins *builtins // Only non-nil for descriptor.proto.
 
+	// References that could not be resolved and that could be fixed by adding
+	// an import. See [SuggestImports].
+	unresolved []unresolvedRef
+
 	arenas struct {
 		types     arena.Arena[rawType]
 		members   arena.Arena[rawMember]
@@ -19,10 +19,15 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/incremental"
+	"github.com/bufbuild/protocompile/experimental/incremental/queries"
 	"github.com/bufbuild/protocompile/experimental/ir"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/source"
 	"github.com/bufbuild/protocompile/internal/ext/mapsx"
 	"github.com/bufbuild/protocompile/internal/intern"
 )
@@ -142,3 +147,29 @@ func transitive(f *ir.File) []imp {
 		return imp{i.File.Path(), i.Public, i.Weak}
 	}))
 }
+
+func TestSuggestImports(t *testing.T) {
+	t.Parallel()
+
+	sources := source.NewMap(map[string]*source.File{
+		"a.proto": source.NewFile("a.proto", "syntax = \"proto3\";\npackage p;\nmessage A { B b = 1; }\n"),
+		"b.proto": source.NewFile("b.proto", "syntax = \"proto3\";\npackage p;\nmessage B {}\n"),
+	})
+	results, r, err := incremental.Run(t.Context(), incremental.New(), queries.Link{
+		Opener:    &source.Openers{sources, source.WKTs()},
+		Session:   new(ir.Session),
+		Workspace: source.NewWorkspace("a.proto", "b.proto"),
+	})
+	require.NoError(t, err)
+
+	// The link query replaces the error from lowering a.proto with one that
+	// suggests importing b.proto.
+	require.Len(t, r.Diagnostics, 1)
+	assert.True(t, r.Diagnostics[0].Is(rtags.UnknownSymbol))
+	assert.Equal(t, []string{"`p.B` is defined in \"b.proto\""}, r.Diagnostics[0].Help())
+
+	// Suggesting again has no effect.
+	ir.SuggestImports(r, results[0].Value...)
+	ir.SuggestImports(r, results[0].Value...)
+	assert.Len(t, r.Diagnostics, 1)
+}
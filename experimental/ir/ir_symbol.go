@@ -139,7 +139,7 @@ func (s Symbol) Deprecated() Value {
 	case SymbolKindMessage, SymbolKindEnum:
 		return s.AsType().Deprecated()
 	case SymbolKindField, SymbolKindExtension, SymbolKindEnumValue:
-		return s.AsType().Deprecated()
+		return s.AsMember().Deprecated()
 	case SymbolKindService:
 		return s.AsService().Deprecated()
 	case SymbolKindMethod:
@@ -87,6 +87,11 @@ type Test struct {
 	// tables.
 	Symtab bool `yaml:"symtab"`
 
+	// Whether to run [ir.DiagnoseDeadDefinitions] on the workspace, and the
+	// roots to pass to it.
+	DeadDefinitions bool     `yaml:"dead_definitions"`
+	Roots           []string `yaml:"roots"`
+
 	// If true, skip this test when the protobuf-go runtime supports message
 	// sets (e.g. when built with the `protolegacy` build tag). Used for tests
 	// that assert on diagnostics that are only emitted when message sets are
@@ -206,6 +211,11 @@ func TestIR(t *testing.T) {
 		irs := linkResult[0].Value
 		irs = slices.DeleteFunc(irs, func(f *ir.File) bool { return f == nil })
 
+		if test.DeadDefinitions {
+			roots := slicesx.Transform(test.Roots, func(s string) ir.FullName { return ir.FullName(s) })
+			ir.DiagnoseDeadDefinitions(r, workspace, roots, irs...)
+		}
+
 		r.Diagnostics = slices.DeleteFunc(r.Diagnostics, func(d report.Diagnostic) bool {
 			matches := func(r *regexp.Regexp) bool {
 				return r.MatchString(d.Message())
@@ -222,6 +232,11 @@ func TestIR(t *testing.T) {
 		t.Log(stderr)
 		outputs[0], _, _ = report.Renderer{}.RenderString(r)
 		assert.NotContains(t, outputs[0], "unexpected panic; this is a bug")
+		for _, d := range r.Diagnostics {
+			// Every diagnostic must be tagged, so that it can be targeted by a
+			// report.Policy.
+			assert.True(t, d.Level() == report.ICE || d.Tag() != "", "untagged diagnostic: %q", d.Message())
+		}
 		if !test.Descriptor && !test.Symtab {
 			require.NotEmpty(t, outputs[0], "test must emit diagnostics")
 			return
@@ -135,4 +135,5 @@ func lower(file *File, r *report.Report, importer Importer) {
 	diagnoseUnusedImports(file, r)
 	validateConstraints(file, r)
 	checkDeprecated(file, r)
+	checkCEL(file, r)
 }
@@ -0,0 +1,1097 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ir
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/bufbuild/protocompile/experimental/ast/predeclared"
+	"github.com/bufbuild/protocompile/experimental/expr"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/source"
+	"github.com/bufbuild/protocompile/experimental/token"
+	"github.com/bufbuild/protocompile/experimental/token/keyword"
+)
+
+// checkCEL type-checks the CEL expressions in the protovalidate rules set on
+// the messages and fields of the given file.
+//
+// Message rules are checked with `this` bound to the message; field rules are
+// checked with `this` bound to the field's value.
+func checkCEL(file *File, r *report.Report) {
+	for ty := range seq.Values(file.AllTypes()) {
+		if !ty.IsMessage() {
+			continue
+		}
+		c := &celChecker{file: file, scope: ty.FullName(), r: r}
+		c.vars = []celVar{{"this", celValueType(ty)}, {"now", celType{kind: celTimestamp}}}
+		forEachCELRule(ty.Options(), "buf.validate.message", c.checkRule)
+	}
+
+	for m := range file.AllMembers() {
+		if m.IsEnumValue() {
+			continue
+		}
+		c := &celChecker{file: file, scope: m.Scope(), r: r}
+		c.vars = []celVar{{"this", celFieldType(m)}, {"now", celType{kind: celTimestamp}}}
+		forEachCELRule(m.Options(), "buf.validate.field", c.checkRule)
+	}
+}
+
+// forEachCELRule calls yield with each CEL expression set in the protovalidate
+// rules extension with the given name, whether as a `cel` rule's
+// `expression` or as a `cel_expression`.
+func forEachCELRule(options MessageValue, extension FullName, yield func(Element)) {
+	for value := range options.Fields() {
+		if value.Field().FullName() != extension {
+			continue
+		}
+		for rule := range value.AsMessage().Fields() {
+			switch rule.Field().Name() {
+			case "cel":
+				for elem := range seq.Values(rule.Elements()) {
+					for field := range elem.AsMessage().Fields() {
+						if field.Field().Name() != "expression" {
+							continue
+						}
+						for e := range seq.Values(field.Elements()) {
+							yield(e)
+						}
+					}
+				}
+			case "cel_expression":
+				for elem := range seq.Values(rule.Elements()) {
+					yield(elem)
+				}
+			}
+		}
+	}
+}
+
+// celKind is the kind of a [celType].
+type celKind int8
+
+const (
+	celDyn celKind = iota
+	celNull
+	celBool
+	celInt
+	celUint
+	celDouble
+	celString
+	celBytes
+	celDuration
+	celTimestamp
+	celList
+	celMap
+	celMessage
+	celTypeValue
+)
+
+var celKindNames = [...]string{
+	celDyn:       "dyn",
+	celNull:      "null_type",
+	celBool:      "bool",
+	celInt:       "int",
+	celUint:      "uint",
+	celDouble:    "double",
+	celString:    "string",
+	celBytes:     "bytes",
+	celDuration:  "google.protobuf.Duration",
+	celTimestamp: "google.protobuf.Timestamp",
+	celList:      "list",
+	celMap:       "map",
+	celTypeValue: "type",
+}
+
+// celType is the static type of a CEL expression.
+//
+// Anything the checker cannot type precisely, including expressions that
+// failed to type-check, is dyn, which is compatible with every other type.
+type celType struct {
+	kind celKind
+	// Set for messages and wrapper types, which may be compared to null.
+	nullable bool
+	// The element type of a list, or the key and value types of a map.
+	args []celType
+	// The message type of a message.
+	msg Type
+}
+
+func celListOf(elem celType) celType {
+	return celType{kind: celList, args: []celType{elem}}
+}
+
+func celMapOf(key, value celType) celType {
+	return celType{kind: celMap, args: []celType{key, value}}
+}
+
+// String implements [fmt.Stringer].
+func (t celType) String() string {
+	switch t.kind {
+	case celList:
+		return fmt.Sprintf("list(%v)", t.args[0])
+	case celMap:
+		return fmt.Sprintf("map(%v, %v)", t.args[0], t.args[1])
+	case celMessage:
+		return string(t.msg.FullName())
+	default:
+		return celKindNames[t.kind]
+	}
+}
+
+// isNumeric returns whether this is int, uint or double.
+func (t celType) isNumeric() bool {
+	return t.kind == celInt || t.kind == celUint || t.kind == celDouble
+}
+
+// is returns whether this type is dyn or of the given kind.
+func (t celType) is(kind celKind) bool {
+	return t.kind == celDyn || t.kind == kind
+}
+
+// same returns whether values of types t and u may be compared for equality,
+// or used where the other is expected.
+func (t celType) same(u celType) bool {
+	switch {
+	case t.kind == celDyn || u.kind == celDyn:
+		return true
+	case t.kind == celNull:
+		return u.kind == celNull || u.nullable
+	case u.kind == celNull:
+		return t.nullable
+	case t.kind != u.kind:
+		return false
+	case t.kind == celMessage:
+		return t.msg == u.msg
+	}
+	for i := range t.args {
+		if !t.args[i].same(u.args[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// join returns the type of a value that is either of type t or u.
+func (t celType) join(u celType) celType {
+	switch {
+	case t.kind == celNull && u.nullable:
+		return u
+	case u.kind == celNull && t.nullable, u.kind == celDyn:
+		return t
+	case t.kind == celDyn, t.same(u):
+		return u
+	default:
+		return celType{}
+	}
+}
+
+// celWrappers maps the well-known types that CEL treats as other types to
+// those types.
+var celWrappers = map[FullName]celType{
+	"google.protobuf.Timestamp":   {kind: celTimestamp},
+	"google.protobuf.Duration":    {kind: celDuration},
+	"google.protobuf.BoolValue":   {kind: celBool, nullable: true},
+	"google.protobuf.Int32Value":  {kind: celInt, nullable: true},
+	"google.protobuf.Int64Value":  {kind: celInt, nullable: true},
+	"google.protobuf.UInt32Value": {kind: celUint, nullable: true},
+	"google.protobuf.UInt64Value": {kind: celUint, nullable: true},
+	"google.protobuf.FloatValue":  {kind: celDouble, nullable: true},
+	"google.protobuf.DoubleValue": {kind: celDouble, nullable: true},
+	"google.protobuf.StringValue": {kind: celString, nullable: true},
+	"google.protobuf.BytesValue":  {kind: celBytes, nullable: true},
+	"google.protobuf.Any":         {kind: celDyn},
+	"google.protobuf.Value":       {kind: celDyn},
+	"google.protobuf.Struct":      celMapOf(celType{kind: celString}, celType{}),
+	"google.protobuf.ListValue":   celListOf(celType{}),
+}
+
+// celFieldType returns the CEL type of the value of a field.
+func celFieldType(m Member) celType {
+	if m.IsMap() {
+		k, v := m.Element().EntryFields()
+		return celMapOf(celValueType(k.Element()), celValueType(v.Element()))
+	}
+	elem := celValueType(m.Element())
+	if m.IsRepeated() {
+		return celListOf(elem)
+	}
+	return elem
+}
+
+// celValueType returns the CEL type of a value of the given type.
+func celValueType(ty Type) celType {
+	switch {
+	case ty.IsPredeclared():
+		switch ty.Predeclared() {
+		case predeclared.Int32, predeclared.Int64,
+			predeclared.SInt32, predeclared.SInt64,
+			predeclared.SFixed32, predeclared.SFixed64:
+			return celType{kind: celInt}
+		case predeclared.UInt32, predeclared.UInt64,
+			predeclared.Fixed32, predeclared.Fixed64:
+			return celType{kind: celUint}
+		case predeclared.Float, predeclared.Double:
+			return celType{kind: celDouble}
+		case predeclared.Bool:
+			return celType{kind: celBool}
+		case predeclared.String:
+			return celType{kind: celString}
+		case predeclared.Bytes:
+			return celType{kind: celBytes}
+		}
+	case ty.IsEnum():
+		return celType{kind: celInt}
+	case ty.IsMessage():
+		if t, ok := celWrappers[ty.FullName()]; ok {
+			return t
+		}
+		return celType{kind: celMessage, nullable: true, msg: ty}
+	}
+	return celType{}
+}
+
+// celOverload is a signature of a CEL function or method.
+type celOverload struct {
+	// Whether the first parameter is the receiver of a method call.
+	method bool
+	// Whether the last parameter may be repeated any number of times.
+	variadic bool
+	params   []celKind
+	result   celType
+}
+
+// celFunctions are the functions and methods that the checker knows about:
+// CEL's standard library, the strings extension, and protovalidate's
+// extensions.
+//
+// Macros, such as `has()` and `all()`, are handled by [celChecker.macro].
+var celFunctions = func() map[string][]celOverload {
+	var (
+		b   = celType{kind: celBool}
+		i   = celType{kind: celInt}
+		u   = celType{kind: celUint}
+		d   = celType{kind: celDouble}
+		s   = celType{kind: celString}
+		by  = celType{kind: celBytes}
+		dur = celType{kind: celDuration}
+		ts  = celType{kind: celTimestamp}
+	)
+	fn := func(result celType, params ...celKind) celOverload {
+		return celOverload{params: params, result: result}
+	}
+	method := func(result celType, params ...celKind) celOverload {
+		return celOverload{method: true, params: params, result: result}
+	}
+	variadic := func(result celType, params ...celKind) celOverload {
+		return celOverload{variadic: true, params: params, result: result}
+	}
+
+	fns := map[string][]celOverload{
+		"size": {
+			fn(i, celString), fn(i, celBytes), fn(i, celList), fn(i, celMap),
+			method(i, celString), method(i, celBytes), method(i, celList), method(i, celMap),
+		},
+		"matches":    {fn(b, celString, celString), method(b, celString, celString)},
+		"contains":   {method(b, celString, celString), method(b, celBytes, celBytes)},
+		"startsWith": {method(b, celString, celString), method(b, celBytes, celBytes)},
+		"endsWith":   {method(b, celString, celString), method(b, celBytes, celBytes)},
+
+		"int":       {fn(i, celInt), fn(i, celUint), fn(i, celDouble), fn(i, celString), fn(i, celTimestamp)},
+		"uint":      {fn(u, celInt), fn(u, celUint), fn(u, celDouble), fn(u, celString)},
+		"double":    {fn(d, celInt), fn(d, celUint), fn(d, celDouble), fn(d, celString)},
+		"bool":      {fn(b, celBool), fn(b, celString)},
+		"bytes":     {fn(by, celBytes), fn(by, celString)},
+		"duration":  {fn(dur, celDuration), fn(dur, celString)},
+		"timestamp": {fn(ts, celTimestamp), fn(ts, celString), fn(ts, celInt)},
+		"string": {
+			fn(s, celInt), fn(s, celUint), fn(s, celDouble), fn(s, celString),
+			fn(s, celBytes), fn(s, celBool), fn(s, celTimestamp), fn(s, celDuration),
+		},
+		"dyn":  {fn(celType{}, celDyn)},
+		"type": {fn(celType{kind: celTypeValue}, celDyn)},
+
+		"charAt":      {method(s, celString, celInt)},
+		"indexOf":     {method(i, celString, celString), method(i, celString, celString, celInt)},
+		"lastIndexOf": {method(i, celString, celString), method(i, celString, celString, celInt)},
+		"lowerAscii":  {method(s, celString)},
+		"upperAscii":  {method(s, celString)},
+		"trim":        {method(s, celString)},
+		"reverse":     {method(s, celString)},
+		"replace": {
+			method(s, celString, celString, celString),
+			method(s, celString, celString, celString, celInt),
+		},
+		"split": {
+			method(celListOf(s), celString, celString),
+			method(celListOf(s), celString, celString, celInt),
+		},
+		"substring": {method(s, celString, celInt), method(s, celString, celInt, celInt)},
+		"join":      {method(s, celList), method(s, celList, celString)},
+		"format":    {method(s, celString, celList)},
+
+		"isEmail":    {method(b, celString)},
+		"isHostname": {method(b, celString)},
+		"isUri":      {method(b, celString)},
+		"isUriRef":   {method(b, celString)},
+		"isIp":       {method(b, celString), method(b, celString, celInt)},
+		"isIpPrefix": {
+			method(b, celString), method(b, celString, celInt),
+			method(b, celString, celBool), method(b, celString, celInt, celBool),
+		},
+		"isHostAndPort": {method(b, celString, celBool)},
+		"isNan":         {method(b, celDouble)},
+		"isInf":         {method(b, celDouble), method(b, celDouble, celInt)},
+		"unique":        {method(b, celList)},
+
+		"math.greatest": {variadic(celType{}, celDyn)},
+		"math.least":    {variadic(celType{}, celDyn)},
+	}
+
+	for _, name := range []string{
+		"getFullYear", "getMonth", "getDate", "getDayOfMonth", "getDayOfWeek", "getDayOfYear",
+	} {
+		fns[name] = []celOverload{method(i, celTimestamp), method(i, celTimestamp, celString)}
+	}
+	for _, name := range []string{
+		"getHours", "getMinutes", "getSeconds", "getMilliseconds",
+	} {
+		fns[name] = []celOverload{
+			method(i, celTimestamp), method(i, celTimestamp, celString), method(i, celDuration),
+		}
+	}
+	return fns
+}()
+
+// celTypeNames are the identifiers that name CEL types.
+var celTypeNames = map[string]bool{
+	"bool": true, "int": true, "uint": true, "double": true, "string": true,
+	"bytes": true, "list": true, "map": true, "null_type": true, "type": true,
+	"dyn": true,
+}
+
+// celVar is a variable in scope in a CEL expression.
+type celVar struct {
+	name string
+	ty   celType
+}
+
+// celChecker is the state for type-checking a single CEL expression.
+type celChecker struct {
+	file  *File
+	scope FullName // The scope in which to resolve qualified names.
+	vars  []celVar
+	r     *report.Report
+}
+
+// checkRule type-checks a single CEL expression, given as a string option
+// value.
+func (c *celChecker) checkRule(elem Element) {
+	lit := elem.AST().AsLiteral()
+	if lit.IsZero() || lit.Kind() != token.String {
+		return
+	}
+
+	str := lit.AsString()
+	if str.IsPure() {
+		c.checkResult(expr.ParseCEL(str.RawContent(), c.r))
+		return
+	}
+
+	// Spans into an expression with escapes or string concatenation cannot
+	// point into the file, so we check the expression as a file of its own,
+	// and report the diagnostics on the whole string literal instead.
+	text := str.Text()
+	outer := c.r
+	c.r = new(report.Report)
+	c.checkResult(expr.ParseCEL(source.NewFile(c.file.Path(), text).Span(0, len(text)), c.r))
+	for _, d := range c.r.Diagnostics {
+		if d.Level() > report.Error {
+			continue
+		}
+		outer.Errorf("%s", d.Message()).Apply(
+			report.Snippet(lit),
+			report.Notef("in the CEL expression `%s`", text),
+			report.Tag(rtags.InvalidCEL),
+		)
+	}
+	c.r = outer
+}
+
+// checkResult type-checks a whole rule expression, which must produce a
+// bool or a string.
+//
+// If the expression does not depend on any variables, it is also evaluated,
+// to diagnose rules that always fail.
+func (c *celChecker) checkResult(e expr.Expr) {
+	n := len(c.r.Diagnostics)
+	ty := c.check(e)
+	if !ty.is(celBool) && !ty.is(celString) {
+		c.r.Errorf("CEL rule must evaluate to `bool` or `string`").Apply(
+			report.Snippetf(e, "this evaluates to `%v`", ty),
+			report.Helpf("a rule fails when it evaluates to `false` or a non-empty string"),
+			report.Tag(rtags.InvalidCEL),
+		)
+	}
+	if len(c.r.Diagnostics) > n {
+		return
+	}
+
+	ev := &celEvaluator{resolve: c.constant}
+	v, err := ev.eval(e)
+	if celErr := (*CELError)(nil); errors.As(err, &celErr) {
+		if !celErr.unbound {
+			c.r.Error(celErr)
+		}
+		return
+	}
+
+	failed := v == false
+	if s, ok := v.(string); ok {
+		failed = s != ""
+	}
+	if failed {
+		c.r.Warnf("CEL rule always fails").Apply(
+			report.Snippetf(e, "this always evaluates to `%s`", celFormat(v)),
+			report.Tag(rtags.InvalidCEL),
+		)
+	}
+}
+
+// constant resolves a qualified name to the value of an enum value, for
+// constant-folding expressions.
+func (c *celChecker) constant(name string) (any, bool) {
+	value := FullName(name)
+	enum := c.resolve(string(value.Parent())).AsType()
+	if !enum.IsEnum() {
+		return nil, false
+	}
+	if m := enum.MemberByName(value.Name()); !m.IsZero() {
+		return int64(m.Number()), true
+	}
+	return nil, false
+}
+
+// check type-checks an expression and returns its type.
+func (c *celChecker) check(e expr.Expr) celType {
+	switch e.Kind() {
+	case expr.KindToken:
+		return c.token(e.AsToken())
+	case expr.KindOp:
+		return c.op(e.AsOp())
+	case expr.KindCall:
+		return c.call(e.AsCall())
+	case expr.KindRecord:
+		return c.record(e.AsRecord())
+	default:
+		return celType{}
+	}
+}
+
+// token type-checks a literal or identifier.
+func (c *celChecker) token(tok expr.Token) celType {
+	switch tok.Kind() {
+	case token.Number:
+		switch {
+		case tok.AsNumber().IsFloat():
+			return celType{kind: celDouble}
+		case tok.AsNumber().Suffix().Text() != "":
+			return celType{kind: celUint}
+		default:
+			return celType{kind: celInt}
+		}
+
+	case token.String:
+		if strings.ContainsAny(tok.AsString().Prefix().Text(), "bB") {
+			return celType{kind: celBytes}
+		}
+		return celType{kind: celString}
+	}
+
+	switch name := tok.Text(); name {
+	case "true", "false":
+		return celType{kind: celBool}
+	case "null":
+		return celType{kind: celNull}
+	default:
+		if ty, ok := c.lookup(name); ok {
+			return ty
+		}
+		if celTypeNames[name] {
+			return celType{kind: celTypeValue}
+		}
+		return c.reference(tok.AsAny(), name)
+	}
+}
+
+// lookup finds the innermost variable with the given name.
+func (c *celChecker) lookup(name string) (celType, bool) {
+	for i := len(c.vars) - 1; i >= 0; i-- {
+		if c.vars[i].name == name {
+			return c.vars[i].ty, true
+		}
+	}
+	return celType{}, false
+}
+
+// reference type-checks a qualified name that is not a variable, which must
+// refer to an enum value or a type.
+func (c *celChecker) reference(e expr.Expr, name string) celType {
+	switch c.resolve(name).Kind() {
+	case SymbolKindMessage, SymbolKindEnum:
+		return celType{kind: celTypeValue}
+	}
+
+	// CEL names enum values as members of their enum, unlike Protobuf, where
+	// they are siblings of it.
+	value := FullName(name)
+	if enum := c.resolve(string(value.Parent())).AsType(); enum.IsEnum() &&
+		!enum.MemberByName(value.Name()).IsZero() {
+		return celType{kind: celInt}
+	}
+
+	c.r.Errorf("undeclared reference to `%s`", name).Apply(
+		report.Snippet(e),
+		report.Tag(rtags.InvalidCEL),
+	)
+	return celType{}
+}
+
+// resolve resolves a possibly-qualified name the way CEL does: relative to
+// the scope the expression appears in, and then each enclosing scope.
+func (c *celChecker) resolve(name string) Symbol {
+	if abs, ok := strings.CutPrefix(name, "."); ok {
+		return c.file.FindSymbol(FullName(abs))
+	}
+	for scope := c.scope; ; scope = scope.Parent() {
+		if sym := c.file.FindSymbol(scope.Append(name)); !sym.IsZero() {
+			return sym
+		}
+		if scope == "" {
+			return Symbol{}
+		}
+	}
+}
+
+// qualifiedName returns the dotted name that e spells out, if it consists
+// only of identifiers and `.`s, and the first identifier in it.
+func qualifiedName(e expr.Expr) (name, first string, ok bool) {
+	switch e.Kind() {
+	case expr.KindToken:
+		tok := e.AsToken()
+		if tok.Kind() != token.Ident {
+			return "", "", false
+		}
+		return tok.Text(), tok.Text(), true
+
+	case expr.KindOp:
+		op := e.AsOp()
+		right := op.Right().AsToken()
+		if op.Operator() != keyword.Dot || right.IsZero() {
+			return "", "", false
+		}
+		if op.IsUnary() {
+			return "." + right.Text(), "", true
+		}
+		name, first, ok := qualifiedName(op.Left())
+		return name + "." + right.Text(), first, ok
+	}
+	return "", "", false
+}
+
+// op type-checks an operator.
+func (c *celChecker) op(op expr.Op) celType {
+	if op.Operator() == keyword.Dot {
+		return c.selection(op)
+	}
+	if op.Operator() == keyword.Ask {
+		return c.conditional(op)
+	}
+
+	if op.IsUnary() {
+		ty := c.check(op.Right())
+		switch {
+		case op.Operator() == keyword.Bang && ty.is(celBool):
+			return celType{kind: celBool}
+		case op.Operator() == keyword.Sub && (ty.is(celInt) || ty.is(celDouble)):
+			return ty
+		}
+		c.r.Errorf("cannot apply `%s` to `%v`", op.OperatorToken().Text(), ty).Apply(
+			report.Snippet(op.OperatorToken()),
+			report.Snippetf(op.Right(), "this is `%v`", ty),
+			report.Tag(rtags.InvalidCEL),
+		)
+		return celType{}
+	}
+
+	left, right := c.check(op.Left()), c.check(op.Right())
+	if ty, ok := celBinary(op.Operator(), left, right); ok {
+		return ty
+	}
+	c.r.Errorf("cannot apply `%s` to `%v` and `%v`", op.OperatorToken().Text(), left, right).Apply(
+		report.Snippet(op.OperatorToken()),
+		report.Snippetf(op.Left(), "this is `%v`", left),
+		report.Snippetf(op.Right(), "this is `%v`", right),
+		report.Tag(rtags.InvalidCEL),
+	)
+	return celType{}
+}
+
+// celBinary returns the type of applying a binary operator to values of
+// the given types.
+func celBinary(op keyword.Keyword, l, r celType) (celType, bool) {
+	var (
+		b     = celType{kind: celBool}
+		dyn   = l.kind == celDyn || r.kind == celDyn
+		same  = l.kind == r.kind && l.kind != celNull
+		other = r
+	)
+	if r.kind == celDyn {
+		other = l
+	}
+
+	switch op {
+	case keyword.Pipes, keyword.Amps:
+		return b, l.is(celBool) && r.is(celBool)
+
+	case keyword.Eq, keyword.Ne:
+		return b, l.same(r)
+
+	case keyword.Lt, keyword.Le, keyword.Gt, keyword.Ge:
+		switch {
+		case dyn, l.isNumeric() && r.isNumeric():
+			return b, true
+		case same:
+			switch l.kind {
+			case celBool, celString, celBytes, celDuration, celTimestamp:
+				return b, true
+			}
+		}
+		return b, false
+
+	case keyword.In:
+		switch r.kind {
+		case celDyn:
+			return b, true
+		case celList, celMap:
+			return b, l.same(r.args[0])
+		}
+		return b, false
+
+	case keyword.Add:
+		switch {
+		case dyn:
+			return celType{}, other.isNumeric() || other.kind == celString ||
+				other.kind == celBytes || other.kind == celList ||
+				other.kind == celDuration || other.kind == celTimestamp || other.kind == celDyn
+		case l.kind == celList && r.kind == celList:
+			return celListOf(l.args[0].join(r.args[0])), true
+		case l.kind == celTimestamp && r.kind == celDuration,
+			l.kind == celDuration && r.kind == celTimestamp:
+			return celType{kind: celTimestamp}, true
+		case same:
+			switch l.kind {
+			case celInt, celUint, celDouble, celString, celBytes, celDuration:
+				return celType{kind: l.kind}, true
+			}
+		}
+		return celType{}, false
+
+	case keyword.Sub:
+		switch {
+		case dyn:
+			return celType{}, other.isNumeric() || other.kind == celDuration ||
+				other.kind == celTimestamp || other.kind == celDyn
+		case l.kind == celTimestamp && r.kind == celDuration:
+			return celType{kind: celTimestamp}, true
+		case l.kind == celTimestamp && r.kind == celTimestamp:
+			return celType{kind: celDuration}, true
+		case same && (l.isNumeric() || l.kind == celDuration):
+			return celType{kind: l.kind}, true
+		}
+		return celType{}, false
+
+	case keyword.Mul, keyword.Div:
+		switch {
+		case dyn:
+			return celType{}, other.isNumeric() || other.kind == celDyn
+		case same && l.isNumeric():
+			return celType{kind: l.kind}, true
+		}
+		return celType{}, false
+
+	case keyword.Rem:
+		switch {
+		case dyn:
+			return celType{}, other.kind == celInt || other.kind == celUint || other.kind == celDyn
+		case same && (l.kind == celInt || l.kind == celUint):
+			return celType{kind: l.kind}, true
+		}
+		return celType{}, false
+	}
+	return celType{}, true
+}
+
+// selection type-checks a field selection, or a qualified name.
+func (c *celChecker) selection(op expr.Op) celType {
+	if name, first, ok := qualifiedName(op.AsAny()); ok {
+		if _, isVar := c.lookup(first); !isVar {
+			return c.reference(op.AsAny(), name)
+		}
+	}
+
+	name := op.Right().AsToken()
+	if name.IsZero() {
+		return celType{}
+	}
+
+	ty := c.check(op.Left())
+	switch ty.kind {
+	case celDyn:
+		return celType{}
+	case celMessage:
+		if field := ty.msg.MemberByName(name.Text()); !field.IsZero() {
+			return celFieldType(field)
+		}
+		c.r.Errorf("message `%s` has no field named `%s`", ty.msg.FullName(), name.Text()).Apply(
+			report.Snippet(name),
+			report.Snippetf(op.Left(), "this is `%v`", ty),
+			report.Tag(rtags.InvalidCEL),
+		)
+		return celType{}
+	case celMap:
+		if ty.args[0].is(celString) {
+			return ty.args[1]
+		}
+	}
+
+	c.r.Errorf("cannot select field `%s` of `%v`", name.Text(), ty).Apply(
+		report.Snippet(name),
+		report.Snippetf(op.Left(), "this is `%v`", ty),
+		report.Tag(rtags.InvalidCEL),
+	)
+	return celType{}
+}
+
+// conditional type-checks a conditional expression, `a ? b : c`.
+func (c *celChecker) conditional(op expr.Op) celType {
+	c.checkBool(op.Left(), "condition")
+
+	branches := op.Right().AsOp()
+	if branches.IsZero() {
+		return c.check(op.Right())
+	}
+
+	then, els := c.check(branches.Left()), c.check(branches.Right())
+	if !then.same(els) {
+		c.r.Errorf("branches of conditional have different types").Apply(
+			report.Snippetf(branches.Left(), "this is `%v`", then),
+			report.Snippetf(branches.Right(), "this is `%v`", els),
+			report.Tag(rtags.InvalidCEL),
+		)
+	}
+	return then.join(els)
+}
+
+// checkBool type-checks an expression that must be a bool.
+func (c *celChecker) checkBool(e expr.Expr, what string) {
+	if ty := c.check(e); !ty.is(celBool) {
+		c.r.Errorf("%s must be `bool`", what).Apply(
+			report.Snippetf(e, "this is `%v`", ty),
+			report.Tag(rtags.InvalidCEL),
+		)
+	}
+}
+
+// record type-checks a list or map literal.
+func (c *celChecker) record(rec expr.Record) celType {
+	entries := rec.Entries()
+	if rec.Brackets().Keyword() == keyword.Brackets {
+		var elem celType
+		for i := range entries.Len() {
+			ty := c.check(entries.At(i).Expr)
+			if i == 0 {
+				elem = ty
+			} else {
+				elem = elem.join(ty)
+			}
+		}
+		return celListOf(elem)
+	}
+
+	var key, value celType
+	for i := range entries.Len() {
+		k, v := c.check(entries.At(i).Name), c.check(entries.At(i).Expr)
+		if i == 0 {
+			key, value = k, v
+		} else {
+			key, value = key.join(k), value.join(v)
+		}
+	}
+	return celMapOf(key, value)
+}
+
+// call type-checks a call, index, or message construction.
+func (c *celChecker) call(call expr.Call) celType {
+	args := call.Args()
+	switch call.Brackets().Keyword() {
+	case keyword.Brackets:
+		return c.index(call)
+	case keyword.Braces:
+		return c.construct(call)
+	}
+
+	var (
+		name string
+		recv expr.Expr
+	)
+	switch callee := call.Callee(); callee.Kind() {
+	case expr.KindToken:
+		name = callee.AsToken().Text()
+	case expr.KindOp:
+		op := callee.AsOp()
+		if op.Operator() == keyword.Dot && !op.IsUnary() {
+			name, recv = op.Right().AsToken().Text(), op.Left()
+		}
+	}
+	if name == "" {
+		c.r.Errorf("expression is not a function").Apply(
+			report.Snippet(call.Callee()),
+			report.Tag(rtags.InvalidCEL),
+		)
+		return celType{}
+	}
+
+	if ty, ok := c.macro(call, name, recv); ok {
+		return ty
+	}
+
+	// A receiver that names neither a variable nor anything in scope is the
+	// namespace of an extension function, such as `math.greatest`.
+	if ns, first, ok := qualifiedName(recv); ok && !celTypeNames[ns] {
+		if _, isVar := c.lookup(first); !isVar && c.resolve(ns).IsZero() {
+			name, recv = ns+"."+name, expr.Expr{}
+		}
+	}
+
+	var types []celType
+	if !recv.IsZero() {
+		types = append(types, c.check(recv))
+	}
+	for i := range args.Len() {
+		types = append(types, c.check(args.At(i).Expr))
+	}
+
+	// Functions and overloads that are not known here may be provided by a
+	// CEL library that protovalidate is configured with, so these are only
+	// warnings, and the call is assumed to produce a dyn.
+	overloads, ok := celFunctions[name]
+	if !ok {
+		c.r.Warnf("unknown function `%s`", name).Apply(
+			report.Snippet(call.Callee()),
+			report.Tag(rtags.InvalidCEL),
+		)
+		return celType{}
+	}
+
+outer:
+	for _, o := range overloads {
+		if o.method != !recv.IsZero() || len(types) < len(o.params) ||
+			(!o.variadic && len(types) != len(o.params)) {
+			continue
+		}
+		for i, ty := range types {
+			param := o.params[min(i, len(o.params)-1)]
+			if param != celDyn && !ty.is(param) {
+				continue outer
+			}
+		}
+		return o.result
+	}
+
+	var list []string
+	for _, ty := range types {
+		list = append(list, ty.String())
+	}
+	what := "function"
+	if !recv.IsZero() {
+		what = "method"
+	}
+	c.r.Warnf("no known overload of %s `%s` accepts `(%s)`", what, name, strings.Join(list, ", ")).Apply(
+		report.Snippet(call),
+		report.Tag(rtags.InvalidCEL),
+	)
+	return celType{}
+}
+
+// macro type-checks a call to one of CEL's macros, which bind variables or
+// take a field selection rather than a value. Returns false if this is not
+// a call to a macro.
+func (c *celChecker) macro(call expr.Call, name string, recv expr.Expr) (celType, bool) {
+	args := call.Args()
+	b := celType{kind: celBool}
+
+	if recv.IsZero() {
+		if name != "has" || args.Len() != 1 {
+			return celType{}, false
+		}
+		arg := args.At(0).Expr
+		if op := arg.AsOp(); op.IsZero() || op.Operator() != keyword.Dot || op.IsUnary() {
+			c.r.Errorf("argument to `has` must be a field selection").Apply(
+				report.Snippet(arg),
+				report.Tag(rtags.InvalidCEL),
+			)
+			return b, true
+		}
+		c.check(arg)
+		return b, true
+	}
+
+	switch name {
+	case "all", "exists", "exists_one", "filter":
+		if args.Len() != 2 {
+			return celType{}, false
+		}
+	case "map":
+		if args.Len() != 2 && args.Len() != 3 {
+			return celType{}, false
+		}
+	default:
+		return celType{}, false
+	}
+
+	v := args.At(0).Expr.AsToken()
+	if v.IsZero() || v.Kind() != token.Ident {
+		c.r.Errorf("first argument to `%s` must be a variable name", name).Apply(
+			report.Snippet(args.At(0).Expr),
+			report.Tag(rtags.InvalidCEL),
+		)
+		return celType{}, true
+	}
+
+	var elem celType
+	switch ty := c.check(recv); ty.kind {
+	case celDyn:
+	case celList, celMap:
+		elem = ty.args[0]
+	default:
+		c.r.Errorf("`%s` requires a list or map", name).Apply(
+			report.Snippetf(recv, "this is `%v`", ty),
+			report.Tag(rtags.InvalidCEL),
+		)
+	}
+
+	c.vars = append(c.vars, celVar{v.Text(), elem})
+	defer func() { c.vars = c.vars[:len(c.vars)-1] }()
+
+	switch name {
+	case "filter":
+		c.checkBool(args.At(1).Expr, "filter predicate")
+		return celListOf(elem), true
+	case "map":
+		if args.Len() == 3 {
+			c.checkBool(args.At(1).Expr, "filter predicate")
+		}
+		return celListOf(c.check(args.At(args.Len() - 1).Expr)), true
+	default:
+		c.checkBool(args.At(1).Expr, "predicate")
+		return b, true
+	}
+}
+
+// index type-checks an index expression, `a[b]`.
+func (c *celChecker) index(call expr.Call) celType {
+	ty := c.check(call.Callee())
+	if call.Args().Len() != 1 {
+		// Already diagnosed by the parser.
+		return celType{}
+	}
+	arg := call.Args().At(0).Expr
+	idx := c.check(arg)
+
+	switch ty.kind {
+	case celDyn:
+		return celType{}
+	case celList:
+		if idx.is(celInt) || idx.is(celUint) {
+			return ty.args[0]
+		}
+		c.r.Errorf("list index must be an integer").Apply(
+			report.Snippetf(arg, "this is `%v`", idx),
+			report.Tag(rtags.InvalidCEL),
+		)
+		return ty.args[0]
+	case celMap:
+		if !ty.args[0].same(idx) {
+			c.r.Errorf("map key must be `%v`", ty.args[0]).Apply(
+				report.Snippetf(arg, "this is `%v`", idx),
+				report.Tag(rtags.InvalidCEL),
+			)
+		}
+		return ty.args[1]
+	}
+
+	c.r.Errorf("cannot index into `%v`", ty).Apply(
+		report.Snippetf(call.Callee(), "this is `%v`", ty),
+		report.Tag(rtags.InvalidCEL),
+	)
+	return celType{}
+}
+
+// construct type-checks a message construction expression, `T{a: b}`.
+func (c *celChecker) construct(call expr.Call) celType {
+	var ty Type
+	if name, _, ok := qualifiedName(call.Callee()); ok {
+		ty = c.resolve(name).AsType()
+	}
+	if !ty.IsMessage() {
+		c.r.Errorf("expected a message type").Apply(
+			report.Snippet(call.Callee()),
+			report.Tag(rtags.InvalidCEL),
+		)
+		return celType{}
+	}
+
+	args := call.Args()
+	for i := range args.Len() {
+		arg := args.At(i)
+		value := c.check(arg.Expr)
+
+		key := arg.Name.AsToken()
+		if key.IsZero() || key.Kind() != token.Ident {
+			continue
+		}
+		field := ty.MemberByName(key.Text())
+		if field.IsZero() {
+			c.r.Errorf("message `%s` has no field named `%s`", ty.FullName(), key.Text()).Apply(
+				report.Snippet(key),
+				report.Tag(rtags.InvalidCEL),
+			)
+			continue
+		}
+		if want := celFieldType(field); !want.same(value) {
+			c.r.Errorf("expected `%v` for field `%s`", want, key.Text()).Apply(
+				report.Snippetf(arg.Expr, "this is `%v`", value),
+				report.Tag(rtags.InvalidCEL),
+			)
+		}
+	}
+	return celValueType(ty)
+}
@@ -26,6 +26,7 @@ import (
 	"github.com/bufbuild/protocompile/experimental/internal/taxa"
 	"github.com/bufbuild/protocompile/experimental/ir/presence"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/seq"
 	"github.com/bufbuild/protocompile/experimental/source"
 	"github.com/bufbuild/protocompile/experimental/token"
@@ -327,6 +328,7 @@ func (e *evaluator) evalKey(args evalArgs, expr ast.ExprField) Member {
 
 	cannotResolveKey := func() {
 		d := e.Errorf("cannot resolve %s name for `%s`", taxa.Field, ty.FullName()).Apply(
+			report.Tag(rtags.UnknownField),
 			report.Snippetf(expr, "field referenced here"),
 			report.Snippetf(args.annotation, "expected `%s` field due to this", ty.Name()),
 		)
@@ -349,6 +351,7 @@ again:
 
 			// This appears to be an Any type name.
 			d := e.Errorf("unexpected %s", taxa.TypeURL).Apply(
+				report.Tag(rtags.InvalidAny),
 				report.Snippet(expr.Key()),
 				report.Snippetf(args.annotation, "expected this to be `google.protobuf.Any`"),
 				report.Notef("%s may only appear in a `google.protobuf.Any`-typed %s", taxa.Dict),
@@ -466,6 +469,7 @@ validate:
 		}
 
 		d := e.Errorf("%s `%s` referenced incorrectly", member.noun(), member.FullName()).Apply(
+			report.Tag(rtags.InvalidPath),
 			report.Snippetf(expr.Key(), "referenced here"),
 			report.SuggestEdits(expr.Key(), fmt.Sprintf("reference it as `%s`", replace), report.Edit{
 				Start: 0, End: expr.Key().Span().Len(),
@@ -562,6 +566,7 @@ func (e *evaluator) evalMessage(args evalArgs, expr ast.ExprDict) Value {
 				}
 
 				d := e.Errorf("unexpected field in `Any` expression").Apply(
+					report.Tag(rtags.InvalidAny),
 					report.Snippet(expr.Key()),
 					report.Notef("the %s must be the only field", taxa.TypeURL),
 				)
@@ -597,11 +602,13 @@ func (e *evaluator) evalMessage(args evalArgs, expr ast.ExprDict) Value {
 				break
 			case "":
 				e.Errorf("missing domain in %s", taxa.TypeURL).Apply(
+					report.Tag(rtags.InvalidAny),
 					report.Snippet(urlExpr.Key()),
 					report.Notef(anyDomainNote),
 				)
 			default:
 				e.Errorf("unsupported domain `%s` in %s", host, taxa.TypeURL).Apply(
+					report.Tag(rtags.InvalidAny),
 					report.Snippet(hostPath),
 					report.Notef(anyDomainNote),
 				)
@@ -637,6 +644,7 @@ func (e *evaluator) evalMessage(args evalArgs, expr ast.ExprDict) Value {
 			if path != string(ty.FullName()) {
 				_, typePath := splitURL(key.AsPath().Path)
 				e.Errorf("partly-qualified name in %s", taxa.TypeURL).Apply(
+					report.Tag(rtags.InvalidAny),
 					report.Snippetf(typePath, "type referenced here"),
 					report.SuggestEdits(typePath, fmt.Sprintf("replace with %s", taxa.FullyQualifiedName), report.Edit{
 						Start: 0, End: typePath.Span().Len(),
@@ -746,6 +754,7 @@ func (e *evaluator) evalLiteral(args evalArgs, expr ast.ExprLiteral, neg ast.Exp
 			text := expr.Text()
 			if !taxa.IsFloatText(text) && (strings.HasPrefix(text, "0x") || strings.HasPrefix(text, "0X")) {
 				e.Errorf("unsupported base for %s", taxa.Float).Apply(
+					report.Tag(rtags.InvalidLiteral),
 					report.SuggestEdits(expr, "use a decimal literal instead", report.Edit{
 						Start: 0, End: len(text),
 						Replace: strconv.FormatFloat(n, 'g', 40, 64),
@@ -768,6 +777,7 @@ func (e *evaluator) evalLiteral(args evalArgs, expr ast.ExprLiteral, neg ast.Exp
 			// the value 0.0 but expr.Text() contains non-zero digits?
 			if math.IsInf(n, 0) {
 				d := e.Warnf("%s rounds to infinity", taxa.Float).Apply(
+					report.Tag(rtags.OutOfRange),
 					report.Snippetf(expr, "this value is beyond the dynamic range of `%s`", scalar),
 					report.SuggestEdits(expr, "replace with `inf`", report.Edit{
 						Start: 0, End: len(text),
@@ -953,7 +963,7 @@ func (e *evaluator) evalPath(args evalArgs, expr ast.Path, neg ast.ExprPrefixed)
 		//
 		// TODO: This depends on field numbers being resolved before options,
 		// but some options need to be resolved first.
-		value := ty.MemberByName(expr.Span().Text())
+		value := ty.MemberByName(pathText(expr))
 
 		if !value.IsZero() {
 			v := value.Number()
@@ -998,6 +1008,7 @@ func (e *evaluator) evalPath(args evalArgs, expr ast.Path, neg ast.ExprPrefixed)
 			}
 		} else {
 			e.Errorf("`max` outside of range end").Apply(
+				report.Tag(rtags.InvalidRange),
 				report.Snippet(expr),
 				report.Notef(
 					"the special `max` expression can only be used at the end of a range"),
@@ -1007,6 +1018,7 @@ func (e *evaluator) evalPath(args evalArgs, expr ast.Path, neg ast.ExprPrefixed)
 
 		if !neg.IsZero() {
 			e.Errorf("negated `max`").Apply(
+				report.Tag(rtags.InvalidRange),
 				report.Snippet(neg),
 				report.Notef("the special `max` expression may not be negated"),
 			)
@@ -1081,7 +1093,7 @@ func (e *evaluator) evalPath(args evalArgs, expr ast.Path, neg ast.ExprPrefixed)
 	// Match the "non standard" symbols for true, false, inf, and nan. Make
 	// sure to warn when users do it in text mode, and error when outside of
 	// it.
-	text := expr.Span().Text()
+	text := pathText(expr)
 	switch scalar {
 	case predeclared.Bool:
 		if slicesx.Among(text, "False", "f", "True", "t") {
@@ -1094,6 +1106,7 @@ func (e *evaluator) evalPath(args evalArgs, expr ast.Path, neg ast.ExprPrefixed)
 			}
 
 			d.Apply(
+				report.Tag(rtags.NonCanonicalLiteral),
 				report.Snippet(expr),
 				report.SuggestEdits(expr, fmt.Sprintf("replace with `%v`", value), report.Edit{
 					Start: 0, End: len(text),
@@ -1142,6 +1155,7 @@ func (e *evaluator) evalPath(args evalArgs, expr ast.Path, neg ast.ExprPrefixed)
 		}
 
 		d.Apply(
+			report.Tag(rtags.NonCanonicalLiteral),
 			report.Snippet(expr),
 			report.SuggestEdits(expr, fmt.Sprintf("replace with `%v`", canonical), report.Edit{
 				Start: 0, End: len(text),
@@ -1171,6 +1185,7 @@ func (e *evaluator) evalPath(args evalArgs, expr ast.Path, neg ast.ExprPrefixed)
 	} else if ev := sym.AsMember(); ev.IsEnumValue() {
 		if ev.Container() == args.Type() {
 			e.Errorf("qualified enum value reference").Apply(
+				report.Tag(rtags.InvalidPath),
 				report.Snippet(expr),
 				report.SuggestEdits(expr, "replace it with the value's name", report.Edit{
 					Start: 0, End: expr.Span().Len(),
@@ -1188,6 +1203,17 @@ func (e *evaluator) evalPath(args evalArgs, expr ast.Path, neg ast.ExprPrefixed)
 	return 0, false
 }
 
+// pathText returns the text of a path expression.
+//
+// Synthetic paths, such as those built by package builder, have no source
+// text, so for those this is the name of the path's identifier, if it is one.
+func pathText(expr ast.Path) string {
+	if expr.IsSynthetic() {
+		return expr.AsIdent().Name()
+	}
+	return expr.Span().Text()
+}
+
 // errTypeCheck is a type-checking failure.
 type errTypeCheck struct {
 	want, got any
@@ -1224,6 +1250,7 @@ func (e errTypeCheck) Diagnose(d *report.Diagnostic) {
 
 	d.Apply(
 		report.Message("mismatched types"),
+		report.Tag(rtags.TypeMismatch),
 		report.Snippetf(e.expr, "expected %s, found %s", wantName, gotName),
 		report.Notef("expected: %s\n   found: %s", wantWhat, gotWhat),
 	)
@@ -1244,6 +1271,7 @@ type errTypeConstraint struct {
 func (e errTypeConstraint) Diagnose(d *report.Diagnostic) {
 	d.Apply(
 		report.Message("expected %s, found %s `%s`", e.want, e.got.noun(), e.got.FullName()),
+		report.Tag(rtags.InvalidType),
 		report.Snippet(e.decl.RemovePrefixes()),
 	)
 }
@@ -1305,6 +1333,7 @@ func (e errLiteralRange) Diagnose(d *report.Diagnostic) {
 	if e.bits == tags.FieldBits {
 		d.Apply(
 			report.Message("%s out of range", taxa.FieldNumber),
+			report.Tag(rtags.OutOfRange),
 			report.Snippet(e.expr),
 			report.Notef("the range for %ss is `%v to %v`,\n"+
 				"minus `%v to %v`, which is reserved for internal use",
@@ -1317,6 +1346,7 @@ func (e errLiteralRange) Diagnose(d *report.Diagnostic) {
 	} else {
 		d.Apply(
 			report.Message("literal out of range for %s", name),
+			report.Tag(rtags.OutOfRange),
 			report.Snippet(e.expr),
 			report.Notef("the range for %s is `%v%v to %v`", name, sign,
 				itoa(lo), itoa(hi)),
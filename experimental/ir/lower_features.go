@@ -23,6 +23,7 @@ import (
 	"github.com/bufbuild/protocompile/experimental/id"
 	"github.com/bufbuild/protocompile/experimental/internal/erredition"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/seq"
 	"github.com/bufbuild/protocompile/internal/ext/slicesx"
 )
@@ -54,6 +55,7 @@ func buildFeatureInfo(field Member, r *report.Report) {
 	info := new(rawFeatureInfo)
 	if defaults.IsZero() {
 		r.Warnf("expected feature field to set `%s`", builtins.EditionDefaults.Name()).Apply(
+			report.Tag(rtags.InvalidFeatureDefinition),
 			report.Snippet(field.AST().Options()), mistake,
 		)
 	} else {
@@ -68,6 +70,7 @@ func buildFeatureInfo(field Member, r *report.Report) {
 					builtins.EditionDefaultsKey.Container().Name(),
 					builtins.EditionDefaultsKey.Name(),
 				).Apply(
+					report.Tag(rtags.InvalidFeatureDefinition),
 					report.Snippet(def.AsValue().ValueAST()),
 					mistake,
 				)
@@ -77,6 +80,7 @@ func buildFeatureInfo(field Member, r *report.Report) {
 					builtins.EditionDefaultsKey.Container().Name(),
 					builtins.EditionDefaultsKey.Name(),
 				).Apply(
+					report.Tag(rtags.InvalidFeatureDefinition),
 					report.Snippet(value.ValueAST()),
 					mistake,
 					report.Helpf("this should be a released edition or `%s`",
@@ -94,6 +98,7 @@ func buildFeatureInfo(field Member, r *report.Report) {
 					builtins.EditionDefaultsKey.Container().Name(),
 					builtins.EditionDefaultsKey.Name(),
 				).Apply(
+					report.Tag(rtags.InvalidFeatureDefinition),
 					report.Snippet(def.AsValue().ValueAST()), mistake,
 				)
 			} else {
@@ -106,6 +111,7 @@ func buildFeatureInfo(field Member, r *report.Report) {
 							builtins.EditionDefaultsKey.Container().Name(),
 							builtins.EditionDefaultsKey.Name(),
 						).Apply(
+							report.Tag(rtags.InvalidFeatureDefinition),
 							report.Snippet(value.ValueAST()),
 							report.Snippetf(field.TypeAST(), "expected due to this"),
 							report.Helpf("`value` must be the name of a value in `%s`", field.Element().FullName()),
@@ -124,6 +130,7 @@ func buildFeatureInfo(field Member, r *report.Report) {
 							builtins.EditionDefaultsValue.Container().Name(),
 							builtins.EditionDefaultsValue.Name(),
 						).Apply(
+							report.Tag(rtags.InvalidFeatureDefinition),
 							report.Snippet(value.ValueAST()),
 							report.Snippetf(field.TypeAST(), "expected due to this"),
 							report.Helpf("`value` must one of \"true\" or \"false\""),
@@ -168,6 +175,7 @@ func buildFeatureInfo(field Member, r *report.Report) {
 
 	if len(info.defaults) > 0 && !slicesx.Among(info.defaults[0].edition, syntax.EditionLegacy, syntax.Proto2) {
 		r.Warnf("`%s` does not cover all editions", builtins.EditionDefaults.Name()).Apply(
+			report.Tag(rtags.InvalidFeatureDefinition),
 			report.Snippet(defaults.ValueAST()),
 			report.Helpf(
 				"`%s` must specify a default for `%s` or `%s` to cover all editions",
@@ -189,6 +197,7 @@ func buildFeatureInfo(field Member, r *report.Report) {
 
 	if support.IsZero() {
 		r.Warnf("expected feature field to set `%s`", builtins.EditionSupport.Name()).Apply(
+			report.Tag(rtags.InvalidFeatureDefinition),
 			report.Snippet(field.AST().Options()), mistake,
 		)
 	} else {
@@ -200,6 +209,7 @@ func buildFeatureInfo(field Member, r *report.Report) {
 				builtins.EditionSupportIntroduced.Container().Name(),
 				builtins.EditionSupportIntroduced.Name(),
 			).Apply(
+				report.Tag(rtags.InvalidFeatureDefinition),
 				report.Snippet(support.AsValue().ValueAST()),
 				mistake,
 			)
@@ -208,6 +218,7 @@ func buildFeatureInfo(field Member, r *report.Report) {
 				info.introduced.DescriptorName(),
 				builtins.EditionSupportIntroduced.Name(),
 			).Apply(
+				report.Tag(rtags.InvalidFeatureDefinition),
 				report.Snippet(value.ValueAST()),
 				mistake,
 			)
@@ -221,6 +232,7 @@ func buildFeatureInfo(field Member, r *report.Report) {
 				info.deprecated.DescriptorName(),
 				builtins.EditionSupportDeprecated.Name(),
 			).Apply(
+				report.Tag(rtags.InvalidFeatureDefinition),
 				report.Snippet(value.ValueAST()),
 				mistake,
 			)
@@ -234,6 +246,7 @@ func buildFeatureInfo(field Member, r *report.Report) {
 				info.removed.DescriptorName(),
 				builtins.EditionSupportRemoved.Name(),
 			).Apply(
+				report.Tag(rtags.InvalidFeatureDefinition),
 				report.Snippet(value.ValueAST()),
 				mistake,
 			)
@@ -363,6 +376,7 @@ func validateFeatures(features MessageValue, r *report.Report) {
 		info := feature.Field().FeatureInfo()
 		if info.IsZero() {
 			r.Warnf("non-feature field set within `%s`", features.AsValue().Field().Name()).Apply(
+				report.Tag(rtags.InvalidFeature),
 				report.Snippet(feature.ValueAST()),
 				report.Helpf("a feature field is a field which sets the `%s` and `%s` options",
 					builtins.EditionDefaults.Name(),
@@ -25,6 +25,7 @@ import (
 	"github.com/bufbuild/protocompile/experimental/ast"
 	"github.com/bufbuild/protocompile/experimental/internal/cycle"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/internal/ext/iterx"
 	"github.com/bufbuild/protocompile/internal/intern"
 )
@@ -73,11 +74,13 @@ func buildImports(file *File, r *report.Report, importer Importer) {
 			continue
 		case errors.Is(err, fs.ErrNotExist):
 			r.Errorf("imported file does not exist").Apply(
+				report.Tag(rtags.ImportNotFound),
 				report.Snippetf(imp, "imported here"),
 			)
 			continue
 		default:
 			r.Errorf("could not open imported file: %v", err).Apply(
+				report.Tag(rtags.ImportNotFound),
 				report.Snippetf(imp, "imported here"),
 			)
 			continue
@@ -85,6 +88,7 @@ func buildImports(file *File, r *report.Report, importer Importer) {
 
 		if prev, ok := dedup.AddID(imported.InternedPath(), imp); !ok {
 			d := r.Errorf("file imported multiple times").Apply(
+				report.Tag(rtags.DuplicateImport),
 				report.Snippet(imp),
 				report.Snippetf(prev, "first imported here"),
 			)
@@ -144,7 +148,9 @@ func buildImports(file *File, r *report.Report, importer Importer) {
 // import contributing to the cycle in turn.
 func diagnoseCycle(r *report.Report, cycle *ErrCycle) {
 	path := cycle.Cycle[0].ImportPath().AsLiteral().AsString().Text()
-	err := r.Errorf("detected cyclic import while importing %q", path)
+	err := r.Errorf("detected cyclic import while importing %q", path).Apply(
+		report.Tag(rtags.ImportCycle),
+	)
 
 	for i, imp := range cycle.Cycle {
 		var message string
@@ -176,6 +182,7 @@ func canonicalizeImportPath(path string, r *report.Report, decl ast.DeclImport)
 	if path == "" {
 		if r != nil {
 			r.Errorf("import path cannot be empty").Apply(
+				report.Tag(rtags.InvalidImportPath),
 				report.Snippet(decl.ImportPath()),
 			)
 		}
@@ -188,6 +195,7 @@ func canonicalizeImportPath(path string, r *report.Report, decl ast.DeclImport)
 	hasBackslash := orig != path
 	if r != nil && hasBackslash {
 		r.Errorf("import path cannot use `\\` as a path separator").Apply(
+			report.Tag(rtags.InvalidImportPath),
 			report.Snippetf(decl.ImportPath(), "this path begins with a `%c`", path[0]),
 			report.SuggestEdits(decl.ImportPath(), "use `/` as the separator instead", report.Edit{
 				Start: 0, End: decl.ImportPath().Span().Len(),
@@ -201,6 +209,7 @@ func canonicalizeImportPath(path string, r *report.Report, decl ast.DeclImport)
 	isClean := !hasBackslash && orig == path
 	if r != nil && !isClean {
 		r.Errorf("import path must not contain `.`, `..`, or repeated separators").Apply(
+			report.Tag(rtags.InvalidImportPath),
 			report.Snippetf(decl.ImportPath(), "imported here"),
 			report.SuggestEdits(decl.ImportPath(), "canonicalize this path", report.Edit{
 				Start: 0, End: decl.ImportPath().Span().Len(),
@@ -211,6 +220,7 @@ func canonicalizeImportPath(path string, r *report.Report, decl ast.DeclImport)
 
 	if r != nil && isClean && strings.HasPrefix(path, "../") {
 		r.Errorf("import path must not refer to parent directory").Apply(
+			report.Tag(rtags.InvalidImportPath),
 			report.Snippetf(decl.ImportPath(), "imported here"),
 		)
 
@@ -224,6 +234,7 @@ func canonicalizeImportPath(path string, r *report.Report, decl ast.DeclImport)
 		if len(path) >= 2 && isLetter(path[0]) && path[1] == ':' {
 			// TODO: error on windows?
 			r.Warnf("import path appears to begin with the Windows drive prefix `%s`", path[:2]).Apply(
+				report.Tag(rtags.InvalidImportPath),
 				report.Snippet(decl.ImportPath()),
 				report.Notef("this is not an error, because `protoc` accepts it, but may result in unexpected behavior on Windows"),
 			)
@@ -232,6 +243,7 @@ func canonicalizeImportPath(path string, r *report.Report, decl ast.DeclImport)
 
 	if r != nil && strings.HasPrefix(path, "/") {
 		r.Errorf("import path must be relative").Apply(
+			report.Tag(rtags.InvalidImportPath),
 			report.Snippetf(decl.ImportPath(), "this path begins with a `%c`", path[0]),
 		)
 		return ""
@@ -20,6 +20,7 @@ import (
 	"github.com/bufbuild/protocompile/experimental/ast/syntax"
 	"github.com/bufbuild/protocompile/experimental/internal/taxa"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/seq"
 	"github.com/bufbuild/protocompile/internal"
 	"github.com/bufbuild/protocompile/internal/intern"
@@ -111,6 +112,7 @@ func populateJSONNames(file *File, r *report.Report) {
 
 		if custom {
 			d := r.SoftErrorf(want != got, "%s cannot specify `json_name`", taxa.Extension).Apply(
+				report.Tag(rtags.InvalidOption),
 				report.Snippet(option.OptionSpan()),
 				report.Notef("JSON format for extensions always uses the extension's fully-qualified name"),
 			)
@@ -131,6 +133,8 @@ func (e errJSONConflict) Diagnose(d *report.Diagnostic) {
 	eitherIsCustom := !e.first.PseudoOptions().JSONName.IsZero() ||
 		!e.second.PseudoOptions().JSONName.IsZero()
 
+	d.Apply(report.Tag(rtags.JSONNameConflict))
+
 	if !e.involvesCustomName && eitherIsCustom {
 		d.Apply(report.Message("%ss have the same (default) JSON name", e.first.noun()))
 	} else {
@@ -22,6 +22,7 @@ import (
 	"github.com/bufbuild/protocompile/experimental/id"
 	"github.com/bufbuild/protocompile/experimental/ir/presence"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/seq"
 	pcinternal "github.com/bufbuild/protocompile/internal"
 	"github.com/bufbuild/protocompile/internal/tags"
@@ -127,6 +128,7 @@ func generateMapEntries(file *File, r *report.Report) {
 		}
 
 		r.Errorf("unsupported map-typed extension").Apply(
+			report.Tag(rtags.InvalidMap),
 			report.Snippetf(extn.AST().Type(), "declared here"),
 			report.Helpf("extensions cannot be map-typed; instead, "+
 				"define a message type with a map-typed field"),
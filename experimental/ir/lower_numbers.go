@@ -19,6 +19,7 @@ import (
 	"github.com/bufbuild/protocompile/experimental/id"
 	"github.com/bufbuild/protocompile/experimental/internal/taxa"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/seq"
 	"github.com/bufbuild/protocompile/internal/arena"
 	"github.com/bufbuild/protocompile/internal/ext/iterx"
@@ -70,6 +71,7 @@ func evaluateFieldNumbers(file *File, r *report.Report) {
 					}
 
 					r.Errorf("empty %s %v %v", what).Apply(
+						report.Tag(rtags.InvalidRange),
 						report.Snippet(tags.AST()),
 						report.Notef("range syntax requires that start <= end"),
 					)
@@ -78,6 +80,7 @@ func evaluateFieldNumbers(file *File, r *report.Report) {
 
 				if start == end {
 					r.Warnf("singleton range can be simplified").Apply(
+						report.Tag(rtags.Redundant),
 						report.Snippet(tags.AST()),
 						report.SuggestEdits(tags.AST(), "replace with a single number", report.Edit{
 							Start: 0, End: tags.AST().Span().Len(),
@@ -276,6 +279,7 @@ extensions:
 			}
 
 			d = r.Errorf("extension with unreserved number `%v`", n).Apply(
+				report.Tag(rtags.UnreservedExtension),
 				report.Snippet(extn.AST().Value()),
 			)
 		}
@@ -305,6 +309,7 @@ again:
 		if first := e.first.AsMember(); !first.IsZero() {
 			d.Apply(
 				report.Message("%v `%v` used more than once", what, second.Number()),
+				report.Tag(rtags.DuplicateNumber),
 				report.Snippetf(second.AST().Value(), "used here"),
 				report.Snippetf(first.AST().Value(), "previously used here"),
 			)
@@ -312,6 +317,7 @@ again:
 			first := e.first.AsReserved()
 			d.Apply(
 				report.Message("use of reserved %v `%v`", what, second.Number()),
+				report.Tag(rtags.ReservedNumber),
 				report.Snippetf(second.AST().Value(), "used here"),
 				report.Snippetf(first.AST(), "%v reserved here", what),
 			)
@@ -329,6 +335,7 @@ again:
 		lo2, hi2 := second.Range()
 		d.Apply(
 			report.Message("overlapping %v ranges", what),
+			report.Tag(rtags.OverlappingRanges),
 			report.Snippetf(second.AST(), "this range"),
 			report.Snippetf(first.AST(), "overlaps with this one"),
 		)
@@ -22,6 +22,7 @@ import (
 	"github.com/bufbuild/protocompile/experimental/id"
 	"github.com/bufbuild/protocompile/experimental/internal/taxa"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/seq"
 	"github.com/bufbuild/protocompile/experimental/source"
 	"github.com/bufbuild/protocompile/experimental/token/keyword"
@@ -45,6 +46,7 @@ func checkMessageSetFieldUsage(field Member, span source.Spanner, r *report.Repo
 		"field `%s` may not be used in an option: it uses 'message set wire format' legacy proto1 feature which is not supported",
 		field.FullName(),
 	).Apply(
+		report.Tag(rtags.InvalidMessageSet),
 		report.Snippet(span),
 		report.PageBreak,
 		report.Snippetf(extendee.AST().Stem(), "`%s` declared as message set here", extendee.FullName()),
@@ -377,6 +379,7 @@ func validateOptionTargetsInValue(m MessageValue, decl source.Span, target Optio
 			}
 
 			d := r.Errorf("unsupported option target for `%s`", field.Name()).Apply(
+				report.Tag(rtags.InvalidOptionTarget),
 				report.Snippetf(span, "option set here"),
 				report.Snippetf(decl, "applied to this"),
 				report.Snippetf(constraints.ValueAST(), "targets constrained here"),
@@ -445,6 +448,7 @@ func (r optionRef) resolve() {
 	if r.field.IsZero() {
 		fqn := r.session.intern.Value(r.fieldFQN)
 		d := r.Errorf("cannot resolve %s", taxa.Option).Apply(
+			report.Tag(rtags.UnknownSymbol),
 			report.Snippet(r.def),
 		)
 		if dpFile := r.imports.DescriptorProto(); dpFile != nil {
@@ -524,6 +528,7 @@ func (r optionRef) resolve() {
 				d := r.Errorf("expected `%s` extension, found %s in `%s`",
 					message.FullName(), field.noun(), field.Container().FullName(),
 				).Apply(
+					report.Tag(rtags.WrongSymbolKind),
 					report.Snippetf(pc, "because of this %s", taxa.FieldSelector),
 					report.Snippetf(field.AST().Name(), "`%s` defined here", field.FullName()),
 				)
@@ -539,6 +544,7 @@ func (r optionRef) resolve() {
 			if !field.IsExtension() {
 				// Protoc accepts this! The horror!
 				r.Warnf("redundant %s syntax", taxa.CustomOption).Apply(
+					report.Tag(rtags.Redundant),
 					report.Snippetf(pc, "this field is not a %s", taxa.Extension),
 					report.Snippetf(field.AST().Name(), "field declared inside of `%s` here", field.Parent().FullName()),
 					report.Helpf("%s syntax should only be used with %ss", taxa.CustomOption, taxa.Extension),
@@ -552,6 +558,7 @@ func (r optionRef) resolve() {
 			field = message.MemberByName(ident.Text())
 			if field.IsZero() {
 				d := r.Errorf("cannot find %s `%s` in `%s`", taxa.Field, ident.Text(), message.FullName()).Apply(
+					report.Tag(rtags.UnknownField),
 					report.Snippetf(pc, "because of this %s", taxa.FieldSelector),
 				)
 				if !pc.IsFirst() {
@@ -581,6 +588,7 @@ func (r optionRef) resolve() {
 			switch field.InternedFullName() {
 			case ids.MapEntry:
 				r.Errorf("`map_entry` cannot be set explicitly").Apply(
+					report.Tag(rtags.InvalidOption),
 					report.Snippet(pc),
 					report.Helpf("`map_entry` is set automatically for synthetic map "+
 						"entry types, and cannot be set with an %s", taxa.Option),
@@ -591,6 +599,7 @@ func (r optionRef) resolve() {
 				ids.EnumUninterpreted, ids.EnumValueUninterpreted,
 				ids.MethodUninterpreted, ids.ServiceUninterpreted:
 				r.Errorf("`uninterpreted_option` cannot be set explicitly").Apply(
+					report.Tag(rtags.InvalidOption),
 					report.Snippet(pc),
 					report.Helpf("`uninterpreted_option` is an implementation detail of protoc"),
 				)
@@ -600,6 +609,7 @@ func (r optionRef) resolve() {
 				ids.EnumFeatures, ids.EnumValueFeatures:
 				if syn := r.Syntax(); !syn.IsEdition() {
 					r.Errorf("`features` cannot be set in %s", syn.Name()).Apply(
+						report.Tag(rtags.InvalidOption),
 						report.Snippet(pc),
 						report.Snippetf(r.AST().Syntax().Value(), "syntax specified here"),
 					)
@@ -759,6 +769,7 @@ func (e errSetMultipleTimes) Diagnose(d *report.Diagnostic) {
 
 	d.Apply(
 		report.Message("%v `%v` set multiple times", what, name),
+		report.Tag(rtags.DuplicateOption),
 		report.Snippetf(e.second, "... also set here"),
 		report.Snippetf(e.first, "first set here..."),
 		report.Snippetf(def, "not a repeated field"),
@@ -779,6 +790,7 @@ func (e errOptionMustBeMessage) Diagnose(d *report.Diagnostic) {
 
 	d.Apply(
 		report.Message("expected singular message, found %s", got),
+		report.Tag(rtags.InvalidType),
 		report.Snippetf(e.selector, "%s requires singular message", taxa.FieldSelector),
 		report.Snippetf(e.prev, "found %s", got),
 	)
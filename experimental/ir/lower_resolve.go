@@ -15,8 +15,6 @@
 package ir
 
 import (
-	"fmt"
-
 	"github.com/bufbuild/protocompile/experimental/ast"
 	"github.com/bufbuild/protocompile/experimental/ast/predeclared"
 	"github.com/bufbuild/protocompile/experimental/ast/syntax"
@@ -163,6 +161,7 @@ func resolveFieldType(field Member, r *report.Report) {
 
 		if mf := sym.AsType().MapField(); !mf.IsZero() {
 			r.Errorf("use of synthetic map entry type").Apply(
+				report.Tag(rtags.InvalidMap),
 				report.Snippetf(path, "referenced here"),
 				report.Snippetf(mf.TypeAST(), "synthesized by this type"),
 				report.Helpf("despite having a user-visible symbol, map entry "+
@@ -343,6 +342,15 @@ func (r symbolRef) resolve() Symbol {
 // operation.
 func (r symbolRef) diagnoseLookup(sym Symbol, expectedName FullName) *report.Diagnostic {
 	if sym.IsZero() {
+		if r.suggestImport {
+			r.File.unresolved = append(r.File.unresolved, unresolvedRef{
+				scope:  r.scope,
+				name:   r.name,
+				span:   source.GetSpan(r.span),
+				accept: r.accept,
+			})
+		}
+
 		return r.Errorf("cannot find `%s` in this scope", r.name).Apply(
 			report.Tag(rtags.UnknownSymbol),
 			report.Snippetf(r.span, "not found in this scope"),
@@ -352,6 +360,7 @@ func (r symbolRef) diagnoseLookup(sym Symbol, expectedName FullName) *report.Dia
 
 	if k := sym.Kind(); r.accept != nil && !r.accept(k) {
 		return r.Errorf("expected %s, found %s `%s`", r.want, k.noun(), sym.FullName()).Apply(
+			report.Tag(rtags.WrongSymbolKind),
 			report.Snippetf(r.span, "expected %s", r.want),
 			report.Snippetf(sym.Definition(), "defined here"),
 		)
@@ -383,6 +392,7 @@ func (r symbolRef) diagnoseLookup(sym Symbol, expectedName FullName) *report.Dia
 
 			// This symbol is only visible in option position.
 			return r.Errorf("`%s` is only imported for use in options", r.name).Apply(
+				report.Tag(rtags.OptionOnlyImport),
 				report.Snippetf(r.span, "requires non-`option` import"),
 				report.Snippetf(decl, "imported as `option` here"),
 				report.SuggestEdits(span, "delete `option`", report.Edit{
@@ -397,6 +407,7 @@ func (r symbolRef) diagnoseLookup(sym Symbol, expectedName FullName) *report.Dia
 		if imp := sym.Import(r.File); imp.Visible {
 			if ty := sym.AsType(); !ty.IsZero() {
 				d := r.Errorf("found unexported %s `%s`", ty.noun(), ty.FullName()).Apply(
+					report.Tag(rtags.Visibility),
 					report.Snippetf(r.span, "unexported type"),
 				)
 
@@ -431,6 +442,7 @@ func (r symbolRef) diagnoseLookup(sym Symbol, expectedName FullName) *report.Dia
 
 		// Complain that we need to import a symbol.
 		d := r.Errorf("cannot find `%s` in this scope", r.name).Apply(
+			report.Tag(rtags.UnknownSymbol),
 			report.Snippetf(r.span, "not visible in this scope"),
 			report.Snippetf(sym.Definition(), "found in unimported file"),
 		)
@@ -439,28 +451,7 @@ func (r symbolRef) diagnoseLookup(sym Symbol, expectedName FullName) *report.Dia
 			return d
 		}
 
-		// Find the last import statement and stick the suggestion after it.
-		decls := sym.Context().AST().Decls()
-		_, _, imp := iterx.Find2(seq.Backward(decls), func(_ int, d ast.DeclAny) bool {
-			return d.Kind() == ast.DeclKindImport
-		})
-
-		var offset int
-		if !imp.IsZero() {
-			offset = imp.Span().End
-		}
-
-		replacement := fmt.Sprintf("\nimport %q;", sym.Context().Path())
-		if offset == 0 {
-			replacement = replacement[1:] + "\n"
-		}
-
-		d.Apply(report.SuggestEdits(
-			imp.Span().File.Span(offset, offset),
-			fmt.Sprintf("bring `%s` into scope", r.name),
-			report.Edit{Replace: replacement},
-		))
-
+		d.Apply(suggestImport(r.File.AST(), sym.Context().Path(), r.name))
 		return d
 	}
 
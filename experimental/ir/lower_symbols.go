@@ -22,6 +22,7 @@ import (
 	"github.com/bufbuild/protocompile/experimental/id"
 	"github.com/bufbuild/protocompile/experimental/internal/taxa"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/seq"
 	"github.com/bufbuild/protocompile/experimental/source"
 	"github.com/bufbuild/protocompile/internal/arena"
@@ -332,6 +333,7 @@ func (e errDuplicates) Diagnose(d *report.Diagnostic) {
 	noun := first.Kind().noun()
 	d.Apply(
 		report.Message("`%s` declared multiple times%s", name, inParent),
+		report.Tag(rtags.DuplicateSymbol),
 		report.Snippetf(first.Definition(),
 			"first here, as %s %s",
 			article(noun), noun),
@@ -141,6 +141,7 @@ func validateEnum(ty Type, r *report.Report) {
 
 	if ty.Members().Len() == 0 {
 		r.Errorf("%s must define at least one value", taxa.EnumType).Apply(
+			report.Tag(rtags.EmptyDefinition),
 			report.Snippet(ty.AST()),
 		)
 		return
@@ -164,6 +165,7 @@ func validateEnum(ty Type, r *report.Report) {
 		if !hasAlias {
 			option := ty.Options().Field(builtins.AllowAlias)
 			r.Errorf("`%s` requires at least one aliasing %s", option.Field().Name(), taxa.EnumValue).Apply(
+				report.Tag(rtags.InvalidEnum),
 				report.Snippet(option.OptionSpan()),
 			)
 		}
@@ -179,6 +181,7 @@ func validateEnum(ty Type, r *report.Report) {
 		}
 
 		r.Errorf("first value of open enum must be zero").Apply(
+			report.Tag(rtags.InvalidEnum),
 			report.Snippet(first.AST().Value()),
 			report.PageBreak,
 			report.Snippetf(why, "this makes `%s` an open enum", ty.FullName()),
@@ -243,8 +246,11 @@ type errEnumValueConflict struct {
 }
 
 func (e errEnumValueConflict) Diagnose(d *report.Diagnostic) {
-	d.Apply(report.Message("%ss have the same name with the `%s` prefix removed",
-		e.first.noun(), e.enumName))
+	d.Apply(
+		report.Message("%ss have the same name with the `%s` prefix removed",
+			e.first.noun(), e.enumName),
+		report.Tag(rtags.EnumValueConflict),
+	)
 	d.Apply(
 		report.Snippetf(e.second.AST().Name(), "this also implies that name"),
 		report.Snippetf(e.first.AST().Name(), "this implies canonical name `%s`", e.canonicalName),
@@ -263,6 +269,7 @@ func validateFileOptions(f *File, r *report.Report) {
 		}
 
 		r.Errorf("cannot set `%s` in %s", javaUTF8.Field().Name(), taxa.EditionMode).Apply(
+			report.Tag(rtags.InvalidOption),
 			report.Snippet(javaUTF8.KeyAST()),
 			javaUTF8.suggestEdit("features.(pb.java).utf8_validation", want, "replace with `features.(pb.java).utf8_validation`"),
 		)
@@ -292,6 +299,7 @@ func validateFileOptions(f *File, r *report.Report) {
 			impOptimize := imp.Options().Field(builtins.OptimizeFor)
 			if v, _ := impOptimize.AsInt(); v == tags.FileOptions_OptimizeMode_LiteRuntime {
 				r.Errorf("`LITE_RUNTIME` file imported in non-`LITE_RUNTIME` file").Apply(
+					report.Tag(rtags.InvalidOption),
 					report.Snippet(imp.Decl.ImportPath()),
 					report.Snippetf(optimize.ValueAST(), "optimization level set here"),
 					report.Snippetf(impOptimize.ValueAST(), "`%s` set as `LITE_RUNTIME` here", path.Base(imp.Path())),
@@ -305,6 +313,7 @@ func validateFileOptions(f *File, r *report.Report) {
 	defaultPresence := f.FeatureSet().Lookup(builtins.FeaturePresence).Value()
 	if v, _ := defaultPresence.AsInt(); v == tags.FeatureSet_FieldPresence_LegacyRequired {
 		r.Errorf("cannot set `LEGACY_REQUIRED` at the file level").Apply(
+			report.Tag(rtags.InvalidFeature),
 			report.Snippet(defaultPresence.ValueAST()),
 		)
 	}
@@ -322,6 +331,7 @@ func validateReservedNames(ty Type, r *report.Report) {
 		}
 
 		r.Errorf("use of reserved %s name", member.noun()).Apply(
+			report.Tag(rtags.ReservedName),
 			report.Snippet(member.AST().Name()),
 			report.Snippetf(name.AST(), "`%s` reserved here", member.Name()),
 		)
@@ -338,7 +348,7 @@ func validateReservedNames(ty Type, r *report.Report) {
 			what = taxa.EnumValue
 		}
 
-		d := r.Errorf("%s `%s` reserved more than once", what, name).Apply(report.Snippet(spans[0]))
+		d := r.Errorf("%s `%s` reserved more than once", what, name).Apply(report.Tag(rtags.DuplicateReserved), report.Snippet(spans[0]))
 		for _, span := range spans[1:] {
 			d.Apply(report.Snippetf(span, "`%s` also reserved here", name))
 		}
@@ -348,6 +358,7 @@ func validateReservedNames(ty Type, r *report.Report) {
 func validateOneof(oneof Oneof, r *report.Report) {
 	if oneof.Members().Len() == 0 {
 		r.Errorf("oneof must define at least one member").Apply(
+			report.Tag(rtags.EmptyDefinition),
 			report.Snippet(oneof.AST()),
 		)
 	}
@@ -359,6 +370,7 @@ func validateExtensionRange(rr ReservedRange, r *report.Report) {
 	}
 
 	r.Errorf("%s in \"proto3\"", taxa.Extensions).Apply(
+		report.Tag(rtags.RemovedInEdition),
 		report.Snippet(rr.AST()),
 		report.PageBreak,
 		report.Snippetf(rr.Context().AST().Syntax().Value(), "\"proto3\" specified here"),
@@ -369,6 +381,7 @@ func validateExtensionRange(rr ReservedRange, r *report.Report) {
 func validateExtend(extend Extend, r *report.Report) {
 	if extend.Extensions().Len() == 0 {
 		r.Errorf("%s must declare at least one %s", taxa.Extend, taxa.Extension).Apply(
+			report.Tag(rtags.EmptyDefinition),
 			report.Snippet(extend.AST()),
 		)
 	}
@@ -409,6 +422,7 @@ func validateMessageSet(ty Type, r *report.Report) {
 
 	if f.Syntax() == syntax.Proto3 {
 		r.Errorf("%s are not supported", taxa.MessageSet).Apply(
+			report.Tag(rtags.InvalidMessageSet),
 			report.Snippetf(ty.Options().Field(builtins.MessageSet).KeyAST(), "declared as message set here"),
 			report.Snippet(ty.AST().Stem()),
 			report.PageBreak,
@@ -424,6 +438,7 @@ func validateMessageSet(ty Type, r *report.Report) {
 	for member := range seq.Values(ty.Members()) {
 		ok = false
 		r.Errorf("field declared in %s `%s`", taxa.MessageSet, ty.FullName()).Apply(
+			report.Tag(rtags.InvalidMessageSet),
 			report.Snippet(member.AST()),
 			report.PageBreak,
 			report.Snippet(ty.AST().Stem()),
@@ -435,6 +450,7 @@ func validateMessageSet(ty Type, r *report.Report) {
 	for oneof := range seq.Values(ty.Oneofs()) {
 		ok = false
 		r.Errorf("field declared in %s `%s`", taxa.MessageSet, ty.FullName()).Apply(
+			report.Tag(rtags.InvalidMessageSet),
 			report.Snippet(oneof.AST()),
 			report.PageBreak,
 			report.Snippetf(ty.Options().Field(builtins.MessageSet).KeyAST(), "declared as message set here"),
@@ -446,6 +462,7 @@ func validateMessageSet(ty Type, r *report.Report) {
 	if ty.ExtensionRanges().Len() == 0 {
 		ok = false
 		r.Errorf("%s `%s` declares no %ss", taxa.MessageSet, ty.FullName(), taxa.Extensions).Apply(
+			report.Tag(rtags.InvalidMessageSet),
 			report.Snippetf(ty.Options().Field(builtins.MessageSet).KeyAST(), "declared as message set here"),
 			report.Snippet(ty.AST().Stem()),
 		)
@@ -453,6 +470,7 @@ func validateMessageSet(ty Type, r *report.Report) {
 
 	if ok {
 		r.Warnf("%ss are deprecated", taxa.MessageSet).Apply(
+			report.Tag(rtags.DeprecatedSyntax),
 			report.Snippetf(ty.Options().Field(builtins.MessageSet).KeyAST(), "declared as message set here"),
 			report.Snippet(ty.AST().Stem()),
 			report.Helpf("%ss are not implemented correctly in most Protobuf implementations", taxa.MessageSet),
@@ -469,6 +487,7 @@ func validateMessageSetExtension(extn Member, r *report.Report) {
 		})
 
 		r.Errorf("repeated message set extension").Apply(
+			report.Tag(rtags.InvalidMessageSet),
 			report.Snippet(repeated.PrefixToken()),
 			report.PageBreak,
 			report.Snippetf(extendee.Options().Field(builtins.MessageSet).KeyAST(), "declared as message set here"),
@@ -479,6 +498,7 @@ func validateMessageSetExtension(extn Member, r *report.Report) {
 
 	if !extn.Element().IsMessage() {
 		r.Errorf("non-message message set extension").Apply(
+			report.Tag(rtags.InvalidMessageSet),
 			report.Snippet(extn.AST().Type().RemovePrefixes()),
 			report.PageBreak,
 			report.Snippetf(extendee.Options().Field(builtins.MessageSet).KeyAST(), "declared as message set here"),
@@ -515,11 +535,13 @@ func validateExtensionDeclarations(ty Type, r *report.Report) {
 		if v, ok := verification.AsInt(); ok && (v == 1) != decls.IsZero() {
 			if decls.IsZero() {
 				r.Errorf("extension range requires declarations, but does not define any").Apply(
+					report.Tag(rtags.InvalidExtensionDecl),
 					report.Snippetf(verification.ValueAST(), "required by this option"),
 					report.Snippet(rangeSpan()),
 				)
 			} else {
 				r.Errorf("unverified extension range defines declarations").Apply(
+					report.Tag(rtags.InvalidExtensionDecl),
 					report.Snippetf(decls.OptionSpan(), "defined here"),
 					report.Snippetf(verification.ValueAST(), "required by this option"),
 				)
@@ -534,6 +556,7 @@ func validateExtensionDeclarations(ty Type, r *report.Report) {
 			// An extension range with declarations and multiple ranges
 			// is not allowed.
 			r.Errorf("multi-range `extensions` with extension declarations").Apply(
+				report.Tag(rtags.InvalidExtensionDecl),
 				report.Snippetf(decls.KeyAST(), "declaration defined here"),
 				report.Snippetf(rangeSpan(), "multiple ranges declared here"),
 				report.Helpf("this is rejected by protoc due to a quirk in its internal representation of extension ranges"),
@@ -560,12 +583,14 @@ func validateExtensionDeclarations(ty Type, r *report.Report) {
 
 				if !found {
 					r.Errorf("out-of-range `%s` in extension declaration", number.Field().Name()).Apply(
+						report.Tag(rtags.InvalidExtensionDecl),
 						report.Snippet(number.ValueAST()),
 						report.Snippetf(rangeSpan(), "%v must be among one of these ranges", n),
 					)
 				}
 			} else {
 				r.Errorf("extension declaration must specify `%s`", builtins.ExtnDeclNumber.Name()).Apply(
+					report.Tag(rtags.InvalidExtensionDecl),
 					report.Snippet(elem.AST()),
 				)
 				haveMissingField = true
@@ -580,6 +605,7 @@ func validateExtensionDeclarations(ty Type, r *report.Report) {
 						d := r.Errorf("expected %s in `%s.%s`", want,
 							v.Field().Container().Name(), v.Field().Name(),
 						).Apply(
+							report.Tag(rtags.InvalidExtensionDecl),
 							report.Snippet(v.ValueAST()),
 						)
 						if strings.ContainsFunc(component, unicode.IsSpace) {
@@ -593,6 +619,7 @@ func validateExtensionDeclarations(ty Type, r *report.Report) {
 					d := r.Errorf("relative name in `%s.%s`",
 						v.Field().Container().Name(), v.Field().Name(),
 					).Apply(
+						report.Tag(rtags.InvalidExtensionDecl),
 						report.Snippet(v.ValueAST()),
 					)
 
@@ -617,6 +644,7 @@ func validateExtensionDeclarations(ty Type, r *report.Report) {
 				validatePath(name, "fully-qualified name")
 			} else if !haveMissingField {
 				r.Errorf("extension declaration must specify `%s`", builtins.ExtnDeclName.Name()).Apply(
+					report.Tag(rtags.InvalidExtensionDecl),
 					report.Snippet(elem.AST()),
 				)
 				haveMissingField = true
@@ -632,6 +660,7 @@ func validateExtensionDeclarations(ty Type, r *report.Report) {
 						sym := ty.Context().FindSymbol(FullName(v).ToRelative())
 						if !sym.IsZero() && !sym.Kind().IsType() {
 							r.Warnf("expected type, got %s `%s`", sym.noun(), sym.FullName()).Apply(
+								report.Tag(rtags.InvalidExtensionDecl),
 								report.Snippet(tyName.ValueAST()),
 								report.PageBreak,
 								report.Snippetf(sym.Definition(), "`%s` declared here", sym.FullName()),
@@ -642,6 +671,7 @@ func validateExtensionDeclarations(ty Type, r *report.Report) {
 				}
 			} else if !haveMissingField {
 				r.Errorf("extension declaration must specify `%s`", builtins.ExtnDeclType.Name()).Apply(
+					report.Tag(rtags.InvalidExtensionDecl),
 					report.Snippet(elem.AST()),
 				)
 				haveMissingField = true
@@ -658,6 +688,7 @@ func validateExtensionDeclarations(ty Type, r *report.Report) {
 			for i := start; i <= end; i++ {
 				if !mapsx.Contains(numbers, i) {
 					r.Warnf("missing declaration for extension number `%v`", i).Apply(
+						report.Tag(rtags.MissingExtensionDecl),
 						report.Snippetf(rr.AST(), "required by this range"),
 						report.Notef("this is likely a mistake, but it is not rejected by protoc"),
 					)
@@ -695,6 +726,7 @@ declSearch:
 	reserved := decl.Field(builtins.ExtnDeclReserved)
 	if v, _ := reserved.AsBool(); v {
 		r.Errorf("use of reserved extension number").Apply(
+			report.Tag(rtags.ReservedNumber),
 			report.Snippet(m.AST().Value()),
 			report.PageBreak,
 			report.Snippetf(elem.AST(), "extension declared here"),
@@ -705,6 +737,7 @@ declSearch:
 	name := decl.Field(builtins.ExtnDeclName)
 	if v, ok := name.AsString(); ok && m.FullName() != FullName(v).ToRelative() {
 		r.Errorf("unexpected %s name", taxa.Extension).Apply(
+			report.Tag(rtags.InvalidExtensionDecl),
 			report.Snippetf(m.AST().Name(), "expected `%s`", v),
 			report.PageBreak,
 			report.Snippetf(name.ValueAST(), "expected name declared here"),
@@ -772,6 +805,7 @@ func validatePresence(m Member, r *report.Report) {
 		}
 
 		r.Errorf("expected singular field, found %s field", what).Apply(
+			report.Tag(rtags.InvalidFeature),
 			report.Snippet(m.TypeAST()),
 			report.Snippetf(
 				feature.Value().KeyAST(),
@@ -782,6 +816,7 @@ func validatePresence(m Member, r *report.Report) {
 
 	case m.Presence() == presence.Shared:
 		r.Errorf("expected singular field, found oneof member").Apply(
+			report.Tag(rtags.InvalidFeature),
 			report.Snippet(m.AST()),
 			report.Snippetf(m.Oneof().AST(), "defined in this oneof"),
 			report.Snippetf(
@@ -794,6 +829,7 @@ func validatePresence(m Member, r *report.Report) {
 
 	case m.IsExtension():
 		r.Errorf("expected singular field, found extension").Apply(
+			report.Tag(rtags.InvalidFeature),
 			report.Snippet(m.AST()),
 			report.Snippetf(
 				feature.Value().KeyAST(),
@@ -823,6 +859,7 @@ func validatePresence(m Member, r *report.Report) {
 		}
 	case tags.FeatureSet_FieldPresence_LegacyRequired:
 		r.Warnf("required fields are deprecated").Apply(
+			report.Tag(rtags.DeprecatedSyntax),
 			report.Snippet(feature.Value().ValueAST()),
 			report.Helpf(
 				"do not attempt to change this to `EXPLICIT` if the field is "+
@@ -839,6 +876,7 @@ func validatePacked(m Member, r *report.Report) {
 		switch {
 		case m.IsSingular() || m.IsMap():
 			r.Errorf("expected repeated field, found singular field").Apply(
+				report.Tag(rtags.InvalidOption),
 				report.Snippet(m.TypeAST()),
 				report.Snippetf(span, "packed encoding set here"),
 				report.Helpf("packed encoding can only be set on repeated fields of integer, float, `bool`, or enum type"),
@@ -911,6 +949,7 @@ func validateLazy(m Member, r *report.Report) {
 
 		if m.IsGroup() {
 			r.SoftErrorf(set, "expected length-prefixed field").Apply(
+				report.Tag(rtags.InvalidOption),
 				report.Snippet(m.AST()),
 				report.Snippetf(m.AST().KeywordToken(), "groups are not length-prefixed"),
 				report.Snippetf(lazy.KeyAST(), "`%s` set here", lazy.Field().Name()),
@@ -922,6 +961,7 @@ func validateLazy(m Member, r *report.Report) {
 		groupValue, _ := group.Value().AsInt()
 		if groupValue == tags.FeatureSet_MessageEncoding_Delimited {
 			d := r.SoftErrorf(set, "expected length-prefixed field").Apply(
+				report.Tag(rtags.InvalidOption),
 				report.Snippet(m.AST()),
 				report.Snippetf(lazy.KeyAST(), "`%s` set here", lazy.Field().Name()),
 				report.Helpf("`%s` only makes sense for length-prefixed messages", lazy.Field().Name()),
@@ -1012,6 +1052,7 @@ func validateCType(m Member, r *report.Report) {
 
 	case m.IsExtension() && ctypeValue == tags.FieldOptions_CType_Cord:
 		d := r.SoftErrorf(is2023, "cannot use `CORD` on an extension field").Apply(
+			report.Tag(rtags.InvalidOption),
 			report.Snippet(m.AST()),
 			report.Snippetf(ctype.ValueAST(), "`CORD` set here"),
 		)
@@ -1110,6 +1151,7 @@ func validateDefault(m Member, r *report.Report) {
 
 	if file := m.Context(); file.Syntax() == syntax.Proto3 {
 		r.Errorf("custom default in \"proto3\"").Apply(
+			report.Tag(rtags.RemovedInEdition),
 			report.Snippet(option.OptionSpan()),
 			report.PageBreak,
 			report.Snippetf(file.AST().Syntax().Value(), "\"proto3\" specified here"),
@@ -1139,6 +1181,7 @@ func validateDefault(m Member, r *report.Report) {
 	// Warn if the zero value is used, because it's redundant.
 	if option.IsZeroValue() {
 		r.Warnf("redundant custom default").Apply(
+			report.Tag(rtags.Redundant),
 			report.Snippetf(option.ValueAST(), "this is the zero value for `%s`", m.Element().FullName()),
 			report.Helpf("fields without a custom default will default to the zero value, making this option redundant"),
 		)
@@ -1187,6 +1230,7 @@ func validateVisibility(ty Type, r *report.Report) {
 	export := vis.Keyword() == keyword.Export
 	if !ty.Raw().visibility.IsZero() && export == impliedExport {
 		r.Warnf("redundant visibility modifier").Apply(
+			report.Tag(rtags.Redundant),
 			report.Snippetf(vis, "specified here"),
 			report.PageBreak,
 			report.Snippetf(why, "this implies it"),
@@ -1249,6 +1293,7 @@ func validateVisibility(ty Type, r *report.Report) {
 		switch {
 		case parent.ReservedRanges().Len() != 1:
 			d := r.Errorf("expected exactly one reserved range").Apply(
+				report.Tag(rtags.Visibility),
 				report.Snippetf(vis, "nested type exported here"),
 				report.Snippetf(parent.AST(), "... within this type"),
 			)
@@ -1270,6 +1315,7 @@ func validateVisibility(ty Type, r *report.Report) {
 			)
 		case ty.IsMessage():
 			r.Errorf("nested message type marked as exported").Apply(
+				report.Tag(rtags.Visibility),
 				report.Snippetf(vis, "nested type exported here"),
 				report.Snippetf(parent.AST(), "... within this type"),
 				report.PageBreak,
@@ -1286,6 +1332,7 @@ func validateVisibility(ty Type, r *report.Report) {
 	bugged := parent.ReservedRanges().Len() == 1
 	//nolint:dupword
 	d := r.SoftErrorf(!bugged, "%s `%s` does not reserve all field numbers", parent.noun(), parent.FullName()).Apply(
+		report.Tag(rtags.Visibility),
 		report.Snippetf(vis, "nested type exported here"),
 		report.Snippetf(parent.AST(), "... within this type"),
 		report.PageBreak,
@@ -1318,6 +1365,7 @@ func validateNamingStyle(f *File, r *report.Report) {
 		pkg := f.Package()
 		if pkg != "" && !isValidPackageName(string(pkg)) {
 			r.Errorf("package name should be lower_snake_case").Apply(
+				report.Tag(rtags.NamingStyle),
 				report.Snippetf(f.AST().Package().Path(), "this name violates STYLE2024"),
 				report.Helpf("STYLE2024 requires package names to be lower_snake_case or dot.delimited.lower_snake_case"),
 			)
@@ -1330,6 +1378,7 @@ func validateNamingStyle(f *File, r *report.Report) {
 		// PascalCase required for services.
 		if isStyle2024(svc.FeatureSet()) && !isPascalCase(name) {
 			r.Errorf("service name should be PascalCase").Apply(
+				report.Tag(rtags.NamingStyle),
 				report.Snippetf(svc.AST().Name(), "this name violates STYLE2024"),
 				report.Helpf("STYLE2024 requires service names to be PascalCase (e.g., MyService)"),
 			)
@@ -1343,6 +1392,7 @@ func validateNamingStyle(f *File, r *report.Report) {
 			methodName := method.Name()
 			if !isPascalCase(methodName) {
 				r.Errorf("RPC method name should be PascalCase").Apply(
+					report.Tag(rtags.NamingStyle),
 					report.Snippetf(method.AST().Name(), "this name violates STYLE2024"),
 					report.Helpf("STYLE2024 requires RPC method names to be PascalCase (e.g., GetMessage)"),
 				)
@@ -1358,6 +1408,7 @@ func validateNamingStyle(f *File, r *report.Report) {
 			// PascalCase required for messages.
 			if isStyle2024(ty.FeatureSet()) && !isPascalCase(name) {
 				r.Errorf("%s name should be PascalCase", ty.noun()).Apply(
+					report.Tag(rtags.NamingStyle),
 					report.Snippetf(ty.AST().Name(), "this name violates STYLE2024"),
 					report.Helpf("STYLE2024 requires message names to be PascalCase (e.g., MyMessage)"),
 				)
@@ -1371,6 +1422,7 @@ func validateNamingStyle(f *File, r *report.Report) {
 				fieldName := field.Name()
 				if !isSnakeCase(fieldName) {
 					r.Errorf("field name should be snake_case").Apply(
+						report.Tag(rtags.NamingStyle),
 						report.Snippetf(field.AST().Name(), "this name violates STYLE2024"),
 						report.Helpf("STYLE2024 requires field names to be snake_case (e.g., my_field)"),
 					)
@@ -1385,6 +1437,7 @@ func validateNamingStyle(f *File, r *report.Report) {
 				oneofName := oneof.Name()
 				if !isSnakeCase(oneofName) {
 					r.Errorf("oneof name should be snake_case").Apply(
+						report.Tag(rtags.NamingStyle),
 						report.Snippetf(oneof.AST().Name(), "this name violates STYLE2024"),
 						report.Helpf("STYLE2024 requires oneof names to be snake_case (e.g., my_choice)"),
 					)
@@ -1394,6 +1447,7 @@ func validateNamingStyle(f *File, r *report.Report) {
 			// PascalCase required for enums.
 			if isStyle2024(ty.FeatureSet()) && !isPascalCase(name) {
 				r.Errorf("%s name should be PascalCase", ty.noun()).Apply(
+					report.Tag(rtags.NamingStyle),
 					report.Snippetf(ty.AST().Name(), "this name violates STYLE2024"),
 					report.Helpf("STYLE2024 requires enum names to be PascalCase (e.g., MyEnum)"),
 				)
@@ -1407,6 +1461,7 @@ func validateNamingStyle(f *File, r *report.Report) {
 				valueName := value.Name()
 				if !isScreamingSnakeCase(valueName) {
 					r.Errorf("enum value name should be SCREAMING_SNAKE_CASE").Apply(
+						report.Tag(rtags.NamingStyle),
 						report.Snippetf(value.AST().Name(), "this name violates STYLE2024"),
 						report.Helpf("STYLE2024 requires enum value names to be SCREAMING_SNAKE_CASE (e.g., MY_VALUE)"),
 					)
@@ -1520,7 +1575,10 @@ type errNotUTF8 struct {
 }
 
 func (e *errNotUTF8) Diagnose(d *report.Diagnostic) {
-	d.Apply(report.Message("non-UTF-8 string literal"))
+	d.Apply(
+		report.Message("non-UTF-8 string literal"),
+		report.Tag(rtags.InvalidUTF8),
+	)
 
 	if lit := e.value.AST().AsLiteral().AsString(); !lit.IsZero() {
 		// Figure out the byte offset and the invalid byte. Because this will
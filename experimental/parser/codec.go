@@ -0,0 +1,537 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	"google.golang.org/protobuf/proto"
+
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/internal/astx"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/seq"
+	"github.com/bufbuild/protocompile/experimental/source"
+	"github.com/bufbuild/protocompile/experimental/token"
+	"github.com/bufbuild/protocompile/experimental/token/keyword"
+	compilerpb "github.com/bufbuild/protocompile/internal/gen/buf/compiler/v1alpha1"
+)
+
+// EncodeOptions contains options for the [Encode] function.
+type EncodeOptions struct {
+	// If set, the contents of the file the AST was parsed from will not
+	// be serialized. The same file must then be passed to [Decode].
+	OmitFile bool
+}
+
+// Encode serializes an AST as a buf.compiler.v1alpha1.File message, which
+// can be turned back into an AST with [Decode].
+//
+// Panics if the AST contains a cycle.
+func Encode(file *ast.File, options EncodeOptions) ([]byte, error) {
+	msg := astx.ToProto(file, astx.ToProtoOptions{OmitFile: options.OmitFile})
+	return proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+}
+
+// Decode deserializes an AST produced by [Encode].
+//
+// path is the semantic import path of the file, as in [Parse]. source is the
+// file the AST was parsed from; if it is nil, the file included in the
+// encoding is used instead. If both are present, their contents must agree.
+//
+// Decoding relexes the file and rebuilds each node from the tokens at its
+// encoded spans; the encoding must therefore include spans. Returns an error
+// if the encoded spans do not describe an AST for the file's tokens, such as
+// when they place a token in more than one node. The stream of the returned
+// file is frozen, just like in the result of Parse.
+func Decode(path string, source *source.File, data []byte) (*ast.File, error) {
+	msg := new(compilerpb.File)
+	if err := proto.Unmarshal(data, msg); err != nil {
+		return nil, err
+	}
+	return decode(path, source, msg)
+}
+
+// decode implements [Decode].
+func decode(path string, src *source.File, msg *compilerpb.File) (file *ast.File, err error) {
+	switch {
+	case src == nil && msg.GetFile() == nil:
+		return nil, errors.New("protocompile/parser: encoded AST does not include its file")
+	case src == nil:
+		src = source.NewFile(msg.GetFile().GetPath(), string(msg.GetFile().GetText()))
+	case msg.GetFile() != nil && string(msg.GetFile().GetText()) != src.Text():
+		return nil, fmt.Errorf("protocompile/parser: encoded AST was not produced from %q", src.Path())
+	}
+
+	// The parser's diagnostics are not part of the encoding, so there is no
+	// need to record the lexer's diagnostics either.
+	file = ast.New(path, lex.Lex(src, new(report.Report)))
+	defer file.Stream().Freeze()
+
+	d := &decoder{
+		file:    file,
+		stream:  file.Stream(),
+		claimed: make(map[token.ID]struct{}),
+	}
+	// The lexer gives up on files it cannot lex, such as ones that are not
+	// UTF-8, so spans are bounded by the last token rather than the text.
+	if last, _ := d.stream.Around(len(src.Text())); !last.IsZero() {
+		d.end = last.LeafSpan().End
+	}
+	defer func() {
+		if panicked := recover(); panicked != nil {
+			e, ok := panicked.(decodeError)
+			if !ok {
+				panic(panicked)
+			}
+			file, err = nil, e.err
+		}
+	}()
+
+	for _, decl := range msg.GetDecls() {
+		seq.Append(file.Decls(), d.decl(decl))
+	}
+
+	// The encoding of some nodes omits tokens that we recovered from the
+	// stream instead, so make sure that reencoding the AST produces exactly
+	// what we were given.
+	got := astx.ToProto(file, astx.ToProtoOptions{OmitFile: true}).(*compilerpb.File)
+	for i, decl := range msg.GetDecls() {
+		if i >= len(got.Decls) || !equalEncoding(decl, got.Decls[i]) {
+			return nil, fmt.Errorf("protocompile/parser: encoded declaration %d does not match %q", i, src.Path())
+		}
+	}
+
+	return file, nil
+}
+
+// equalEncoding returns whether two messages have the same wire encoding.
+//
+// Unlike [proto.Equal], this treats a nil element of a repeated field as
+// equal to an empty message, which is what it becomes after a round-trip.
+func equalEncoding(a, b proto.Message) bool {
+	opts := proto.MarshalOptions{Deterministic: true}
+	ab, err1 := opts.Marshal(a)
+	bb, err2 := opts.Marshal(b)
+	return err1 == nil && err2 == nil && bytes.Equal(ab, bb)
+}
+
+// decoder is the state needed for converting a Protobuf message back into an
+// AST node.
+type decoder struct {
+	file   *ast.File
+	stream *token.Stream
+	end    int // The end of the last token in stream.
+
+	// Tokens that already belong to a node. A token may only belong to one
+	// node, which rules out encodings that would decode into a cyclic AST.
+	claimed map[token.ID]struct{}
+}
+
+// decodeError is used to unwind a decoder on failure.
+type decodeError struct{ err error }
+
+// fail aborts decoding with the given error.
+func (d *decoder) fail(format string, args ...any) {
+	panic(decodeError{fmt.Errorf("protocompile/parser: "+format, args...)})
+}
+
+// claim records that tok belongs to a node, and fails if it already belongs
+// to another one.
+func (d *decoder) claim(tok token.Token) token.Token {
+	if tok.IsZero() {
+		return tok
+	}
+	if _, ok := d.claimed[tok.ID()]; ok {
+		span := tok.LeafSpan()
+		d.fail("token at %d:%d is used more than once", span.Start, span.End)
+	}
+	d.claimed[tok.ID()] = struct{}{}
+	return tok
+}
+
+// token returns the token whose span is span, and claims it.
+//
+// For token trees, span may be either that of the open token or that of the
+// whole tree. Returns the zero token if span is nil or empty.
+func (d *decoder) token(span *compilerpb.Span) token.Token {
+	tok := d.start(span)
+	if !tok.IsZero() && tok.LeafSpan().End != int(span.GetEnd()) && tok.Span().End != int(span.GetEnd()) {
+		d.fail("span %d:%d does not match any token", span.GetStart(), span.GetEnd())
+	}
+	return d.claim(tok)
+}
+
+// start returns the token that span starts with.
+//
+// Returns the zero token if span is nil or empty.
+func (d *decoder) start(span *compilerpb.Span) token.Token {
+	start, end := int(span.GetStart()), int(span.GetEnd())
+	if start == end {
+		return token.Zero
+	}
+	if start > end || end > d.end {
+		d.fail("span %d:%d is out of bounds", start, end)
+	}
+
+	_, tok := d.stream.Around(start)
+	if tok.IsZero() || tok.Kind().IsSkippable() || tok.LeafSpan().Start != start {
+		d.fail("span %d:%d does not start at a token", start, end)
+	}
+	return tok
+}
+
+// required is like [decoder.token], but fails if span is missing.
+func (d *decoder) required(span *compilerpb.Span, what string) token.Token {
+	tok := d.token(span)
+	if tok.IsZero() {
+		d.fail("missing span for %s", what)
+	}
+	return tok
+}
+
+// last returns the last token at the level of the tokens in span. If that
+// token is a token tree, this returns its open token.
+func (d *decoder) last(span *compilerpb.Span, what string) token.Token {
+	start := d.start(span)
+	if start.IsZero() {
+		d.fail("missing span for %s", what)
+	}
+	tok, _ := d.stream.Around(int(span.GetEnd()))
+	if tok.IsZero() || tok.LeafSpan().End != int(span.GetEnd()) {
+		d.fail("span %d:%d does not end at a token", span.GetStart(), span.GetEnd())
+	}
+	if !tok.IsLeaf() {
+		tok, _ = tok.StartEnd()
+	}
+	if tok.ID() < start.ID() {
+		d.fail("span %d:%d does not match any path", span.GetStart(), span.GetEnd())
+	}
+	return tok
+}
+
+// comma returns the comma following a node, if there is one.
+//
+// This is used for lists whose commas are not part of the encoding.
+func (d *decoder) comma(node source.Spanner) token.Token {
+	span := node.Span()
+	if span.IsZero() {
+		return token.Zero
+	}
+	tok, _ := d.stream.Around(span.End)
+	if !tok.IsLeaf() {
+		tok, _ = tok.StartEnd()
+	}
+	if next := tok.Next(); next.Keyword() == keyword.Comma {
+		return d.claim(next)
+	}
+	return token.Zero
+}
+
+// fuse fuses a pair of angle brackets, like the parser does.
+func (d *decoder) fuse(open token.Token, close *compilerpb.Span) { //nolint:predeclared,revive // For close.
+	// An unclosed bracket is encoded with a close span that is the same as
+	// its open span.
+	if open.Keyword() != keyword.Lt || int(close.GetStart()) == open.LeafSpan().Start {
+		return
+	}
+	end := d.token(close)
+	if end.IsZero() {
+		return
+	}
+	if !open.IsLeaf() || !end.IsLeaf() || end.Keyword() != keyword.Gt || end.ID() < open.ID() {
+		d.fail("span %d:%d does not match any closing angle bracket", close.GetStart(), close.GetEnd())
+	}
+	token.Fuse(open, end)
+}
+
+func (d *decoder) path(msg *compilerpb.Path) ast.Path {
+	if msg == nil {
+		return ast.Path{}
+	}
+	start, end := d.start(msg.GetSpan()), d.last(msg.GetSpan(), "path")
+	for tok := start; ; tok = tok.Next() {
+		d.claim(tok)
+		if tok.ID() == end.ID() {
+			break
+		}
+	}
+	return astx.NewPath(d.file, start, end)
+}
+
+func (d *decoder) decl(msg *compilerpb.Decl) ast.DeclAny {
+	switch decl := msg.GetDecl().(type) {
+	case *compilerpb.Decl_Empty_:
+		return d.file.Nodes().NewDeclEmpty(d.required(decl.Empty.GetSpan(), "empty declaration")).AsAny()
+
+	case *compilerpb.Decl_Syntax_:
+		msg := decl.Syntax
+		return d.file.Nodes().NewDeclSyntax(ast.DeclSyntaxArgs{
+			Keyword:   d.required(msg.GetKeywordSpan(), "syntax keyword"),
+			Equals:    d.token(msg.GetEqualsSpan()),
+			Value:     d.expr(msg.GetValue()),
+			Options:   d.options(msg.GetOptions()),
+			Semicolon: d.token(msg.GetSemicolonSpan()),
+		}).AsAny()
+
+	case *compilerpb.Decl_Package_:
+		msg := decl.Package
+		return d.file.Nodes().NewDeclPackage(ast.DeclPackageArgs{
+			Keyword:   d.required(msg.GetKeywordSpan(), "package keyword"),
+			Path:      d.path(msg.GetPath()),
+			Options:   d.options(msg.GetOptions()),
+			Semicolon: d.token(msg.GetSemicolonSpan()),
+		}).AsAny()
+
+	case *compilerpb.Decl_Import_:
+		msg := decl.Import
+		args := ast.DeclImportArgs{
+			Keyword:    d.required(msg.GetKeywordSpan(), "import keyword"),
+			ImportPath: d.expr(msg.GetImportPath()),
+			Options:    d.options(msg.GetOptions()),
+			Semicolon:  d.token(msg.GetSemicolonSpan()),
+		}
+		for _, span := range msg.GetModifierSpan() {
+			args.Modifiers = append(args.Modifiers, d.required(span, "import modifier"))
+		}
+		return d.file.Nodes().NewDeclImport(args).AsAny()
+
+	case *compilerpb.Decl_Body_:
+		return d.body(decl.Body).AsAny()
+
+	case *compilerpb.Decl_Range_:
+		msg := decl.Range
+		ranges := d.file.Nodes().NewDeclRange(ast.DeclRangeArgs{
+			Keyword:   d.required(msg.GetKeywordSpan(), "range keyword"),
+			Options:   d.options(msg.GetOptions()),
+			Semicolon: d.token(msg.GetSemicolonSpan()),
+		})
+		for _, expr := range msg.GetRanges() {
+			expr := d.expr(expr)
+			ranges.Ranges().AppendComma(expr, d.comma(expr))
+		}
+		return ranges.AsAny()
+
+	case *compilerpb.Decl_Def:
+		return d.def(decl.Def).AsAny()
+
+	default:
+		d.fail("unknown declaration kind %T", decl)
+		return ast.DeclAny{}
+	}
+}
+
+func (d *decoder) body(msg *compilerpb.Decl_Body) ast.DeclBody {
+	if msg == nil {
+		return ast.DeclBody{}
+	}
+
+	body := d.file.Nodes().NewDeclBody(d.required(msg.GetSpan(), "body"))
+	for _, decl := range msg.GetDecls() {
+		seq.Append(body.Decls(), d.decl(decl))
+	}
+	return body
+}
+
+func (d *decoder) def(msg *compilerpb.Def) ast.DeclDef {
+	args := ast.DeclDefArgs{
+		Name:      d.path(msg.GetName()),
+		Equals:    d.token(msg.GetEqualsSpan()),
+		Value:     d.expr(msg.GetValue()),
+		Options:   d.options(msg.GetOptions()),
+		Body:      d.body(msg.GetBody()),
+		Semicolon: d.token(msg.GetSemicolonSpan()),
+	}
+	if sig := msg.GetSignature(); sig != nil {
+		args.Returns = d.token(sig.GetReturnsSpan())
+	}
+
+	switch msg.GetKind() {
+	case compilerpb.Def_KIND_FIELD, compilerpb.Def_KIND_GROUP, compilerpb.Def_KIND_UNSPECIFIED:
+		args.Type = d.type_(msg.GetType())
+
+	case compilerpb.Def_KIND_ENUM_VALUE:
+		// Enum values have no type.
+
+	default:
+		// The type of a def introduced by a keyword is not encoded, so we
+		// rebuild it from the keyword and the modifiers before it.
+		kw := d.required(msg.GetKeywordSpan(), "definition keyword")
+		args.Type = ast.TypePath{Path: astx.NewPath(d.file, kw, kw)}.AsAny()
+
+		var prefixes []token.Token
+		for tok := d.start(msg.GetSpan()); tok.ID() < kw.ID(); tok = tok.Next() {
+			if tok.IsZero() {
+				d.fail("definition keyword is outside of its definition")
+			}
+			prefixes = append(prefixes, d.claim(tok))
+		}
+		for i := len(prefixes) - 1; i >= 0; i-- {
+			args.Type = d.file.Nodes().NewTypePrefixed(ast.TypePrefixedArgs{
+				Prefix: prefixes[i],
+				Type:   args.Type,
+			}).AsAny()
+		}
+	}
+
+	def := d.file.Nodes().NewDeclDef(args)
+	if msg.GetKind() == compilerpb.Def_KIND_UNSPECIFIED {
+		def.MarkCorrupt()
+	}
+
+	if sig := msg.GetSignature(); sig != nil {
+		d.typeList(def.WithSignature().Inputs(), sig.GetInputSpan(), sig.GetInputs())
+		d.typeList(def.WithSignature().Outputs(), sig.GetOutputSpan(), sig.GetOutputs())
+	}
+
+	return def
+}
+
+// typeList fills in a method's input or output types.
+func (d *decoder) typeList(list ast.TypeList, span *compilerpb.Span, types []*compilerpb.Type) {
+	if parens := d.start(span); !parens.IsZero() && !parens.IsLeaf() && parens.Span().End == int(span.GetEnd()) {
+		list.SetBrackets(d.claim(parens))
+	}
+	for _, ty := range types {
+		ty := d.type_(ty)
+		list.AppendComma(ty, d.comma(ty))
+	}
+}
+
+func (d *decoder) options(msg *compilerpb.Options) ast.CompactOptions {
+	if msg == nil {
+		return ast.CompactOptions{}
+	}
+
+	options := d.file.Nodes().NewCompactOptions(d.required(msg.GetSpan(), "compact options"))
+	for _, entry := range msg.GetEntries() {
+		opt := ast.Option{
+			Path:   d.path(entry.GetPath()),
+			Equals: d.token(entry.GetEqualsSpan()),
+			Value:  d.expr(entry.GetValue()),
+		}
+		options.Entries().AppendComma(opt, d.comma(source.Join(opt.Path, opt.Equals, opt.Value)))
+	}
+	return options
+}
+
+func (d *decoder) expr(msg *compilerpb.Expr) ast.ExprAny {
+	if msg == nil {
+		return ast.ExprAny{}
+	}
+
+	switch expr := msg.GetExpr().(type) {
+	case *compilerpb.Expr_Literal_:
+		tok := d.required(expr.Literal.GetSpan(), "literal")
+		if kind := tok.Kind(); kind != token.Number && kind != token.String {
+			d.fail("span %d:%d is not a literal", expr.Literal.GetSpan().GetStart(), expr.Literal.GetSpan().GetEnd())
+		}
+		return ast.ExprLiteral{File: d.file, Token: tok}.AsAny()
+
+	case *compilerpb.Expr_Path:
+		return ast.ExprPath{Path: d.path(expr.Path)}.AsAny()
+
+	case *compilerpb.Expr_Prefixed_:
+		return d.file.Nodes().NewExprPrefixed(ast.ExprPrefixedArgs{
+			Prefix: d.required(expr.Prefixed.GetPrefixSpan(), "prefix"),
+			Expr:   d.expr(expr.Prefixed.GetExpr()),
+		}).AsAny()
+
+	case *compilerpb.Expr_Range_:
+		return d.file.Nodes().NewExprRange(ast.ExprRangeArgs{
+			Start: d.expr(expr.Range.GetStart()),
+			To:    d.token(expr.Range.GetToSpan()),
+			End:   d.expr(expr.Range.GetEnd()),
+		}).AsAny()
+
+	case *compilerpb.Expr_Array_:
+		msg := expr.Array
+		array := d.file.Nodes().NewExprArray(d.required(msg.GetOpenSpan(), "array brackets"))
+		for i, elem := range msg.GetElements() {
+			array.Elements().AppendComma(d.expr(elem), d.nthComma(msg.GetCommaSpans(), i))
+		}
+		return array.AsAny()
+
+	case *compilerpb.Expr_Dict_:
+		msg := expr.Dict
+		braces := d.required(msg.GetOpenSpan(), "dictionary braces")
+		d.fuse(braces, msg.GetCloseSpan())
+		dict := d.file.Nodes().NewExprDict(braces)
+		for i, entry := range msg.GetEntries() {
+			dict.Elements().AppendComma(d.field(entry), d.nthComma(msg.GetCommaSpans(), i))
+		}
+		return dict.AsAny()
+
+	case *compilerpb.Expr_Field_:
+		return d.field(expr.Field).AsAny()
+
+	default:
+		d.fail("unknown expression kind %T", expr)
+		return ast.ExprAny{}
+	}
+}
+
+func (d *decoder) field(msg *compilerpb.Expr_Field) ast.ExprField {
+	return d.file.Nodes().NewExprField(ast.ExprFieldArgs{
+		Key:   d.expr(msg.GetKey()),
+		Colon: d.token(msg.GetColonSpan()),
+		Value: d.expr(msg.GetValue()),
+	})
+}
+
+// nthComma returns the comma at index n of spans, if there is one.
+func (d *decoder) nthComma(spans []*compilerpb.Span, n int) token.Token {
+	if n >= len(spans) {
+		return token.Zero
+	}
+	return d.token(spans[n])
+}
+
+//nolint:revive // "method type_ should be type" is incorrect because type is a keyword.
+func (d *decoder) type_(msg *compilerpb.Type) ast.TypeAny {
+	if msg == nil {
+		return ast.TypeAny{}
+	}
+
+	switch ty := msg.GetType().(type) {
+	case *compilerpb.Type_Path:
+		return ast.TypePath{Path: d.path(ty.Path)}.AsAny()
+
+	case *compilerpb.Type_Prefixed_:
+		return d.file.Nodes().NewTypePrefixed(ast.TypePrefixedArgs{
+			Prefix: d.required(ty.Prefixed.GetPrefixSpan(), "type prefix"),
+			Type:   d.type_(ty.Prefixed.GetType()),
+		}).AsAny()
+
+	case *compilerpb.Type_Generic_:
+		msg := ty.Generic
+		angles := d.required(msg.GetOpenSpan(), "type arguments")
+		d.fuse(angles, msg.GetCloseSpan())
+		generic := d.file.Nodes().NewTypeGeneric(ast.TypeGenericArgs{
+			Path:          d.path(msg.GetPath()),
+			AngleBrackets: angles,
+		})
+		for i, arg := range msg.GetArgs() {
+			generic.Args().AppendComma(d.type_(arg), d.nthComma(msg.GetCommaSpans(), i))
+		}
+		return generic.AsAny()
+
+	default:
+		d.fail("unknown type kind %T", ty)
+		return ast.TypeAny{}
+	}
+}
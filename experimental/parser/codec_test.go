@@ -0,0 +1,195 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser_test
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/protobuf/proto"
+
+	"github.com/bufbuild/protocompile/experimental/ast/printer"
+	"github.com/bufbuild/protocompile/experimental/internal/astx"
+	"github.com/bufbuild/protocompile/experimental/parser"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/source"
+	compilerpb "github.com/bufbuild/protocompile/internal/gen/buf/compiler/v1alpha1"
+)
+
+func TestCodecRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	err := filepath.WalkDir("testdata/parser", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !strings.HasSuffix(path, ".proto") {
+			return err
+		}
+		text, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		t.Run(path, func(t *testing.T) {
+			t.Parallel()
+			src := source.NewFile(path, string(text))
+			file, _ := parser.Parse(path, src, new(report.Report))
+			want, err := printer.PrintFile(printer.Options{}, file)
+			require.NoError(t, err)
+
+			data, err := parser.Encode(file, parser.EncodeOptions{})
+			require.NoError(t, err)
+			decoded, err := parser.Decode(path, nil, data)
+			require.NoError(t, err)
+			assert.Equal(t, path, decoded.Path())
+			got, err := printer.PrintFile(printer.Options{}, decoded)
+			require.NoError(t, err)
+			assert.Equal(t, want, got)
+
+			again, err := parser.Encode(decoded, parser.EncodeOptions{})
+			require.NoError(t, err)
+			assert.Equal(t, data, again)
+
+			data, err = parser.Encode(file, parser.EncodeOptions{OmitFile: true})
+			require.NoError(t, err)
+			decoded, err = parser.Decode(path, src, data)
+			require.NoError(t, err)
+			again, err = parser.Encode(decoded, parser.EncodeOptions{OmitFile: true})
+			require.NoError(t, err)
+			assert.Equal(t, data, again)
+		})
+		return nil
+	})
+	require.NoError(t, err)
+}
+
+func FuzzDecode(f *testing.F) {
+	for _, text := range []string{
+		`syntax = "proto3"; package foo.bar; import public "x.proto";`,
+		`message Foo { map<string, int32> x = 1 [(a) = 1, b = -2]; reserved 1 to 3, 5; }`,
+		`service S { rpc M(stream A) returns (B) {} } enum E { A = 1 [x = {a: [1, 2]}]; }`,
+	} {
+		src := source.NewFile("foo.proto", text)
+		file, _ := parser.Parse("foo.proto", src, new(report.Report))
+		data, err := parser.Encode(file, parser.EncodeOptions{})
+		require.NoError(f, err)
+		f.Add(data)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		file, err := parser.Decode("foo.proto", nil, data)
+		if err != nil {
+			return
+		}
+		_, err = parser.Encode(file, parser.EncodeOptions{})
+		require.NoError(t, err)
+	})
+}
+
+func TestCodecErrors(t *testing.T) {
+	t.Parallel()
+
+	text := `syntax = "proto3"; message Foo { map<string, int32> x = 1 [(a) = 1, b = 2]; }`
+	src := source.NewFile("foo.proto", text)
+	file, _ := parser.Parse("foo.proto", src, new(report.Report))
+
+	encode := func(options astx.ToProtoOptions, mutate func(*compilerpb.File)) []byte {
+		msg := astx.ToProto(file, options).(*compilerpb.File)
+		if mutate != nil {
+			mutate(msg)
+		}
+		data, err := proto.Marshal(msg)
+		require.NoError(t, err)
+		return data
+	}
+
+	tests := []struct {
+		name   string
+		source *source.File
+		data   []byte
+		err    string
+	}{
+		{
+			name: "no file",
+			data: encode(astx.ToProtoOptions{OmitFile: true}, nil),
+			err:  "does not include its file",
+		},
+		{
+			name:   "different file",
+			source: source.NewFile("foo.proto", strings.ReplaceAll(text, "Foo", "Bar")),
+			data:   encode(astx.ToProtoOptions{}, nil),
+			err:    "was not produced from",
+		},
+		{
+			name: "no spans",
+			data: encode(astx.ToProtoOptions{OmitSpans: true}, nil),
+			err:  "missing span",
+		},
+		{
+			name: "bad span",
+			data: encode(astx.ToProtoOptions{}, func(f *compilerpb.File) {
+				f.GetDecls()[0].GetSyntax().GetKeywordSpan().End++
+			}),
+			err: "does not match any token",
+		},
+		{
+			name: "out of bounds",
+			data: encode(astx.ToProtoOptions{}, func(f *compilerpb.File) {
+				f.GetDecls()[0].GetSyntax().GetSemicolonSpan().End = 1000
+			}),
+			err: "out of bounds",
+		},
+		{
+			name: "wrong kind",
+			data: encode(astx.ToProtoOptions{}, func(f *compilerpb.File) {
+				f.GetDecls()[0].GetSyntax().Kind = compilerpb.Decl_Syntax_KIND_EDITION
+			}),
+			err: "declaration 0 does not match",
+		},
+		{
+			name: "wrong name",
+			data: encode(astx.ToProtoOptions{}, func(f *compilerpb.File) {
+				def := f.GetDecls()[1].GetDef()
+				def.GetName().GetComponents()[0].Component = &compilerpb.Path_Component_Ident{Ident: "Bar"}
+			}),
+			err: "declaration 1 does not match",
+		},
+		{
+			name: "reused token",
+			data: encode(astx.ToProtoOptions{}, func(f *compilerpb.File) {
+				field := f.GetDecls()[1].GetDef().GetBody().GetDecls()[0].GetDef()
+				entries := field.GetOptions().GetEntries()
+				entries[1].Path = entries[0].GetPath()
+			}),
+			err: "is used more than once",
+		},
+		{
+			name:   "not lexed",
+			source: source.NewFile("foo.proto", "\xff"+text[1:]),
+			data:   encode(astx.ToProtoOptions{OmitFile: true}, nil),
+			err:    "out of bounds",
+		},
+	}
+
+	for _, test := range tests {
+		_, err := parser.Decode("foo.proto", test.source, test.data)
+		if assert.Error(t, err, test.name) {
+			assert.Contains(t, err.Error(), test.err, test.name)
+		}
+	}
+}
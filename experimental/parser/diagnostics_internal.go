@@ -20,6 +20,7 @@ import (
 	"github.com/bufbuild/protocompile/experimental/internal/just"
 	"github.com/bufbuild/protocompile/experimental/internal/taxa"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/source"
 	"github.com/bufbuild/protocompile/experimental/token"
 	"github.com/bufbuild/protocompile/internal/ext/iterx"
@@ -40,6 +41,7 @@ func (e errMoreThanOne) Diagnose(d *report.Diagnostic) {
 
 	d.Apply(
 		report.Message("encountered more than one %v", what),
+		report.Tag(rtags.DuplicateDecl),
 		report.Snippetf(e.second, "help: consider removing this"),
 		report.Snippetf(e.first, "first one is here"),
 	)
@@ -57,6 +59,7 @@ type errHasOptions struct {
 func (e errHasOptions) Diagnose(d *report.Diagnostic) {
 	d.Apply(
 		report.Message("%s cannot specify %s", taxa.Classify(e.what), taxa.CompactOptions),
+		report.Tag(rtags.UnexpectedOptions),
 		report.Snippetf(e.what.Options(), "help: remove this"),
 	)
 }
@@ -69,6 +72,7 @@ type errHasSignature struct {
 func (e errHasSignature) Diagnose(d *report.Diagnostic) {
 	d.Apply(
 		report.Message("%s appears to have %s", taxa.Classify(e.what), taxa.Signature),
+		report.Tag(rtags.UnexpectedSignature),
 		report.Snippetf(e.what.Signature(), "help: remove this"),
 	)
 }
@@ -82,6 +86,7 @@ type errBadNest struct {
 
 func (e errBadNest) Diagnose(d *report.Diagnostic) {
 	what := taxa.Classify(e.child)
+	d.Apply(report.Tag(rtags.InvalidNesting))
 	if e.parent.what == taxa.TopLevel {
 		d.Apply(
 			report.Message("unexpected %s at %s", what, e.parent.what),
@@ -124,6 +129,7 @@ type errUnexpectedMod struct {
 func (e errUnexpectedMod) Diagnose(d *report.Diagnostic) {
 	d.Apply(
 		report.Message("unexpected `%s` modifier %s", e.mod.Keyword(), e.where),
+		report.Tag(rtags.UnexpectedModifier),
 		report.Snippet(e.mod),
 	)
 
@@ -23,6 +23,7 @@ import (
 	"github.com/bufbuild/protocompile/experimental/internal/errtoken"
 	"github.com/bufbuild/protocompile/experimental/internal/taxa"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/seq"
 	"github.com/bufbuild/protocompile/internal/ext/iterx"
 	"github.com/bufbuild/protocompile/internal/ext/slicesx"
@@ -50,6 +51,7 @@ func legalizeDecl(p *parser, parent classified, decl ast.DeclAny) {
 		body := decl.AsBody()
 		braces := body.Braces().Span()
 		p.Errorf("unexpected definition body in %v", parent.what).Apply(
+			report.Tag(rtags.InvalidNesting),
 			report.Snippet(decl),
 			report.SuggestEdits(
 				braces,
@@ -125,6 +127,7 @@ func legalizeRange(p *parser, parent classified, decl ast.DeclRange) {
 				break
 			}
 			p.Errorf("cannot use %vs in %v in %v", taxa.Ident, in, taxa.SyntaxMode).Apply(
+				report.Tag(rtags.InvalidReserved),
 				report.Snippet(expr),
 				report.Snippetf(p.syntaxNode, "%v is specified here", taxa.SyntaxMode),
 				report.SuggestEdits(
@@ -156,6 +159,7 @@ func legalizeRange(p *parser, parent classified, decl ast.DeclRange) {
 				names = append(names, expr)
 				if p.syntax.IsEdition() {
 					err := p.Errorf("cannot use %vs in %v in %v", taxa.String, in, taxa.EditionMode).Apply(
+						report.Tag(rtags.InvalidReserved),
 						report.Snippet(expr),
 						report.Snippetf(p.syntaxNode, "%v is specified here", taxa.EditionMode),
 					)
@@ -180,6 +184,7 @@ func legalizeRange(p *parser, parent classified, decl ast.DeclRange) {
 						field = taxa.EnumValue
 					}
 					p.Errorf("reserved %v name is not a valid identifier", field).Apply(
+						report.Tag(rtags.InvalidReserved),
 						report.Snippet(expr),
 					)
 					break
@@ -225,6 +230,7 @@ func legalizeRange(p *parser, parent classified, decl ast.DeclRange) {
 		}
 
 		err := p.Errorf("cannot mix tags and names in %s", taxa.Reserved).Apply(
+			report.Tag(rtags.InvalidReserved),
 			report.Snippetf(least[0], "this %s %s must go in its own %s", parentWhat, leastWhat, taxa.Reserved),
 			report.Snippetf(most[0], "but expected a %s %s because of this", parentWhat, mostWhat),
 		)
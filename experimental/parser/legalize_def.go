@@ -23,6 +23,7 @@ import (
 	"github.com/bufbuild/protocompile/experimental/internal/errtoken"
 	"github.com/bufbuild/protocompile/experimental/internal/taxa"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/source"
 	"github.com/bufbuild/protocompile/experimental/token/keyword"
 )
@@ -76,6 +77,7 @@ func legalizeTypeDefLike(p *parser, what taxa.Noun, def ast.DeclDef) {
 		def.MarkCorrupt()
 		kw := taxa.Noun(def.Keyword())
 		p.Errorf("missing name %v", kw.After()).Apply(
+			report.Tag(rtags.MissingName),
 			report.Snippet(def),
 		)
 
@@ -165,6 +167,7 @@ func legalizeTypeDefLike(p *parser, what taxa.Noun, def ast.DeclDef) {
 		// NOTE: There is currently no way to trip this diagnostic, because
 		// a message with no body is interpreted as a field.
 		p.Errorf("missing body for %v", what).Apply(
+			report.Tag(rtags.MissingBody),
 			report.Snippet(def),
 		)
 	}
@@ -180,6 +183,7 @@ func legalizeFieldLike(p *parser, what taxa.Noun, def ast.DeclDef, parent classi
 	if def.Name().IsZero() {
 		def.MarkCorrupt()
 		p.Errorf("missing name %v", what.In()).Apply(
+			report.Tag(rtags.MissingName),
 			report.Snippet(def),
 		)
 	} else if def.Name().AsIdent().IsZero() {
@@ -196,6 +200,7 @@ func legalizeFieldLike(p *parser, what taxa.Noun, def ast.DeclDef, parent classi
 	}
 	if def.Value().IsZero() {
 		p.Errorf("missing %v in declaration", tag).Apply(
+			report.Tag(rtags.MissingValue),
 			report.Snippet(def),
 			// TODO: We do not currently provide a suggested field number for
 			// cases where that is permitted, such as for non-extension-fields.
@@ -217,6 +222,7 @@ func legalizeFieldLike(p *parser, what taxa.Noun, def ast.DeclDef, parent classi
 	case taxa.Group:
 		if def.Body().IsZero() {
 			p.Errorf("missing body for %v", what).Apply(
+				report.Tag(rtags.MissingBody),
 				report.Snippet(def),
 			)
 		}
@@ -229,17 +235,20 @@ func legalizeFieldLike(p *parser, what taxa.Noun, def ast.DeclDef, parent classi
 		}
 		if !capitalized {
 			p.Errorf("group names must start with an uppercase letter").Apply(
+				report.Tag(rtags.InvalidGroup),
 				report.Snippet(def.Name()),
 			)
 		}
 
 		if p.syntax == syntax.Proto2 {
 			p.Warnf("group syntax is deprecated").Apply(
+				report.Tag(rtags.DeprecatedSyntax),
 				report.Snippet(def.Type().RemovePrefixes()),
 				report.Notef("group syntax is not available in proto3 or editions"),
 			)
 		} else {
 			p.Errorf("group syntax is not supported").Apply(
+				report.Tag(rtags.RemovedInEdition),
 				report.Snippet(def.Type().RemovePrefixes()),
 				report.Notef("group syntax is only available in proto2"),
 			)
@@ -292,6 +301,7 @@ func legalizeMethod(p *parser, def ast.DeclDef) {
 	if def.Name().IsZero() {
 		def.MarkCorrupt()
 		p.Errorf("missing name %v", taxa.Method.In()).Apply(
+			report.Tag(rtags.MissingName),
 			report.Snippet(def),
 		)
 	} else if def.Name().AsIdent().IsZero() {
@@ -316,6 +326,7 @@ func legalizeMethod(p *parser, def ast.DeclDef) {
 	if sig.IsZero() {
 		def.MarkCorrupt()
 		p.Errorf("missing %v in %v", taxa.Signature, taxa.Method).Apply(
+			report.Tag(rtags.MissingSignature),
 			report.Snippet(def),
 		)
 	} else {
@@ -325,6 +336,7 @@ func legalizeMethod(p *parser, def ast.DeclDef) {
 		if sig.Inputs().Span().IsZero() {
 			def.MarkCorrupt()
 			p.Errorf("missing %v in %v", taxa.MethodIns, taxa.Method).Apply(
+				report.Tag(rtags.MissingSignature),
 				report.Snippetf(def.Name(), "expected %s after this", taxa.Noun(keyword.Parens)),
 			)
 		} else {
@@ -348,6 +360,7 @@ func legalizeMethod(p *parser, def ast.DeclDef) {
 			}
 
 			p.Errorf("missing %v in %v", taxa.MethodOuts, taxa.Method).Apply(
+				report.Tag(rtags.MissingSignature),
 				report.Snippetf(after, "expected %s after this", expected),
 			)
 		} else {
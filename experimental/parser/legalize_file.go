@@ -24,6 +24,7 @@ import (
 	"github.com/bufbuild/protocompile/experimental/internal/errtoken"
 	"github.com/bufbuild/protocompile/experimental/internal/taxa"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/seq"
 	"github.com/bufbuild/protocompile/experimental/source"
 	"github.com/bufbuild/protocompile/experimental/token"
@@ -52,6 +53,7 @@ func legalizeFile(p *parser, file *ast.File) {
 
 		if p.syntaxNode.IsZero() { // Don't complain if we found a bad syntax node.
 			p.Warnf("missing %s", taxa.Syntax).Apply(
+				report.Tag(rtags.MissingSyntax),
 				report.InFile(p.File().Stream().Path()),
 				report.Notef("this defaults to \"proto2\"; not specifying this "+
 					"explicitly is discouraged"),
@@ -77,6 +79,7 @@ func legalizeFile(p *parser, file *ast.File) {
 
 	if pkg.IsZero() {
 		p.Warnf("missing %s", taxa.Package).Apply(
+			report.Tag(rtags.MissingPackage),
 			report.InFile(p.File().Stream().Path()),
 			report.Notef(
 				"not explicitly specifying a package places the file in the "+
@@ -105,6 +108,7 @@ func legalizeSyntax(p *parser, parent classified, idx int, first *ast.DeclSyntax
 	switch {
 	case !first.IsZero():
 		p.Errorf("unexpected %s", in).Apply(
+			report.Tag(rtags.DuplicateDecl),
 			report.Snippet(decl),
 			report.Snippetf(*first, "previous declaration is here"),
 			report.SuggestEdits(
@@ -117,6 +121,7 @@ func legalizeSyntax(p *parser, parent classified, idx int, first *ast.DeclSyntax
 		return
 	case idx > 0:
 		p.Errorf("unexpected %s", in).Apply(
+			report.Tag(rtags.MisplacedDecl),
 			report.Snippet(decl),
 			report.Snippetf(file.Decls().At(idx-1), "previous declaration is here"),
 			// TODO: Add a suggestion to move this up.
@@ -174,6 +179,7 @@ func legalizeSyntax(p *parser, parent classified, idx int, first *ast.DeclSyntax
 		}
 
 		p.Errorf("unrecognized %s value", in).Apply(
+			report.Tag(rtags.InvalidSyntax),
 			report.Snippet(expr),
 			report.Notef("treating the file as %s instead", fallback),
 			report.Helpf("permitted values: %s", iterx.Join(values, ", ")),
@@ -181,6 +187,7 @@ func legalizeSyntax(p *parser, parent classified, idx int, first *ast.DeclSyntax
 
 	case !value.IsSupported():
 		p.Errorf("sorry, Edition %s is not fully implemented", value).Apply(
+			report.Tag(rtags.UnsupportedEdition),
 			report.Snippet(expr),
 			report.Helpf("Edition %s will be implemented in a future release", value),
 		)
@@ -189,6 +196,7 @@ func legalizeSyntax(p *parser, parent classified, idx int, first *ast.DeclSyntax
 	if value.IsValid() {
 		if value.IsEdition() && in == taxa.Syntax {
 			p.Errorf("editions must use the `edition` keyword").Apply(
+				report.Tag(rtags.InvalidSyntax),
 				report.Snippet(decl.KeywordToken()),
 				report.SuggestEdits(decl.KeywordToken(), "replace with `edition`", report.Edit{
 					Start: 0, End: decl.KeywordToken().Span().Len(),
@@ -200,6 +208,7 @@ func legalizeSyntax(p *parser, parent classified, idx int, first *ast.DeclSyntax
 		if !value.IsEdition() && in == taxa.Edition {
 			lit := expr.Span().Text()
 			p.Errorf("%s use the `syntax` keyword", lit).Apply(
+				report.Tag(rtags.InvalidSyntax),
 				report.Snippet(decl.KeywordToken()),
 				report.SuggestEdits(decl.KeywordToken(), "replace with `syntax`", report.Edit{
 					Start: 0, End: decl.KeywordToken().Span().Len(),
@@ -212,6 +221,7 @@ func legalizeSyntax(p *parser, parent classified, idx int, first *ast.DeclSyntax
 		if lit.Kind() != token.String {
 			span := expr.Span()
 			p.Errorf("the value of a %s must be a string literal", in).Apply(
+				report.Tag(rtags.InvalidSyntax),
 				report.Snippet(span),
 				report.SuggestEdits(
 					span,
@@ -245,6 +255,7 @@ func legalizePackage(p *parser, parent classified, idx int, first *ast.DeclPacka
 	switch {
 	case !first.IsZero():
 		p.Errorf("unexpected %s", taxa.Package).Apply(
+			report.Tag(rtags.DuplicateDecl),
 			report.Snippet(decl),
 			report.Snippetf(*first, "previous declaration is here"),
 			report.SuggestEdits(
@@ -258,6 +269,7 @@ func legalizePackage(p *parser, parent classified, idx int, first *ast.DeclPacka
 	case idx > 0:
 		if idx > 1 || file.Decls().At(0).Kind() != ast.DeclKindSyntax {
 			p.Warnf("the %s should be placed at the top of the file", taxa.Package).Apply(
+				report.Tag(rtags.MisplacedDecl),
 				report.Snippet(decl),
 				report.Snippetf(file.Decls().At(idx-1), "previous declaration is here"),
 				// TODO: Add a suggestion to move this up.
@@ -279,6 +291,7 @@ func legalizePackage(p *parser, parent classified, idx int, first *ast.DeclPacka
 
 	if decl.Path().IsZero() {
 		p.Errorf("missing path in %s", taxa.Package).Apply(
+			report.Tag(rtags.MissingPath),
 			report.Snippet(decl),
 			report.Helpf(
 				"to place a file in the unnamed package, omit the %s; however, "+
@@ -348,6 +361,7 @@ func legalizeImport(p *parser, parent classified, decl ast.DeclImport) {
 		}
 
 		p.Errorf("missing import path in %s", in).Apply(
+			report.Tag(rtags.MissingPath),
 			report.Snippet(decl),
 		)
 		return
@@ -365,6 +379,7 @@ func legalizeImport(p *parser, parent classified, decl ast.DeclImport) {
 	for i, mod := range seq.All(decl.ModifierTokens()) {
 		if i > 0 {
 			p.Errorf("unexpected `%s` modifier in %s", mod.Text(), in).Apply(
+				report.Tag(rtags.UnexpectedModifier),
 				report.Snippet(mod),
 				report.Snippetf(source.Join(
 					decl.KeywordToken(),
@@ -429,6 +444,7 @@ func legalizeImport(p *parser, parent classified, decl ast.DeclImport) {
 
 	if !isOption && !p.importOptionNode.IsZero() {
 		p.Errorf("%s after `import option`", taxa.Import).Apply(
+			report.Tag(rtags.MisplacedDecl),
 			report.Snippet(decl),
 			report.Snippetf(p.importOptionNode, "previous `import option` here"),
 			report.Helpf("`import option`s must be the last imports in a file"),
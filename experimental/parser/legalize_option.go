@@ -22,6 +22,7 @@ import (
 	"github.com/bufbuild/protocompile/experimental/internal/errtoken"
 	"github.com/bufbuild/protocompile/experimental/internal/taxa"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/seq"
 	"github.com/bufbuild/protocompile/experimental/source"
 	"github.com/bufbuild/protocompile/experimental/token"
@@ -38,6 +39,7 @@ func legalizeCompactOptions(p *parser, opts ast.CompactOptions) {
 	entries := opts.Entries()
 	if entries.Len() == 0 {
 		p.Errorf("%s cannot be empty", taxa.CompactOptions).Apply(
+			report.Tag(rtags.SyntaxError),
 			report.Snippetf(opts, "help: remove this"),
 		)
 		return
@@ -57,6 +59,7 @@ func legalizeCompactOptions(p *parser, opts ast.CompactOptions) {
 func legalizeOptionEntry(p *parser, opt ast.Option, decl source.Span) {
 	if opt.Path.IsZero() {
 		p.Errorf("missing %v path", taxa.Option).Apply(
+			report.Tag(rtags.MissingPath),
 			report.Snippet(decl),
 		)
 
@@ -72,6 +75,7 @@ func legalizeOptionEntry(p *parser, opt ast.Option, decl source.Span) {
 
 	if opt.Value.IsZero() {
 		p.Errorf("missing %v", taxa.OptionValue).Apply(
+			report.Tag(rtags.MissingValue),
 			report.Snippet(decl),
 		)
 	} else {
@@ -140,6 +144,7 @@ func legalizeValue(p *parser, decl source.Span, parent ast.ExprAny, value ast.Ex
 
 		case parent.Kind() == ast.ExprKindArray:
 			p.Errorf("nested %ss are not allowed", taxa.Array).Apply(
+				report.Tag(rtags.InvalidOptionValue),
 				report.Snippetf(value, "cannot nest this %s...", taxa.Array),
 				report.Snippetf(parent, "...within this %s", taxa.Array),
 			)
@@ -151,6 +156,7 @@ func legalizeValue(p *parser, decl source.Span, parent ast.ExprAny, value ast.Ex
 
 			if parent.Kind() == ast.ExprKindField && array.Len() == 0 {
 				p.Warnf("empty %s has no effect", taxa.Array).Apply(
+					report.Tag(rtags.Redundant),
 					report.Snippet(value),
 					report.SuggestEdits(
 						parent,
@@ -169,9 +175,13 @@ func legalizeValue(p *parser, decl source.Span, parent ast.ExprAny, value ast.Ex
 		if dict.Braces().Keyword() == keyword.Angles {
 			var err *report.Diagnostic
 			if parent.IsZero() {
-				err = p.Errorf("cannot use `<...>` for %s here", taxa.Dict)
+				err = p.Errorf("cannot use `<...>` for %s here", taxa.Dict).Apply(
+					report.Tag(rtags.InvalidOptionValue),
+				)
 			} else {
-				err = p.Warnf("using `<...>` for %s is not recommended", taxa.Dict)
+				err = p.Warnf("using `<...>` for %s is not recommended", taxa.Dict).Apply(
+					report.Tag(rtags.NonCanonicalLiteral),
+				)
 			}
 
 			err.Apply(
@@ -198,6 +208,7 @@ func legalizeValue(p *parser, decl source.Span, parent ast.ExprAny, value ast.Ex
 				if !first.AsExtension().IsZero() {
 					// TODO: move this into ir/lower_eval.go
 					p.Errorf("cannot name extension field using `(...)` in %s", taxa.Dict).Apply(
+						report.Tag(rtags.InvalidOptionValue),
 						report.Snippetf(path, "expected this to be wrapped in `[...]` instead"),
 						report.SuggestEdits(
 							path, "replace the `(...)` with `[...]`",
@@ -311,7 +322,9 @@ func legalizeLiteral(p *parser, value ast.ExprLiteral) {
 		// Diagnose against number literals we currently accept but which are not
 		// part of Protobuf.
 		if !validBase {
-			d := p.Errorf("unsupported base for %s", what)
+			d := p.Errorf("unsupported base for %s", what).Apply(
+				report.Tag(rtags.InvalidLiteral),
+			)
 			if what == taxa.Int {
 				switch base {
 				case 2:
@@ -354,6 +367,7 @@ func legalizeLiteral(p *parser, value ast.ExprLiteral) {
 
 		if suffix := n.Suffix(); suffix.Text() != "" {
 			p.Errorf("unrecognized suffix for %s", what).Apply(
+				report.Tag(rtags.InvalidLiteral),
 				report.SuggestEdits(suffix, "delete it", report.Edit{
 					Start: 0,
 					End:   len(suffix.Text()),
@@ -364,6 +378,7 @@ func legalizeLiteral(p *parser, value ast.ExprLiteral) {
 
 		if n.HasSeparators() {
 			p.Errorf("%s contains underscores", what).Apply(
+				report.Tag(rtags.InvalidLiteral),
 				report.SuggestEdits(value, "remove these underscores", report.Edit{
 					Start:   0,
 					End:     len(value.Text()),
@@ -378,6 +393,7 @@ func legalizeLiteral(p *parser, value ast.ExprLiteral) {
 		s := value.AsString()
 		if sigil := s.Prefix(); sigil.Text() != "" {
 			p.Errorf("unrecognized prefix for %s", taxa.String).Apply(
+				report.Tag(rtags.InvalidLiteral),
 				report.SuggestEdits(sigil, "delete it", report.Edit{
 					Start: 0,
 					End:   len(sigil.Text()),
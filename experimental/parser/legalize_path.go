@@ -18,6 +18,7 @@ import (
 	"github.com/bufbuild/protocompile/experimental/ast"
 	"github.com/bufbuild/protocompile/experimental/internal/taxa"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/token"
 	"github.com/bufbuild/protocompile/experimental/token/keyword"
 )
@@ -55,6 +56,7 @@ func legalizePath(p *parser, where taxa.Place, path ast.Path, opts pathOptions)
 
 		if pc.IsFirst() && !opts.AllowAbsolute && pc.Separator().Text() == "." {
 			p.Errorf("unexpected absolute path %s", where).Apply(
+				report.Tag(rtags.InvalidPath),
 				report.Snippetf(path, "expected a path without a leading `%s`", pc.Separator().Text()),
 				report.SuggestEdits(path, "remove the leading `.`", report.Edit{Start: 0, End: 1}),
 			)
@@ -65,12 +67,14 @@ func legalizePath(p *parser, where taxa.Place, path ast.Path, opts pathOptions)
 		if pc.Separator().Keyword() == keyword.Div {
 			if !opts.AllowSlash {
 				p.Errorf("unexpected `/` in path %s", where).Apply(
+					report.Tag(rtags.InvalidPath),
 					report.Snippetf(pc.Separator(), "help: replace this with a `.`"),
 				)
 				ok = false
 				continue
 			} else if !slash.IsZero() {
 				p.Errorf("type URL can only contain a single `/`").Apply(
+					report.Tag(rtags.InvalidPath),
 					report.Snippet(pc.Separator()),
 					report.Snippetf(slash, "first one is here"),
 				)
@@ -91,6 +95,7 @@ func legalizePath(p *parser, where taxa.Place, path ast.Path, opts pathOptions)
 				}
 			} else {
 				p.Errorf("unexpected nested extension path %s", where).Apply(
+					report.Tag(rtags.InvalidPath),
 					// Use Name() here so we get the outer parens of the extension.
 					report.Snippet(pc.Name()),
 				)
@@ -103,11 +108,13 @@ func legalizePath(p *parser, where taxa.Place, path ast.Path, opts pathOptions)
 	if ok {
 		if opts.MaxBytes > 0 && bytes > opts.MaxBytes {
 			p.Errorf("path %s is too large", where).Apply(
+				report.Tag(rtags.InvalidPath),
 				report.Snippet(path),
 				report.Notef("Protobuf imposes a limit of %v bytes here", opts.MaxBytes),
 			)
 		} else if opts.MaxComponents > 0 && components > opts.MaxComponents {
 			p.Errorf("path %s is too large", where).Apply(
+				report.Tag(rtags.InvalidPath),
 				report.Snippet(path),
 				report.Notef("Protobuf imposes a limit of %v components here", opts.MaxComponents),
 			)
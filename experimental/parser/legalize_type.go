@@ -22,6 +22,7 @@ import (
 	"github.com/bufbuild/protocompile/experimental/internal/just"
 	"github.com/bufbuild/protocompile/experimental/internal/taxa"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/token/keyword"
 )
 
@@ -29,6 +30,7 @@ import (
 func legalizeMethodParams(p *parser, list ast.TypeList, what taxa.Noun) {
 	if list.Len() != 1 {
 		p.Errorf("expected exactly one type in %s, got %d", what, list.Len()).Apply(
+			report.Tag(rtags.SyntaxError),
 			report.Snippet(list),
 		)
 		return
@@ -118,6 +120,7 @@ func legalizeFieldType(p *parser, what taxa.Noun, ty ast.TypeAny, topLevel bool,
 		ty := ty.AsPrefixed()
 		if !mod.IsZero() {
 			p.Errorf("multiple modifiers on %v type", taxa.Field).Apply(
+				report.Tag(rtags.UnexpectedModifier),
 				report.Snippet(ty.PrefixToken()),
 				report.Snippetf(mod.PrefixToken(), "previous one is here"),
 				just.Justify(p.File().Stream(), ty.PrefixToken().Span(), "delete it", just.Edit{
@@ -155,6 +158,7 @@ func legalizeFieldType(p *parser, what taxa.Noun, ty ast.TypeAny, topLevel bool,
 						// TODO: This appears verbatim in lower_validate. Move this check
 						// into IR lowering?
 						p.Warnf("required fields are deprecated").Apply(
+							report.Tag(rtags.DeprecatedSyntax),
 							report.Snippet(ty.PrefixToken()),
 							report.Helpf(
 								"do not attempt to change this to `optional` if the field is "+
@@ -231,10 +235,12 @@ func legalizeFieldType(p *parser, what taxa.Noun, ty ast.TypeAny, topLevel bool,
 		switch {
 		case ty.Path().AsPredeclared() != predeclared.Map:
 			p.Errorf("generic types other than `map` are not supported").Apply(
+				report.Tag(rtags.InvalidMap),
 				report.Snippet(ty.Path()),
 			)
 		case !oneof.IsZero():
 			p.Errorf("map fields are not allowed inside of a %s", taxa.Oneof).Apply(
+				report.Tag(rtags.InvalidMap),
 				report.Snippet(ty),
 				report.Helpf(
 					"to emulate a map field in a %s, fine a local message type with a single map field",
@@ -243,6 +249,7 @@ func legalizeFieldType(p *parser, what taxa.Noun, ty ast.TypeAny, topLevel bool,
 
 		case ty.Args().Len() != 2:
 			p.Errorf("expected exactly two type arguments, got %d", ty.Args().Len()).Apply(
+				report.Tag(rtags.InvalidMap),
 				report.Snippet(ty.Args()),
 			)
 		default:
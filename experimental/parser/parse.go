@@ -77,33 +77,45 @@ func Parse(path string, source *source.File, r *report.Report) (file *ast.File,
 	prior := len(r.Diagnostics)
 
 	r.SaveOptions(func() {
-		if path == "google/protobuf/descriptor.proto" {
-			// descriptor.proto contains required fields, which we warn against.
-			// However, that would cause literally every project ever to have
-			// warnings, and in general, any warnings we add should not ding
-			// the worst WKT file of them all.
-			r.SuppressWarnings = true
-		}
+		setOptions(path, r)
 
+		info := &reparseInfo{prior: len(r.Diagnostics)}
 		file = ast.New(path, lex.Lex(source, r))
-		parse(file, r)
+		parse(file, r, info)
 
 		defer file.Stream().Freeze()
 	})
 
-	ok = true
+	return file, succeeded(r, prior)
+}
+
+// setOptions configures r for parsing the file at path.
+func setOptions(path string, r *report.Report) {
+	if path == "google/protobuf/descriptor.proto" {
+		// descriptor.proto contains required fields, which we warn against.
+		// However, that would cause literally every project ever to have
+		// warnings, and in general, any warnings we add should not ding
+		// the worst WKT file of them all.
+		r.SuppressWarnings = true
+	}
+}
+
+// succeeded returns whether no errors were added to r after the first prior
+// diagnostics.
+func succeeded(r *report.Report, prior int) bool {
 	for _, d := range r.Diagnostics[prior:] {
 		if d.Level() >= report.Error {
-			ok = false
-			break
+			return false
 		}
 	}
-
-	return file, ok
+	return true
 }
 
 // parse implements the core parser loop.
-func parse(file *ast.File, errs *report.Report) {
+//
+// If info is not nil, parse records the information [Reparse] needs to reuse
+// this parse in it.
+func parse(file *ast.File, errs *report.Report, info *reparseInfo) {
 	p := &parser{
 		Nodes:  file.Nodes(),
 		Report: errs,
@@ -112,6 +124,9 @@ func parse(file *ast.File, errs *report.Report) {
 	defer p.CatchICE(false, nil)
 
 	c := file.Stream().Cursor()
+	if info != nil {
+		info.start = file.Nodes().Watermark()
+	}
 
 	var mark token.CursorMark
 	for !c.Done() {
@@ -119,11 +134,20 @@ func parse(file *ast.File, errs *report.Report) {
 		node := parseDecl(p, c, taxa.TopLevel)
 		if !node.IsZero() {
 			seq.Append(file.Decls(), node)
+			info.record(p, c)
+		} else {
+			info = nil
 		}
 	}
 
+	clean := info != nil && len(errs.Diagnostics) == info.prior
+
 	p.parseComplete = true
 	legalizeFile(p, file)
+
+	if clean {
+		info.save(file)
+	}
 }
 
 // ensureProgress is used to make sure that the parser makes progress on each
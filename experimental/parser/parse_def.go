@@ -20,6 +20,7 @@ import (
 	"github.com/bufbuild/protocompile/experimental/internal/just"
 	"github.com/bufbuild/protocompile/experimental/internal/taxa"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/seq"
 	"github.com/bufbuild/protocompile/experimental/source"
 	"github.com/bufbuild/protocompile/experimental/token"
@@ -152,6 +153,7 @@ func (p *defParser) parse() ast.DeclDef {
 	} else if !p.outputTy.IsZero() {
 		span := p.outputTy.Span()
 		p.Errorf("missing `(...)` around method return type").Apply(
+			report.Tag(rtags.SyntaxError),
 			report.Snippet(span),
 			report.SuggestEdits(
 				span,
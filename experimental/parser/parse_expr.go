@@ -20,6 +20,7 @@ import (
 	"github.com/bufbuild/protocompile/experimental/internal/just"
 	"github.com/bufbuild/protocompile/experimental/internal/taxa"
 	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
 	"github.com/bufbuild/protocompile/experimental/token"
 	"github.com/bufbuild/protocompile/experimental/token/keyword"
 	"github.com/bufbuild/protocompile/internal/ext/slicesx"
@@ -66,6 +67,7 @@ func parseExprInfix(p *parser, c *token.Cursor, where taxa.Place, lhs ast.ExprAn
 			switch next.Keyword() {
 			case keyword.Assign: // Allow equals signs, which are usually a mistake.
 				p.Errorf("unexpected `=` in expression").Apply(
+					report.Tag(rtags.SyntaxError),
 					report.Snippet(next),
 					just.Justify(p.File().Stream(), next.Span(), "replace this with an `:`", just.Edit{
 						Edit: report.Edit{Start: 0, End: 1, Replace: ":"},
@@ -59,6 +59,14 @@ func TestParse(t *testing.T) {
 			t.Fail()
 		}
 
+		// Every diagnostic must be tagged, so that it can be targeted by a
+		// report.Policy.
+		for _, d := range errs.Diagnostics {
+			if d.Level() != report.ICE && d.Tag() == "" {
+				t.Errorf("untagged diagnostic: %q", d.Message())
+			}
+		}
+
 		proto := astx.ToProto(file, astx.ToProtoOptions{
 			OmitSpans: !strings.Contains(text, preserveSpans),
 			OmitFile:  true,
@@ -0,0 +1,246 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"math"
+	"runtime"
+	"slices"
+	"sync"
+	"weak"
+
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/id"
+	"github.com/bufbuild/protocompile/experimental/internal/taxa"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/source"
+	"github.com/bufbuild/protocompile/experimental/token"
+	"github.com/bufbuild/protocompile/experimental/token/keyword"
+)
+
+// Reparse is like [Parse], but reuses as much of a previous parse as it can.
+//
+// prev must be the result of a call to Parse or Reparse, and edit is an edit
+// to its text, with offsets relative to the start of the file. Returns the
+// parsed result of the edited text, which is identical to what Parse would
+// produce for it.
+//
+// Reparse relexes only the innermost token tree that contains the edit, and
+// reparses only the top-level declaration that contains that tree; the nodes
+// for all other declarations are copied from prev. If this is not possible,
+// for example because prev had syntax errors, the edit is not within a pair of
+// brackets, or the edit changes the extent of the declaration, it falls back
+// to parsing the whole file.
+//
+// prev must not have been modified since it was parsed.
+func Reparse(prev *ast.File, edit report.Edit, r *report.Report) (file *ast.File, ok bool) {
+	prior := len(r.Diagnostics)
+
+	text := prev.Stream().Text()
+	if edit.Start < 0 || edit.Start > edit.End || edit.End > len(text) {
+		panic("protocompile/parser: Reparse called with out-of-bounds edit")
+	}
+	src := source.NewFile(prev.Stream().Path(), text[:edit.Start]+edit.Replace+text[edit.End:])
+
+	r.SaveOptions(func() {
+		setOptions(prev.Path(), r)
+		file = reparse(prev, src, edit, r)
+	})
+	if file == nil {
+		return Parse(prev.Path(), src, r)
+	}
+
+	return file, succeeded(r, prior)
+}
+
+// reparse implements [Reparse]. Returns nil if prev cannot be reused.
+func reparse(prev *ast.File, src *source.File, edit report.Edit, r *report.Report) *ast.File {
+	info := loadReparseInfo(prev)
+	if info == nil {
+		return nil
+	}
+
+	stream, tree, ok := lex.Relex(prev.Stream(), src, edit)
+	if !ok {
+		return nil
+	}
+	_, end := tree.StartEnd()
+	_, newEnd := id.Wrap(stream, tree.ID()).StartEnd()
+	delta := newEnd.ID() - end.ID()
+
+	// Find the top-level declaration that contains tree.
+	i, _ := slices.BinarySearchFunc(info.decls, tree.ID(), func(d parsedDecl, tok token.ID) int {
+		if d.next <= tok {
+			return -1
+		}
+		return 1
+	})
+	if i == len(info.decls) {
+		return nil
+	}
+
+	args := ast.SpliceArgs{
+		Start:  i,
+		End:    i + 1,
+		Before: info.start,
+		After:  info.decls[i].mark,
+	}
+	start := token.ID(1)
+	if i > 0 {
+		args.Before = info.decls[i-1].mark
+		start = info.decls[i-1].next
+	}
+	want := info.decls[i].next
+	if want != math.MaxInt32 {
+		want += delta
+	}
+
+	// The parser fuses angle brackets itself, so we need to undo that for the
+	// tokens we are about to reparse.
+	last, _ := stream.Around(len(src.Text()))
+	for t := start; t < want && t <= last.ID(); t++ {
+		tok := id.Wrap(stream, t)
+		if open, _ := tok.StartEnd(); open == tok && !tok.IsLeaf() && tok.Keyword() == keyword.Angles {
+			token.Unfuse(tok)
+		}
+	}
+
+	// Reparse the declaration into a scratch report: if it produces any
+	// diagnostics, we cannot tell which of the other declarations' diagnostics
+	// it would have been ordered among, so we fall back to a full parse.
+	scratch := new(report.Report)
+	next := &reparseInfo{decls: slices.Clone(info.decls[:i])}
+	var mid ast.Watermark
+	args.Parse = func(file *ast.File) (decls []ast.DeclAny) {
+		p := &parser{
+			Nodes:  file.Nodes(),
+			Report: scratch,
+		}
+		defer p.CatchICE(false, nil)
+
+		c := token.NewCursorAt(id.Wrap(stream, start))
+		var mark token.CursorMark
+		for !c.Done() && position(c) < want {
+			ensureProgress(c, &mark)
+			node := parseDecl(p, c, taxa.TopLevel)
+			if node.IsZero() || position(c) > want {
+				return nil
+			}
+			decls = append(decls, node)
+			next.record(p, c)
+		}
+		if position(c) != want {
+			return nil
+		}
+
+		mid = file.Nodes().Watermark()
+		return decls
+	}
+
+	file := prev.Splice(stream, args)
+	if mid == (ast.Watermark{}) || len(scratch.Diagnostics) > 0 {
+		return nil
+	}
+
+	next.start = info.start
+	for _, d := range info.decls[i+1:] {
+		if d.next != math.MaxInt32 {
+			d.next += delta
+		}
+		d.mark = d.mark.Rebase(args.After, mid)
+		next.decls = append(next.decls, d)
+	}
+
+	p := &parser{
+		Nodes:         file.Nodes(),
+		Report:        r,
+		parseComplete: true,
+	}
+	func() {
+		defer p.CatchICE(false, nil)
+		legalizeFile(p, file)
+		next.save(file)
+	}()
+
+	file.Stream().Freeze()
+	return file
+}
+
+// reparseInfo is information about a parse that [Reparse] uses to reuse it.
+type reparseInfo struct {
+	prior int // The number of diagnostics prior to lexing.
+	start ast.Watermark
+	decls []parsedDecl
+	total ast.Watermark // Used to detect modification of the file.
+}
+
+// parsedDecl is information about a top-level declaration in a parse.
+type parsedDecl struct {
+	// The token after the last token consumed when parsing this declaration,
+	// or math.MaxInt32 if it was the last token in the file.
+	next token.ID
+	// The watermark after parsing this declaration.
+	mark ast.Watermark
+}
+
+// reparseInfos maps each file with a reusable parse to its reparseInfo.
+var reparseInfos sync.Map // [weak.Pointer[ast.File], *reparseInfo]
+
+// record records the top-level declaration that was just parsed. Does nothing
+// if info is nil.
+func (info *reparseInfo) record(p *parser, c *token.Cursor) {
+	if info == nil {
+		return
+	}
+	info.decls = append(info.decls, parsedDecl{
+		next: position(c),
+		mark: p.Watermark(),
+	})
+}
+
+// save makes info available to calls to [Reparse] with file.
+func (info *reparseInfo) save(file *ast.File) {
+	info.total = file.Nodes().Watermark()
+
+	key := weak.Make(file)
+	reparseInfos.Store(key, info)
+	runtime.AddCleanup(file, func(key weak.Pointer[ast.File]) {
+		reparseInfos.Delete(key)
+	}, key)
+}
+
+// loadReparseInfo returns the reparseInfo saved for file, if there is one and
+// file has not been modified since.
+func loadReparseInfo(file *ast.File) *reparseInfo {
+	v, ok := reparseInfos.Load(weak.Make(file))
+	if !ok {
+		return nil
+	}
+	info := v.(*reparseInfo) //nolint:errcheck // Only ever stores this type.
+	if info.total != file.Nodes().Watermark() {
+		return nil
+	}
+	return info
+}
+
+// position returns the ID of the next token c will yield, including skippable
+// tokens, or math.MaxInt32 if there is none.
+func position(c *token.Cursor) token.ID {
+	tok := c.PeekSkippable()
+	if tok.IsZero() {
+		return math.MaxInt32
+	}
+	return tok.ID()
+}
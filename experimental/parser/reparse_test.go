@@ -0,0 +1,141 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parser
+
+import (
+	"io/fs"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bufbuild/protocompile/experimental/ast"
+	"github.com/bufbuild/protocompile/experimental/internal/astx"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/source"
+	"github.com/bufbuild/protocompile/internal/prototest"
+	"github.com/bufbuild/protocompile/wellknownimports"
+)
+
+const reparseTestFile = `syntax = "proto3";
+
+package test.reparse;
+
+import "google/protobuf/descriptor.proto";
+
+option (foo) = { a: 1, b: < c: [1, 2, 3] > };
+
+message Foo {
+  // A comment.
+  map<string, Bar> bars = 1 [(x) = { y: "z" }];
+  repeated int32 nums = 2;
+  oneof o {
+    string s = 3;
+    bytes b = 4;
+  }
+  message Bar {
+    reserved 1 to 5, 10;
+    optional Foo foo = 1;
+  }
+}
+
+enum E {
+  E_ZERO = 0;
+  E_ONE = 1 [deprecated = true];
+}
+
+service S {
+  rpc Get(Foo) returns (stream Foo.Bar) { option idempotency_level = NO_SIDE_EFFECTS; }
+}
+
+extend google.protobuf.MessageOptions { Foo foo = 1000; }
+`
+
+func TestReparse(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{"test.proto": reparseTestFile}
+	for _, path := range []string{
+		"google/protobuf/api.proto",
+		"google/protobuf/struct.proto",
+		"google/protobuf/type.proto",
+	} {
+		text, err := fs.ReadFile(wellknownimports.FS(), path)
+		require.NoError(t, err)
+		files[path] = string(text)
+	}
+
+	// Snippets of Protobuf to splice into files. These are chosen to produce a
+	// mix of valid and invalid edits.
+	snippets := []string{
+		"", " ", "\n", "x", "1", "foo_bar", ";", ",", "{", "}", "(", ")", "[", "]",
+		"<", ">", "\"", "//", "/*", "*/", "= 5", "int32 x = 1;", "message M {}",
+		"map<string, int32> m = 7;", "[deprecated = true]", "option (x) = { y: <z: 1> };",
+		"reserved 1 to max;", "\"foo\" \"bar\"",
+	}
+
+	for path, text := range files {
+		t.Run(path, func(t *testing.T) {
+			t.Parallel()
+			rng := rand.New(rand.NewPCG(1, uint64(len(text))))
+
+			var reused int
+			file, _ := Parse(path, source.NewFile(path, text), new(report.Report))
+			for range 100 {
+				text := file.Stream().Text()
+				edit := report.Edit{Start: rng.IntN(len(text) + 1)}
+				edit.End = min(len(text), edit.Start+rng.IntN(8))
+				edit.Replace = snippets[rng.IntN(len(snippets))]
+
+				edited := text[:edit.Start] + edit.Replace + text[edit.End:]
+				r1, r2 := new(report.Report), new(report.Report)
+				want, wantOK := Parse(path, source.NewFile(path, edited), r1)
+				if loadReparseInfo(file) != nil && reparse(file, source.NewFile(path, edited), edit, new(report.Report)) != nil {
+					reused++
+				}
+				got, gotOK := Reparse(file, edit, r2)
+
+				require.Equal(t, edited, got.Stream().Text())
+				assert.Equal(t, wantOK, gotOK)
+				assert.Equal(t, render(r1), render(r2), "edit: %#v", edit)
+				assert.Equal(t, toYAML(want), toYAML(got), "edit: %#v", edit)
+				if t.Failed() {
+					t.Logf("edited text:\n%s", edited)
+					return
+				}
+
+				// Keep going from whichever file still parses cleanly, so that
+				// we exercise reparsing the result of a reparse.
+				if loadReparseInfo(got) != nil || loadReparseInfo(file) == nil {
+					file = got
+				}
+			}
+			t.Logf("reparsed %d edits incrementally", reused)
+			if path == "test.proto" {
+				assert.Positive(t, reused, "no edits were reparsed incrementally")
+			}
+		})
+	}
+}
+
+func render(r *report.Report) string {
+	text, _, _ := report.Renderer{}.RenderString(r)
+	return text
+}
+
+func toYAML(file *ast.File) string {
+	return prototest.ToYAML(astx.ToProto(file, astx.ToProtoOptions{}), prototest.ToYAMLOptions{})
+}
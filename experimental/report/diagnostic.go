@@ -57,6 +57,8 @@ type Diagnostic struct {
 	// is used for errors like "file too big" that cannot be given a snippet.
 	inFile string
 
+	flags diagnosticFlags
+
 	// A list of annotated source code spans in the diagnostic.
 	snippets           []snippet
 	notes, help, debug []string
@@ -285,6 +287,14 @@ func Debugf(format string, args ...any) DiagnosticOption {
 // diagnostic snippets before and after it into separate windows.
 var PageBreak pageBreak
 
+// Supersede is a DiagnosticOption that marks a diagnostic as replacing every
+// diagnostic from an earlier stage (see [Options].Stage) with the same primary
+// span and tag, when the report is canonicalized.
+//
+// This allows a later stage, which knows more, to refine a diagnostic emitted
+// by an earlier one.
+var Supersede supersede
+
 // snippet is an annotated source code snippet within a [Diagnostic].
 //
 // Snippets will render as annotated source code spans that show the context
@@ -345,6 +355,19 @@ type help lazySprintf
 type debug lazySprintf
 
 type pageBreak struct{}
+type supersede struct{}
+
+// diagnosticFlags is a set of boolean properties of a [Diagnostic].
+//
+// This is an integer, rather than a set of bools, so that a Diagnostic can
+// still be formatted with %q.
+type diagnosticFlags uint8
+
+const (
+	// The diagnostic replaces diagnostics from earlier stages. See
+	// [Supersede].
+	flagSupersedes diagnosticFlags = 1 << iota
+)
 
 func (t tag) apply(d *Diagnostic) {
 	if d.tag != "" {
@@ -374,6 +397,8 @@ func (n note) apply(d *Diagnostic)  { d.notes = append(d.notes, lazySprintf(n).S
 func (n help) apply(d *Diagnostic)  { d.help = append(d.help, lazySprintf(n).String()) }
 func (n debug) apply(d *Diagnostic) { d.debug = append(d.debug, lazySprintf(n).String()) }
 
+func (supersede) apply(d *Diagnostic) { d.flags |= flagSupersedes }
+
 func (pageBreak) apply(d *Diagnostic) {
 	if len(d.snippets) == 0 {
 		return
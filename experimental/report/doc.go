@@ -24,11 +24,17 @@ be pleasant to read, provide rich information about the error, and come in
 a standard, machine-readable format.
 
 Reports can be rendered using a [Renderer], which provides several options
-for how to render the result to the user.
+for how to render the result to the user. Colors and box-drawing glyphs are
+configured with a [StyleSheet]; [Renderer.RenderHTML] produces HTML styled
+with CSS classes instead, for display in web pages.
 
 A Report can be converted into a Protobuf using [Report.ToProto]. This can
 be serialized to e.g. JSON as an alternative error output.
 
+Every diagnostic carries a stable tag (see package rtags), which a [Policy]
+can use to remap its severity or suppress it, either by rule or with inline
+[IgnoreDirective] comments.
+
 The [File] type is a generic utility for converting file offsets into
 text editor coordinates. E.g., given a byte offset, what is the user-visible
 line and column number? Package report expects the caller to construct this
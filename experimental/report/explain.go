@@ -0,0 +1,100 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"fmt"
+	"iter"
+	"maps"
+	"slices"
+	"sync"
+
+	"github.com/bufbuild/protocompile/internal/ext/cmpx"
+)
+
+// Explanation is long-form documentation for a diagnostic tag.
+//
+// Explanations are intended to be shown to users who want to learn more about
+// a diagnostic than fits in its message and footers, in the style of
+// `rustc --explain`. See [Explain].
+type Explanation struct {
+	// The tag this explanation is for.
+	Tag string
+
+	// A one-paragraph summary of what the diagnostic means.
+	Summary string
+
+	// Files that cause the diagnostic to be emitted, and the same files with
+	// the problem fixed. Either may be empty, for diagnostics that cannot be
+	// triggered by a self-contained example.
+	//
+	// The first file of each example is the one that is compiled; the others
+	// are available for it to import.
+	Bad, Good []ExampleFile
+
+	// Prose explaining why the diagnostic exists.
+	Rationale string
+
+	// The full text of the explanation, as Markdown.
+	Text string
+}
+
+// ExampleFile is a source file in an [Explanation]'s example.
+type ExampleFile struct {
+	Path, Text string
+}
+
+var explanations = struct {
+	sync.RWMutex
+	byTag map[string]Explanation
+}{byTag: make(map[string]Explanation)}
+
+// RegisterExplanation registers an explanation for its tag, making it
+// available to [Explain].
+//
+// Panics if the tag is empty or already has an explanation.
+func RegisterExplanation(e Explanation) {
+	if e.Tag == "" {
+		panic("protocompile/report: explanation must have a tag")
+	}
+
+	explanations.Lock()
+	defer explanations.Unlock()
+	if _, ok := explanations.byTag[e.Tag]; ok {
+		panic(fmt.Sprintf("protocompile/report: duplicate explanation for %q", e.Tag))
+	}
+	explanations.byTag[e.Tag] = e
+}
+
+// Explain returns the explanation for the given tag, if one is registered.
+//
+// Explanations for the compiler's own tags are registered by package rtags.
+func Explain(tag string) (Explanation, bool) {
+	explanations.RLock()
+	defer explanations.RUnlock()
+	e, ok := explanations.byTag[tag]
+	return e, ok
+}
+
+// Explanations returns all registered explanations, sorted by tag.
+func Explanations() iter.Seq[Explanation] {
+	explanations.RLock()
+	all := slices.SortedFunc(
+		maps.Values(explanations.byTag),
+		cmpx.Key(func(e Explanation) string { return e.Tag }),
+	)
+	explanations.RUnlock()
+	return slices.Values(all)
+}
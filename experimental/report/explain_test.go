@@ -0,0 +1,42 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
+)
+
+func TestShowExplanations(t *testing.T) {
+	t.Parallel()
+
+	r := new(report.Report)
+	r.Warnf("unused import").Apply(report.Tag(rtags.UnusedImport))
+	r.Warnf("untagged")
+	r.Warnf("unexplained").Apply(report.Tag("example:unexplained"))
+
+	footer := "see `explain " + rtags.UnusedImport + "`"
+
+	text, _, _ := report.Renderer{}.RenderString(r)
+	assert.NotContains(t, text, footer)
+
+	text, _, _ = report.Renderer{ShowExplanations: true}.RenderString(r)
+	assert.Contains(t, text, footer)
+	assert.NotContains(t, text, "explain example:unexplained")
+}
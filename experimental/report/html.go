@@ -0,0 +1,154 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// HTMLStyleSheet is a default CSS stylesheet for the output of
+// [Renderer.RenderHTML].
+//
+// Rendered HTML does not contain any inline styles; instead, every styled
+// region is wrapped in a <span> with the following classes, which this
+// stylesheet gives colors similar to those of [DefaultStyleSheet]:
+//
+//   - pc-error, pc-warning, pc-remark: text relating to a diagnostic level,
+//     such as underlines and level names.
+//   - pc-accent: line numbers, the gutter, and other rendering details.
+//   - pc-add, pc-delete: insertions and deletions in a suggested edit.
+//   - pc-strong: added to any of the above to mark it as bold.
+//
+// The whole report is wrapped in a <pre class="pc-report"> element.
+const HTMLStyleSheet = `.pc-report {
+  font-family: ui-monospace, Menlo, Consolas, monospace;
+  line-height: 1.25;
+}
+.pc-error { color: #cd3131; }
+.pc-warning { color: #b58900; }
+.pc-remark { color: #0e8c9c; }
+.pc-accent { color: #2472c8; }
+.pc-add { color: #0d8b2f; }
+.pc-delete { color: #cd3131; }
+.pc-strong { font-weight: bold; }
+`
+
+// htmlClasses is the CSS classes used by [Renderer.RenderHTML], in the order
+// their escapes appear in htmlStyleSheet.
+var htmlClasses = []string{"error", "warning", "remark", "accent", "add", "delete"}
+
+// htmlStyleSheet is the stylesheet used for rendering HTML. Rather than actual
+// colors, it uses private escapes, which are then replaced with <span>s
+// by [writeHTML].
+var htmlStyleSheet = func() *StyleSheet {
+	escape := func(bold, class int) string {
+		return fmt.Sprintf("\033[%d;%dm", bold, 60+class)
+	}
+	color := func(class int) Color {
+		return Color{escape(0, class), escape(1, class)}
+	}
+
+	return &StyleSheet{
+		Reset:   "\033[0m",
+		Error:   color(0),
+		Warning: color(1),
+		Remark:  color(2),
+		Accent:  color(3),
+		Add:     color(4),
+		Delete:  color(5),
+	}
+}()
+
+var htmlEscapePat = regexp.MustCompile("\033\\[(0|[01];6[0-5])m")
+
+// RenderHTML is like [Renderer.Render], but produces HTML instead of text.
+//
+// The output reproduces the text rendering exactly, but instead of ANSI
+// escapes, it uses <span>s with the CSS classes documented in
+// [HTMLStyleSheet]. Colorize is ignored; the glyphs of r.StyleSheet, if set,
+// are respected.
+func (r Renderer) RenderHTML(report *Report, out io.Writer) (errorCount, warningCount int, err error) {
+	sheet := *htmlStyleSheet
+	if r.StyleSheet != nil {
+		sheet.Unicode = r.StyleSheet.Unicode
+	}
+	r.StyleSheet = &sheet
+	r.Colorize = true
+
+	text, errorCount, warningCount := r.RenderString(report)
+	return errorCount, warningCount, writeHTML(text, out)
+}
+
+// RenderHTMLString is a helper for calling [Renderer.RenderHTML] with a
+// [strings.Builder].
+func (r Renderer) RenderHTMLString(report *Report) (text string, errorCount, warningCount int) {
+	var buf strings.Builder
+	e, w, _ := r.RenderHTML(report, &buf)
+	return buf.String(), e, w
+}
+
+// writeHTML converts text rendered with htmlStyleSheet into HTML.
+func writeHTML(text string, out io.Writer) error {
+	var buf strings.Builder
+	buf.WriteString(`<pre class="pc-report">`)
+	if text != "" {
+		buf.WriteString("\n")
+	}
+
+	var inSpan bool
+	closeSpan := func() {
+		if inSpan {
+			buf.WriteString("</span>")
+			inSpan = false
+		}
+	}
+
+	for text != "" {
+		loc := htmlEscapePat.FindStringSubmatchIndex(text)
+		if loc == nil {
+			buf.WriteString(html.EscapeString(text))
+			break
+		}
+
+		buf.WriteString(html.EscapeString(text[:loc[0]]))
+		code := text[loc[2]:loc[3]]
+		text = text[loc[1]:]
+
+		closeSpan()
+		if code == "0" {
+			continue
+		}
+
+		// The escape has the form bold;6class.
+		bold := code[0] == '1'
+		class := htmlClasses[code[3]-'0']
+		fmt.Fprintf(&buf, `<span class="pc-%s`, class)
+		if bold {
+			buf.WriteString(" pc-strong")
+		}
+		buf.WriteString(`">`)
+		inSpan = true
+	}
+
+	closeSpan()
+	buf.WriteString("</pre>\n")
+
+	_, err := io.WriteString(out, buf.String())
+	return err
+}
@@ -0,0 +1,240 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"path"
+	"slices"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"github.com/bufbuild/protocompile/experimental/source"
+)
+
+// IgnoreDirective is the comment directive that suppresses diagnostics when
+// it appears in one of a [Policy]'s Comments.
+//
+// It is followed by one or more tag patterns, separated by spaces, e.g.
+//
+//	// protocompile:ignore protobuf:unused_import protobuf:deprecated
+//
+// A directive applies to diagnostics whose primary span starts on the line
+// containing it. A directive in a comment that is the only thing on its line
+// also applies to the line immediately after it.
+const IgnoreDirective = "protocompile:ignore"
+
+// Policy is a set of rules for remapping the severity of diagnostics, or
+// suppressing them outright, based on their tag, file, and location.
+//
+// A Policy can be applied explicitly with [Report.ApplyPolicy], or set as
+// [Options].Policy, in which case it is applied when rendering a report and
+// when converting it with [Report.ToProto].
+//
+// [ICE] diagnostics are never affected by a policy.
+type Policy struct {
+	// Rules to apply to each diagnostic. When several rules match a diagnostic,
+	// the last one wins.
+	Rules []Rule
+
+	// The spans of the comments to search for [IgnoreDirective]s, such as
+	// those of every comment token in the files being compiled. Directives
+	// suppress diagnostics with a matching tag, and take precedence over Rules.
+	Comments []source.Span
+}
+
+// Rule is a single entry in a [Policy].
+//
+// A rule matches a diagnostic if all of its non-empty matchers match.
+type Rule struct {
+	// A pattern to match against the diagnostic's tag, as in [path.Match].
+	// For example, "protobuf:*" matches every tag in the protobuf namespace.
+	//
+	// If empty, matches any diagnostic, including untagged ones.
+	Tag string
+
+	// A doublestar glob to match against the path of the diagnostic's
+	// primary span (or its [InFile] path, if it has no primary span).
+	//
+	// If empty, matches diagnostics in any file.
+	Path string
+
+	// If not zero, the diagnostic's primary span must be contained in this
+	// span.
+	Span source.Span
+
+	// The level to give a matching diagnostic. Zero means that the level
+	// is left unchanged.
+	Level Level
+
+	// If set, matching diagnostics are discarded. This takes precedence over
+	// Level.
+	Suppress bool
+}
+
+// ApplyPolicy applies p to every diagnostic in this report, remapping levels
+// and deleting suppressed diagnostics in place.
+//
+// A nil policy is a no-op.
+func (r *Report) ApplyPolicy(p *Policy) {
+	if p == nil {
+		return
+	}
+
+	apply := p.applier()
+	kept := r.Diagnostics[:0]
+	for _, d := range r.Diagnostics {
+		if apply(&d) {
+			kept = append(kept, d)
+		}
+	}
+	clear(r.Diagnostics[len(kept):])
+	r.Diagnostics = kept
+}
+
+// Matches returns whether this rule applies to the given diagnostic.
+func (r Rule) Matches(d *Diagnostic) bool {
+	if r.Tag != "" && !matchTag(r.Tag, d.tag) {
+		return false
+	}
+
+	primary := d.Primary()
+	if r.Path != "" {
+		file := primary.Path()
+		if file == "" {
+			file = d.inFile
+		}
+		if ok, _ := doublestar.Match(r.Path, file); !ok {
+			return false
+		}
+	}
+
+	if !r.Span.IsZero() {
+		if primary.IsZero() || primary.Path() != r.Span.Path() ||
+			primary.Start < r.Span.Start || primary.End > r.Span.End {
+			return false
+		}
+	}
+
+	return true
+}
+
+// applier returns a function that applies this policy to a diagnostic,
+// updating its level. The function returns false if the diagnostic should be
+// discarded.
+//
+// The directives in p's comments are found when this is called, so the
+// returned function should be used for every diagnostic in a report.
+func (p *Policy) applier() func(*Diagnostic) bool {
+	if p == nil {
+		return func(*Diagnostic) bool { return true }
+	}
+
+	directives := findDirectives(p.Comments)
+	return func(d *Diagnostic) bool {
+		if d.level == ICE {
+			return true
+		}
+
+		if ignoredByComment(d, directives) {
+			return false
+		}
+
+		for _, rule := range slices.Backward(p.Rules) {
+			if !rule.Matches(d) {
+				continue
+			}
+
+			if rule.Suppress {
+				return false
+			}
+			if rule.Level != 0 {
+				d.level = rule.Level
+			}
+			break
+		}
+
+		return true
+	}
+}
+
+// directive is an [IgnoreDirective] comment.
+type directive struct {
+	line       int // 1-indexed.
+	standalone bool
+	patterns   []string
+}
+
+// ignoredByComment returns whether an [IgnoreDirective] that applies to the
+// line of d's primary span names d's tag.
+//
+// directives holds the directives of each file.
+func ignoredByComment(d *Diagnostic, directives map[*source.File][]directive) bool {
+	primary := d.Primary()
+	if primary.IsZero() || d.tag == "" {
+		return false
+	}
+
+	line := primary.StartLoc().Line
+	for _, dir := range directives[primary.File] {
+		if dir.line != line && (dir.line != line-1 || !dir.standalone) {
+			continue
+		}
+		for _, pattern := range dir.patterns {
+			if matchTag(pattern, d.tag) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// findDirectives finds the [IgnoreDirective]s among comments, grouped by file.
+func findDirectives(comments []source.Span) map[*source.File][]directive {
+	directives := make(map[*source.File][]directive)
+	for _, comment := range comments {
+		if comment.IsZero() {
+			continue
+		}
+
+		text := comment.Text()
+		if body, ok := strings.CutPrefix(text, "/*"); ok {
+			text = strings.TrimSuffix(body, "*/")
+		} else {
+			text = strings.TrimPrefix(text, "//")
+		}
+		rest, ok := strings.CutPrefix(strings.TrimSpace(text), IgnoreDirective)
+		if !ok {
+			continue
+		}
+
+		before := comment.File.Text()[:comment.Start]
+		before = before[strings.LastIndexByte(before, '\n')+1:]
+		directives[comment.File] = append(directives[comment.File], directive{
+			line:       comment.StartLoc().Line,
+			standalone: strings.TrimSpace(before) == "",
+			patterns:   strings.Fields(rest),
+		})
+	}
+	return directives
+}
+
+// matchTag matches a tag against a [path.Match] pattern. Malformed patterns
+// match nothing.
+func matchTag(pattern, tag string) bool {
+	ok, _ := path.Match(pattern, tag)
+	return ok
+}
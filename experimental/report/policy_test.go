@@ -0,0 +1,176 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bufbuild/protocompile/experimental/parser"
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
+	"github.com/bufbuild/protocompile/experimental/source"
+	"github.com/bufbuild/protocompile/experimental/token"
+	compilerpb "github.com/bufbuild/protocompile/internal/gen/buf/compiler/v1alpha1"
+)
+
+const policyText = `syntax = "proto3";
+import "a.proto"; // protocompile:ignore protobuf:unused_*
+// protocompile:ignore protobuf:deprecated
+import "b.proto";
+message M {}
+`
+
+// newPolicyReport builds a report with a handful of tagged diagnostics, each
+// pointing at a different line of policyText.
+func newPolicyReport(t *testing.T) (*report.Report, *source.File) {
+	t.Helper()
+
+	file := source.NewFile("foo/bar.proto", policyText)
+	span := func(needle string) source.Span {
+		i := strings.Index(policyText, needle)
+		require.GreaterOrEqual(t, i, 0)
+		return file.Span(i, i+len(needle))
+	}
+
+	r := new(report.Report)
+	r.Warnf("unused import").Apply(report.Tag(rtags.UnusedImport), report.Snippet(span(`"a.proto"`)))
+	r.Warnf("deprecated").Apply(report.Tag(rtags.Deprecated), report.Snippet(span(`"b.proto"`)))
+	r.Warnf("unused import").Apply(report.Tag(rtags.UnusedImport), report.Snippet(span(`"b.proto"`)))
+	r.Errorf("empty").Apply(report.Tag(rtags.EmptyDefinition), report.Snippet(span(`M {}`)))
+	r.Fatalf("oops").Apply(report.Snippet(span(`M {}`)))
+	return r, file
+}
+
+func messages(r *report.Report) []string {
+	names := map[report.Level]string{
+		report.ICE:     "ice",
+		report.Error:   "error",
+		report.Warning: "warning",
+		report.Remark:  "remark",
+	}
+
+	var out []string
+	for _, d := range r.Diagnostics {
+		out = append(out, names[d.Level()]+": "+d.Message())
+	}
+	return out
+}
+
+// comments returns the spans of the comments in file.
+func comments(file *source.File) []source.Span {
+	ast, _ := parser.Parse(file.Path(), file, new(report.Report))
+	var spans []source.Span
+	for tok := range ast.Stream().All() {
+		if tok.Kind() == token.Comment {
+			spans = append(spans, tok.Span())
+		}
+	}
+	return spans
+}
+
+func TestPolicyRules(t *testing.T) {
+	t.Parallel()
+
+	r, file := newPolicyReport(t)
+	r.ApplyPolicy(&report.Policy{
+		Rules: []report.Rule{
+			{Tag: "protobuf:*", Level: report.Error},
+			{Path: "foo/**", Span: file.Span(0, strings.Index(policyText, "message")), Level: report.Remark},
+			{Tag: rtags.UnusedImport, Suppress: true},
+			{Path: "other/**", Tag: rtags.Deprecated, Suppress: true},
+		},
+	})
+
+	assert.Equal(t, []string{
+		"remark: deprecated",
+		"error: empty",
+		"ice: oops",
+	}, messages(r))
+}
+
+func TestPolicyComments(t *testing.T) {
+	t.Parallel()
+
+	r, file := newPolicyReport(t)
+	r.ApplyPolicy(&report.Policy{Comments: comments(file)})
+
+	assert.Equal(t, []string{
+		"warning: unused import",
+		"error: empty",
+		"ice: oops",
+	}, messages(r))
+
+	// Only the second unused import, which is not covered by a comment,
+	// survives.
+	assert.Equal(t, 4, r.Diagnostics[0].Primary().StartLoc().Line)
+}
+
+func TestPolicyCommentsLexed(t *testing.T) {
+	t.Parallel()
+
+	text := `syntax = "proto3";
+import "a.proto"; // protocompile:ignore protobuf:unused_import
+import "b.proto";
+option go_package = "http://x"; // protocompile:ignore protobuf:deprecated
+option java_package = "// protocompile:ignore protobuf:deprecated";
+/* protocompile:ignore protobuf:deprecated */
+message M {}
+`
+	file := source.NewFile("test.proto", text)
+	span := func(needle string) source.Span {
+		i := strings.Index(text, needle)
+		require.GreaterOrEqual(t, i, 0)
+		return file.Span(i, i+len(needle))
+	}
+
+	r := new(report.Report)
+	r.Warnf("unused a").Apply(report.Tag(rtags.UnusedImport), report.Snippet(span(`"a.proto"`)))
+	r.Warnf("unused b").Apply(report.Tag(rtags.UnusedImport), report.Snippet(span(`"b.proto"`)))
+	r.Warnf("go_package").Apply(report.Tag(rtags.Deprecated), report.Snippet(span(`go_package`)))
+	r.Warnf("java_package").Apply(report.Tag(rtags.Deprecated), report.Snippet(span(`java_package`)))
+	r.Warnf("M").Apply(report.Tag(rtags.Deprecated), report.Snippet(span(`M {}`)))
+	r.ApplyPolicy(&report.Policy{Comments: comments(file)})
+
+	// A trailing comment only applies to its own line, and text inside of a
+	// string is not a comment.
+	assert.Equal(t, []string{
+		"warning: unused b",
+		"warning: java_package",
+	}, messages(r))
+}
+
+func TestPolicyOptions(t *testing.T) {
+	t.Parallel()
+
+	r, _ := newPolicyReport(t)
+	r.Policy = &report.Policy{Rules: []report.Rule{{Tag: "protobuf:*", Suppress: true}}}
+
+	text, errors, warnings := report.Renderer{Compact: true}.RenderString(r)
+	assert.Equal(t, 1, errors)
+	assert.Equal(t, 0, warnings)
+	assert.Contains(t, text, "oops")
+	assert.NotContains(t, text, "unused import")
+
+	m, ok := r.ToProto().(*compilerpb.Report)
+	require.True(t, ok)
+	assert.Len(t, m.Diagnostics, 1)
+
+	// The report itself is not modified.
+	assert.Len(t, r.Diagnostics, 5)
+}
@@ -47,6 +47,10 @@ type Renderer struct {
 	// If set, rendering results are enriched with ANSI color escapes.
 	Colorize bool
 
+	// The colors and glyphs to render with. If nil, [DefaultStyleSheet] is
+	// used. Colors are only used if Colorize is set.
+	StyleSheet *StyleSheet
+
 	// Upgrades all warnings to errors.
 	WarningsAreErrors bool
 
@@ -55,6 +59,10 @@ type Renderer struct {
 
 	// If set, rendering a diagnostic will show the debug footer.
 	ShowDebug bool
+
+	// If set, diagnostics whose tag has a registered [Explanation] end with
+	// a footer pointing the user to it.
+	ShowExplanations bool
 }
 
 // renderer contains shared state for a rendering operation, allowing e.g.
@@ -73,7 +81,8 @@ type renderer struct {
 // Render renders a diagnostic report.
 //
 // In addition to returning the rendering result, returns whether the report
-// contains any errors.
+// contains any errors. The report's [Options].Policy, if any, is applied to
+// each diagnostic before it is rendered.
 //
 // On the other hand, the actual error-typed return is an error when writing to
 // the writer.
@@ -94,7 +103,11 @@ func (r Renderer) RenderString(report *Report) (text string, errorCount, warning
 }
 
 func (r *renderer) render(report *Report) (errorCount, warningCount int, err error) {
+	apply := report.Policy.applier()
 	for _, diagnostic := range report.Diagnostics {
+		if !apply(&diagnostic) {
+			continue
+		}
 		if !r.ShowRemarks && diagnostic.level == Remark {
 			continue
 		}
@@ -247,7 +260,7 @@ func (r *renderer) diagnostic(report *Report, d Diagnostic) {
 			r.WriteString("\n")
 			r.WriteString(r.ss.nAccent)
 			r.WriteSpaces(r.margin)
-			r.WriteString(" | ")
+			r.WriteString(r.ss.gutter)
 		}
 
 		if i == 0 || d.snippets[i-1].pageBreak || d.snippets[i-1].Path() != d.snippets[i].Path() {
@@ -257,9 +270,9 @@ func (r *renderer) diagnostic(report *Report, d Diagnostic) {
 
 			primary := snippets[0]
 			start := locations[i][0]
-			sep := ":::"
+			sep := r.ss.cont
 			if i == 0 {
-				sep = "-->"
+				sep = r.ss.arrow
 			}
 			fmt.Fprintf(r, "%s %s:%d:%d\n", sep, primary.Path(), start.Line, start.Column)
 		} else if len(snippets[0].edits) > 0 {
@@ -275,7 +288,7 @@ func (r *renderer) diagnostic(report *Report, d Diagnostic) {
 		// visual breathing room.
 		r.WriteString(r.ss.nAccent)
 		r.WriteSpaces(r.margin)
-		r.WriteString(" | ")
+		r.WriteString(r.ss.gutter)
 
 		window := buildWindow(d.level, locations[i:i+len(snippets)], snippets)
 		needsTrailingBreak = r.window(window)
@@ -287,14 +300,21 @@ func (r *renderer) diagnostic(report *Report, d Diagnostic) {
 		r.WriteString(r.ss.nAccent)
 		r.WriteSpaces(r.margin - 1)
 
-		fmt.Fprintf(r, "--> %s", d.inFile)
+		fmt.Fprintf(r, "%s %s", r.ss.arrow, d.inFile)
+	}
+
+	var explain []string
+	if r.ShowExplanations {
+		if _, ok := Explain(d.tag); ok {
+			explain = []string{fmt.Sprintf("for more information, see `explain %s`", d.tag)}
+		}
 	}
 
-	if needsTrailingBreak && !(d.notes == nil && d.help == nil && (!r.ShowDebug || d.debug == nil)) {
+	if needsTrailingBreak && !(d.notes == nil && d.help == nil && explain == nil && (!r.ShowDebug || d.debug == nil)) {
 		r.WriteString("\n")
 		r.WriteString(r.ss.nAccent)
 		r.WriteSpaces(r.margin)
-		r.WriteString(" | ")
+		r.WriteString(r.ss.gutter)
 	}
 
 	type footer struct {
@@ -303,6 +323,7 @@ func (r *renderer) diagnostic(report *Report, d Diagnostic) {
 	footers := iterx.Chain(
 		slicesx.Map(d.notes, func(s string) footer { return footer{r.ss.bRemark, "note", s} }),
 		slicesx.Map(d.help, func(s string) footer { return footer{r.ss.bRemark, "help", s} }),
+		slicesx.Map(explain, func(s string) footer { return footer{r.ss.bRemark, "note", s} }),
 		slicesx.Map(d.debug, func(s string) footer { return footer{r.ss.bError, "debug", s} }),
 	)
 
@@ -326,7 +347,7 @@ func (r *renderer) diagnostic(report *Report, d Diagnostic) {
 		}
 	}
 
-	if !haveFooter && bytes.Equal(bytes.TrimSpace(r.buf), []byte("|")) {
+	if !haveFooter && bytes.Equal(bytes.TrimSpace(r.buf), []byte(strings.TrimSpace(r.ss.gutter))) {
 		r.buf = r.buf[:0]
 		r.WriteString(r.ss.reset)
 		r.WriteString("\n")
@@ -731,7 +752,7 @@ func (r *renderer) window(w *window) (needsTrailingBreak bool) {
 
 			// Splat in the one with all the pipes in it as-is. Having two rows like
 			// this ensures that each message has one pipe directly above it.
-			cur.underlines = append(cur.underlines, strings.TrimRight(sidebar+string(buf1), " "))
+			cur.underlines = append(cur.underlines, r.ss.art(strings.TrimRight(sidebar+string(buf1), " ")))
 
 			// Then, splat in the messages for this line.
 			offset := 0
@@ -751,20 +772,20 @@ func (r *renderer) window(w *window) (needsTrailingBreak bool) {
 				offset += len(color)
 			}
 
-			// Insert the colors for the pipes, again...
+			// Insert the colors for the pipes, again... This time, the pipes are
+			// converted to glyphs directly, since buf2 also contains messages.
 			for i := 0; i < len(buf2); i++ {
 				b := buf2[i]
 				if b&0x80 == 0 {
 					continue
 				}
 
-				color := r.ss.BoldForLevel(Level(^b))
-				buf2[i] = '|'
-				buf2 = slices.Insert(buf2, i, []byte(color)...)
-				i += len(color)
+				pipe := r.ss.BoldForLevel(Level(^b)) + r.ss.pipe
+				buf2 = slices.Replace(buf2, i, i+1, []byte(pipe)...)
+				i += len(pipe) - 1
 			}
 
-			cur.underlines = append(cur.underlines, strings.TrimRight(sidebar+string(buf2), " "))
+			cur.underlines = append(cur.underlines, strings.TrimRight(r.ss.art(sidebar)+string(buf2), " "))
 		}
 
 		// Finally, finalize the underlines by coloring them!
@@ -810,18 +831,21 @@ func (r *renderer) window(w *window) (needsTrailingBreak bool) {
 				pipes--
 			}
 
-			// Append the message for this subline, if any.
+			// Append the message for this subline, if any. Everything before the
+			// message is art.
+			art := len(buf)
 			if ul != nil && ul.message != "" {
+				art = ul.end + offset
 				color := r.ss.BoldForLevel(ul.level)
-				end := ul.end + offset + len(color) + len(ul.message)
+				end := art + len(color) + len(ul.message)
 				for len(buf) < end {
 					buf = append(buf, ' ')
 				}
-				copy(buf[ul.end+offset:], color)
-				copy(buf[ul.end+offset+len(color):], ul.message)
+				copy(buf[art:], color)
+				copy(buf[art+len(color):], ul.message)
 			}
 
-			cur.underlines[i] = sidebar + string(buf)
+			cur.underlines[i] = r.ss.art(sidebar+string(buf[:art])) + string(buf[art:])
 		}
 	}
 
@@ -861,18 +885,21 @@ func (r *renderer) window(w *window) (needsTrailingBreak bool) {
 				// We also need to erase the bars of any multis that are before this multi
 				// and start/end on the same line.
 				if !isStart {
-					for mlIdx, otherML := range cur.sidebar[:mlIdx+1] {
-						if otherML != nil && otherML.end == ml.end {
-							// All the color escapes have the same byte length, so we can use the length of
-							// any of them to measure how far we need to adjust the offset to get to the
-							// pipe. We need to account for one escape per multiline, and also need to skip
-							// past the color escape on the pipe we want to erase.
-							codeLen := len(r.ss.bAccent)
-							idx := mlIdx*(2+codeLen) + codeLen
-							if idx < len(sidebar) {
-								sidebar[idx] = ' '
-							}
+					// Each entry in the sidebar is two bytes, preceded by a color
+					// escape if it is not empty; we need to skip past the color escape
+					// on the pipe we want to erase.
+					var idx int
+					for _, otherML := range cur.sidebar[:mlIdx+1] {
+						if otherML == nil {
+							idx += 2
+							continue
 						}
+
+						idx += len(r.ss.BoldForLevel(otherML.level))
+						if otherML.end == ml.end && idx < len(sidebar) {
+							sidebar[idx] = ' '
+						}
+						idx += 2
 					}
 				}
 
@@ -908,11 +935,11 @@ func (r *renderer) window(w *window) (needsTrailingBreak bool) {
 				// columns). It is unlikely users will hit this problem with "realistic"
 				// inputs, though, and e.g. rustc and clang do not bother to handle this
 				// case nicely.
+				art := r.ss.art(line.String())
 				if !isStart && ml.message != "" {
-					line.WriteByte(' ')
-					line.WriteString(ml.message)
+					art += " " + ml.message
 				}
-				cur.underlines = append(cur.underlines, line.String())
+				cur.underlines = append(cur.underlines, art)
 			}
 
 			if isStart {
@@ -1021,13 +1048,13 @@ func (r *renderer) window(w *window) (needsTrailingBreak bool) {
 				slashAt = len(prevSidebar) - 1
 			}
 
-			r.WriteString(r.sidebar(sidebarLen, lastEmit+1, slashAt, info[lastEmit-w.start].sidebar))
+			r.WriteString(r.ss.art(r.sidebar(sidebarLen, lastEmit+1, slashAt, info[lastEmit-w.start].sidebar)))
 		}
 
 		// Ok, we are definitely printing this line out.
 		//
 		// Note that sidebar already includes a trailing ss.reset for us.
-		fmt.Fprintf(r, "\n%s%*d | %s%s", r.ss.nAccent, r.margin, lineno, sidebar, r.ss.reset)
+		fmt.Fprintf(r, "\n%s%*d%s%s%s", r.ss.nAccent, r.margin, lineno, r.ss.gutter, r.ss.art(sidebar), r.ss.reset)
 		lastEmit = lineno
 
 		// Re-use the logic from width calculation to correctly format a line for
@@ -1041,7 +1068,7 @@ func (r *renderer) window(w *window) (needsTrailingBreak bool) {
 			r.WriteString("\n")
 			r.WriteString(r.ss.nAccent)
 			r.WriteSpaces(r.margin)
-			r.WriteString(" | ")
+			r.WriteString(r.ss.gutter)
 			r.WriteString(line)
 
 			// Gross hack to pick up whether a trailing break is necessary; we
@@ -1112,7 +1139,7 @@ func (r *renderer) suggestion(snip snippet) {
 	r.WriteString("\n")
 	r.WriteSpaces(r.margin)
 	r.WriteString(r.ss.nAccent)
-	r.WriteString(" | ")
+	r.WriteString(r.ss.gutter)
 
 	// When the suggestion spans multiple lines, we don't bother doing a by-the-rune
 	// diff, because the result can be hard for users to understand how to apply
@@ -1151,8 +1178,8 @@ func (r *renderer) suggestion(snip snippet) {
 
 				// Draw the line as we would for an ordinary window, but prefix
 				// each line with a the hunk's kind and color.
-				fmt.Fprintf(r, "\n%s%*d | %s%c%s ",
-					r.ss.nAccent, r.margin, lineno,
+				fmt.Fprintf(r, "\n%s%*d%s%s%c%s ",
+					r.ss.nAccent, r.margin, lineno, r.ss.gutter,
 					hunk.bold(&r.ss), hunk.kind, hunk.color(&r.ss),
 				)
 				uw := &unicodex.Width{EscapeNonPrint: true, Out: &r.writer}
@@ -1173,12 +1200,12 @@ func (r *renderer) suggestion(snip snippet) {
 		r.WriteString("\n")
 		r.WriteString(r.ss.nAccent)
 		r.WriteSpaces(r.margin)
-		r.WriteString(" | ")
+		r.WriteString(r.ss.gutter)
 		return
 	}
 
 	span, hunks := hunkDiff(snip.Span, snip.edits)
-	fmt.Fprintf(r, "\n%s%*d | ", r.ss.nAccent, r.margin, span.StartLoc().Line)
+	fmt.Fprintf(r, "\n%s%*d%s", r.ss.nAccent, r.margin, span.StartLoc().Line, r.ss.gutter)
 	uw := &unicodex.Width{EscapeNonPrint: true, Out: &r.writer}
 	for _, hunk := range hunks {
 		if hunk.content == "" {
@@ -1196,7 +1223,7 @@ func (r *renderer) suggestion(snip snippet) {
 	r.WriteString("\n")
 	r.WriteString(r.ss.nAccent)
 	r.WriteSpaces(r.margin)
-	r.WriteString(" | ")
+	r.WriteString(r.ss.gutter)
 	uw.Column = 0
 	uw.Out = nil
 	for _, hunk := range hunks {
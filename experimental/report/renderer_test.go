@@ -69,6 +69,8 @@ func TestRender(t *testing.T) {
 			{Extension: "simple.txt"},
 			{Extension: "fancy.txt"},
 			{Extension: "color.txt"},
+			{Extension: "unicode.txt"},
+			{Extension: "html"},
 		},
 	}
 
@@ -131,5 +133,18 @@ func TestRender(t *testing.T) {
 		// -test.skip.
 		t.Log("\n" + text)
 		outputs[2] = ansiToMarkup(text)
+
+		text, _, _ = report.Renderer{
+			StyleSheet:  &report.StyleSheet{Unicode: true},
+			ShowRemarks: true,
+			ShowDebug:   true,
+		}.RenderString(r)
+		outputs[3] = text
+
+		text, _, _ = report.Renderer{
+			ShowRemarks: true,
+			ShowDebug:   true,
+		}.RenderHTMLString(r)
+		outputs[4] = text
 	})
 }
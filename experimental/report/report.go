@@ -65,6 +65,10 @@ type Options struct {
 	// If set, all diagnostics of severity at most Warning (i.e., >= Warning
 	// as integers) are suppressed.
 	SuppressWarnings bool
+
+	// If not nil, this policy is applied to diagnostics when the report is
+	// rendered or converted with [Report.ToProto]. See [Policy].
+	Policy *Policy
 }
 
 // Diagnose is a type that can be rendered as a diagnostic.
@@ -257,11 +261,15 @@ func (r *Report) AnnotateICE(options ...DiagnosticOption) {
 // diagnostics for the same sort order (lex, parse, etc) go together, and they
 // are otherwise ordered by where they occur in the file.
 //
-// Canonicalize will deduplicate diagnostics whose primary span and (nonempty)
-// diagnostic tags are equal, selecting the diagnostic that sorts as greatest
-// as the canonical value. This allows later diagnostics to replace earlier
-// diagnostics, so long as they cooperate by using the same tag. Deduplication
-// can be suppressed using [Options].KeepDuplicates.
+// Canonicalize will deduplicate diagnostics whose primary span, (nonempty)
+// diagnostic tag, and message are equal, selecting the diagnostic that sorts
+// as greatest as the canonical value. This allows later diagnostics to replace
+// earlier diagnostics, so long as they cooperate by using the same tag and
+// message. Because every diagnostic carries a tag, the message is part of the
+// key, so that distinct problems of the same kind at the same span are kept.
+// A diagnostic marked with [Supersede] also replaces diagnostics from earlier
+// stages with the same primary span and tag, whatever their message.
+// Deduplication can be suppressed using [Options].KeepDuplicates.
 func (r *Report) Canonicalize() {
 	slices.SortFunc(r.Diagnostics, cmpx.Join(
 		cmpx.Key(func(d Diagnostic) string { return d.Primary().Path() }),
@@ -277,8 +285,9 @@ func (r *Report) Canonicalize() {
 	}
 
 	type key struct {
-		span source.Span
-		tag  string
+		span    source.Span
+		tag     string
+		message string
 	}
 	var cur key
 	slices.Backward(r.Diagnostics)(func(i int, d Diagnostic) bool {
@@ -286,7 +295,7 @@ func (r *Report) Canonicalize() {
 			return true
 		}
 
-		key := key{d.Primary().Span(), d.tag}
+		key := key{d.Primary().Span(), d.tag, d.message}
 		if cur.tag != "" && cur == key {
 			r.Diagnostics[i].level = -1 // Use this to mark which diagnostics to delete.
 		} else {
@@ -296,14 +305,37 @@ func (r *Report) Canonicalize() {
 		return true
 	})
 
+	// Diagnostics marked with [Supersede] may replace diagnostics from earlier
+	// stages, which do not sort next to them. These are matched by span and
+	// tag only, since the later diagnostic may describe the problem better.
+	type supersedeKey struct {
+		span source.Span
+		tag  string
+	}
+	superseded := make(map[supersedeKey]int)
+	for _, d := range r.Diagnostics {
+		if d.flags&flagSupersedes != 0 && d.level != -1 {
+			k := supersedeKey{d.Primary().Span(), d.tag}
+			superseded[k] = max(superseded[k], d.sortOrder)
+		}
+	}
+	if len(superseded) > 0 {
+		for i, d := range r.Diagnostics {
+			k := supersedeKey{d.Primary().Span(), d.tag}
+			if stage, ok := superseded[k]; ok && d.sortOrder < stage {
+				r.Diagnostics[i].level = -1
+			}
+		}
+	}
+
 	r.Diagnostics = slices.DeleteFunc(r.Diagnostics, func(d Diagnostic) bool { return d.level == -1 })
 }
 
 // ToProto converts this report into a Protobuf message for serialization.
 //
-// This operation is lossy: only the Diagnostics slice is serialized. It also discards
-// concrete types of Diagnostic.Err, replacing them with opaque [errors.New] values
-// on deserialization.
+// This operation is lossy: only the Diagnostics slice is serialized, after
+// applying [Options].Policy. It also discards concrete types of Diagnostic.Err,
+// replacing them with opaque [errors.New] values on deserialization.
 //
 // It will also deduplicate [File2] values based on their paths, paying no attention to
 // their contents.
@@ -311,7 +343,12 @@ func (r *Report) ToProto() proto.Message {
 	proto := new(compilerpb.Report)
 
 	fileToIndex := map[string]uint32{}
+	apply := r.Policy.applier()
 	for _, d := range r.Diagnostics {
+		if !apply(&d) {
+			continue
+		}
+
 		dProto := &compilerpb.Diagnostic{
 			Message: d.message,
 			Tag:     d.tag,
@@ -0,0 +1,67 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bufbuild/protocompile/experimental/report"
+	"github.com/bufbuild/protocompile/experimental/report/rtags"
+	"github.com/bufbuild/protocompile/experimental/source"
+)
+
+func TestCanonicalize(t *testing.T) {
+	t.Parallel()
+
+	file := source.NewFile("test.proto", "message M {}")
+	span := file.Span(8, 9)
+
+	r := new(report.Report)
+	r.Errorf("b").Apply(report.Tag(rtags.InvalidType), report.Snippet(span))
+	r.Errorf("a").Apply(report.Tag(rtags.InvalidType), report.Snippet(span))
+	r.Errorf("b").Apply(report.Tag(rtags.InvalidType), report.Snippet(span))
+	r.Errorf("c").Apply(report.Tag(rtags.DuplicateDecl), report.Snippet(span))
+	r.Canonicalize()
+
+	// Diagnostics at the same span are ordered by tag and then message, and
+	// only those with the same tag and message are deduplicated.
+	assert.Equal(t, []string{"error: c", "error: a", "error: b"}, messages(r))
+}
+
+func TestCanonicalizeAcrossStages(t *testing.T) {
+	t.Parallel()
+
+	file := source.NewFile("test.proto", "message M { N n = 1; }")
+
+	r := new(report.Report)
+	r.Errorf("cannot find N").Apply(report.Tag(rtags.UnknownSymbol), report.Snippet(file.Span(12, 13)))
+	r.Errorf("other").Apply(report.Tag(rtags.InvalidType), report.Snippet(file.Span(14, 15)))
+	r.Stage++
+	r.Errorf("other").Apply(report.Tag(rtags.InvalidType), report.Snippet(file.Span(14, 15)))
+	r.Errorf("N is not imported").Apply(
+		report.Tag(rtags.UnknownSymbol), report.Snippet(file.Span(12, 13)),
+		report.Helpf("import it"), report.Supersede,
+	)
+	r.Canonicalize()
+
+	// The later stage's diagnostic replaces the earlier one with the same span
+	// and tag, even though another diagnostic sorts between them and their
+	// messages differ. Duplicates from different stages that are not marked
+	// with Supersede are kept.
+	assert.Equal(t, []string{"error: other", "error: N is not imported", "error: other"}, messages(r))
+	assert.Equal(t, []string{"import it"}, r.Diagnostics[1].Help())
+}
@@ -0,0 +1,165 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtags
+
+import (
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"github.com/bufbuild/protocompile/experimental/report"
+)
+
+// explainFS contains one Markdown file per tag, named after the tag without
+// its "protobuf:" prefix.
+//
+// Each file has the following shape:
+//
+//	# protobuf:some_tag
+//
+//	Summary paragraph.
+//
+//	## Example
+//
+//	```proto
+//	// A file that triggers the diagnostic.
+//	```
+//
+//	## Fix
+//
+//	```proto
+//	// The same file, fixed.
+//	```
+//
+//	## Rationale
+//
+//	Why the diagnostic exists.
+//
+// A code block's info string may name the file after the language, e.g.
+// "```proto dep.proto"; otherwise, the file is named "test.proto". The first
+// code block of a section is the one that is compiled.
+//
+//go:embed explain/*.md
+var explainFS embed.FS
+
+func init() {
+	entries, err := fs.ReadDir(explainFS, "explain")
+	if err != nil {
+		panic(err)
+	}
+
+	for _, entry := range entries {
+		name := path.Join("explain", entry.Name())
+		text, err := fs.ReadFile(explainFS, name)
+		if err != nil {
+			panic(err)
+		}
+
+		e, err := parseExplanation(string(text))
+		if err != nil {
+			panic(fmt.Sprintf("protocompile/rtags: %s: %v", name, err))
+		}
+		if want := "protobuf:" + strings.TrimSuffix(entry.Name(), ".md"); e.Tag != want {
+			panic(fmt.Sprintf("protocompile/rtags: %s: expected tag %q, got %q", name, want, e.Tag))
+		}
+
+		report.RegisterExplanation(e)
+	}
+}
+
+// parseExplanation parses an explanation file, as described in [explainFS].
+func parseExplanation(text string) (report.Explanation, error) {
+	e := report.Explanation{Text: text}
+
+	header, body, _ := strings.Cut(text, "\n")
+	tag, ok := strings.CutPrefix(header, "# ")
+	if !ok {
+		return e, fmt.Errorf("expected `# <tag>` header, got %q", header)
+	}
+	e.Tag = strings.TrimSpace(tag)
+
+	var (
+		section string
+		prose   strings.Builder
+		block   *strings.Builder
+		files   *[]report.ExampleFile
+	)
+	flush := func() {
+		text := strings.TrimSpace(prose.String())
+		prose.Reset()
+		switch section {
+		case "":
+			e.Summary = text
+		case "Rationale":
+			e.Rationale = text
+		}
+	}
+
+	for line := range strings.Lines(body) {
+		trimmed := strings.TrimRight(line, "\n")
+
+		if block != nil {
+			if trimmed == "```" {
+				(*files)[len(*files)-1].Text = block.String()
+				block = nil
+				continue
+			}
+			block.WriteString(line)
+			continue
+		}
+
+		if heading, ok := strings.CutPrefix(trimmed, "## "); ok {
+			flush()
+			section = heading
+			switch section {
+			case "Example":
+				files = &e.Bad
+			case "Fix":
+				files = &e.Good
+			case "Rationale":
+				files = nil
+			default:
+				return e, fmt.Errorf("unknown section %q", section)
+			}
+			continue
+		}
+
+		if info, ok := strings.CutPrefix(trimmed, "```proto"); ok && files != nil {
+			path := strings.TrimSpace(info)
+			if path == "" {
+				path = "test.proto"
+			}
+			*files = append(*files, report.ExampleFile{Path: path})
+			block = new(strings.Builder)
+			continue
+		}
+
+		prose.WriteString(line)
+	}
+	if block != nil {
+		return e, fmt.Errorf("unterminated code block")
+	}
+	flush()
+
+	if e.Summary == "" {
+		return e, fmt.Errorf("missing summary")
+	}
+	if e.Rationale == "" {
+		return e, fmt.Errorf("missing rationale")
+	}
+	return e, nil
+}
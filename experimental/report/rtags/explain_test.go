@@ -0,0 +1,147 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package rtags_test
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"slices"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bufbuild/protocompile/experimental/incremental"
+	"github.com/bufbuild/protocompile/experimental/incremental/queries"
+	"github.com/bufbuild/protocompile/experimental/ir"
+	"github.com/bufbuild/protocompile/experimental/ir/depgraph"
+	"github.com/bufbuild/protocompile/experimental/report"
+	_ "github.com/bufbuild/protocompile/experimental/report/rtags" // Registers explanations.
+	"github.com/bufbuild/protocompile/experimental/source"
+)
+
+// noExample lists tags whose diagnostics cannot be triggered by a
+// self-contained example.
+var noExample = map[string]string{
+	"protobuf:file_too_large":      "requires a file larger than 2GB",
+	"protobuf:invalid_encoding":    "requires a file that is not valid UTF-8",
+	"protobuf:file_not_found":      "requires the file being compiled to not exist",
+	"protobuf:missing_builtin":     "requires a corrupt descriptor.proto",
+	"protobuf:missing_body":        "is currently unreachable: a message with no body parses as a field",
+	"protobuf:merge_conflict":      "is only emitted when merging files with ast/merge",
+	"protobuf:layering_violation":  "requires a user-supplied set of allowed package dependencies",
+	"protobuf:unsupported_edition": "is currently unreachable: every valid edition is supported",
+}
+
+func TestEveryTagIsExplained(t *testing.T) {
+	t.Parallel()
+
+	files := token.NewFileSet()
+	file, err := parser.ParseFile(files, "rtags.go", nil, 0)
+	require.NoError(t, err)
+
+	var tags []string
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.ValueSpec)
+		if !ok {
+			return true
+		}
+		for _, value := range spec.Values {
+			if lit, ok := value.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				tag, err := strconv.Unquote(lit.Value)
+				require.NoError(t, err)
+				tags = append(tags, tag)
+			}
+		}
+		return false
+	})
+	require.NotEmpty(t, tags)
+
+	var explained []string
+	for e := range report.Explanations() {
+		explained = append(explained, e.Tag)
+	}
+
+	slices.Sort(tags)
+	assert.Equal(t, tags, explained)
+}
+
+func TestExplanationExamples(t *testing.T) {
+	t.Parallel()
+
+	for e := range report.Explanations() {
+		t.Run(e.Tag, func(t *testing.T) {
+			t.Parallel()
+
+			if reason, ok := noExample[e.Tag]; ok {
+				assert.Empty(t, e.Bad, "tag has no example because it %s", reason)
+				return
+			}
+
+			require.NotEmpty(t, e.Bad, "missing example")
+			require.NotEmpty(t, e.Good, "missing fix")
+
+			r := compile(t, e.Bad)
+			assert.True(t,
+				slices.ContainsFunc(r.Diagnostics, func(d report.Diagnostic) bool { return d.Is(e.Tag) }),
+				"example did not emit %s:\n%s", e.Tag, render(r),
+			)
+
+			r = compile(t, e.Good)
+			for _, d := range r.Diagnostics {
+				assert.False(t, d.Is(e.Tag) || d.Level() <= report.Error,
+					"fix emitted unexpected diagnostic:\n%s", render(r),
+				)
+			}
+		})
+	}
+}
+
+// compile runs the given example through the compiler, including the
+// dead-definition and recursive message analyses, returning the resulting
+// diagnostics.
+func compile(t *testing.T, files []report.ExampleFile) *report.Report {
+	t.Helper()
+
+	sources := make(map[string]*source.File)
+	for _, f := range files {
+		sources[f.Path] = source.NewFile(f.Path, f.Text)
+	}
+
+	exec := incremental.New(incremental.WithParallelism(1))
+	results, r, err := incremental.Run(t.Context(), exec, queries.Link{
+		Opener:    &source.Openers{source.NewMap(sources), source.WKTs()},
+		Session:   new(ir.Session),
+		Workspace: source.NewWorkspace(files[0].Path),
+	})
+	require.NoError(t, err)
+	if linked := results[0].Value; linked != nil {
+		linked = slices.DeleteFunc(linked, func(f *ir.File) bool { return f == nil })
+		ir.DiagnoseDeadDefinitions(r, nil, nil, linked...)
+
+		// Only check the example's own files: descriptor.proto contains
+		// recursive messages.
+		examples := slices.DeleteFunc(slices.Clone(linked), func(f *ir.File) bool { return sources[f.Path()] == nil })
+		depgraph.New(examples...).DiagnoseCycles(r)
+	}
+	return r
+}
+
+func render(r *report.Report) string {
+	text, _, _ := report.Renderer{}.RenderString(r)
+	return text
+}
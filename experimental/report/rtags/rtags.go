@@ -13,19 +13,371 @@
 // limitations under the License.
 
 // Package rtags defines publicly-exposed diagnostic tag constants for use with [report.Tag].
+//
+// Every diagnostic emitted by the compiler, other than internal compiler
+// errors, carries one of these tags. Tags are stable: once published, a tag
+// will not be renamed or repurposed, which makes them suitable for use in
+// a [report.Policy].
 package rtags
 
-import _ "github.com/bufbuild/protocompile/experimental/report" // Imported for package documentation.
+// Tags for diagnostics emitted while lexing.
+const (
+	// UnrecognizedToken is the tag for a diagnostic where the lexer encounters
+	// text that is not a valid token.
+	UnrecognizedToken = "protobuf:unrecognized_token"
+
+	// UnmatchedDelimiter is the tag for a diagnostic about an unclosed or
+	// unopened bracket, brace, parenthesis, or block comment.
+	UnmatchedDelimiter = "protobuf:unmatched_delimiter"
+
+	// NonASCIIIdent is the tag for a diagnostic about an identifier containing
+	// non-ASCII characters.
+	NonASCIIIdent = "protobuf:non_ascii_ident"
+
+	// FileTooLarge is the tag for a diagnostic about a file that exceeds the
+	// maximum supported size.
+	FileTooLarge = "protobuf:file_too_large"
+
+	// InvalidEncoding is the tag for a diagnostic about a file that is not
+	// valid UTF-8.
+	InvalidEncoding = "protobuf:invalid_encoding"
+
+	// InvalidNumber is the tag for a diagnostic about a malformed number
+	// literal, such as one with an invalid digit.
+	InvalidNumber = "protobuf:invalid_number"
+
+	// UnterminatedString is the tag for a diagnostic about a string literal
+	// without a closing quote.
+	UnterminatedString = "protobuf:unterminated_string"
+
+	// InvalidStringChar is the tag for a diagnostic about a character that
+	// may not appear unescaped in a string literal, such as a newline or a
+	// NUL byte.
+	InvalidStringChar = "protobuf:invalid_string_char"
+
+	// NonPrintableChar is the tag for a diagnostic about an unescaped
+	// non-printable character in a string literal.
+	NonPrintableChar = "protobuf:non_printable_char"
+
+	// InvalidEscape is the tag for a diagnostic about an invalid escape
+	// sequence in a string literal.
+	InvalidEscape = "protobuf:invalid_escape"
+
+	// IncompatibleStringPrefix is the tag for a diagnostic about implicitly
+	// concatenated string literals with different prefixes.
+	IncompatibleStringPrefix = "protobuf:incompatible_string_prefix"
+)
+
+// Tags for diagnostics emitted while parsing.
+const (
+	// SyntaxError is the tag for a diagnostic about an unexpected token or
+	// syntax construct.
+	SyntaxError = "protobuf:syntax_error"
+
+	// MissingSyntax is the tag for a diagnostic about a file without a
+	// syntax or edition declaration.
+	MissingSyntax = "protobuf:missing_syntax"
+
+	// MissingPackage is the tag for a diagnostic about a file without a
+	// package declaration.
+	MissingPackage = "protobuf:missing_package"
+
+	// InvalidSyntax is the tag for a diagnostic about an invalid syntax or
+	// edition declaration.
+	InvalidSyntax = "protobuf:invalid_syntax"
+
+	// UnsupportedEdition is the tag for a diagnostic about an edition that
+	// the compiler recognizes but does not implement.
+	UnsupportedEdition = "protobuf:unsupported_edition"
+
+	// DuplicateDecl is the tag for a diagnostic about a declaration or
+	// syntax element that may appear at most once, but appears more than
+	// once.
+	DuplicateDecl = "protobuf:duplicate_declaration"
+
+	// MisplacedDecl is the tag for a diagnostic about a declaration that
+	// appears out of order, such as a syntax declaration that is not the
+	// first declaration in a file.
+	MisplacedDecl = "protobuf:misplaced_declaration"
+
+	// InvalidNesting is the tag for a diagnostic about a declaration that
+	// appears within a scope that does not permit it.
+	InvalidNesting = "protobuf:invalid_nesting"
+
+	// UnexpectedOptions is the tag for a diagnostic about compact options on
+	// a declaration that does not permit them.
+	UnexpectedOptions = "protobuf:unexpected_options"
 
+	// UnexpectedSignature is the tag for a diagnostic about a method
+	// signature on a declaration that is not a method.
+	UnexpectedSignature = "protobuf:unexpected_signature"
+
+	// UnexpectedModifier is the tag for a diagnostic about a modifier, such
+	// as `optional` or `stream`, used where it is not permitted.
+	UnexpectedModifier = "protobuf:unexpected_modifier"
+
+	// MissingName is the tag for a diagnostic about a definition without a
+	// name.
+	MissingName = "protobuf:missing_name"
+
+	// MissingBody is the tag for a diagnostic about a definition without a
+	// body.
+	MissingBody = "protobuf:missing_body"
+
+	// MissingPath is the tag for a diagnostic about a package, import, or
+	// option without a path.
+	MissingPath = "protobuf:missing_path"
+
+	// MissingValue is the tag for a diagnostic about a declaration without a
+	// required value, such as a field without a field number.
+	MissingValue = "protobuf:missing_value"
+
+	// MissingSignature is the tag for a diagnostic about a method with a
+	// missing or incomplete signature.
+	MissingSignature = "protobuf:missing_signature"
+
+	// InvalidPath is the tag for a diagnostic about a malformed path, such as
+	// one that is too long or uses the wrong separators.
+	InvalidPath = "protobuf:invalid_path"
+
+	// InvalidReserved is the tag for a diagnostic about a malformed reserved
+	// declaration.
+	InvalidReserved = "protobuf:invalid_reserved"
+
+	// InvalidMap is the tag for a diagnostic about an invalid map type or use
+	// of a map entry type.
+	InvalidMap = "protobuf:invalid_map"
+
+	// InvalidGroup is the tag for a diagnostic about an invalid group
+	// definition.
+	InvalidGroup = "protobuf:invalid_group"
+
+	// InvalidLiteral is the tag for a diagnostic about a literal that is not
+	// valid Protobuf, such as a number with an unsupported base or suffix.
+	InvalidLiteral = "protobuf:invalid_literal"
+
+	// InvalidOptionValue is the tag for a diagnostic about a structurally
+	// invalid option value, such as a nested array.
+	InvalidOptionValue = "protobuf:invalid_option_value"
+
+	// NonCanonicalLiteral is the tag for a diagnostic about a literal that
+	// is accepted but written in a non-canonical form, such as a string with
+	// unnecessary escapes.
+	NonCanonicalLiteral = "protobuf:non_canonical_literal"
+
+	// Redundant is the tag for a diagnostic about syntax that has no effect
+	// and can be removed or simplified.
+	Redundant = "protobuf:redundant"
+
+	// DeprecatedSyntax is the tag for a diagnostic about a language construct
+	// that is deprecated, such as `required` fields or groups.
+	DeprecatedSyntax = "protobuf:deprecated_syntax"
+)
+
+// Tags for diagnostics about editions and syntax versions.
+const (
+	// RequiresNewerEdition is the tag for a diagnostic about a construct that
+	// is not available in the file's syntax or edition yet.
+	RequiresNewerEdition = "protobuf:requires_newer_edition"
+
+	// RemovedInEdition is the tag for a diagnostic about a construct that is
+	// no longer available in the file's syntax or edition.
+	RemovedInEdition = "protobuf:removed_in_edition"
+
+	// DeprecatedInEdition is the tag for a diagnostic about a construct that
+	// is deprecated in the file's syntax or edition.
+	DeprecatedInEdition = "protobuf:deprecated_in_edition"
+)
+
+// Tags for diagnostics about imports.
 const (
 	// UnusedImport is the tag for an unused import diagnostic.
 	UnusedImport = "protobuf:unused_import"
 
+	// ImportNotFound is the tag for a diagnostic about an import that could
+	// not be opened.
+	ImportNotFound = "protobuf:import_not_found"
+
+	// DuplicateImport is the tag for a diagnostic about a file that is
+	// imported more than once.
+	DuplicateImport = "protobuf:duplicate_import"
+
+	// ImportCycle is the tag for a diagnostic about a cycle of imports.
+	ImportCycle = "protobuf:import_cycle"
+
+	// InvalidImportPath is the tag for a diagnostic about a malformed import
+	// path.
+	InvalidImportPath = "protobuf:invalid_import_path"
+
+	// OptionOnlyImport is the tag for a diagnostic about a symbol from an
+	// `import option` that is used outside of an option.
+	OptionOnlyImport = "protobuf:option_only_import"
+
+	// FileNotFound is the tag for a diagnostic about a file that could not be
+	// opened.
+	FileNotFound = "protobuf:file_not_found"
+)
+
+// Tags for diagnostics about symbols and name resolution.
+const (
 	// UnknownSymbol is the tag for a diagnostic where a symbol cannot be found
 	// in the current scope. This may indicate a missing import or a reference
 	// to a non-existent symbol.
 	UnknownSymbol = "protobuf:unknown_symbol"
 
+	// UnknownField is the tag for a diagnostic about a field name that does
+	// not exist in a message, such as in an option.
+	UnknownField = "protobuf:unknown_field"
+
+	// WrongSymbolKind is the tag for a diagnostic where a symbol was found, but
+	// it is the wrong kind of symbol, such as a service where a type is
+	// expected.
+	WrongSymbolKind = "protobuf:wrong_symbol_kind"
+
+	// DuplicateSymbol is the tag for a diagnostic about two symbols with the
+	// same name.
+	DuplicateSymbol = "protobuf:duplicate_symbol"
+
+	// Visibility is the tag for a diagnostic about a symbol's visibility,
+	// such as a reference to an unexported type.
+	Visibility = "protobuf:visibility"
+
 	// Deprecated is the tag for a diagnostic where a symbol is deprecated.
 	Deprecated = "protobuf:deprecated"
+
+	// DeadDefinition is the tag for a diagnostic about a definition that is
+	// never referenced anywhere in a workspace. It is only emitted by
+	// ir.DiagnoseDeadDefinitions.
+	DeadDefinition = "protobuf:dead_definition"
+
+	// MergeConflict is the tag for a diagnostic about a declaration in an
+	// overlay file that conflicts with the file it is merged into. It is only
+	// emitted by the experimental/ast/merge package.
+	MergeConflict = "protobuf:merge_conflict"
+
+	// RecursiveMessage is the tag for a diagnostic about messages that
+	// contain themselves through their fields. It is only emitted by the
+	// experimental/ir/depgraph package.
+	RecursiveMessage = "protobuf:recursive_message"
+
+	// LayeringViolation is the tag for a diagnostic about an import of a
+	// package that the importing package is not allowed to depend on. It is
+	// only emitted by the experimental/ir/depgraph package.
+	LayeringViolation = "protobuf:layering_violation"
+
+	// MissingBuiltin is the tag for a diagnostic about a descriptor.proto
+	// that is missing a symbol the compiler requires.
+	MissingBuiltin = "protobuf:missing_builtin"
+
+	// NamingStyle is the tag for a diagnostic about a name that does not
+	// follow the required naming style.
+	NamingStyle = "protobuf:naming_style"
+)
+
+// Tags for diagnostics about definitions and their members.
+const (
+	// EmptyDefinition is the tag for a diagnostic about a definition that
+	// must contain at least one member, but contains none.
+	EmptyDefinition = "protobuf:empty_definition"
+
+	// DuplicateNumber is the tag for a diagnostic about a field or enum
+	// value number that is used more than once.
+	DuplicateNumber = "protobuf:duplicate_number"
+
+	// ReservedNumber is the tag for a diagnostic about the use of a reserved
+	// field, extension, or enum value number.
+	ReservedNumber = "protobuf:reserved_number"
+
+	// ReservedName is the tag for a diagnostic about the use of a reserved
+	// name.
+	ReservedName = "protobuf:reserved_name"
+
+	// DuplicateReserved is the tag for a diagnostic about a name or number
+	// that is reserved more than once.
+	DuplicateReserved = "protobuf:duplicate_reserved"
+
+	// InvalidRange is the tag for a diagnostic about an invalid reserved or
+	// extension range, such as an empty one.
+	InvalidRange = "protobuf:invalid_range"
+
+	// OverlappingRanges is the tag for a diagnostic about two ranges that
+	// overlap.
+	OverlappingRanges = "protobuf:overlapping_ranges"
+
+	// UnreservedExtension is the tag for a diagnostic about an extension
+	// whose number is not covered by an extension range.
+	UnreservedExtension = "protobuf:unreserved_extension"
+
+	// InvalidEnum is the tag for a diagnostic about an enum that violates a
+	// constraint on its values, such as an open enum whose first value is not
+	// zero.
+	InvalidEnum = "protobuf:invalid_enum"
+
+	// EnumValueConflict is the tag for a diagnostic about enum values whose
+	// names collide once the enum's prefix is removed.
+	EnumValueConflict = "protobuf:enum_value_conflict"
+
+	// JSONNameConflict is the tag for a diagnostic about two fields with the
+	// same JSON name.
+	JSONNameConflict = "protobuf:json_name_conflict"
+
+	// InvalidMessageSet is the tag for a diagnostic about an invalid use of
+	// message set wire format.
+	InvalidMessageSet = "protobuf:invalid_message_set"
+
+	// InvalidExtensionDecl is the tag for a diagnostic about an invalid
+	// extension declaration.
+	InvalidExtensionDecl = "protobuf:invalid_extension_declaration"
+
+	// MissingExtensionDecl is the tag for a diagnostic about an extension
+	// number that is not covered by an extension declaration.
+	MissingExtensionDecl = "protobuf:missing_extension_declaration"
+)
+
+// Tags for diagnostics about options and their values.
+const (
+	// InvalidOption is the tag for a diagnostic about an option that is
+	// set where it is not permitted, or set to a value that is not permitted.
+	InvalidOption = "protobuf:invalid_option"
+
+	// InvalidOptionTarget is the tag for a diagnostic about an option set on
+	// an entity it does not apply to.
+	InvalidOptionTarget = "protobuf:invalid_option_target"
+
+	// DuplicateOption is the tag for a diagnostic about a non-repeated option
+	// that is set more than once.
+	DuplicateOption = "protobuf:duplicate_option"
+
+	// TypeMismatch is the tag for a diagnostic about a value whose type does
+	// not match the type expected in its position.
+	TypeMismatch = "protobuf:type_mismatch"
+
+	// InvalidType is the tag for a diagnostic about a type that does not
+	// satisfy a constraint, such as expecting a message type.
+	InvalidType = "protobuf:invalid_type"
+
+	// OutOfRange is the tag for a diagnostic about a numeric value that does
+	// not fit in its type.
+	OutOfRange = "protobuf:out_of_range"
+
+	// InvalidUTF8 is the tag for a diagnostic about a string value that is
+	// not valid UTF-8.
+	InvalidUTF8 = "protobuf:invalid_utf8"
+
+	// InvalidAny is the tag for a diagnostic about an invalid
+	// `google.protobuf.Any` expression or type URL.
+	InvalidAny = "protobuf:invalid_any"
+
+	// InvalidFeature is the tag for a diagnostic about a misused editions
+	// feature.
+	InvalidFeature = "protobuf:invalid_feature"
+
+	// InvalidFeatureDefinition is the tag for a diagnostic about a feature
+	// field that is defined incorrectly.
+	InvalidFeatureDefinition = "protobuf:invalid_feature_definition"
+
+	// InvalidCEL is the tag for a diagnostic about a CEL expression in a
+	// `buf.validate` rule that does not type-check against the message or
+	// field it validates.
+	InvalidCEL = "protobuf:invalid_cel"
 )
@@ -14,7 +14,67 @@
 
 package report
 
-// styleSheet is the colors used for pretty-rendering diagnostics.
+import "strings"
+
+// StyleSheet configures the colors and glyphs a [Renderer] uses to draw
+// diagnostics.
+//
+// Colors are arbitrary escape sequences, typically ANSI SGR escapes such as
+// "\033[1;31m". They are only used when [Renderer].Colorize is set. Every
+// color must match the regular expression `\033\[[\d;]*m`, since the renderer
+// needs to be able to skip over them when measuring the width of a line.
+//
+// The zero value renders without color, using only ASCII.
+type StyleSheet struct {
+	// The escape that resets the terminal to its default style.
+	Reset string
+
+	// Colors for each diagnostic level. Remark is also used for note and help
+	// footers.
+	Error, Warning, Remark Color
+
+	// The color for "accents" such as non-primary span underlines, line
+	// numbers, and other rendering details, which clearly separates them from
+	// the source code.
+	Accent Color
+
+	// Colors for the insertions and deletions of a suggested edit.
+	Add, Delete Color
+
+	// If set, the frame, underlines and multi-line spans of a diagnostic window
+	// are drawn with Unicode box-drawing characters. Otherwise, only ASCII is
+	// used, since many terminals (and fonts) garble box-drawing characters.
+	Unicode bool
+}
+
+// Color is a pair of escapes for the regular and bold variants of a color in
+// a [StyleSheet].
+type Color struct {
+	Normal, Bold string
+}
+
+// DefaultStyleSheet returns the stylesheet that a [Renderer] uses when its
+// StyleSheet is nil.
+func DefaultStyleSheet() *StyleSheet {
+	return &StyleSheet{
+		Reset: "\033[0m",
+		// Red.
+		Error: Color{"\033[0;31m", "\033[1;31m"},
+		// Yellow.
+		Warning: Color{"\033[0;33m", "\033[1;33m"},
+		// Cyan.
+		Remark: Color{"\033[0;36m", "\033[1;36m"},
+		// Blue.
+		Accent: Color{"\033[0;34m", "\033[1;34m"},
+		// Green.
+		Add: Color{"\033[0;32m", "\033[1;32m"},
+		// Red.
+		Delete: Color{"\033[0;31m", "\033[1;31m"},
+	}
+}
+
+// styleSheet is the colors and glyphs used for pretty-rendering diagnostics,
+// resolved from a [StyleSheet].
 type styleSheet struct {
 	r Renderer
 
@@ -23,41 +83,80 @@ type styleSheet struct {
 	nError, nWarning, nRemark, nAccent, nAdd, nDelete string
 	// Bold colors.
 	bError, bWarning, bRemark, bAccent, bAdd, bDelete string
+
+	glyphs
 }
 
-func newStyleSheet(r Renderer) styleSheet {
-	if !r.Colorize {
-		return styleSheet{r: r}
-	}
+// glyphs is the characters used to draw the frame of a diagnostic window.
+type glyphs struct {
+	// The separator between line numbers and source code, including padding.
+	gutter string
+	// The markers before the first and subsequent file names in a diagnostic.
+	arrow, cont string
 
-	return styleSheet{
-		r:     r,
-		reset: "\033[0m",
-		// Red.
-		nError: "\033[0;31m",
-		bError: "\033[1;31m",
+	// Replaces the ASCII characters used to lay out underlines and multi-line
+	// spans with their Unicode equivalents. Nil when rendering ASCII.
+	box *strings.Replacer
+	// The pipe that connects an underline to its message.
+	pipe string
+}
 
-		// Yellow.
-		nWarning: "\033[0;33m",
-		bWarning: "\033[1;33m",
+var (
+	asciiGlyphs = glyphs{
+		gutter: " | ",
+		arrow:  "-->",
+		cont:   ":::",
+		pipe:   "|",
+	}
+	unicodeGlyphs = glyphs{
+		gutter: " │ ",
+		arrow:  " ╭▸",
+		cont:   " ├▸",
+		pipe:   "│",
+		box: strings.NewReplacer(
+			"|", "│",
+			"/", "╭",
+			"\\", "╰",
+			"_", "─",
+			"-", "╌",
+			"^", "━",
+		),
+	}
+)
 
-		// Cyan.
-		nRemark: "\033[0;36m",
-		bRemark: "\033[1;36m",
+func newStyleSheet(r Renderer) styleSheet {
+	sheet := r.StyleSheet
+	if sheet == nil {
+		sheet = DefaultStyleSheet()
+	}
 
-		// Blue. Used for "accents" such as non-primary span underlines, line
-		// numbers, and other rendering details to clearly separate them from
-		// the source code (which appears in white).
-		nAccent: "\033[0;34m",
-		bAccent: "\033[1;34m",
+	ss := styleSheet{r: r, glyphs: asciiGlyphs}
+	if sheet.Unicode {
+		ss.glyphs = unicodeGlyphs
+	}
+	if !r.Colorize {
+		return ss
+	}
 
-		// Green.
-		nAdd: "\033[0;32m",
-		bAdd: "\033[1;32m",
-		// Red.
-		nDelete: "\033[0;31m",
-		bDelete: "\033[1;31m",
+	ss.reset = sheet.Reset
+	ss.nError, ss.bError = sheet.Error.Normal, sheet.Error.Bold
+	ss.nWarning, ss.bWarning = sheet.Warning.Normal, sheet.Warning.Bold
+	ss.nRemark, ss.bRemark = sheet.Remark.Normal, sheet.Remark.Bold
+	ss.nAccent, ss.bAccent = sheet.Accent.Normal, sheet.Accent.Bold
+	ss.nAdd, ss.bAdd = sheet.Add.Normal, sheet.Add.Bold
+	ss.nDelete, ss.bDelete = sheet.Delete.Normal, sheet.Delete.Bold
+	return ss
+}
+
+// art converts a string of ASCII art, consisting of the characters used to
+// draw underlines and multi-line spans, into the glyphs of this stylesheet.
+//
+// Must not be called on strings that contain source code or messages.
+func (c styleSheet) art(s string) string {
+	if c.box == nil {
+		return s
 	}
+	return c.box.Replace(s)
 }
 
 // ColorForLevel returns the escape sequence for the non-bold color to use for
@@ -35,6 +35,11 @@ import (
 type File struct {
 	path, text string
 
+	// Set for files with a version number, such as editor buffers. See
+	// [NewVersionedFile].
+	version   int64
+	versioned bool
+
 	once sync.Once
 	// A prefix sum of the line lengths of text. Given a byte offset, it is possible
 	// to recover which line that offset is on by performing a binary search on this
@@ -50,6 +55,23 @@ func NewFile(path, text string) *File {
 	return &File{path: path, text: text}
 }
 
+// NewVersionedFile constructs a new source file with a version number.
+//
+// Versions are opaque to Protocompile, and are intended for tracking which
+// revision of an editor buffer a file corresponds to. See [Overlay].
+func NewVersionedFile(path, text string, version int64) *File {
+	return &File{path: path, text: text, version: version, versioned: true}
+}
+
+// Version returns this file's version number, if it has one.
+func (f *File) Version() (version int64, ok bool) {
+	if f == nil {
+		return 0, false
+	}
+
+	return f.version, f.versioned
+}
+
 // Path returns this file's filesystem path.
 //
 // It doesn't need to be a real path, but it will be used to deduplicate spans
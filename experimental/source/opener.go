@@ -19,6 +19,7 @@ import (
 	"io"
 	"io/fs"
 	"strings"
+	"sync"
 
 	"github.com/bufbuild/protocompile/internal/ext/cmpx"
 )
@@ -77,6 +78,77 @@ func (m Map) Open(path string) (*File, error) {
 	return file, nil
 }
 
+// Overlay implements [Opener] by layering in-memory files, such as unsaved
+// editor buffers, over another Opener.
+//
+// Each overlaid file has a version number, which is exposed via
+// [File.Version] on the files it returns. Overlay is safe to use from multiple
+// goroutines; however, callers that use it with an incremental executor
+// should modify it in a way that also evicts the affected queries. Package
+// queries provides a helper for this.
+type Overlay struct {
+	// The Opener to use for paths that are not overlaid. If nil, such paths
+	// result in [fs.ErrNotExist].
+	Base Opener
+
+	mu    sync.RWMutex
+	files map[string]*File
+}
+
+// Set overlays the file at path with the given text and version.
+//
+// If path is already overlaid with a version greater than or equal to this
+// one, this does nothing and returns false. This ensures that out-of-order
+// updates are ignored.
+func (o *Overlay) Set(path, text string, version int64) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if prev, ok := o.files[path]; ok && prev.version >= version {
+		return false
+	}
+	if o.files == nil {
+		o.files = make(map[string]*File)
+	}
+	o.files[path] = NewVersionedFile(path, text, version)
+	return true
+}
+
+// Clear removes the overlay for path, if there is one, so that it is once
+// again opened using o.Base. Returns whether there was an overlay to remove.
+func (o *Overlay) Clear(path string) bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	_, ok := o.files[path]
+	delete(o.files, path)
+	return ok
+}
+
+// Version returns the version of the overlay for path, if there is one.
+func (o *Overlay) Version(path string) (version int64, ok bool) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	return o.files[path].Version()
+}
+
+// Open implements [Opener].
+func (o *Overlay) Open(path string) (*File, error) {
+	o.mu.RLock()
+	file, ok := o.files[path]
+	o.mu.RUnlock()
+
+	switch {
+	case ok:
+		return file, nil
+	case o.Base != nil:
+		return o.Base.Open(path)
+	default:
+		return nil, fs.ErrNotExist
+	}
+}
+
 // FS wraps an [fs.FS] to give it an [Opener] interface.
 type FS struct {
 	fs.FS
@@ -75,3 +75,42 @@ func TestOpeners(t *testing.T) {
 	_, err = opener.Open("missing.txt")
 	require.ErrorIs(t, err, fs.ErrNotExist)
 }
+
+func TestOverlay(t *testing.T) {
+	t.Parallel()
+
+	base := source.NewMap(nil)
+	base.Add("a.proto", "disk a")
+	base.Add("b.proto", "disk b")
+	overlay := &source.Overlay{Base: base}
+
+	assert.True(t, overlay.Set("a.proto", "buffer a", 2))
+	assert.False(t, overlay.Set("a.proto", "stale a", 1))
+	assert.True(t, overlay.Set("c.proto", "buffer c", 1))
+
+	file, err := overlay.Open("a.proto")
+	require.NoError(t, err)
+	assert.Equal(t, "buffer a", file.Text())
+	version, ok := file.Version()
+	assert.True(t, ok)
+	assert.Equal(t, int64(2), version)
+
+	file, err = overlay.Open("b.proto")
+	require.NoError(t, err)
+	assert.Equal(t, "disk b", file.Text())
+	_, ok = file.Version()
+	assert.False(t, ok)
+
+	file, err = overlay.Open("c.proto")
+	require.NoError(t, err)
+	assert.Equal(t, "buffer c", file.Text())
+
+	assert.True(t, overlay.Clear("a.proto"))
+	assert.False(t, overlay.Clear("a.proto"))
+	file, err = overlay.Open("a.proto")
+	require.NoError(t, err)
+	assert.Equal(t, "disk a", file.Text())
+
+	_, err = overlay.Open("d.proto")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
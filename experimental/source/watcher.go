@@ -0,0 +1,165 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source
+
+import (
+	"crypto/sha256"
+	"io"
+	"io/fs"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Watcher wraps an [fs.FS] to give it an [Opener] interface, like [FS], but
+// also remembers the state of every file it has been asked to open, including
+// files that did not exist.
+//
+// This can then be used to determine which files have changed since they were
+// last opened, by calling [Watcher.Poll] periodically, or [Watcher.Check] in
+// response to a filesystem notification. Package queries provides a helper for
+// using this to evict stale queries from an executor.
+//
+// A zero Watcher is not ready to use; FS must be set.
+type Watcher struct {
+	fs.FS
+
+	// If not nil, paths are passed to this function before being forwarded
+	// to fs.
+	PathMapper func(string) string
+
+	mu     sync.Mutex
+	stamps map[string]stamp
+}
+
+// stamp is the state of a file as of the last time it was observed by a
+// [Watcher]. The zero stamp represents a missing file.
+type stamp struct {
+	exists  bool
+	modTime time.Time
+	size    int64
+	hash    [sha256.Size]byte
+}
+
+// Open implements [Opener].
+func (w *Watcher) Open(path string) (*File, error) {
+	name := w.name(path)
+	text, stamp, err := w.read(name)
+	w.record(path, stamp)
+	if err != nil {
+		return nil, err
+	}
+	return NewFile(name, text), nil
+}
+
+// Poll checks every file this watcher has opened, or attempted to open, for
+// changes, and returns the paths of those that changed, sorted.
+//
+// A file is considered changed if it was created, deleted, or its contents
+// differ from when it was last observed. Files whose modification time
+// changed but whose contents did not are not reported.
+//
+// Once a change is returned by Poll or [Watcher.Check], it is not reported
+// again.
+func (w *Watcher) Poll() []string {
+	w.mu.Lock()
+	paths := make([]string, 0, len(w.stamps))
+	for path := range w.stamps {
+		paths = append(paths, path)
+	}
+	w.mu.Unlock()
+
+	return w.Check(paths...)
+}
+
+// Check is like [Watcher.Poll], but only checks the given paths.
+//
+// Paths that this watcher has never been asked to open are ignored, since no
+// query can depend on them.
+func (w *Watcher) Check(paths ...string) []string {
+	var changed []string
+	for _, path := range paths {
+		w.mu.Lock()
+		prev, ok := w.stamps[path]
+		w.mu.Unlock()
+		if !ok {
+			continue
+		}
+
+		name := w.name(path)
+		if prev.exists {
+			// Avoid hashing the file if it looks unchanged.
+			info, err := fs.Stat(w.FS, name)
+			if err == nil && info.Size() == prev.size && info.ModTime().Equal(prev.modTime) {
+				continue
+			}
+		}
+
+		_, next, _ := w.read(name)
+		w.record(path, next)
+		if next.exists != prev.exists || next.hash != prev.hash {
+			changed = append(changed, path)
+		}
+	}
+
+	slices.Sort(changed)
+	return slices.Compact(changed)
+}
+
+// name returns the name of path in the underlying filesystem.
+func (w *Watcher) name(path string) string {
+	if w.PathMapper != nil {
+		return w.PathMapper(path)
+	}
+	return path
+}
+
+// read reads a file, and returns its contents along with its stamp.
+//
+// If an error occurs, the returned stamp is that of a missing file.
+func (w *Watcher) read(name string) (string, stamp, error) {
+	file, err := w.FS.Open(name)
+	if err != nil {
+		return "", stamp{}, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return "", stamp{}, err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return "", stamp{}, err
+	}
+
+	return string(data), stamp{
+		exists:  true,
+		modTime: info.ModTime(),
+		size:    info.Size(),
+		hash:    sha256.Sum256(data),
+	}, nil
+}
+
+// record records the latest stamp for a path.
+func (w *Watcher) record(path string, s stamp) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.stamps == nil {
+		w.stamps = make(map[string]stamp)
+	}
+	w.stamps[path] = s
+}
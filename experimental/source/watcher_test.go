@@ -0,0 +1,64 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package source_test
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bufbuild/protocompile/experimental/source"
+)
+
+func TestWatcher(t *testing.T) {
+	t.Parallel()
+
+	epoch := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	fsys := fstest.MapFS{
+		"a.proto": {Data: []byte("a"), ModTime: epoch},
+		"b.proto": {Data: []byte("b"), ModTime: epoch},
+	}
+	watcher := &source.Watcher{FS: fsys}
+
+	file, err := watcher.Open("a.proto")
+	require.NoError(t, err)
+	assert.Equal(t, "a", file.Text())
+	_, err = watcher.Open("b.proto")
+	require.NoError(t, err)
+	_, err = watcher.Open("c.proto")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+	assert.Empty(t, watcher.Poll())
+
+	// Touching a file without changing its contents is not a change.
+	fsys["a.proto"] = &fstest.MapFile{Data: []byte("a"), ModTime: epoch.Add(time.Second)}
+	assert.Empty(t, watcher.Poll())
+
+	fsys["b.proto"] = &fstest.MapFile{Data: []byte("B"), ModTime: epoch.Add(time.Second)}
+	delete(fsys, "a.proto")
+	fsys["c.proto"] = &fstest.MapFile{Data: []byte("c"), ModTime: epoch}
+	fsys["d.proto"] = &fstest.MapFile{Data: []byte("d"), ModTime: epoch}
+	assert.Equal(t, []string{"a.proto", "b.proto", "c.proto"}, watcher.Poll())
+	assert.Empty(t, watcher.Poll())
+
+	// Check only looks at the paths it is given.
+	fsys["b.proto"] = &fstest.MapFile{Data: []byte("bb"), ModTime: epoch}
+	fsys["c.proto"] = &fstest.MapFile{Data: []byte("cc"), ModTime: epoch}
+	assert.Equal(t, []string{"c.proto"}, watcher.Check("c.proto", "d.proto"))
+	assert.Equal(t, []string{"b.proto"}, watcher.Poll())
+}
@@ -190,10 +190,10 @@ var properties = [...]property{
 
 	Lt: valid | punct | protobuf | cel,
 	Gt: valid | punct | protobuf | cel,
-	Le: valid | punct,
-	Ge: valid | punct,
-	Eq: valid | punct,
-	Ne: valid | punct,
+	Le: valid | punct | cel,
+	Ge: valid | punct | cel,
+	Eq: valid | punct | cel,
+	Ne: valid | punct | cel,
 
 	Parens:       valid | punct | brackets | protobuf | cel,
 	Brackets:     valid | punct | brackets | protobuf | cel,
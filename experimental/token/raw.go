@@ -204,6 +204,10 @@ type synth struct {
 	// nil: it is nil for the closer.
 	otherEnd ID
 	children []ID
+
+	// Non-zero if this token stands in for a natural token that it replaced
+	// in the AST. See [Stream.Supersede].
+	supersedes ID
 }
 
 // Keyword returns the keyword for this token, if it is an identifier.
@@ -0,0 +1,127 @@
+// Copyright 2020-2026 Buf Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package token
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/bufbuild/protocompile/experimental/internal/tokenmeta"
+	"github.com/bufbuild/protocompile/experimental/source"
+)
+
+// Splice builds a new stream over file by replacing the token tree tree in s
+// with a token tree taken from with.
+//
+// file must be the result of editing the text within tree, and with must be a
+// stream over file that contains a token tree starting at the same offset as
+// tree and ending at the offset its closer was moved to by the edit, such as
+// one produced by re-lexing just that part of file. Tokens outside of tree are
+// copied from s, with their offsets and IDs adjusted to account for the edit.
+//
+// Returns false if with does not contain such a tree, or if either stream
+// contains synthetic tokens.
+func (s *Stream) Splice(file *source.File, tree Token, with *Stream) (*Stream, bool) {
+	if len(s.synths) > 0 || len(with.synths) > 0 ||
+		tree.IsZero() || tree.IsSynthetic() || tree.Context() != s || !tree.nat().IsOpen() {
+		return nil, false
+	}
+
+	open := naturalIndex(tree.ID())
+	close := open + tree.nat().Offset() //nolint:predeclared,revive // For close.
+	start, _ := tree.offsets()
+	textDelta := len(file.Text()) - len(s.Text())
+	end := int(s.nats[close].end) + textDelta
+
+	// Find the tokens in with that make up the replacement tree. We search by
+	// end offset, since that is what nat records.
+	search := func(offset int) (int, bool) {
+		return slices.BinarySearchFunc(with.nats, offset, func(n nat, offset int) int {
+			return cmp.Compare(int(n.end), offset)
+		})
+	}
+	lo := 0
+	if start > 0 {
+		idx, ok := search(start)
+		if !ok {
+			return nil, false
+		}
+		lo = idx + 1
+	}
+	hi, ok := search(end)
+	if !ok || lo >= hi || with.nats[lo].Offset() != hi-lo {
+		return nil, false
+	}
+	tokenDelta := (hi - lo) - (close - open)
+
+	out := &Stream{File: file}
+	out.nats = make([]nat, 0, len(s.nats)+tokenDelta)
+	out.nats = append(out.nats, s.nats[:open]...)
+	out.nats = append(out.nats, with.nats[lo:hi+1]...)
+	for _, n := range s.nats[close+1:] {
+		n.end = uint32(int(n.end) + textDelta)
+		out.nats = append(out.nats, n)
+	}
+
+	// Trees that enclose the spliced tree now have a different number of
+	// tokens between their open and close.
+	if tokenDelta != 0 {
+		for i := range open {
+			n := &out.nats[i]
+			if n.IsOpen() && i+n.Offset() > close {
+				diff := n.Offset() + tokenDelta
+				n.setOffset(diff)
+				out.nats[i+diff].setOffset(-diff)
+			}
+		}
+	}
+
+	for k, v := range s.meta {
+		switch idx := naturalIndex(k); {
+		case idx < open:
+			out.setMeta(k, v)
+		case idx > close:
+			if str, ok := v.(*tokenmeta.String); ok && len(str.Escapes) > 0 {
+				shifted := *str
+				shifted.Escapes = slices.Clone(str.Escapes)
+				for i := range shifted.Escapes {
+					shifted.Escapes[i].Start = uint32(int(shifted.Escapes[i].Start) + textDelta)
+					shifted.Escapes[i].End = uint32(int(shifted.Escapes[i].End) + textDelta)
+				}
+				v = &shifted
+			}
+			out.setMeta(k+ID(tokenDelta), v)
+		}
+	}
+	for k, v := range with.meta {
+		if idx := naturalIndex(k); idx >= lo && idx <= hi {
+			out.setMeta(ID(open+idx-lo+1), v)
+		}
+	}
+
+	return out, true
+}
+
+func (s *Stream) setMeta(k ID, v any) {
+	if s.meta == nil {
+		s.meta = make(map[ID]any)
+	}
+	s.meta[k] = v
+}
+
+// setOffset updates the offset to this token's matching open/close.
+func (t *nat) setOffset(diff int) {
+	t.metadata = t.metadata&(1<<offsetShift-1) | int32(diff)<<offsetShift
+}
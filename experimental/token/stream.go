@@ -20,6 +20,8 @@ import (
 	"iter"
 	"math"
 	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/bufbuild/protocompile/experimental/id"
 	"github.com/bufbuild/protocompile/experimental/internal/tokenmeta"
@@ -216,6 +218,41 @@ func (s *Stream) NewString(text string) Token {
 	})
 }
 
+// NewInt mints a new synthetic number token for the given integer, written
+// in decimal.
+func (s *Stream) NewInt(v uint64) Token {
+	return s.newSynth(synth{
+		text: strconv.FormatUint(v, 10),
+		kind: Number,
+	})
+}
+
+// NewFloat mints a new synthetic number token for the given floating-point
+// value, which is always formatted so that it lexes as a float.
+//
+// Panics if v is negative, infinite, or NaN; these values are not expressible
+// as a single number token.
+func (s *Stream) NewFloat(v float64) Token {
+	if v < 0 || math.IsInf(v, 0) || math.IsNaN(v) {
+		panic(fmt.Sprintf("protocompile/token: called NewFloat() with non-literal value %v", v))
+	}
+
+	text := strconv.FormatFloat(v, 'g', -1, 64)
+	if !strings.ContainsAny(text, ".e") {
+		text += ".0"
+	}
+
+	tok := s.newSynth(synth{
+		text: text,
+		kind: Number,
+	})
+	meta := MutateMeta[tokenmeta.Number](tok)
+	meta.Base = 10
+	meta.IsFloat = true
+	meta.Word = math.Float64bits(v)
+	return tok
+}
+
 // NewFused mints a new synthetic open/close pair using the given tokens.
 //
 // Panics if either open or close is natural or non-leaf.
@@ -236,6 +273,24 @@ func (s *Stream) NewFused(openTok, closeTok Token, children ...Token) {
 	closeTok.synth().otherEnd = openTok.ID()
 }
 
+// Supersede records that the synthetic leaf token tok replaces the natural
+// leaf token orig, such as when an edit renames a field.
+//
+// A superseding token reports orig's span, and tools that key information on
+// natural tokens, such as the printer's comment attachment, may treat it as
+// if it were orig.
+//
+// Panics if tok is not a synthetic leaf, or if orig is not a natural leaf.
+func (s *Stream) Supersede(tok, orig Token) {
+	if !tok.IsSynthetic() || !tok.IsLeaf() {
+		panic("protocompile/token: called Supersede() with a natural or non-leaf token")
+	}
+	if orig.IsZero() || orig.IsSynthetic() || !orig.IsLeaf() {
+		panic("protocompile/token: called Supersede() on a synthetic or non-leaf token")
+	}
+	tok.synth().supersedes = orig.ID()
+}
+
 func (s *Stream) newSynth(tok synth) Token {
 	raw := ID(^len(s.synths))
 	s.synths = append(s.synths, tok)
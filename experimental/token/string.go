@@ -44,6 +44,9 @@ func (s StringToken) Text() string {
 	if s.Raw() != nil && s.Raw().Text != "" {
 		return s.Raw().Text
 	}
+	if synth := s.Token().synth(); synth != nil {
+		return synth.text
+	}
 	return s.RawContent().Text()
 }
 
@@ -191,11 +191,27 @@ func (t Token) SetKind(k Kind) {
 	}
 }
 
+// Supersedes returns the natural token that this token replaced, if this is a
+// synthetic token passed to [Stream.Supersede]. Otherwise, returns [Zero].
+func (t Token) Supersedes() Token {
+	synth := t.synth()
+	if synth == nil || synth.supersedes == 0 {
+		return Zero
+	}
+	return id.Wrap(t.Context(), synth.supersedes)
+}
+
 // Span implements [Spanner].
+//
+// Synthetic tokens have a zero span, unless they supersede a natural token,
+// in which case they report its span.
 func (t Token) Span() source.Span {
-	if t.IsZero() || t.IsSynthetic() {
+	if t.IsZero() {
 		return source.Span{}
 	}
+	if t.IsSynthetic() {
+		return t.Supersedes().Span()
+	}
 
 	var a, b int
 	if !t.IsLeaf() {
@@ -211,9 +227,12 @@ func (t Token) Span() source.Span {
 
 // LeafSpan returns the span that this token would have if it was a leaf token.
 func (t Token) LeafSpan() source.Span {
-	if t.IsZero() || t.IsSynthetic() {
+	if t.IsZero() {
 		return source.Span{}
 	}
+	if t.IsSynthetic() {
+		return t.Supersedes().LeafSpan()
+	}
 
 	return t.Context().Span(t.offsets())
 }
@@ -302,6 +321,32 @@ func Fuse(open, close Token) { //nolint:predeclared,revive // For close.
 	fuseImpl(int32(close.ID()-open.ID()), impl1, impl2)
 }
 
+// Unfuse reverses [Fuse], turning the token tree tok back into a pair of leaf
+// tokens.
+//
+// If tok is synthetic, a leaf, or part of a frozen [Stream], this function
+// panics.
+func Unfuse(tok Token) {
+	if tok.Context().frozen {
+		panic("protocompile/token: attempted to mutate frozen stream")
+	}
+	if tok.IsSynthetic() {
+		panic("protocompile/token: called Unfuse() with a synthetic token")
+	}
+	if tok.IsLeaf() {
+		panic("protocompile/token: called Unfuse() with a leaf token")
+	}
+
+	start, end := tok.StartEnd()
+	open, close, _ := start.Keyword().Brackets() //nolint:predeclared,revive // For close.
+	unfuse := func(t Token, kw keyword.Keyword) {
+		impl := t.nat()
+		impl.metadata = int32(impl.Kind()) | int32(kw)<<keywordShift
+	}
+	unfuse(start, open)
+	unfuse(end, close)
+}
+
 // Children returns a Cursor over the children of this token.
 //
 // If the token is zero or is a leaf token, returns nil.
@@ -75,6 +75,40 @@ func TestLeafTokens(t *testing.T) {
 	tokensEq(t, slices.Collect(jkl.Children().Rest()))
 }
 
+func TestSupersede(t *testing.T) {
+	t.Parallel()
+	assert := assert.New(t)
+
+	s := &token.Stream{
+		File: source.NewFile("test", "abc 42"),
+	}
+
+	abc := s.Push(3, token.Ident)
+	s.Push(1, token.Space)
+	num := s.Push(2, token.Number)
+
+	xyz := s.NewIdent("xyz")
+	assert.True(xyz.Span().IsZero())
+	assert.True(xyz.Supersedes().IsZero())
+
+	s.Supersede(xyz, abc)
+	assert.Equal(abc, xyz.Supersedes())
+	assert.Equal(abc.Span(), xyz.Span())
+	assert.Equal(abc.LeafSpan(), xyz.LeafSpan())
+	assert.Equal("xyz", xyz.Text())
+	assert.True(xyz.IsSynthetic())
+
+	seven := s.NewInt(7)
+	assert.Equal(token.Number, seven.Kind())
+	assert.Equal("7", seven.Text())
+	v, exact := seven.AsNumber().Int()
+	assert.Equal(uint64(7), v)
+	assert.True(exact)
+
+	assert.Panics(func() { s.Supersede(abc, num) })
+	assert.Panics(func() { s.Supersede(seven, xyz) })
+}
+
 func TestTreeTokens(t *testing.T) {
 	t.Parallel()
 	assert := assert.New(t)
@@ -137,7 +137,7 @@ func (a *Arena[T]) New(value T) *T {
 // compressing the pointer.
 func (a *Arena[T]) NewCompressed(value T) Pointer[T] {
 	_ = a.New(value)
-	return Pointer[T](a.len()) // Note that len, not len-1, is intentional.
+	return Pointer[T](a.Len()) // Note that len, not len-1, is intentional.
 }
 
 // Compress returns a compressed pointer into this arena if ptr belongs to it;
@@ -190,7 +190,8 @@ func (a *Arena[T]) Values() iter.Seq[*T] {
 	}
 }
 
-func (a *Arena[T]) len() int {
+// Len returns the number of values allocated on this arena.
+func (a *Arena[T]) Len() int {
 	if len(a.table) == 0 {
 		return 0
 	}
@@ -240,7 +241,7 @@ func (a *Arena[T]) lenOfFirstNSlices(n int) int {
 // coordinates calculates the coordinates of the given index in table. It
 // also performs a bounds check.
 func (a *Arena[T]) coordinates(idx int) (int, int) {
-	if idx >= a.len() || idx < 0 {
+	if idx >= a.Len() || idx < 0 {
 		panic(fmt.Sprintf("arena: pointer out of range: %#x", idx))
 	}
 
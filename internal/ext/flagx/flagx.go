@@ -25,7 +25,9 @@ import (
 // Main should be called from a main function. It is a wrapper for making it
 // easy to have a main that returns an error.
 //
-// This function terminates the program when main returns.
+// This function terminates the program when main returns. The exit code is 1
+// if main returns an error, unless it comes from [Exit] or is an
+// [exec.ExitError].
 func Main(main func() error) {
 	flag.Parse()
 
@@ -35,10 +37,37 @@ func Main(main func() error) {
 	}
 
 	exit := 1
-	if exec := (*exec.ExitError)(nil); errors.As(err, &exec) {
+	if code := (*exitError)(nil); errors.As(err, &code) {
+		if code.err == nil {
+			os.Exit(code.code)
+		}
+		exit = code.code
+	} else if exec := (*exec.ExitError)(nil); errors.As(err, &exec) {
 		exit = exec.ExitCode()
 	}
 
 	fmt.Fprintf(os.Stderr, "%v\n", err)
 	os.Exit(exit)
 }
+
+// Exit returns an error that makes [Main] exit with the given code, after
+// printing err. If err is nil, nothing is printed.
+func Exit(code int, err error) error {
+	return &exitError{code, err}
+}
+
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}